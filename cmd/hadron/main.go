@@ -2,33 +2,72 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/urfave/cli/v2"
+
+	"github.com/the-agent-c-ai/hadron/sdk"
 )
 
 const (
-	flagNamePlan     = "plan"
-	goCommandRunVerb = "run"
+	flagNamePlan      = "plan"
+	flagNameManifest  = "manifest"
+	flagNameYes       = "yes"
+	flagNameGC        = "gc"
+	flagNameContainer = "container"
+	flagNameFollow    = "follow"
+	flagNameTail      = "tail"
+	flagNameOnly      = "only"
+	flagNameSkip      = "skip"
+	flagNameHost      = "host"
+	flagNameTimeout   = "timeout"
+	flagNameForce     = "force"
+	flagNameRecreate  = "force-recreate"
+	goCommandRunVerb  = "run"
+
+	// timeoutGrace is how long a plan gets past --timeout to stop on its own before it is killed.
+	timeoutGrace = 30 * time.Second
 )
 
-var errPlanFileNotFound = errors.New("plan file not found")
+// Build metadata, injected with -ldflags "-X main.version=... -X main.commit=... -X main.date=...".
+// When unset (e.g. go install), buildInfo falls back to what the Go toolchain recorded.
+var version, commit, date string
+
+var (
+	errPlanFileNotFound     = errors.New("plan file not found")
+	errPlanSourceRequired   = errors.New("exactly one of --plan or --manifest is required")
+	errConfirmationRequired = errors.New("destroy removes all plan resources, re-run with --yes to confirm")
+	errExecCommandRequired  = errors.New("exec requires a command after --")
+	errTimeout              = errors.New("plan did not finish within --timeout")
+)
 
 func main() {
 	// Configure zerolog
 	zerolog.TimeFieldFormat = time.RFC3339
 	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
 
+	cli.VersionPrinter = func(c *cli.Context) {
+		_, _ = fmt.Fprintln(c.App.Writer, versionString())
+	}
+
 	app := &cli.App{
-		Name:  "hadron",
-		Usage: "Declarative Docker deployment tool",
+		Name:    "hadron",
+		Usage:   "Declarative Docker deployment tool",
+		Version: versionString(),
 		Flags: []cli.Flag{
 			&cli.StringFlag{
 				Name:    "log-level",
@@ -48,20 +87,66 @@ func main() {
 			return nil
 		},
 		Commands: []*cli.Command{
+			{
+				Name:  "version",
+				Usage: "Print the hadron version, git commit, and build date",
+				Action: func(c *cli.Context) error {
+					cli.VersionPrinter(c)
+
+					return nil
+				},
+			},
 			{
 				Name:  "deploy",
 				Usage: "Deploy a plan to remote hosts",
 				Flags: []cli.Flag{
 					&cli.StringFlag{
-						Name:     flagNamePlan,
-						Aliases:  []string{"p"},
-						Required: true,
-						Usage:    "Path to the deployment plan (Go file)",
+						Name:    flagNamePlan,
+						Aliases: []string{"p"},
+						Usage:   "Path to the deployment plan (Go file)",
+					},
+					&cli.StringFlag{
+						Name:    flagNameManifest,
+						Aliases: []string{"m"},
+						Usage:   "Path to a declarative plan manifest (YAML or JSON)",
 					},
 					&cli.BoolFlag{
 						Name:  "dry-run",
 						Usage: "Show what would be deployed without executing",
 					},
+					&cli.BoolFlag{
+						Name:    flagNameYes,
+						Aliases: []string{"y"},
+						Usage:   "Confirm destructive actions such as recreating changed volumes",
+					},
+					&cli.BoolFlag{
+						Name:  flagNameGC,
+						Usage: "Remove uploaded files no container mounts anymore after deploying",
+					},
+					&cli.BoolFlag{
+						Name:  flagNameForce,
+						Usage: "Overwrite containers modified outside of Hadron when the plan refuses drift",
+					},
+					&cli.StringSliceFlag{
+						Name:  flagNameRecreate,
+						Usage: "Recreate these containers (comma-separated, or \"all\") even if unchanged",
+					},
+					&cli.StringSliceFlag{
+						Name:  flagNameOnly,
+						Usage: "Deploy only these containers (comma-separated) and their dependencies",
+					},
+					&cli.StringSliceFlag{
+						Name:  flagNameSkip,
+						Usage: "Leave these containers (comma-separated) untouched",
+					},
+					&cli.StringSliceFlag{
+						Name:  flagNameHost,
+						Usage: "Deploy only to these hosts (comma-separated endpoints or addresses)",
+					},
+					&cli.DurationFlag{
+						Name:  flagNameTimeout,
+						Usage: "Abort the deploy if it runs longer than this (e.g. 15m); 0 waits forever",
+					},
 				},
 				Action: deploy,
 			},
@@ -75,9 +160,112 @@ func main() {
 						Required: true,
 						Usage:    "Path to the deployment plan (Go file)",
 					},
+					&cli.BoolFlag{
+						Name:    flagNameYes,
+						Aliases: []string{"y"},
+						Usage:   "Confirm removal of all plan resources",
+					},
+					&cli.StringSliceFlag{
+						Name:  flagNameHost,
+						Usage: "Destroy only the resources on these hosts (comma-separated endpoints or addresses)",
+					},
+					&cli.DurationFlag{
+						Name:  flagNameTimeout,
+						Usage: "Abort the destroy if it runs longer than this (e.g. 15m); 0 waits forever",
+					},
 				},
 				Action: destroy,
 			},
+			{
+				Name:  "render",
+				Usage: "Print the docker commands a plan would run, without executing them",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    flagNamePlan,
+						Aliases: []string{"p"},
+						Usage:   "Path to the deployment plan (Go file)",
+					},
+					&cli.StringFlag{
+						Name:    flagNameManifest,
+						Aliases: []string{"m"},
+						Usage:   "Path to a declarative plan manifest (YAML or JSON)",
+					},
+				},
+				Action: render,
+			},
+			{
+				Name:  "status",
+				Usage: "Show which plan resources exist on the hosts, their drift, and container health",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    flagNamePlan,
+						Aliases: []string{"p"},
+						Usage:   "Path to the deployment plan (Go file)",
+					},
+					&cli.StringFlag{
+						Name:    flagNameManifest,
+						Aliases: []string{"m"},
+						Usage:   "Path to a declarative plan manifest (YAML or JSON)",
+					},
+				},
+				Action: status,
+			},
+			{
+				Name:  "logs",
+				Usage: "Show a plan container's logs, resolving its host from the plan",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    flagNamePlan,
+						Aliases: []string{"p"},
+						Usage:   "Path to the deployment plan (Go file)",
+					},
+					&cli.StringFlag{
+						Name:    flagNameManifest,
+						Aliases: []string{"m"},
+						Usage:   "Path to a declarative plan manifest (YAML or JSON)",
+					},
+					&cli.StringFlag{
+						Name:     flagNameContainer,
+						Aliases:  []string{"c"},
+						Required: true,
+						Usage:    "Name of the container in the plan",
+					},
+					&cli.BoolFlag{
+						Name:    flagNameFollow,
+						Aliases: []string{"f"},
+						Usage:   "Keep streaming new log output",
+					},
+					&cli.IntFlag{
+						Name:  flagNameTail,
+						Usage: "Number of lines to show from the end of the logs (0 shows all)",
+					},
+				},
+				Action: logs,
+			},
+			{
+				Name:      "exec",
+				Usage:     "Run a command in a plan container, resolving its host from the plan",
+				ArgsUsage: "-- COMMAND [ARG...]",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    flagNamePlan,
+						Aliases: []string{"p"},
+						Usage:   "Path to the deployment plan (Go file)",
+					},
+					&cli.StringFlag{
+						Name:    flagNameManifest,
+						Aliases: []string{"m"},
+						Usage:   "Path to a declarative plan manifest (YAML or JSON)",
+					},
+					&cli.StringFlag{
+						Name:     flagNameContainer,
+						Aliases:  []string{"c"},
+						Required: true,
+						Usage:    "Name of the container in the plan",
+					},
+				},
+				Action: execInContainer,
+			},
 		},
 	}
 
@@ -88,81 +276,412 @@ func main() {
 
 func deploy(c *cli.Context) error {
 	planPath := c.String(flagNamePlan)
+	manifestPath := c.String(flagNameManifest)
 	dryRun := c.Bool("dry-run")
 
-	// Determine if planPath is a directory or file
-	stat, err := os.Stat(planPath)
-	if err != nil {
-		return fmt.Errorf("%w: %s", errPlanFileNotFound, planPath)
+	if (planPath == "") == (manifestPath == "") {
+		return errPlanSourceRequired
 	}
 
-	var planDir string
-
-	var args []string
+	if manifestPath != "" {
+		return deployManifest(c, manifestPath, dryRun)
+	}
 
-	if stat.IsDir() {
-		// Directory: go run .
-		planDir = planPath
-		args = []string{goCommandRunVerb, "."}
-	} else {
-		// File: go run basename
-		planDir = filepath.Dir(planPath)
-		args = []string{goCommandRunVerb, filepath.Base(planPath)}
+	planDir, args, err := goRunArgs(planPath)
+	if err != nil {
+		return err
 	}
 
 	log.Info().Str("plan", planPath).Bool("dry-run", dryRun).Msg("Deploying plan")
 
+	ctx, cancel := timeoutContext(c)
+	defer cancel()
+
 	// Execute go run on the plan
 	//nolint:gosec
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("HADRON_DRY_RUN=%t", dryRun),
+		"HADRON_TIMEOUT="+c.Duration(flagNameTimeout).String(),
+		fmt.Sprintf("HADRON_CONFIRM=%t", c.Bool(flagNameYes)),
+		fmt.Sprintf("HADRON_GC=%t", c.Bool(flagNameGC)),
+		fmt.Sprintf("HADRON_FORCE=%t", c.Bool(flagNameForce)),
+		"HADRON_FORCE_RECREATE="+strings.Join(c.StringSlice(flagNameRecreate), ","),
+		"HADRON_ONLY="+strings.Join(c.StringSlice(flagNameOnly), ","),
+		"HADRON_SKIP="+strings.Join(c.StringSlice(flagNameSkip), ","),
+		"HADRON_HOSTS="+strings.Join(c.StringSlice(flagNameHost), ","),
+	)
+	cmd.Dir = planDir
+
+	if err := runPlan(c, cmd); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func destroy(c *cli.Context) error {
+	planPath := c.String(flagNamePlan)
+
+	// Destroy is always destructive, so refuse before running the plan at all
+	if !c.Bool(flagNameYes) {
+		return errConfirmationRequired
+	}
+
+	planDir, args, err := goRunArgs(planPath)
+	if err != nil {
+		return err
+	}
+
+	log.Info().Str("plan", planPath).Msg("Destroying resources")
+
+	ctx, cancel := timeoutContext(c)
+	defer cancel()
+
+	// Execute go run on the plan with destroy mode
+	//nolint:gosec
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), destroyEnv(c)...)
+	cmd.Dir = planDir
+
+	if err := runPlan(c, cmd); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// destroyEnv returns the HADRON_* variables that tell the plan process to destroy its resources.
+func destroyEnv(c *cli.Context) []string {
+	return []string{
+		"HADRON_DESTROY=true",
+		"HADRON_CONFIRM=true",
+		"HADRON_TIMEOUT=" + c.Duration(flagNameTimeout).String(),
+		"HADRON_HOSTS=" + strings.Join(c.StringSlice(flagNameHost), ","),
+	}
+}
+
+// deployManifest builds a plan from a declarative manifest and runs it in-process.
+func deployManifest(c *cli.Context, manifestPath string, dryRun bool) error {
+	plan, err := sdk.PlanFromManifest(manifestPath)
+	if err != nil {
+		return fmt.Errorf("failed to load manifest: %w", err)
+	}
+
+	plan.WithLogger(log.Logger)
+
+	if c.Bool(flagNameYes) {
+		plan.RequireConfirmation(func() bool { return true })
+	}
+
+	if c.Bool(flagNameGC) {
+		plan.WithGarbageCollection()
+	}
+
+	plan.Only(c.StringSlice(flagNameOnly)...).
+		Skip(c.StringSlice(flagNameSkip)...).
+		ForceRecreate(c.StringSlice(flagNameRecreate)...).
+		TargetHosts(c.StringSlice(flagNameHost)...).
+		DeployTimeout(c.Duration(flagNameTimeout))
+
+	log.Info().Str("manifest", manifestPath).Bool("dry-run", dryRun).Msg("Deploying manifest")
+
+	if dryRun {
+		if err := plan.DryRun(); err != nil {
+			return fmt.Errorf("dry run failed: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := plan.Execute(context.Background()); err != nil {
+		return fmt.Errorf("failed to execute manifest: %w", err)
+	}
+
+	return nil
+}
+
+func render(c *cli.Context) error {
+	planPath := c.String(flagNamePlan)
+	manifestPath := c.String(flagNameManifest)
+
+	if (planPath == "") == (manifestPath == "") {
+		return errPlanSourceRequired
+	}
+
+	if manifestPath != "" {
+		plan, err := sdk.PlanFromManifest(manifestPath)
+		if err != nil {
+			return fmt.Errorf("failed to load manifest: %w", err)
+		}
+
+		if err := plan.WithLogger(log.Logger).Render(os.Stdout); err != nil {
+			return fmt.Errorf("failed to render manifest: %w", err)
+		}
+
+		return nil
+	}
+
+	planDir, args, err := goRunArgs(planPath)
+	if err != nil {
+		return err
+	}
+
+	// Execute go run on the plan in render mode (the plan calls plan.Render)
+	//nolint:gosec
 	cmd := exec.Command("go", args...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-	cmd.Env = append(os.Environ(), fmt.Sprintf("HADRON_DRY_RUN=%t", dryRun))
+	cmd.Env = append(os.Environ(), "HADRON_RENDER=true")
 	cmd.Dir = planDir
 
 	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to execute plan: %w", err)
+		return fmt.Errorf("failed to render plan: %w", err)
 	}
 
 	return nil
 }
 
-func destroy(c *cli.Context) error {
+func status(c *cli.Context) error {
 	planPath := c.String(flagNamePlan)
+	manifestPath := c.String(flagNameManifest)
 
-	// Determine if planPath is a directory or file
-	stat, err := os.Stat(planPath)
+	if (planPath == "") == (manifestPath == "") {
+		return errPlanSourceRequired
+	}
+
+	if manifestPath != "" {
+		plan, err := sdk.PlanFromManifest(manifestPath)
+		if err != nil {
+			return fmt.Errorf("failed to load manifest: %w", err)
+		}
+
+		if err := plan.WithLogger(log.Logger).Status(c.Context, os.Stdout); err != nil {
+			return fmt.Errorf("failed to get manifest status: %w", err)
+		}
+
+		return nil
+	}
+
+	planDir, args, err := goRunArgs(planPath)
 	if err != nil {
-		return fmt.Errorf("%w: %s", errPlanFileNotFound, planPath)
+		return err
 	}
 
-	var planDir string
+	// Execute go run on the plan in status mode (the plan calls plan.Status)
+	//nolint:gosec
+	cmd := exec.Command("go", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), "HADRON_STATUS=true")
+	cmd.Dir = planDir
 
-	var args []string
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to get plan status: %w", err)
+	}
 
-	if stat.IsDir() {
-		// Directory: go run .
-		planDir = planPath
-		args = []string{goCommandRunVerb, "."}
-	} else {
-		// File: go run basename
-		planDir = filepath.Dir(planPath)
-		args = []string{goCommandRunVerb, filepath.Base(planPath)}
+	return nil
+}
+
+func logs(c *cli.Context) error {
+	planPath := c.String(flagNamePlan)
+	manifestPath := c.String(flagNameManifest)
+
+	if (planPath == "") == (manifestPath == "") {
+		return errPlanSourceRequired
 	}
 
-	log.Info().Str("plan", planPath).Msg("Destroying resources")
+	if manifestPath != "" {
+		plan, err := sdk.PlanFromManifest(manifestPath)
+		if err != nil {
+			return fmt.Errorf("failed to load manifest: %w", err)
+		}
 
-	// Execute go run on the plan with destroy mode
+		opts := sdk.LogsOptions{Follow: c.Bool(flagNameFollow), Tail: c.Int(flagNameTail)}
+
+		err = plan.WithLogger(log.Logger).Logs(c.String(flagNameContainer), opts, os.Stdout, os.Stderr)
+		if err != nil {
+			return fmt.Errorf("failed to show logs: %w", err)
+		}
+
+		return nil
+	}
+
+	planDir, args, err := goRunArgs(planPath)
+	if err != nil {
+		return err
+	}
+
+	// Execute go run on the plan in logs mode (the plan calls plan.Logs)
 	//nolint:gosec
 	cmd := exec.Command("go", args...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-	cmd.Env = append(os.Environ(), "HADRON_DESTROY=true")
+	cmd.Env = append(os.Environ(),
+		"HADRON_LOGS="+c.String(flagNameContainer),
+		fmt.Sprintf("HADRON_LOGS_FOLLOW=%t", c.Bool(flagNameFollow)),
+		fmt.Sprintf("HADRON_LOGS_TAIL=%d", c.Int(flagNameTail)),
+	)
 	cmd.Dir = planDir
 
 	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to execute plan: %w", err)
+		return fmt.Errorf("failed to show logs: %w", err)
 	}
 
 	return nil
 }
+
+func execInContainer(c *cli.Context) error {
+	planPath := c.String(flagNamePlan)
+	manifestPath := c.String(flagNameManifest)
+	command := c.Args().Slice()
+
+	if (planPath == "") == (manifestPath == "") {
+		return errPlanSourceRequired
+	}
+
+	if len(command) == 0 {
+		return errExecCommandRequired
+	}
+
+	if manifestPath != "" {
+		plan, err := sdk.PlanFromManifest(manifestPath)
+		if err != nil {
+			return fmt.Errorf("failed to load manifest: %w", err)
+		}
+
+		if err := plan.WithLogger(log.Logger).Exec(c.String(flagNameContainer), command...); err != nil {
+			return fmt.Errorf("failed to exec: %w", err)
+		}
+
+		return nil
+	}
+
+	planDir, args, err := goRunArgs(planPath)
+	if err != nil {
+		return err
+	}
+
+	// The command is passed as JSON so arguments keep their boundaries
+	encoded, err := json.Marshal(command)
+	if err != nil {
+		return fmt.Errorf("failed to encode exec command: %w", err)
+	}
+
+	// Execute go run on the plan in exec mode (the plan calls plan.Exec), attached to this terminal
+	//nolint:gosec
+	cmd := exec.Command("go", args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"HADRON_EXEC="+c.String(flagNameContainer),
+		"HADRON_EXEC_COMMAND="+string(encoded),
+	)
+	cmd.Dir = planDir
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to exec: %w", err)
+	}
+
+	return nil
+}
+
+// goRunArgs returns the directory to run a plan from and the go arguments that run it: "go run ."
+// for a plan directory, "go run <file>" for a single plan file.
+func goRunArgs(planPath string) (string, []string, error) {
+	stat, err := os.Stat(planPath)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %s", errPlanFileNotFound, planPath)
+	}
+
+	if stat.IsDir() {
+		return planPath, []string{goCommandRunVerb, "."}, nil
+	}
+
+	return filepath.Dir(planPath), []string{goCommandRunVerb, filepath.Base(planPath)}, nil
+}
+
+// timeoutContext returns the context a plan subprocess runs under: bounded by --timeout plus a grace
+// period, so a plan honoring HADRON_TIMEOUT can stop cleanly before it is stopped, and cancelled on
+// interrupt, since the plan runs in its own process group and does not see the terminal's signals.
+func timeoutContext(c *cli.Context) (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(c.Context, os.Interrupt, syscall.SIGTERM)
+
+	timeout := c.Duration(flagNameTimeout)
+	if timeout <= 0 {
+		return ctx, stop
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout+timeoutGrace)
+
+	return ctx, func() {
+		cancel()
+		stop()
+	}
+}
+
+// runPlan runs a plan subprocess, reporting a clear error when it exceeded --timeout.
+func runPlan(c *cli.Context, cmd *exec.Cmd) error {
+	timeout := c.Duration(flagNameTimeout)
+	start := time.Now()
+
+	// Once the context is done, the plan's process group is stopped; give it a moment to exit
+	stopGroupOnCancel(cmd)
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	if err == nil {
+		return nil
+	}
+
+	if timeout > 0 && time.Since(start) >= timeout {
+		return fmt.Errorf("%w (%s): %w", errTimeout, timeout, err)
+	}
+
+	return fmt.Errorf("failed to execute plan: %w", err)
+}
+
+// versionString describes the binary for bug reports: version, commit, build date, and Go toolchain.
+func versionString() string {
+	buildVersion, buildCommit, buildDate := buildInfo()
+
+	return fmt.Sprintf("hadron %s (commit %s, built %s, %s %s/%s)",
+		buildVersion, buildCommit, buildDate, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+}
+
+// buildInfo returns the injected build metadata, filling gaps from the module and VCS information
+// the Go toolchain embeds in the binary.
+func buildInfo() (string, string, string) {
+	buildVersion, buildCommit, buildDate := version, commit, date
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if buildVersion == "" && info.Main.Version != "" {
+			buildVersion = info.Main.Version
+		}
+
+		for _, setting := range info.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && buildCommit == "":
+				buildCommit = setting.Value
+			case setting.Key == "vcs.time" && buildDate == "":
+				buildDate = setting.Value
+			}
+		}
+	}
+
+	return orUnknown(buildVersion), orUnknown(buildCommit), orUnknown(buildDate)
+}
+
+// orUnknown replaces missing build metadata with "unknown".
+func orUnknown(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+
+	return value
+}
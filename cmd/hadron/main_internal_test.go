@@ -0,0 +1,34 @@
+package main
+
+import (
+	"flag"
+	"slices"
+	"testing"
+
+	"github.com/urfave/cli/v2"
+)
+
+func TestDestroyEnv(t *testing.T) {
+	t.Parallel()
+
+	set := flag.NewFlagSet("destroy", flag.ContinueOnError)
+
+	for _, f := range []cli.Flag{&cli.DurationFlag{Name: flagNameTimeout}, &cli.StringSliceFlag{Name: flagNameHost}} {
+		if err := f.Apply(set); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := set.Parse([]string{"--timeout", "15m", "--host", "web1,web2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	env := destroyEnv(cli.NewContext(cli.NewApp(), set, nil))
+
+	// The plan reads its DeployTimeout from HADRON_TIMEOUT, so Destroy can stop before the CLI kills it
+	for _, want := range []string{"HADRON_DESTROY=true", "HADRON_TIMEOUT=15m0s", "HADRON_HOSTS=web1,web2"} {
+		if !slices.Contains(env, want) {
+			t.Errorf("expected %s in the destroy environment, got %v", want, env)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package main
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// stopGroupOnCancel starts cmd in its own process group and makes cancelling its context send SIGTERM
+// to the whole group. "go run" execs the plan as a child, so killing only cmd would orphan the plan.
+func stopGroupOnCancel(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+	}
+}
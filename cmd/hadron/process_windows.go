@@ -0,0 +1,9 @@
+//go:build windows
+
+package main
+
+import "os/exec"
+
+// stopGroupOnCancel is a no-op on Windows, which has no process groups to signal; cancelling the
+// context kills only "go run".
+func stopGroupOnCancel(*exec.Cmd) {}
@@ -4,7 +4,6 @@ package main
 import (
 	"context"
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -120,26 +119,8 @@ func main() {
 		SecurityOpt("no-new-privileges").
 		Build()
 
-	// Execute plan based on environment
-	ctx := context.Background()
-
-	var err error
-
-	switch {
-	case os.Getenv("HADRON_DESTROY") == "true":
-		err = plan.Destroy()
-		if err != nil {
-			log.Fatal().Err(err).Msg("Failed to destroy resources")
-		}
-	case os.Getenv("HADRON_DRY_RUN") == "true":
-		err = plan.DryRun()
-		if err != nil {
-			log.Fatal().Err(err).Msg("Dry run failed")
-		}
-	default:
-		err = plan.Execute(ctx)
-		if err != nil {
-			log.Fatal().Err(err).Msg("Deployment failed")
-		}
+	// Run whatever the hadron CLI asked for: deploy, dry-run, destroy, render, status, logs, or exec
+	if err := plan.Run(context.Background()); err != nil {
+		log.Fatal().Err(err).Msg("Plan failed")
 	}
 }
@@ -45,8 +45,8 @@ func installDocker(client ssh.Connection) error {
 
 // installDockerPrerequisites installs ca-certificates and curl.
 func installDockerPrerequisites(client ssh.Connection) error {
-	cmd := "sudo DEBIAN_FRONTEND=noninteractive apt-get update -qq && " +
-		"sudo apt-get install -qq --no-install-recommends ca-certificates curl"
+	cmd := ssh.Privileged(client, "DEBIAN_FRONTEND=noninteractive apt-get update -qq") + " && " +
+		ssh.Privileged(client, "apt-get install -qq --no-install-recommends ca-certificates curl")
 
 	_, stderr, err := client.Execute(cmd)
 	if err != nil {
@@ -59,18 +59,19 @@ func installDockerPrerequisites(client ssh.Connection) error {
 // addDockerGPGKey downloads and installs Docker's GPG key.
 func addDockerGPGKey(client ssh.Connection) error {
 	// Create keyrings directory with proper permissions
-	createDirCmd := "sudo install -m 0755 -d " + dockerKeyrings
+	createDirCmd := "install -m 0755 -d " + dockerKeyrings
 
-	_, stderr, err := client.Execute(createDirCmd)
+	_, stderr, err := ssh.RunPrivileged(client, createDirCmd)
 	if err != nil {
 		return fmt.Errorf("%w: %s", ErrDockerKeyringsDir, stderr)
 	}
 
-	// Download Docker GPG key to temp location, then move with sudo
+	// Download Docker GPG key to temp location, then move it as root
+	tempKey := ssh.TempPath(client, "docker.asc")
 	downloadKeyCmd := fmt.Sprintf(
-		"curl -fsSL %s -o /tmp/docker.asc && sudo mv /tmp/docker.asc %s",
-		dockerGPGURL,
-		dockerGPGPath,
+		"curl -fsSL %s -o %s && %s",
+		dockerGPGURL, tempKey,
+		ssh.Privileged(client, "mv "+tempKey+" "+dockerGPGPath),
 	)
 
 	_, stderr, err = client.Execute(downloadKeyCmd)
@@ -79,9 +80,9 @@ func addDockerGPGKey(client ssh.Connection) error {
 	}
 
 	// Set proper permissions on GPG key
-	chmodCmd := "sudo chmod a+r " + dockerGPGPath
+	chmodCmd := "chmod a+r " + dockerGPGPath
 
-	_, stderr, err = client.Execute(chmodCmd)
+	_, stderr, err = ssh.RunPrivileged(client, chmodCmd)
 	if err != nil {
 		return fmt.Errorf("%w: %s", ErrDockerGPGPermissions, stderr)
 	}
@@ -92,9 +93,9 @@ func addDockerGPGKey(client ssh.Connection) error {
 // setupDockerRepository adds Docker's apt repository to sources.list.d.
 func setupDockerRepository(client ssh.Connection) error {
 	// Get architecture
-	archCmd := "sudo dpkg --print-architecture"
+	archCmd := "dpkg --print-architecture"
 
-	arch, _, err := client.Execute(archCmd)
+	arch, _, err := ssh.RunPrivileged(client, archCmd)
 	if err != nil {
 		return fmt.Errorf("%w: %w", ErrDockerArchFetch, err)
 	}
@@ -118,22 +119,22 @@ func setupDockerRepository(client ssh.Connection) error {
 	)
 
 	// Write repository file via temp file
-	tempPath := "/tmp/hadron-docker.list"
+	tempPath := ssh.TempPath(client, "hadron-docker.list")
 	if err := client.UploadData([]byte(repoLine), tempPath); err != nil {
 		return fmt.Errorf("%w: %w", ErrDockerRepoWrite, err)
 	}
 
-	moveCmd := fmt.Sprintf("sudo mv %s %s", tempPath, dockerRepoFile)
+	moveCmd := fmt.Sprintf("mv %s %s", tempPath, dockerRepoFile)
 
-	_, stderr, err := client.Execute(moveCmd)
+	_, stderr, err := ssh.RunPrivileged(client, moveCmd)
 	if err != nil {
 		return fmt.Errorf("%w: %s", ErrDockerRepoWrite, stderr)
 	}
 
 	// Update apt cache
-	updateCmd := "sudo apt-get update -qq"
+	updateCmd := "apt-get update -qq"
 
-	_, stderr, err = client.Execute(updateCmd)
+	_, stderr, err = ssh.RunPrivileged(client, updateCmd)
 	if err != nil {
 		return fmt.Errorf("%w: %s", ErrDockerRepoUpdate, stderr)
 	}
@@ -145,9 +146,9 @@ func setupDockerRepository(client ssh.Connection) error {
 func installDockerPackages(client ssh.Connection) error {
 	// Create docker group with predictable GID 900 before package installation
 	// This ensures the docker group exists with a known GID for container access
-	createGroupCmd := "sudo groupadd --system --gid 900 docker 2>/dev/null || true"
+	createGroupCmd := "groupadd --system --gid 900 docker 2>/dev/null || true"
 
-	_, stderr, err := client.Execute(createGroupCmd)
+	_, stderr, err := ssh.RunPrivileged(client, createGroupCmd)
 	if err != nil {
 		return fmt.Errorf("%w: %s", errCreateDockerGroup, stderr)
 	}
@@ -158,12 +159,12 @@ func installDockerPackages(client ssh.Connection) error {
 		"containerd.io",
 	}
 
-	installCmd := "sudo DEBIAN_FRONTEND=noninteractive apt-get install -qq --no-install-recommends " + strings.Join(
+	installCmd := "DEBIAN_FRONTEND=noninteractive apt-get install -qq --no-install-recommends " + strings.Join(
 		packages,
 		" ",
 	)
 
-	_, stderr, err = client.Execute(installCmd)
+	_, stderr, err = ssh.RunPrivileged(client, installCmd)
 	if err != nil {
 		return fmt.Errorf("%w: %s", ErrDockerPackageInstall, stderr)
 	}
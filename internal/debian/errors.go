@@ -8,6 +8,8 @@ var (
 	ErrPackageInstallFailed = errors.New("package installation failed")
 	// ErrPackageRemoveFailed indicates a package removal failed.
 	ErrPackageRemoveFailed = errors.New("package removal failed")
+	// ErrPackageHoldFailed indicates holding or unholding a package failed.
+	ErrPackageHoldFailed = errors.New("package hold failed")
 )
 
 // Docker installation errors.
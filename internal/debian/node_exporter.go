@@ -31,23 +31,44 @@ var (
 // installNodeExporter installs prometheus-node-exporter and configures it to listen on docker0.
 // This allows containers to scrape host metrics without exposing node_exporter publicly.
 func installNodeExporter(client ssh.Connection) error {
-	// Step 1: Install the package via apt
 	if err := installWithApt(client, nodeExporterPackage); err != nil {
 		return fmt.Errorf("failed to install %s: %w", nodeExporterPackage, err)
 	}
 
-	// Step 2: Configure node_exporter to listen on docker0 interface
-	if err := configureNodeExporter(client); err != nil {
+	return setupNodeExporter(client, true)
+}
+
+// EnsureNodeExporter installs prometheus-node-exporter if it is missing and makes sure it listens on
+// docker0 and is running. The service is only restarted when its configuration changed, so repeated
+// deploys leave it alone. openFirewall adds the UFW rule letting containers reach it; hosts whose
+// firewall rules are managed by the plan allow the port there instead.
+func EnsureNodeExporter(client ssh.Connection, openFirewall bool) error {
+	if !isInstalled(client, nodeExporterPackage) {
+		if err := installWithApt(client, nodeExporterPackage); err != nil {
+			return fmt.Errorf("failed to install %s: %w", nodeExporterPackage, err)
+		}
+	}
+
+	return setupNodeExporter(client, openFirewall)
+}
+
+// setupNodeExporter configures an installed node_exporter and makes sure it is running.
+func setupNodeExporter(client ssh.Connection, openFirewall bool) error {
+	// Step 1: Configure node_exporter to listen on docker0 interface
+	changed, err := configureNodeExporter(client)
+	if err != nil {
 		return fmt.Errorf("failed to configure node_exporter: %w", err)
 	}
 
-	// Step 3: Configure firewall to allow Docker containers to reach node_exporter
-	if err := configureNodeExporterFirewall(client); err != nil {
-		return fmt.Errorf("failed to configure node_exporter firewall: %w", err)
+	// Step 2: Configure firewall to allow Docker containers to reach node_exporter
+	if openFirewall {
+		if err := configureNodeExporterFirewall(client); err != nil {
+			return fmt.Errorf("failed to configure node_exporter firewall: %w", err)
+		}
 	}
 
-	// Step 4: Enable and start the service
-	if err := enableNodeExporter(client); err != nil {
+	// Step 3: Enable and start the service
+	if err := enableNodeExporter(client, changed); err != nil {
 		return fmt.Errorf("failed to enable node_exporter service: %w", err)
 	}
 
@@ -55,18 +76,19 @@ func installNodeExporter(client ssh.Connection) error {
 }
 
 // configureNodeExporter creates the configuration file to make node_exporter listen on docker0.
-func configureNodeExporter(client ssh.Connection) error {
+// Returns true if the configuration changed.
+func configureNodeExporter(client ssh.Connection) (bool, error) {
 	// Get docker0 IP address dynamically
 	getIPCmd := "ip -4 addr show docker0 | grep -oP '(?<=inet\\s)\\d+(\\.\\d+){3}'"
 
 	docker0IPAddr, stderr, err := client.Execute(getIPCmd)
 	if err != nil {
-		return fmt.Errorf("%w: %s", errDockerZeroIP, stderr)
+		return false, fmt.Errorf("%w: %s", errDockerZeroIP, stderr)
 	}
 
 	docker0IPAddr = strings.TrimSpace(docker0IPAddr)
 	if docker0IPAddr == "" {
-		return errNoIPv4
+		return false, errNoIPv4
 	}
 
 	// Configuration: listen on docker0 interface only
@@ -74,29 +96,34 @@ func configureNodeExporter(client ssh.Connection) error {
 	listenAddr := fmt.Sprintf("%s:%s", docker0IPAddr, nodeExporterPort)
 	config := fmt.Sprintf("ARGS=\"--web.listen-address=%s\"\n", listenAddr)
 
+	// Skip if the configuration is already in place
+	if current, _, err := ssh.RunPrivileged(client, "cat "+nodeExporterConfigFile); err == nil && current == config {
+		return false, nil
+	}
+
 	// Upload config to temp location
-	tempPath := "/tmp/hadron-node-exporter-config"
+	tempPath := ssh.TempPath(client, "hadron-node-exporter-config")
 	if err := client.UploadData([]byte(config), tempPath); err != nil {
-		return fmt.Errorf("failed to upload config: %w", err)
+		return false, fmt.Errorf("failed to upload config: %w", err)
 	}
 
-	// Move to final location with sudo
-	moveCmd := fmt.Sprintf("sudo mv %s %s", tempPath, nodeExporterConfigFile)
+	// Move to final location as root
+	moveCmd := fmt.Sprintf("mv %s %s", tempPath, nodeExporterConfigFile)
 
-	_, moveStderr, err := client.Execute(moveCmd)
+	_, moveStderr, err := ssh.RunPrivileged(client, moveCmd)
 	if err != nil {
-		return fmt.Errorf("%w: %s", errMoveConfig, moveStderr)
+		return false, fmt.Errorf("%w: %s", errMoveConfig, moveStderr)
 	}
 
 	// Set proper permissions (readable by prometheus-node-exporter user)
-	chmodCmd := "sudo chmod 644 " + nodeExporterConfigFile
+	chmodCmd := "chmod 644 " + nodeExporterConfigFile
 
-	_, chmodStderr, err := client.Execute(chmodCmd)
+	_, chmodStderr, err := ssh.RunPrivileged(client, chmodCmd)
 	if err != nil {
-		return fmt.Errorf("%w: %s", errConfigPermissions, chmodStderr)
+		return false, fmt.Errorf("%w: %s", errConfigPermissions, chmodStderr)
 	}
 
-	return nil
+	return true, nil
 }
 
 // configureNodeExporterFirewall configures UFW to allow Docker containers to reach node_exporter.
@@ -104,7 +131,7 @@ func configureNodeExporter(client ssh.Connection) error {
 // This function is idempotent - safe to run multiple times.
 func configureNodeExporterFirewall(client ssh.Connection) error {
 	// Check if the rule already exists
-	checkCmd := "sudo ufw status | grep -q '172.16.0.0/12.*172.17.0.1.*9100'"
+	checkCmd := ssh.Privileged(client, "ufw status") + " | grep -q '172.16.0.0/12.*172.17.0.1.*9100'"
 	_, _, err := client.Execute(checkCmd)
 
 	if err == nil {
@@ -116,12 +143,12 @@ func configureNodeExporterFirewall(client ssh.Connection) error {
 	// to reach docker0 gateway IP on port 9100
 	// This supports dynamic network allocation while restricting destination to docker0 only
 	addRuleCmd := fmt.Sprintf(
-		"sudo ufw allow from 172.16.0.0/12 to %s port %s comment 'node_exporter from Docker networks'",
+		"ufw allow from 172.16.0.0/12 to %s port %s comment 'node_exporter from Docker networks'",
 		docker0IP,
 		nodeExporterPort,
 	)
 
-	_, stderr, err := client.Execute(addRuleCmd)
+	_, stderr, err := ssh.RunPrivileged(client, addRuleCmd)
 	if err != nil {
 		return fmt.Errorf("%w: %s", errAddFirewallRule, stderr)
 	}
@@ -129,20 +156,23 @@ func configureNodeExporterFirewall(client ssh.Connection) error {
 	return nil
 }
 
-// enableNodeExporter enables and starts the systemd service.
-func enableNodeExporter(client ssh.Connection) error {
+// enableNodeExporter enables and starts the systemd service, restarting it to apply a changed configuration.
+func enableNodeExporter(client ssh.Connection, restart bool) error {
 	// Enable service to start on boot
-	enableCmd := "sudo systemctl enable " + nodeExporterService
+	enableCmd := "systemctl enable " + nodeExporterService
 
-	_, stderr, err := client.Execute(enableCmd)
+	_, stderr, err := ssh.RunPrivileged(client, enableCmd)
 	if err != nil {
 		return fmt.Errorf("%w: %s", errEnableService, stderr)
 	}
 
-	// Restart service to apply new configuration
-	restartCmd := "sudo systemctl restart " + nodeExporterService
+	// Restart service to apply new configuration, otherwise just make sure it is running
+	restartCmd := "systemctl start " + nodeExporterService
+	if restart {
+		restartCmd = "systemctl restart " + nodeExporterService
+	}
 
-	_, stderr, err = client.Execute(restartCmd)
+	_, stderr, err = ssh.RunPrivileged(client, restartCmd)
 	if err != nil {
 		return fmt.Errorf("%w: %s", errRestartService, stderr)
 	}
@@ -3,6 +3,8 @@ package debian
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/the-agent-c-ai/hadron/sdk/ssh"
 )
@@ -27,7 +29,8 @@ func getCustomInstaller(packageName string) (customInstaller, bool) {
 func isInstalled(client ssh.Connection, packageName string) bool {
 	// Check if package is installed using dpkg
 	// Output format: "ii  package-name  version  architecture  description"
-	cmd := fmt.Sprintf("sudo dpkg -l %s 2>/dev/null | grep '^ii' | grep -q '%s'", packageName, packageName)
+	cmd := fmt.Sprintf("%s 2>/dev/null | grep '^ii' | grep -q '%s'",
+		ssh.Privileged(client, "dpkg -l "+packageName), packageName)
 
 	_, _, err := client.Execute(cmd)
 	if err != nil {
@@ -54,9 +57,9 @@ func install(client ssh.Connection, packageName string) error {
 // installWithApt installs a package using apt-get with standard flags.
 func installWithApt(client ssh.Connection, packageName string) error {
 	// Update package lists
-	updateCmd := "sudo apt-get update -qq"
+	updateCmd := "apt-get update -qq"
 
-	_, stderr, err := client.Execute(updateCmd)
+	_, stderr, err := ssh.RunPrivileged(client, updateCmd)
 	if err != nil {
 		return fmt.Errorf("%w: apt-get update failed: %s", ErrPackageInstallFailed, stderr)
 	}
@@ -65,9 +68,9 @@ func installWithApt(client ssh.Connection, packageName string) error {
 	// -y: assume yes to all prompts
 	// -qq: very quiet output
 	// --no-install-recommends: only install dependencies, not recommended packages
-	installCmd := "sudo DEBIAN_FRONTEND=noninteractive apt-get install -qq --no-install-recommends " + packageName
+	installCmd := "DEBIAN_FRONTEND=noninteractive apt-get install -qq --no-install-recommends " + packageName
 
-	_, stderr, err = client.Execute(installCmd)
+	_, stderr, err = ssh.RunPrivileged(client, installCmd)
 	if err != nil {
 		return fmt.Errorf("%w: %s: %s", ErrPackageInstallFailed, packageName, stderr)
 	}
@@ -80,17 +83,17 @@ func remove(client ssh.Connection, packageName string) error {
 	// Remove package with:
 	// -y: assume yes to all prompts
 	// -qq: very quiet output
-	removeCmd := "sudo DEBIAN_FRONTEND=noninteractive apt-get remove -qq " + packageName
+	removeCmd := "DEBIAN_FRONTEND=noninteractive apt-get remove -qq " + packageName
 
-	_, stderr, err := client.Execute(removeCmd)
+	_, stderr, err := ssh.RunPrivileged(client, removeCmd)
 	if err != nil {
 		return fmt.Errorf("%w: %s: %s", ErrPackageRemoveFailed, packageName, stderr)
 	}
 
 	// Clean up unused dependencies
-	autoremoveCmd := "sudo apt-get autoremove -qq"
+	autoremoveCmd := "apt-get autoremove -qq"
 
-	_, stderr, err = client.Execute(autoremoveCmd)
+	_, stderr, err = ssh.RunPrivileged(client, autoremoveCmd)
 	if err != nil {
 		return fmt.Errorf("%w: autoremove failed: %s", ErrPackageRemoveFailed, stderr)
 	}
@@ -115,3 +118,48 @@ func EnsureRemoved(client ssh.Connection, packageName string) error {
 
 	return remove(client, packageName)
 }
+
+// isHeld checks if a package is held back from upgrades (apt-mark showhold).
+func isHeld(client ssh.Connection, packageName string) (bool, error) {
+	stdout, stderr, err := client.Execute("apt-mark showhold")
+	if err != nil {
+		return false, fmt.Errorf("%w: apt-mark showhold failed: %s", ErrPackageHoldFailed, stderr)
+	}
+
+	return slices.Contains(strings.Fields(stdout), packageName), nil
+}
+
+// setHold holds or unholds a package with apt-mark.
+func setHold(client ssh.Connection, packageName string, hold bool) error {
+	action := "unhold"
+	if hold {
+		action = "hold"
+	}
+
+	_, stderr, err := ssh.RunPrivileged(client, fmt.Sprintf("apt-mark %s %s", action, packageName))
+	if err != nil {
+		return fmt.Errorf("%w: apt-mark %s %s: %s", ErrPackageHoldFailed, action, packageName, stderr)
+	}
+
+	return nil
+}
+
+// EnsureHeld ensures a package is held, so neither apt-get upgrade nor unattended-upgrades upgrades it.
+func EnsureHeld(client ssh.Connection, packageName string) error {
+	held, err := isHeld(client, packageName)
+	if err != nil || held {
+		return err
+	}
+
+	return setHold(client, packageName, true)
+}
+
+// EnsureUnheld ensures a package is not held, releasing a previous hold if necessary.
+func EnsureUnheld(client ssh.Connection, packageName string) error {
+	held, err := isHeld(client, packageName)
+	if err != nil || !held {
+		return err
+	}
+
+	return setHold(client, packageName, false)
+}
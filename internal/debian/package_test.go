@@ -2,6 +2,7 @@ package debian_test
 
 import (
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/the-agent-c-ai/hadron/internal/debian"
@@ -102,3 +103,44 @@ func TestEnsureRemoved(t *testing.T) { //nolint:paralleltest // Integration test
 		},
 	)
 }
+
+func TestEnsureHeld(t *testing.T) { //nolint:paralleltest // Integration tests use shared container
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testutil.StartDebianSSHContainer(t)
+	client := container.Client()
+
+	if err := debian.EnsureInstalled(client, "curl"); err != nil {
+		t.Fatalf("failed to install curl: %v", err)
+	}
+
+	t.Run("holds package", func(t *testing.T) { //nolint:paralleltest // Subtests share container
+		// Hold twice - should be idempotent
+		for range 2 {
+			if err := debian.EnsureHeld(client, "curl"); err != nil {
+				t.Fatalf("expected EnsureHeld to succeed, got error: %v", err)
+			}
+		}
+
+		stdout, _, err := client.Execute("apt-mark showhold")
+		if err != nil || !strings.Contains(stdout, "curl") {
+			t.Errorf("expected curl to be held, got %q (err: %v)", stdout, err)
+		}
+	})
+
+	t.Run("releases hold", func(t *testing.T) { //nolint:paralleltest // Subtests share container
+		// Unhold twice - should be idempotent
+		for range 2 {
+			if err := debian.EnsureUnheld(client, "curl"); err != nil {
+				t.Fatalf("expected EnsureUnheld to succeed, got error: %v", err)
+			}
+		}
+
+		stdout, _, err := client.Execute("apt-mark showhold")
+		if err != nil || strings.Contains(stdout, "curl") {
+			t.Errorf("expected curl not to be held, got %q (err: %v)", stdout, err)
+		}
+	})
+}
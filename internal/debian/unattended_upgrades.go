@@ -2,16 +2,66 @@ package debian
 
 import (
 	"fmt"
+	"path"
 	"strings"
 
 	"github.com/the-agent-c-ai/hadron/sdk/ssh"
 )
 
 const (
-	unattendedUpgradesPackage = "unattended-upgrades"
-	autoUpgradesConfigPath    = "/etc/apt/apt.conf.d/20auto-upgrades"
+	unattendedUpgradesPackage    = "unattended-upgrades"
+	autoUpgradesConfigPath       = "/etc/apt/apt.conf.d/20auto-upgrades"
+	unattendedUpgradesConfigPath = "/etc/apt/apt.conf.d/50unattended-upgrades"
+
+	// hadronUpgradesConfigPath holds Hadron's settings. apt reads it after the distribution's
+	// 50unattended-upgrades, whose origins (which differ between Debian and Ubuntu) it keeps.
+	hadronUpgradesConfigPath = "/etc/apt/apt.conf.d/52hadron-unattended-upgrades"
+
+	// unattendedUpgradesMarker identifies a configuration file written by Hadron.
+	unattendedUpgradesMarker = "// Generated by Hadron"
 )
 
+// AutoUpdatesConfig adjusts unattended-upgrades. The zero value keeps the distribution's behavior.
+type AutoUpdatesConfig struct {
+	RebootTime string   // reboot automatically at this time (e.g., "02:00") when an upgrade requires it
+	Email      string   // address mailed a report whenever packages are upgraded
+	Blacklist  []string // packages (regular expressions) never upgraded automatically
+}
+
+// IsZero reports whether the configuration keeps the distribution's behavior.
+func (c AutoUpdatesConfig) IsZero() bool {
+	return c.RebootTime == "" && c.Email == "" && len(c.Blacklist) == 0
+}
+
+// RenderUnattendedUpgrades renders /etc/apt/apt.conf.d/52hadron-unattended-upgrades. It only sets
+// reboot, email, and blacklist settings; the origins to upgrade from stay the distribution's.
+func RenderUnattendedUpgrades(cfg AutoUpdatesConfig) string {
+	var b strings.Builder
+
+	b.WriteString(unattendedUpgradesMarker + "\n")
+	b.WriteString("Unattended-Upgrade::Package-Blacklist {\n")
+
+	for _, pkg := range cfg.Blacklist {
+		fmt.Fprintf(&b, "\t%q;\n", pkg)
+	}
+
+	b.WriteString("};\n")
+
+	if cfg.RebootTime != "" {
+		b.WriteString("Unattended-Upgrade::Automatic-Reboot \"true\";\n")
+		fmt.Fprintf(&b, "Unattended-Upgrade::Automatic-Reboot-Time %q;\n", cfg.RebootTime)
+	} else {
+		b.WriteString("Unattended-Upgrade::Automatic-Reboot \"false\";\n")
+	}
+
+	if cfg.Email != "" {
+		fmt.Fprintf(&b, "Unattended-Upgrade::Mail %q;\n", cfg.Email)
+		b.WriteString("Unattended-Upgrade::MailReport \"on-change\";\n")
+	}
+
+	return b.String()
+}
+
 // isAutoUpdatesConfigured checks if automatic updates are properly configured.
 // Verifies that /etc/apt/apt.conf.d/20auto-upgrades exists and contains proper settings.
 func isAutoUpdatesConfigured(client ssh.Connection) (bool, error) {
@@ -45,9 +95,9 @@ func isAutoUpdatesConfigured(client ssh.Connection) (bool, error) {
 // configureAutoUpdates enables automatic security updates via dpkg-reconfigure.
 func configureAutoUpdates(client ssh.Connection) error {
 	// Use -plow for non-interactive configuration (low priority = enable auto-updates)
-	cmd := "sudo DEBIAN_FRONTEND=noninteractive dpkg-reconfigure -plow unattended-upgrades"
+	cmd := "DEBIAN_FRONTEND=noninteractive dpkg-reconfigure -plow unattended-upgrades"
 
-	_, stderr, err := client.Execute(cmd)
+	_, stderr, err := ssh.RunPrivileged(client, cmd)
 	if err != nil {
 		return fmt.Errorf("failed to configure automatic updates: %w (stderr: %s)", err, stderr)
 	}
@@ -55,12 +105,84 @@ func configureAutoUpdates(client ssh.Connection) error {
 	return nil
 }
 
+// renderDistroOrigins renders a 50unattended-upgrades with the stable and security origins of the
+// running distribution, replacing one written by earlier Hadron versions with Debian's origins only.
+func renderDistroOrigins() string {
+	return unattendedUpgradesMarker + " (settings are in " + hadronUpgradesConfigPath + ")\n" +
+		"Unattended-Upgrade::Allowed-Origins {\n" +
+		"\t\"${distro_id}:${distro_codename}\";\n" +
+		"\t\"${distro_id}:${distro_codename}-security\";\n" +
+		"};\n"
+}
+
+// applyUnattendedUpgradesConfig writes Hadron's unattended-upgrades settings if they changed, or
+// removes them for a zero configuration. The distribution's 50unattended-upgrades is left alone,
+// unless an earlier Hadron version wrote it. Returns true if anything changed.
+func applyUnattendedUpgradesConfig(client ssh.Connection, cfg AutoUpdatesConfig) (bool, error) {
+	changed := false
+
+	legacy, _, err := client.Execute("cat " + unattendedUpgradesConfigPath)
+	if err == nil && strings.HasPrefix(legacy, unattendedUpgradesMarker) && legacy != renderDistroOrigins() {
+		if err := writeAptConfig(client, unattendedUpgradesConfigPath, renderDistroOrigins()); err != nil {
+			return false, err
+		}
+
+		changed = true
+	}
+
+	current, _, err := client.Execute("cat " + hadronUpgradesConfigPath)
+	if err != nil {
+		current = ""
+	}
+
+	if cfg.IsZero() {
+		if current == "" {
+			return changed, nil
+		}
+
+		if _, stderr, err := ssh.RunPrivileged(client, "rm -f "+hadronUpgradesConfigPath); err != nil {
+			return changed, fmt.Errorf("failed to remove unattended-upgrades settings: %w (stderr: %s)", err, stderr)
+		}
+
+		return true, nil
+	}
+
+	config := RenderUnattendedUpgrades(cfg)
+	if current == config {
+		return changed, nil
+	}
+
+	if err := writeAptConfig(client, hadronUpgradesConfigPath, config); err != nil {
+		return changed, err
+	}
+
+	return true, nil
+}
+
+// writeAptConfig installs an apt configuration file through a temp file (avoids shell escaping issues).
+func writeAptConfig(client ssh.Connection, file, content string) error {
+	tempPath := ssh.TempPath(client, "hadron-"+path.Base(file))
+	if err := client.UploadData([]byte(content), tempPath); err != nil {
+		return fmt.Errorf("failed to upload %s: %w", file, err)
+	}
+
+	moveCmd := ssh.Privileged(client, fmt.Sprintf("mv %s %s", tempPath, file)) + " && " +
+		ssh.Privileged(client, "chmod 644 "+file)
+
+	if _, stderr, err := client.Execute(moveCmd); err != nil {
+		return fmt.Errorf("failed to write %s: %w (stderr: %s)", file, err, stderr)
+	}
+
+	return nil
+}
+
 // EnsureAutoUpdatesEnabled ensures unattended-upgrades is installed and configured.
 // This is a consolidated function that:
 // 1. Installs unattended-upgrades package if not already installed.
 // 2. Checks if automatic updates are already configured.
 // 3. Configures automatic updates if needed.
-func EnsureAutoUpdatesEnabled(client ssh.Connection) error {
+// 4. Writes reboot, email, and blacklist settings to 52hadron-unattended-upgrades.
+func EnsureAutoUpdatesEnabled(client ssh.Connection, cfg AutoUpdatesConfig) error {
 	// Step 1: Ensure package is installed
 	if err := EnsureInstalled(client, unattendedUpgradesPackage); err != nil {
 		return fmt.Errorf("failed to install unattended-upgrades: %w", err)
@@ -79,5 +201,10 @@ func EnsureAutoUpdatesEnabled(client ssh.Connection) error {
 		}
 	}
 
+	// Step 4: Apply reboot, email, and blacklist settings
+	if _, err := applyUnattendedUpgradesConfig(client, cfg); err != nil {
+		return fmt.Errorf("failed to configure unattended-upgrades: %w", err)
+	}
+
 	return nil
 }
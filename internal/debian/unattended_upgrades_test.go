@@ -18,7 +18,7 @@ func TestEnsureAutoUpdatesEnabled(t *testing.T) { //nolint:paralleltest // Integ
 
 	t.Run("enables auto-updates when not configured", func(t *testing.T) { //nolint:paralleltest // Subtests share
 		// Ensure auto-updates are enabled
-		err := debian.EnsureAutoUpdatesEnabled(client)
+		err := debian.EnsureAutoUpdatesEnabled(client, debian.AutoUpdatesConfig{})
 		if err != nil {
 			t.Fatalf("expected EnsureAutoUpdatesEnabled to succeed, got error: %v", err)
 		}
@@ -52,15 +52,68 @@ func TestEnsureAutoUpdatesEnabled(t *testing.T) { //nolint:paralleltest // Integ
 
 	t.Run("is idempotent when already configured", func(t *testing.T) { //nolint:paralleltest // Subtests share
 		// Enable first time
-		err := debian.EnsureAutoUpdatesEnabled(client)
+		err := debian.EnsureAutoUpdatesEnabled(client, debian.AutoUpdatesConfig{})
 		if err != nil {
 			t.Fatalf("first enable failed: %v", err)
 		}
 
 		// Enable again - should be idempotent
-		err = debian.EnsureAutoUpdatesEnabled(client)
+		err = debian.EnsureAutoUpdatesEnabled(client, debian.AutoUpdatesConfig{})
 		if err != nil {
 			t.Fatalf("expected EnsureAutoUpdatesEnabled to be idempotent, got error: %v", err)
 		}
 	})
+
+	t.Run("writes reboot, email, and blacklist settings", func(t *testing.T) { //nolint:paralleltest // Subtests share
+		cfg := debian.AutoUpdatesConfig{
+			RebootTime: "02:00",
+			Email:      "ops@example.com",
+			Blacklist:  []string{"postgresql-"},
+		}
+
+		if err := debian.EnsureAutoUpdatesEnabled(client, cfg); err != nil {
+			t.Fatalf("expected EnsureAutoUpdatesEnabled to succeed, got error: %v", err)
+		}
+
+		stdout, _, err := client.Execute("cat /etc/apt/apt.conf.d/52hadron-unattended-upgrades")
+		if err != nil {
+			t.Fatalf("failed to read unattended-upgrades config: %v", err)
+		}
+
+		if stdout != debian.RenderUnattendedUpgrades(cfg) {
+			t.Errorf("expected rendered config to be written, got:\n%s", stdout)
+		}
+	})
+}
+
+func TestRenderUnattendedUpgrades(t *testing.T) {
+	t.Parallel()
+
+	config := debian.RenderUnattendedUpgrades(debian.AutoUpdatesConfig{
+		RebootTime: "02:00",
+		Email:      "ops@example.com",
+		Blacklist:  []string{"docker-ce", "postgresql-"},
+	})
+
+	for _, want := range []string{
+		"Unattended-Upgrade::Automatic-Reboot \"true\";\n",
+		"Unattended-Upgrade::Automatic-Reboot-Time \"02:00\";\n",
+		"Unattended-Upgrade::Mail \"ops@example.com\";\n",
+		"Unattended-Upgrade::Package-Blacklist {\n\t\"docker-ce\";\n\t\"postgresql-\";\n};\n",
+	} {
+		if !strings.Contains(config, want) {
+			t.Errorf("expected config to contain %q, got:\n%s", want, config)
+		}
+	}
+
+	// Origins differ between Debian and Ubuntu, so they are left to the distribution's file
+	if strings.Contains(config, "Origins") {
+		t.Errorf("expected no origins in Hadron's settings, got:\n%s", config)
+	}
+
+	defaults := debian.RenderUnattendedUpgrades(debian.AutoUpdatesConfig{})
+	if !strings.Contains(defaults, "Unattended-Upgrade::Automatic-Reboot \"false\";") ||
+		strings.Contains(defaults, "Unattended-Upgrade::Mail ") {
+		t.Errorf("expected defaults without reboot or mail, got:\n%s", defaults)
+	}
 }
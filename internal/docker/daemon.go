@@ -4,7 +4,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
+	"path"
 	"reflect"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,20 +18,46 @@ import (
 const (
 	daemonConfigPath = "/etc/docker/daemon.json"
 	nofileLimit      = 64000
+
+	// daemonKeysPath records the daemon.json keys hadron last wrote, so optional ones dropped from the
+	// plan (mirrors, DNS, ...) can be removed without touching keys the operator set. dockerd rejects
+	// unknown keys, so the record cannot live in daemon.json itself.
+	daemonKeysPath = "/etc/docker/hadron-managed-keys.json"
 )
 
 // DaemonConfig represents the Docker daemon configuration.
 //
 //nolint:tagliatelle
 type DaemonConfig struct {
-	LiveRestore     bool                    `json:"live-restore"`
-	UserlandProxy   bool                    `json:"userland-proxy"`
-	NoNewPrivileges bool                    `json:"no-new-privileges"`
-	ICC             bool                    `json:"icc"`
-	BIP             string                  `json:"bip,omitempty"` // Bridge IP (docker0 network)
-	LogDriver       string                  `json:"log-driver"`
-	LogOpts         map[string]string       `json:"log-opts"`
-	DefaultUlimits  map[string]UlimitConfig `json:"default-ulimits"`
+	LiveRestore         bool                    `json:"live-restore"`
+	UserlandProxy       bool                    `json:"userland-proxy"`
+	NoNewPrivileges     bool                    `json:"no-new-privileges"`
+	ICC                 bool                    `json:"icc"`
+	BIP                 string                  `json:"bip,omitempty"` // Bridge IP (docker0 network)
+	LogDriver           string                  `json:"log-driver"`
+	LogOpts             map[string]string       `json:"log-opts"`
+	DefaultUlimits      map[string]UlimitConfig `json:"default-ulimits"`
+	RegistryMirrors     []string                `json:"registry-mirrors,omitempty"`
+	InsecureRegistries  []string                `json:"insecure-registries,omitempty"`
+	DNS                 []string                `json:"dns,omitempty"`
+	DefaultAddressPools []AddressPool           `json:"default-address-pools,omitempty"`
+}
+
+// AddressPool represents a pool of subnets Docker allocates user-defined networks from.
+type AddressPool struct {
+	Base string `json:"base"` // CIDR the pool is carved from (e.g., "10.10.0.0/16")
+	Size int    `json:"size"` // prefix length of each allocated subnet (e.g., 24)
+}
+
+// DaemonOverrides holds user-provided adjustments folded onto the secure defaults.
+// Zero values leave the corresponding secure default untouched.
+type DaemonOverrides struct {
+	RegistryMirrors    []string
+	InsecureRegistries []string
+	DNS                []string
+	AddressPools       []AddressPool
+	LogMaxSize         string
+	LogMaxFile         int
 }
 
 // UlimitConfig represents a ulimit configuration.
@@ -62,6 +92,24 @@ func GetSecureDefaults() *DaemonConfig {
 	}
 }
 
+// Apply folds the overrides onto the configuration and returns it.
+func (c *DaemonConfig) Apply(overrides DaemonOverrides) *DaemonConfig {
+	c.RegistryMirrors = append(c.RegistryMirrors, overrides.RegistryMirrors...)
+	c.InsecureRegistries = append(c.InsecureRegistries, overrides.InsecureRegistries...)
+	c.DNS = append(c.DNS, overrides.DNS...)
+	c.DefaultAddressPools = append(c.DefaultAddressPools, overrides.AddressPools...)
+
+	if overrides.LogMaxSize != "" {
+		c.LogOpts["max-size"] = overrides.LogMaxSize
+	}
+
+	if overrides.LogMaxFile > 0 {
+		c.LogOpts["max-file"] = strconv.Itoa(overrides.LogMaxFile)
+	}
+
+	return c
+}
+
 // DaemonConfigExists checks if /etc/docker/daemon.json exists.
 func DaemonConfigExists(client ssh.Connection) (bool, error) {
 	cmd := fmt.Sprintf("test -f %s && echo exists || echo missing", daemonConfigPath)
@@ -74,8 +122,9 @@ func DaemonConfigExists(client ssh.Connection) (bool, error) {
 	return strings.TrimSpace(stdout) == "exists", nil
 }
 
-// GetDaemonConfig reads the current daemon configuration.
-func GetDaemonConfig(client ssh.Connection) (*DaemonConfig, error) {
+// GetDaemonConfig reads the current daemon configuration as a generic map.
+// Keys hadron doesn't manage (e.g., "data-root") are preserved as-is.
+func GetDaemonConfig(client ssh.Connection) (map[string]any, error) {
 	cmd := "cat " + daemonConfigPath
 
 	stdout, _, err := client.Execute(cmd)
@@ -83,52 +132,168 @@ func GetDaemonConfig(client ssh.Connection) (*DaemonConfig, error) {
 		return nil, fmt.Errorf("failed to read daemon config: %w", err)
 	}
 
-	var config DaemonConfig
+	config := make(map[string]any)
+	if strings.TrimSpace(stdout) == "" {
+		return config, nil
+	}
+
 	if err := json.Unmarshal([]byte(stdout), &config); err != nil {
 		return nil, fmt.Errorf("failed to parse daemon config: %w", err)
 	}
 
-	return &config, nil
+	return config, nil
 }
 
 // WriteDaemonConfig writes the daemon configuration to /etc/docker/daemon.json.
-func WriteDaemonConfig(client ssh.Connection, config *DaemonConfig) error {
+func WriteDaemonConfig(client ssh.Connection, config map[string]any) error {
 	// Marshal to JSON with indentation
 	jsonBytes, err := json.MarshalIndent(config, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal daemon config: %w", err)
 	}
 
+	return writeDockerFile(client, jsonBytes, daemonConfigPath)
+}
+
+// GetManagedDaemonKeys returns the daemon.json keys hadron wrote last time, or nil if it never
+// recorded any (including hosts hardened before the record existed).
+func GetManagedDaemonKeys(client ssh.Connection) ([]string, error) {
+	stdout, stderr, err := client.Execute(ssh.Privileged(client, "cat "+daemonKeysPath) + " 2>/dev/null || true")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read managed daemon keys: %w (stderr: %s)", err, stderr)
+	}
+
+	if strings.TrimSpace(stdout) == "" {
+		return nil, nil
+	}
+
+	var keys []string
+	if err := json.Unmarshal([]byte(stdout), &keys); err != nil {
+		return nil, fmt.Errorf("failed to parse managed daemon keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// WriteManagedDaemonKeys records the daemon.json keys desired manages, for the next deploy's
+// MergeDaemonConfig to remove the ones it no longer sets.
+func WriteManagedDaemonKeys(client ssh.Connection, desired *DaemonConfig) error {
+	keys, err := ManagedDaemonKeys(desired)
+	if err != nil {
+		return err
+	}
+
+	jsonBytes, err := json.Marshal(keys)
+	if err != nil {
+		return fmt.Errorf("failed to marshal managed daemon keys: %w", err)
+	}
+
+	return writeDockerFile(client, jsonBytes, daemonKeysPath)
+}
+
+// writeDockerFile writes data to remotePath in /etc/docker via a temp file (avoids shell escaping issues).
+func writeDockerFile(client ssh.Connection, data []byte, remotePath string) error {
 	// Ensure /etc/docker directory exists
-	mkdirCmd := "sudo mkdir -p /etc/docker"
-	if _, _, err := client.Execute(mkdirCmd); err != nil {
+	mkdirCmd := "mkdir -p /etc/docker"
+	if _, _, err := ssh.RunPrivileged(client, mkdirCmd); err != nil {
 		return fmt.Errorf("failed to create /etc/docker directory: %w", err)
 	}
 
-	// Write config via temp file (avoids shell escaping issues)
-	tempPath := "/tmp/hadron-daemon.json"
-	if err := client.UploadData(jsonBytes, tempPath); err != nil {
-		return fmt.Errorf("failed to write temp daemon config: %w", err)
+	tempPath := ssh.TempPath(client, "hadron-"+path.Base(remotePath))
+	if err := client.UploadData(data, tempPath); err != nil {
+		return fmt.Errorf("failed to write temp file for %s: %w", remotePath, err)
 	}
 
-	moveCmd := fmt.Sprintf("sudo mv %s %s", tempPath, daemonConfigPath)
-	if _, stderr, err := client.Execute(moveCmd); err != nil {
-		return fmt.Errorf("failed to move daemon config: %w (stderr: %s)", err, stderr)
+	moveCmd := fmt.Sprintf("mv %s %s", tempPath, remotePath)
+	if _, stderr, err := ssh.RunPrivileged(client, moveCmd); err != nil {
+		return fmt.Errorf("failed to move %s into place: %w (stderr: %s)", remotePath, err, stderr)
 	}
 
 	return nil
 }
 
-// ConfigsEqual checks if two daemon configs are equivalent.
-func ConfigsEqual(a, b *DaemonConfig) bool {
-	return reflect.DeepEqual(a, b)
+// managedKeys converts the desired configuration into the generic map form used by daemon.json.
+// Only keys present in the result are managed by hadron; everything else belongs to the operator.
+func managedKeys(desired *DaemonConfig) (map[string]any, error) {
+	jsonBytes, err := json.Marshal(desired)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal daemon config: %w", err)
+	}
+
+	managed := make(map[string]any)
+	if err := json.Unmarshal(jsonBytes, &managed); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal daemon config: %w", err)
+	}
+
+	return managed, nil
+}
+
+// ManagedDaemonKeys returns the sorted daemon.json keys desired sets.
+func ManagedDaemonKeys(desired *DaemonConfig) ([]string, error) {
+	managed, err := managedKeys(desired)
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.Sorted(maps.Keys(managed)), nil
+}
+
+// MergeDaemonConfig overlays the hadron-managed keys from desired onto the current configuration and
+// removes the keys of previous (see GetManagedDaemonKeys) that desired no longer sets. Other unknown
+// keys in current are preserved. The current map is not modified.
+func MergeDaemonConfig(current map[string]any, desired *DaemonConfig, previous []string) (map[string]any, error) {
+	managed, err := managedKeys(desired)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := make(map[string]any, len(current)+len(managed))
+	for k, v := range current {
+		merged[k] = v
+	}
+
+	for _, k := range previous {
+		if _, ok := managed[k]; !ok {
+			delete(merged, k)
+		}
+	}
+
+	for k, v := range managed {
+		merged[k] = v
+	}
+
+	return merged, nil
+}
+
+// ConfigsEqual checks if the current configuration already matches desired for every key hadron manages,
+// and holds none of the keys of previous that desired no longer sets. Other keys are ignored.
+func ConfigsEqual(current map[string]any, desired *DaemonConfig, previous []string) bool {
+	managed, err := managedKeys(desired)
+	if err != nil {
+		return false
+	}
+
+	for k, v := range managed {
+		if !reflect.DeepEqual(current[k], v) {
+			return false
+		}
+	}
+
+	for _, k := range previous {
+		_, stale := current[k]
+		if _, ok := managed[k]; stale && !ok {
+			return false
+		}
+	}
+
+	return true
 }
 
 // RestartDockerDaemon restarts the Docker daemon.
 func RestartDockerDaemon(client ssh.Connection) error {
-	cmd := "sudo systemctl restart docker"
+	cmd := "systemctl restart docker"
 
-	_, stderr, err := client.Execute(cmd)
+	_, stderr, err := ssh.RunPrivileged(client, cmd)
 	if err != nil {
 		return fmt.Errorf("failed to restart docker daemon: %w (stderr: %s)", err, stderr)
 	}
@@ -0,0 +1,202 @@
+package docker_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/the-agent-c-ai/hadron/internal/docker"
+)
+
+// parseDaemonJSON decodes a daemon.json document the same way GetDaemonConfig does.
+func parseDaemonJSON(t *testing.T, content string) map[string]any {
+	t.Helper()
+
+	config := make(map[string]any)
+	if err := json.Unmarshal([]byte(content), &config); err != nil {
+		t.Fatalf("failed to parse daemon config: %v", err)
+	}
+
+	return config
+}
+
+// hardenedHost returns the daemon.json contents of a host previously hardened with desired, as read back.
+func hardenedHost(t *testing.T, current map[string]any, desired *docker.DaemonConfig) map[string]any {
+	t.Helper()
+
+	merged, err := docker.MergeDaemonConfig(current, desired, nil)
+	if err != nil {
+		t.Fatalf("expected merge to succeed, got: %v", err)
+	}
+
+	jsonBytes, err := json.Marshal(merged)
+	if err != nil {
+		t.Fatalf("failed to marshal merged config: %v", err)
+	}
+
+	return parseDaemonJSON(t, string(jsonBytes))
+}
+
+func TestMergeDaemonConfigPreservesUnknownKeys(t *testing.T) {
+	t.Parallel()
+
+	current := parseDaemonJSON(t, `{"data-root": "/mnt/docker", "live-restore": false}`)
+
+	merged, err := docker.MergeDaemonConfig(current, docker.GetSecureDefaults(), nil)
+	if err != nil {
+		t.Fatalf("expected merge to succeed, got: %v", err)
+	}
+
+	if merged["data-root"] != "/mnt/docker" {
+		t.Errorf("expected data-root to survive hardening, got %v", merged["data-root"])
+	}
+
+	if merged["live-restore"] != true {
+		t.Errorf("expected live-restore to be hardened to true, got %v", merged["live-restore"])
+	}
+
+	if current["live-restore"] != false {
+		t.Error("expected current config to be left unmodified")
+	}
+}
+
+func TestConfigsEqualIgnoresUnmanagedKeys(t *testing.T) {
+	t.Parallel()
+
+	desired := docker.GetSecureDefaults()
+	current := hardenedHost(t, map[string]any{"data-root": "/mnt/docker"}, desired)
+
+	if !docker.ConfigsEqual(current, desired, nil) {
+		t.Error("expected configs to be equal despite unmanaged data-root key")
+	}
+
+	current["icc"] = true
+
+	if docker.ConfigsEqual(current, desired, nil) {
+		t.Error("expected configs to differ when a managed key changes")
+	}
+}
+
+func TestDaemonConfigApplyOverrides(t *testing.T) {
+	t.Parallel()
+
+	config := docker.GetSecureDefaults().Apply(docker.DaemonOverrides{
+		RegistryMirrors: []string{"https://mirror.example.com"},
+		AddressPools:    []docker.AddressPool{{Base: "10.10.0.0/16", Size: 24}},
+	})
+
+	if len(config.RegistryMirrors) != 1 || config.RegistryMirrors[0] != "https://mirror.example.com" {
+		t.Errorf("expected registry mirror to be set, got %v", config.RegistryMirrors)
+	}
+
+	if len(config.DefaultAddressPools) != 1 || config.DefaultAddressPools[0].Size != 24 {
+		t.Errorf("expected address pool to be set, got %v", config.DefaultAddressPools)
+	}
+
+	// A host hardened with plain defaults must be updated when overrides are added
+	current := hardenedHost(t, map[string]any{}, docker.GetSecureDefaults())
+
+	if docker.ConfigsEqual(current, config, nil) {
+		t.Error("expected overrides to change the effective config")
+	}
+}
+
+func TestDaemonConfigLogRotationOverrides(t *testing.T) {
+	t.Parallel()
+
+	config := docker.GetSecureDefaults().Apply(docker.DaemonOverrides{
+		LogMaxSize: "100m",
+		LogMaxFile: 10,
+	})
+
+	if config.LogOpts["max-size"] != "100m" || config.LogOpts["max-file"] != "10" {
+		t.Errorf("expected log rotation overrides to apply, got %v", config.LogOpts)
+	}
+
+	// Other secure settings remain in place
+	if !config.LiveRestore || !config.NoNewPrivileges || config.ICC || config.LogDriver != "json-file" {
+		t.Errorf("expected secure defaults to remain, got %+v", config)
+	}
+
+	current := hardenedHost(t, map[string]any{}, docker.GetSecureDefaults())
+
+	if docker.ConfigsEqual(current, config, nil) {
+		t.Error("expected log rotation overrides to require a restart")
+	}
+
+	if !docker.ConfigsEqual(hardenedHost(t, current, config), config, nil) {
+		t.Error("expected no restart once overrides are applied")
+	}
+}
+
+func TestDaemonConfigInsecureRegistry(t *testing.T) {
+	t.Parallel()
+
+	config := docker.GetSecureDefaults().Apply(docker.DaemonOverrides{
+		InsecureRegistries: []string{"registry.internal:5000"},
+	})
+
+	merged, err := docker.MergeDaemonConfig(map[string]any{}, config, nil)
+	if err != nil {
+		t.Fatalf("expected merge to succeed, got: %v", err)
+	}
+
+	registries, ok := merged["insecure-registries"].([]any)
+	if !ok || len(registries) != 1 || registries[0] != "registry.internal:5000" {
+		t.Errorf("expected insecure-registries to contain entry, got %v", merged["insecure-registries"])
+	}
+
+	// Adding the registry to a previously hardened host must trigger a restart
+	current := hardenedHost(t, map[string]any{}, docker.GetSecureDefaults())
+
+	if docker.ConfigsEqual(current, config, nil) {
+		t.Error("expected adding an insecure registry to require a restart")
+	}
+}
+
+func TestMergeDaemonConfigRemovesDroppedKeys(t *testing.T) {
+	t.Parallel()
+
+	previous := docker.GetSecureDefaults().Apply(docker.DaemonOverrides{
+		RegistryMirrors: []string{"https://mirror.internal"},
+		DNS:             []string{"10.0.0.2"},
+	})
+
+	previousKeys, err := docker.ManagedDaemonKeys(previous)
+	if err != nil {
+		t.Fatalf("expected managed keys, got: %v", err)
+	}
+
+	// The operator's own insecure registry was never written by hadron, so it stays
+	current := hardenedHost(t, map[string]any{"insecure-registries": []any{"registry.internal:5000"}}, previous)
+	desired := docker.GetSecureDefaults().Apply(docker.DaemonOverrides{DNS: []string{"10.0.0.2"}})
+
+	if docker.ConfigsEqual(current, desired, previousKeys) {
+		t.Error("expected dropping the registry mirror to require a restart")
+	}
+
+	merged, err := docker.MergeDaemonConfig(current, desired, previousKeys)
+	if err != nil {
+		t.Fatalf("expected merge to succeed, got: %v", err)
+	}
+
+	if _, ok := merged["registry-mirrors"]; ok {
+		t.Errorf("expected the dropped registry mirror to be removed, got %v", merged["registry-mirrors"])
+	}
+
+	if _, ok := merged["dns"]; !ok {
+		t.Error("expected the DNS servers still configured to be kept")
+	}
+
+	if _, ok := merged["insecure-registries"]; !ok {
+		t.Error("expected the operator's insecure registry to be kept")
+	}
+
+	desiredKeys, err := docker.ManagedDaemonKeys(desired)
+	if err != nil {
+		t.Fatalf("expected managed keys, got: %v", err)
+	}
+
+	if !docker.ConfigsEqual(hardenedHost(t, merged, desired), desired, desiredKeys) {
+		t.Error("expected no restart once the dropped keys are removed")
+	}
+}
@@ -0,0 +1,50 @@
+package docker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ValidateEnv checks that an environment variable survives docker's --env-file format unchanged.
+// Docker takes everything after the first "=" literally (spaces, "=", "#", and quotes included),
+// but a line break ends the value, so multi-line values must be mounted as files instead.
+func ValidateEnv(key, value string) error {
+	switch {
+	case key == "":
+		return fmt.Errorf("%w: empty name", ErrInvalidEnv)
+	case strings.ContainsAny(key, "= \t\r\n\x00") || strings.HasPrefix(key, "#"):
+		return fmt.Errorf("%w: name %q must not contain '=' or whitespace, or start with '#'", ErrInvalidEnv, key)
+	case strings.ContainsAny(value, "\r\n\x00"):
+		return fmt.Errorf("%w: value of %s contains a line break (mount multi-line values as a file)",
+			ErrInvalidEnv, key)
+	}
+
+	return nil
+}
+
+// RenderEnvFile renders environment variables in docker's --env-file format, sorted by name so the
+// same variables always produce the same file (and content hash). Values are written verbatim.
+func RenderEnvFile(envVars map[string]string) ([]byte, error) {
+	keys := make([]string, 0, len(envVars))
+	for k := range envVars {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	var content strings.Builder
+
+	for _, k := range keys {
+		if err := ValidateEnv(k, envVars[k]); err != nil {
+			return nil, err
+		}
+
+		_, _ = content.WriteString(k)
+		_, _ = content.WriteString("=")
+		_, _ = content.WriteString(envVars[k])
+		_, _ = content.WriteString("\n")
+	}
+
+	return []byte(content.String()), nil
+}
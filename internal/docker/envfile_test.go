@@ -0,0 +1,59 @@
+package docker_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/the-agent-c-ai/hadron/internal/docker"
+)
+
+func TestRenderEnvFileWritesValuesVerbatim(t *testing.T) {
+	t.Parallel()
+
+	content, err := docker.RenderEnvFile(map[string]string{
+		"GREETING":     "hello world ",
+		"DSN":          "postgres://app:p=ss@db/app?sslmode=disable",
+		"COLOR":        "#ff0000",
+		"QUOTED":       `"kept"`,
+		"CITY":         "Zürich 東京",
+		"EMPTY":        "",
+		"LEADING_PADS": "  indented",
+	})
+	if err != nil {
+		t.Fatalf("expected env file to render, got: %v", err)
+	}
+
+	want := "CITY=Zürich 東京\n" +
+		"COLOR=#ff0000\n" +
+		"DSN=postgres://app:p=ss@db/app?sslmode=disable\n" +
+		"EMPTY=\n" +
+		"GREETING=hello world \n" +
+		"LEADING_PADS=  indented\n" +
+		"QUOTED=\"kept\"\n"
+	if string(content) != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, content)
+	}
+}
+
+func TestRenderEnvFileRejectsUnrepresentableVariables(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]map[string]string{
+		"newline in value":         {"CERT": "line1\nline2"},
+		"carriage return in value": {"TOKEN": "abc\r"},
+		"equals in name":           {"A=B": "value"},
+		"space in name":            {"MY VAR": "value"},
+		"comment name":             {"#DISABLED": "value"},
+		"empty name":               {"": "value"},
+	}
+
+	for name, envVars := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := docker.RenderEnvFile(envVars); !errors.Is(err, docker.ErrInvalidEnv) {
+				t.Errorf("expected ErrInvalidEnv, got: %v", err)
+			}
+		})
+	}
+}
@@ -8,4 +8,22 @@ var (
 
 	// ErrPathRelative indicates failure to compute relative path.
 	ErrPathRelative = errors.New("failed to compute relative path")
+
+	// ErrWaitForFileTimeout indicates a waited-for file did not appear in time.
+	ErrWaitForFileTimeout = errors.New("file did not appear")
+
+	// ErrContainerUnhealthy indicates a container stopped or failed its health check instead of becoming healthy.
+	ErrContainerUnhealthy = errors.New("container did not become healthy")
+
+	// ErrExecFailed indicates a command run in a container with docker exec exited non-zero.
+	ErrExecFailed = errors.New("exec in container failed")
+
+	// ErrInitContainerFailed indicates an init container exited non-zero.
+	ErrInitContainerFailed = errors.New("init container failed")
+
+	// ErrImageInUse indicates an image a container still needs would have been pruned.
+	ErrImageInUse = errors.New("refusing to prune an image in use")
+
+	// ErrInvalidEnv indicates an environment variable cannot be passed through an env file unchanged.
+	ErrInvalidEnv = errors.New("invalid environment variable")
 )
@@ -2,13 +2,22 @@
 package docker
 
 import (
+	"archive/tar"
+	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io"
+	"maps"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/rs/zerolog"
 
@@ -20,6 +29,9 @@ const (
 	checkResultExists  = "exists"
 	checkResultMissing = "missing"
 	labelFlagFormat    = " --label %s=%s"
+
+	// pullTimeout bounds a docker pull, like ssh.DefaultCommandTimeout bounds other remote commands.
+	pullTimeout = ssh.DefaultCommandTimeout
 )
 
 // Executor executes Docker commands on remote hosts via SSH.
@@ -53,10 +65,44 @@ func (*Executor) NetworkExists(client ssh.Connection, networkName string) (bool,
 	return strings.TrimSpace(stdout) == checkResultExists, nil
 }
 
+// NetworkOptions holds optional IPAM settings for a Docker network.
+type NetworkOptions struct {
+	Subnet   string // CIDR the network allocates from (e.g., "172.28.0.0/16")
+	Gateway  string // gateway IP inside the subnet
+	IPRange  string // CIDR sub-range containers are assigned from
+	Internal bool   // no external connectivity (docker network create --internal)
+	IPv6     string // IPv6 subnet CIDR; enables dual-stack (--ipv6) when set
+}
+
 // CreateNetwork creates a Docker network on the remote host.
-func (e *Executor) CreateNetwork(client ssh.Connection, networkName, driver string, labels map[string]string) error {
+func (e *Executor) CreateNetwork(
+	client ssh.Connection,
+	networkName, driver string,
+	labels map[string]string,
+	opts NetworkOptions,
+) error {
 	cmd := "docker network create -d " + driver
 
+	if opts.Subnet != "" {
+		cmd += " --subnet " + opts.Subnet
+	}
+
+	if opts.Gateway != "" {
+		cmd += " --gateway " + opts.Gateway
+	}
+
+	if opts.IPRange != "" {
+		cmd += " --ip-range " + opts.IPRange
+	}
+
+	if opts.Internal {
+		cmd += " --internal"
+	}
+
+	if opts.IPv6 != "" {
+		cmd += " --ipv6 --subnet " + opts.IPv6
+	}
+
 	// Add labels
 	for k, v := range labels {
 		cmd += fmt.Sprintf(labelFlagFormat, k, v)
@@ -76,6 +122,18 @@ func (e *Executor) CreateNetwork(client ssh.Connection, networkName, driver stri
 	return nil
 }
 
+// NetworkContainers returns the names of containers attached to a network.
+func (*Executor) NetworkContainers(client ssh.Connection, networkName string) ([]string, error) {
+	cmd := fmt.Sprintf("docker network inspect -f '{{range .Containers}}{{.Name}} {{end}}' %s", networkName)
+
+	stdout, stderr, err := client.Execute(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect network: %w (stderr: %s)", err, stderr)
+	}
+
+	return strings.Fields(stdout), nil
+}
+
 // RemoveNetwork removes a Docker network from the remote host.
 func (e *Executor) RemoveNetwork(client ssh.Connection, networkName string) error {
 	cmd := "docker network rm " + networkName
@@ -122,9 +180,27 @@ func (*Executor) VolumeExists(client ssh.Connection, volumeName string) (bool, e
 }
 
 // CreateVolume creates a Docker volume on the remote host.
-func (e *Executor) CreateVolume(client ssh.Connection, volumeName, driver string, labels map[string]string) error {
+// Driver options (e.g., NFS "type", "o", "device") are passed as --opt key=value in sorted order.
+func (e *Executor) CreateVolume(
+	client ssh.Connection,
+	volumeName, driver string,
+	labels map[string]string,
+	driverOpts map[string]string,
+) error {
 	cmd := "docker volume create --driver " + driver
 
+	// Add driver options (sorted for a stable command line)
+	optKeys := make([]string, 0, len(driverOpts))
+	for k := range driverOpts {
+		optKeys = append(optKeys, k)
+	}
+
+	sort.Strings(optKeys)
+
+	for _, k := range optKeys {
+		cmd += " --opt " + shellQuote(k+"="+driverOpts[k])
+	}
+
 	// Add labels
 	for k, v := range labels {
 		cmd += fmt.Sprintf(labelFlagFormat, k, v)
@@ -160,6 +236,29 @@ func (e *Executor) RemoveVolume(client ssh.Connection, volumeName string) error
 	return nil
 }
 
+// BackupVolume archives a volume's contents to a timestamped tarball under backupsDir with a throwaway
+// container running image (which must provide tar), and returns the archive path.
+func (e *Executor) BackupVolume(client ssh.Connection, volumeName, image string) (string, error) {
+	dir := backupsDir(client)
+	archive := fmt.Sprintf("%s/%s-%s.tar.gz", dir, volumeName, time.Now().UTC().Format("20060102T150405Z"))
+
+	cmd := fmt.Sprintf(
+		"%s && docker run --rm --network none -v %s:/volume:ro -v %s:/backup %s "+
+			"tar -czf /backup/%s -C /volume .",
+		ssh.Privileged(client, "mkdir -p "+dir), volumeName, dir, image, path.Base(archive),
+	)
+
+	e.logger.Debug().Str("command", cmd).Msg("Backing up volume")
+
+	if _, stderr, err := client.Execute(cmd); err != nil {
+		return "", fmt.Errorf("failed to back up volume %s: %w (stderr: %s)", volumeName, err, stderr)
+	}
+
+	e.logger.Info().Str("volume", volumeName).Str("archive", archive).Msg("Volume backed up")
+
+	return archive, nil
+}
+
 // GetVolumeLabel retrieves a label value from a volume.
 func (*Executor) GetVolumeLabel(client ssh.Connection, volumeName, labelKey string) (string, error) {
 	cmd := fmt.Sprintf("docker volume inspect -f '{{index .Labels \"%s\"}}' %s", labelKey, volumeName)
@@ -172,6 +271,60 @@ func (*Executor) GetVolumeLabel(client ssh.Connection, volumeName, labelKey stri
 	return strings.TrimSpace(stdout), nil
 }
 
+// GetVolumeMountpoint returns the host path where a volume's data is stored.
+func (*Executor) GetVolumeMountpoint(client ssh.Connection, volumeName string) (string, error) {
+	cmd := "docker volume inspect -f '{{.Mountpoint}}' " + volumeName
+
+	stdout, stderr, err := client.Execute(cmd)
+	if err != nil {
+		return "", fmt.Errorf("failed to get volume mountpoint: %w (stderr: %s)", err, stderr)
+	}
+
+	return strings.TrimSpace(stdout), nil
+}
+
+// VolumeHasData reports whether a volume's mountpoint contains any file or directory.
+func (e *Executor) VolumeHasData(client ssh.Connection, volumeName string) (bool, error) {
+	mountpoint, err := e.GetVolumeMountpoint(client, volumeName)
+	if err != nil {
+		return false, err
+	}
+
+	stdout, stderr, err := ssh.RunPrivileged(client, fmt.Sprintf("find %s -mindepth 1 -print -quit", mountpoint))
+	if err != nil {
+		return false, fmt.Errorf("failed to list volume %s: %w (stderr: %s)", volumeName, err, stderr)
+	}
+
+	return strings.TrimSpace(stdout) != "", nil
+}
+
+// WaitForFile polls the remote host until the file at path exists, or the timeout elapses.
+// Used to hold back a container until a volume has been seeded (e.g., by a restore or an init container).
+func (e *Executor) WaitForFile(client ssh.Connection, path string, timeout, interval time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	checkCmd := fmt.Sprintf("%s && echo %s || echo %s",
+		ssh.Privileged(client, "test -e "+shellQuote(path)), checkResultExists, checkResultMissing)
+
+	for {
+		stdout, _, err := client.Execute(checkCmd)
+		if err != nil {
+			return fmt.Errorf("failed to check if %s exists: %w", path, err)
+		}
+
+		if strings.TrimSpace(stdout) == checkResultExists {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w: %s within %v", ErrWaitForFileTimeout, path, timeout)
+		}
+
+		e.logger.Debug().Str("path", path).Msg("Waiting for file to appear")
+
+		time.Sleep(interval)
+	}
+}
+
 // ContainerExists checks if a Docker container exists on the remote host.
 func (*Executor) ContainerExists(client ssh.Connection, containerName string) (bool, error) {
 	cmd := fmt.Sprintf(
@@ -189,6 +342,110 @@ func (*Executor) ContainerExists(client ssh.Connection, containerName string) (b
 	return strings.TrimSpace(stdout) == checkResultExists, nil
 }
 
+// ContainerState returns a container's status (e.g. "running", "exited") and its health check
+// status ("healthy", "unhealthy", "starting"), or empty string if it has no health check.
+func (*Executor) ContainerState(client ssh.Connection, containerName string) (status, health string, err error) {
+	cmd := fmt.Sprintf(
+		"docker container inspect -f '{{.State.Status}} {{if .State.Health}}{{.State.Health.Status}}{{end}}' %s",
+		containerName,
+	)
+
+	stdout, stderr, err := client.Execute(cmd)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get container state: %w (stderr: %s)", err, stderr)
+	}
+
+	status, health, _ = strings.Cut(strings.TrimSpace(stdout), " ")
+
+	return status, health, nil
+}
+
+// ContainerInfo is the part of docker container inspect that a deploy controls.
+type ContainerInfo struct {
+	Config struct {
+		Image  string            `json:"Image"`
+		User   string            `json:"User"`
+		Labels map[string]string `json:"Labels"`
+	} `json:"Config"`
+	HostConfig struct {
+		Memory            int64 `json:"Memory"`
+		MemoryReservation int64 `json:"MemoryReservation"`
+		NanoCPUs          int64 `json:"NanoCpus"`
+		CPUShares         int64 `json:"CpuShares"`
+		PidsLimit         int64 `json:"PidsLimit"`
+		ReadonlyRootfs    bool  `json:"ReadonlyRootfs"`
+		Privileged        bool  `json:"Privileged"`
+		OomScoreAdj       int   `json:"OomScoreAdj"`
+		RestartPolicy     struct {
+			Name              string `json:"Name"`
+			MaximumRetryCount int    `json:"MaximumRetryCount"`
+		} `json:"RestartPolicy"`
+	} `json:"HostConfig"`
+}
+
+// InspectContainer returns the live configuration of a container.
+func (*Executor) InspectContainer(client ssh.Connection, containerName string) (*ContainerInfo, error) {
+	stdout, stderr, err := client.Execute("docker container inspect --format '{{json .}}' " + containerName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect container: %w (stderr: %s)", err, stderr)
+	}
+
+	var info ContainerInfo
+	if err := json.Unmarshal([]byte(stdout), &info); err != nil {
+		return nil, fmt.Errorf("failed to parse container inspect output: %w", err)
+	}
+
+	return &info, nil
+}
+
+// WaitHealthy polls a container until its health check passes, or until it is running when it has
+// none. It fails with ErrContainerUnhealthy as soon as the container stops or is marked unhealthy,
+// or once timeout elapses.
+func (e *Executor) WaitHealthy(client ssh.Connection, containerName string, timeout, interval time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		status, health, err := e.ContainerState(client, containerName)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case health == "healthy", health == "" && status == "running":
+			return nil
+		case health == "unhealthy", status == "exited", status == "dead":
+			return fmt.Errorf("%w: %s is %s %s", ErrContainerUnhealthy, containerName, status, health)
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w: %s still %s %s after %v",
+				ErrContainerUnhealthy, containerName, status, health, timeout)
+		}
+
+		e.logger.Debug().
+			Str("container", containerName).
+			Str("status", status).
+			Msg("Waiting for container to be healthy")
+
+		time.Sleep(interval)
+	}
+}
+
+// Exec runs a command in a running container and returns its output, failing with ErrExecFailed
+// when it exits non-zero.
+func (e *Executor) Exec(client ssh.Connection, containerName string, args []string) (string, error) {
+	cmd := ExecCommand(containerName, args, false)
+	e.logger.Debug().Str("command", cmd).Msg("Running command in container")
+
+	stdout, stderr, err := client.Execute(cmd)
+	if err != nil {
+		return stdout, fmt.Errorf("%w: %s: %w (stdout: %s, stderr: %s)",
+			ErrExecFailed, containerName, err, strings.TrimSpace(stdout), strings.TrimSpace(stderr))
+	}
+
+	return stdout, nil
+}
+
 // GetContainerLabel retrieves a label value from a container.
 func (*Executor) GetContainerLabel(client ssh.Connection, containerName, labelKey string) (string, error) {
 	cmd := fmt.Sprintf("docker container inspect -f '{{index .Config.Labels \"%s\"}}' %s", labelKey, containerName)
@@ -210,11 +467,25 @@ func (e *Executor) PullImage(client ssh.Connection, image string) (bool, error)
 
 	pullCmd := "docker pull " + image
 
-	stdout, stderr, err := client.Execute(pullCmd)
+	// Stream progress to the debug log as it arrives; stdout is also kept for the Status: line
+	var stdoutBuf, stderrBuf bytes.Buffer
+
+	// Streaming is not bound by the command timeout, so bound the pull itself: a stalled registry
+	// must not hang the deploy
+	ctx, cancel := context.WithTimeout(context.Background(), pullTimeout)
+	defer cancel()
+
+	progress := newLineLogger(e.logger, "image", image)
+	err := client.StreamCommand(ctx, pullCmd, io.MultiWriter(&stdoutBuf, progress), &stderrBuf)
+
+	progress.Flush()
+
 	if err != nil {
-		return false, fmt.Errorf("failed to pull image %s: %w (stderr: %s)", image, err, stderr)
+		return false, fmt.Errorf("failed to pull image %s: %w (stderr: %s)", image, err, stderrBuf.String())
 	}
 
+	stdout := stdoutBuf.String()
+
 	// Check the Status: line to determine if image was updated
 	// Docker outputs one of:
 	//   "Status: Image is up to date for ..." - no changes
@@ -276,11 +547,106 @@ func (e *Executor) RunContainer(client ssh.Connection, opts ContainerRunOptions)
 		return err
 	}
 
+	cmd := buildRunCommand(opts, envFiles)
+
+	e.logger.Debug().Str("command", cmd).Msg("Running container")
+
+	stdout, stderr, err := client.Execute(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to run container: %w (stderr: %s)", err, stderr)
+	}
+
+	e.logger.Info().Str("container", opts.Name).Str("id", strings.TrimSpace(stdout)).Msg("Container started")
+
+	return nil
+}
+
+// RunInitContainer runs a one-shot container in the foreground until it exits, removing it afterwards.
+// Returns an error if the container exits non-zero.
+func (e *Executor) RunInitContainer(client ssh.Connection, opts ContainerRunOptions) error {
+	opts.RunOnce = true
+
+	envFiles, err := e.prepareEnvFiles(client, opts)
+	if err != nil {
+		return err
+	}
+
+	// Remove leftovers from an interrupted previous run (--rm never got a chance)
+	_, _, _ = client.Execute(fmt.Sprintf("docker rm -f %s >/dev/null 2>&1 || true", opts.Name))
+
+	cmd := buildRunCommand(opts, envFiles)
+
+	e.logger.Debug().Str("command", cmd).Msg("Running init container")
+
+	stdout, stderr, err := client.Execute(cmd)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w (stdout: %s, stderr: %s)",
+			ErrInitContainerFailed, opts.Name, err, strings.TrimSpace(stdout), strings.TrimSpace(stderr))
+	}
+
+	e.logger.Info().Str("container", opts.Name).Msg("Init container completed")
+
+	return nil
+}
+
+// JobScriptPath returns where the script running a scheduled job is stored on the client's host.
+func JobScriptPath(client ssh.Connection, name string) string {
+	return jobsDir(client) + "/" + name + ".sh"
+}
+
+// JobScript uploads the job's env files and returns a shell script that runs the container to
+// completion and removes it afterwards, for a systemd timer to run on schedule.
+func (e *Executor) JobScript(client ssh.Connection, opts ContainerRunOptions) (string, error) {
+	opts.RunOnce = true
+
+	envFiles, err := e.prepareEnvFiles(client, opts)
+	if err != nil {
+		return "", err
+	}
+
+	// Remove leftovers from an interrupted previous run (--rm never got a chance)
+	return fmt.Sprintf("#!/bin/sh\n# Generated by Hadron\ndocker rm -f %s >/dev/null 2>&1 || true\nexec %s\n",
+		opts.Name, buildRunCommand(opts, envFiles)), nil
+}
+
+// RenderRunCommand returns the docker run command for the given options without executing anything.
+// Env files are shown by their local path, and generated env files by variable names only, so
+// rendered output never contains secret values.
+func RenderRunCommand(opts ContainerRunOptions) string {
+	var envFiles []string
+
+	if opts.EnvFile != "" {
+		envFiles = append(envFiles, opts.EnvFile)
+	}
+
+	if len(opts.EnvVars) > 0 {
+		keys := make([]string, 0, len(opts.EnvVars))
+		for key := range opts.EnvVars {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+		envFiles = append(envFiles, "<generated:"+strings.Join(keys, ",")+">")
+	}
+
+	return buildRunCommand(opts, envFiles)
+}
+
+// buildRunCommand builds the docker run command line for the given options and remote env file paths.
+func buildRunCommand(opts ContainerRunOptions, envFiles []string) string {
 	cmd := "docker run -d"
+	if opts.RunOnce {
+		cmd = "docker run --rm"
+	}
 
 	// Container name
 	cmd += " --name " + opts.Name
 
+	// Pull policy (lets docker run fetch the image instead of a separate docker pull)
+	if opts.Pull != "" {
+		cmd += " --pull " + opts.Pull
+	}
+
 	// User
 	if opts.User != "" {
 		cmd += " --user " + opts.User
@@ -307,6 +673,14 @@ func (e *Executor) RunContainer(client ssh.Connection, opts ContainerRunOptions)
 		cmd += fmt.Sprintf(" --pids-limit %d", opts.PIDsLimit)
 	}
 
+	if opts.ShmSize != "" {
+		cmd += " --shm-size " + opts.ShmSize
+	}
+
+	if opts.OOMScoreAdj != 0 {
+		cmd += fmt.Sprintf(" --oom-score-adj %d", opts.OOMScoreAdj)
+	}
+
 	// Hostname
 	if opts.Hostname != "" {
 		cmd += " --hostname " + opts.Hostname
@@ -367,6 +741,10 @@ func (e *Executor) RunContainer(client ssh.Connection, opts ContainerRunOptions)
 		cmd += " --read-only"
 	}
 
+	if opts.Privileged {
+		cmd += " --privileged"
+	}
+
 	// Security options
 	for _, opt := range opts.SecurityOpts {
 		cmd += " --security-opt " + opt
@@ -391,6 +769,39 @@ func (e *Executor) RunContainer(client ssh.Connection, opts ContainerRunOptions)
 		cmd += fmt.Sprintf(labelFlagFormat, k, v)
 	}
 
+	// Logging (options sorted for a stable command line)
+	if opts.LogDriver != "" {
+		cmd += " --log-driver " + opts.LogDriver
+	}
+
+	logOptKeys := make([]string, 0, len(opts.LogOpts))
+	for k := range opts.LogOpts {
+		logOptKeys = append(logOptKeys, k)
+	}
+
+	sort.Strings(logOptKeys)
+
+	for _, k := range logOptKeys {
+		cmd += " --log-opt " + shellQuote(k+"="+opts.LogOpts[k])
+	}
+
+	// Health check
+	if opts.HealthCmd != "" {
+		cmd += " --health-cmd " + shellQuote(opts.HealthCmd)
+
+		if opts.HealthInterval > 0 {
+			cmd += " --health-interval " + opts.HealthInterval.String()
+		}
+
+		if opts.HealthTimeout > 0 {
+			cmd += " --health-timeout " + opts.HealthTimeout.String()
+		}
+
+		if opts.HealthRetries > 0 {
+			cmd += fmt.Sprintf(" --health-retries %d", opts.HealthRetries)
+		}
+	}
+
 	// Image
 	cmd += " " + opts.Image
 
@@ -399,16 +810,39 @@ func (e *Executor) RunContainer(client ssh.Connection, opts ContainerRunOptions)
 		cmd += " " + arg
 	}
 
-	e.logger.Debug().Str("command", cmd).Msg("Running container")
+	return cmd
+}
 
-	stdout, stderr, err := client.Execute(cmd)
-	if err != nil {
-		return fmt.Errorf("failed to run container: %w (stderr: %s)", err, stderr)
+// ExecCommand returns the docker exec command running args in a container. Input is always kept
+// open (-i); tty also allocates a terminal (-t) for interactive programs.
+func ExecCommand(containerName string, args []string, tty bool) string {
+	cmd := "docker exec -i"
+	if tty {
+		cmd += " -t"
 	}
 
-	e.logger.Info().Str("container", opts.Name).Str("id", strings.TrimSpace(stdout)).Msg("Container started")
+	cmd += " " + containerName
 
-	return nil
+	if len(args) > 0 {
+		cmd += " " + ShellCommand(args...)
+	}
+
+	return cmd
+}
+
+// ShellCommand returns args as a command line for the remote shell, each quoted as a single argument.
+func ShellCommand(args ...string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+
+	return strings.Join(quoted, " ")
+}
+
+// shellQuote wraps a value in single quotes for safe use as a single shell argument.
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
 }
 
 // StopContainer stops a Docker container.
@@ -426,6 +860,21 @@ func (e *Executor) StopContainer(client ssh.Connection, containerName string) er
 	return nil
 }
 
+// RestartContainer restarts a running Docker container in place.
+func (e *Executor) RestartContainer(client ssh.Connection, containerName string) error {
+	cmd := "docker restart " + containerName
+	e.logger.Debug().Str("command", cmd).Msg("Restarting container")
+
+	_, stderr, err := client.Execute(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to restart container: %w (stderr: %s)", err, stderr)
+	}
+
+	e.logger.Info().Str("container", containerName).Msg("Container restarted")
+
+	return nil
+}
+
 // RemoveContainer removes a Docker container.
 //
 //revive:disable:flag-parameter
@@ -449,15 +898,15 @@ func (e *Executor) RemoveContainer(client ssh.Connection, containerName string,
 
 // RegistryLogin logs into a Docker registry on the remote host.
 func (e *Executor) RegistryLogin(client ssh.Connection, registry, username, password string) error {
-	// Use echo to pipe password to docker login stdin to avoid password in process list
-	cmd := fmt.Sprintf("echo '%s' | docker login -u '%s' --password-stdin '%s'", password, username, registry)
+	// The password goes on stdin, so it is neither in the remote command line nor in the process list
+	cmd := fmt.Sprintf("docker login -u %s --password-stdin %s", shellQuote(username), shellQuote(registry))
 
 	e.logger.Debug().
 		Str("registry", registry).
 		Str("username", username).
 		Msg("Logging into registry")
 
-	_, stderr, err := client.Execute(cmd)
+	_, stderr, err := client.ExecuteWithInput(cmd, strings.NewReader(password))
 	if err != nil {
 		return fmt.Errorf("failed to login to registry %s: %w (stderr: %s)", registry, err, stderr)
 	}
@@ -467,6 +916,22 @@ func (e *Executor) RegistryLogin(client ssh.Connection, registry, username, pass
 	return nil
 }
 
+// RegistryLogout removes cached credentials for a Docker registry on the remote host.
+func (e *Executor) RegistryLogout(client ssh.Connection, registry string) error {
+	cmd := fmt.Sprintf("docker logout '%s'", registry)
+
+	e.logger.Debug().Str("registry", registry).Msg("Logging out of registry")
+
+	_, stderr, err := client.Execute(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to logout from registry %s: %w (stderr: %s)", registry, err, stderr)
+	}
+
+	e.logger.Info().Str("registry", registry).Msg("Registry logout successful")
+
+	return nil
+}
+
 // ContainerRunOptions represents options for running a container.
 type ContainerRunOptions struct {
 	Name              string
@@ -478,6 +943,8 @@ type ContainerRunOptions struct {
 	CPUShares         int64    // CPU shares (relative weight)
 	CPUs              string   // hard CPU limit (e.g., "1.5" for 1.5 CPUs)
 	PIDsLimit         int64    // maximum number of PIDs (process limit)
+	OOMScoreAdj       int      // --oom-score-adj (0 omits the flag)
+	ShmSize           string   // /dev/shm size (e.g., "1g")
 	Hostname          string   // container hostname
 	Network           string
 	NetworkAlias      string
@@ -488,12 +955,21 @@ type ContainerRunOptions struct {
 	EnvFile           string
 	EnvVars           map[string]string
 	Restart           string
+	Pull              string // --pull policy ("always", "missing", "never"); empty omits the flag
 	ReadOnly          bool
+	Privileged        bool // docker run --privileged
 	SecurityOpts      []string
 	CapDrop           []string
 	CapAdd            []string
 	GroupAdd          []string // additional groups for the container user
 	Labels            map[string]string
+	LogDriver         string            // --log-driver; empty uses the daemon default
+	LogOpts           map[string]string // --log-opt key -> value
+	RunOnce           bool              // run in the foreground to completion and remove afterwards (docker run --rm)
+	HealthCmd         string            // shell command for --health-cmd (empty disables the health flags)
+	HealthInterval    time.Duration     // time between health probes
+	HealthTimeout     time.Duration     // maximum time a single probe may take
+	HealthRetries     int               // consecutive failures before the container is unhealthy
 }
 
 // VolumeMount represents a volume mount for docker run.
@@ -504,7 +980,8 @@ type VolumeMount struct {
 }
 
 // uploadContentAddressable uploads data to a content-addressable location on the remote host.
-// Uses SHA256 hash of data as filename. Checks if file exists before uploading.
+// Uses SHA256 hash of data as filename. The upload is skipped when the remote file's checksum already
+// matches, so a file left incomplete by an interrupted upload is replaced rather than reused.
 // Optional permissions parameter (defaults to 0600 if not provided).
 // Returns the remote file path.
 func (e *Executor) uploadContentAddressable(
@@ -523,27 +1000,25 @@ func (e *Executor) uploadContentAddressable(
 	dataHash := hex.EncodeToString(dataHashRaw[:])
 
 	// 2. Build remote path
-	remotePath := "/var/lib/hadron/files/" + dataHash
-
-	// 3. Check if file exists on remote
-	checkCmd := fmt.Sprintf("test -f %s && echo %s || echo %s", remotePath, checkResultExists, checkResultMissing)
+	remotePath := ownedPath(client, dataHash, "")
 
-	stdout, _, err := client.Execute(checkCmd)
+	// 3. Check if the remote file already holds this content
+	remoteHash, err := client.RemoteSHA256(remotePath)
 	if err != nil {
 		return "", fmt.Errorf("failed to check if file exists on remote: %w", err)
 	}
 
-	if strings.TrimSpace(stdout) == checkResultExists {
+	if remoteHash == dataHash {
 		e.logger.Debug().Str("remote_path", remotePath).Msg("File already exists on remote")
 
 		return remotePath, nil
 	}
 
-	// 4. File doesn't exist, upload it
+	// 4. File is missing or incomplete, upload it
 	e.logger.Debug().Str("remote_path", remotePath).Int("size", len(data)).Msg("Uploading file")
 
 	// Ensure remote directory exists
-	mkdirCmd := "mkdir -p /var/lib/hadron/files"
+	mkdirCmd := "mkdir -p " + filesDir(client)
 	if _, _, err := client.Execute(mkdirCmd); err != nil {
 		return "", fmt.Errorf("failed to create remote files directory: %w", err)
 	}
@@ -588,35 +1063,20 @@ func (e *Executor) uploadEnvVarsFile(client ssh.Connection, envVars map[string]s
 		return "", nil
 	}
 
-	// Generate env file content
-	var content strings.Builder
-
-	// Sort keys for deterministic output (consistent hashing)
-	keys := make([]string, 0, len(envVars))
-	for k := range envVars {
-		keys = append(keys, k)
-	}
-
-	sort.Strings(keys)
-
-	for _, k := range keys {
-		// Docker run --env-file format: KEY=VALUE (no quotes - they become part of the value)
-		// Replace actual newlines with literal \n text (application must convert back)
-		escapedValue := strings.ReplaceAll(envVars[k], "\n", "\\n")
-
-		_, _ = content.WriteString(k)
-		_, _ = content.WriteString("=")
-		_, _ = content.WriteString(escapedValue)
-		_, _ = content.WriteString("\n")
+	// Generate env file content (rejects values docker cannot read back unchanged)
+	content, err := RenderEnvFile(envVars)
+	if err != nil {
+		return "", err
 	}
 
 	// Upload using content-addressable storage (0600 permissions for secrets)
-	return e.uploadContentAddressable(client, []byte(content.String()))
+	return e.uploadContentAddressable(client, content)
 }
 
 // UploadMount uploads a local file or directory to the remote host if it doesn't already exist.
-// Returns the remote path.
-func (e *Executor) UploadMount(client ssh.Connection, localPath string) (string, error) {
+// Without an owner the upload is world-readable; with one (UID or UID:GID), it is chowned to the owner
+// and closed to everyone else. Returns the remote path.
+func (e *Executor) UploadMount(client ssh.Connection, localPath, owner string) (string, error) {
 	// Check if local path is a file or directory
 	info, err := os.Stat(localPath)
 	if err != nil {
@@ -630,17 +1090,15 @@ func (e *Executor) UploadMount(client ssh.Connection, localPath string) (string,
 			return "", fmt.Errorf("failed to hash mount path: %w", err)
 		}
 
-		remotePath := "/var/lib/hadron/files/" + pathHash
-
-		// Check if directory exists on remote
-		checkCmd := fmt.Sprintf("test -e %s && echo %s || echo %s", remotePath, checkResultExists, checkResultMissing)
+		remotePath := ownedPath(client, pathHash, owner)
 
-		stdout, _, err := client.Execute(checkCmd)
+		// Directories are moved into place complete, so existence means the content matches
+		exists, err := client.FileExists(remotePath)
 		if err != nil {
 			return "", fmt.Errorf("failed to check if mount exists on remote: %w", err)
 		}
 
-		if strings.TrimSpace(stdout) == checkResultExists {
+		if exists {
 			e.logger.Debug().Str("remote_path", remotePath).Msg("Mount already exists on remote")
 
 			return remotePath, nil
@@ -649,7 +1107,7 @@ func (e *Executor) UploadMount(client ssh.Connection, localPath string) (string,
 		// Upload directory recursively
 		e.logger.Debug().Str("local_path", localPath).Str("remote_path", remotePath).Msg("Uploading mount directory")
 
-		if err := e.uploadDirectory(client, localPath, remotePath); err != nil {
+		if err := e.uploadDirectory(client, localPath, remotePath, owner); err != nil {
 			return "", fmt.Errorf("failed to upload directory: %w", err)
 		}
 
@@ -665,28 +1123,257 @@ func (e *Executor) UploadMount(client ssh.Connection, localPath string) (string,
 		return "", fmt.Errorf("failed to read file: %w", err)
 	}
 
-	return e.uploadContentAddressable(client, data, PermPublicFile)
+	return e.UploadDataMount(client, data, owner)
 }
 
 // UploadDataMount uploads raw data as a file to the remote host if it doesn't already exist.
-// Uses content-addressable storage (SHA256 hash) to avoid duplicates.
-// Returns the remote path.
-func (e *Executor) UploadDataMount(client ssh.Connection, data []byte) (string, error) {
-	// Upload using content-addressable storage with 0644 permissions
-	// This allows non-root container users to read mounted files
-	// TODO(security): Files are world-readable on host. Future: use ACLs or init containers to set proper ownership
-	return e.uploadContentAddressable(client, data, PermPublicFile)
+// Uses content-addressable storage (SHA256 hash) to avoid duplicates. Without an owner the file is
+// world-readable, since the container user may not exist on the host; with one (UID or UID:GID), it is
+// chowned to the owner with PermSecretFile permissions. Returns the remote path.
+func (e *Executor) UploadDataMount(client ssh.Connection, data []byte, owner string) (string, error) {
+	if owner == "" {
+		return e.uploadContentAddressable(client, data, PermPublicFile)
+	}
+
+	dataHashRaw := sha256.Sum256(data)
+	remotePath := ownedPath(client, hex.EncodeToString(dataHashRaw[:]), owner)
+
+	// Owned files are moved into place complete, and the SSH user cannot read them to checksum
+	exists, err := client.FileExists(remotePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to check if file exists on remote: %w", err)
+	}
+
+	if exists {
+		e.logger.Debug().Str("remote_path", remotePath).Msg("File already exists on remote")
+
+		return remotePath, nil
+	}
+
+	e.logger.Debug().Str("remote_path", remotePath).Int("size", len(data)).Msg("Uploading owned file")
+
+	// A leftover staging file from an interrupted upload may already belong to owner
+	staging := remotePath + ".tmp"
+	prepareCmd := "mkdir -p " + filesDir(client) + " && " + ssh.Privileged(client, "rm -f "+staging)
+
+	if _, stderr, err := client.Execute(prepareCmd); err != nil {
+		return "", fmt.Errorf("failed to prepare remote files directory: %w (stderr: %s)", err, stderr)
+	}
+
+	// UploadData leaves the file at PermSecretFile; it is only moved into place once chowned
+	if err := client.UploadData(data, staging); err != nil {
+		return "", fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	chownCmd := ssh.Privileged(client, fmt.Sprintf("chown %s %s", owner, staging)) +
+		fmt.Sprintf(" && mv %s %s", staging, remotePath)
+
+	if _, stderr, err := client.Execute(chownCmd); err != nil {
+		return "", fmt.Errorf("failed to set file owner: %w (stderr: %s)", err, stderr)
+	}
+
+	e.logger.Info().Str("remote_path", remotePath).Str("owner", owner).Msg("File uploaded")
+
+	return remotePath, nil
+}
+
+// ownedPath returns where content is stored for owner: the content hash, suffixed with the owner
+// ("473:473" becomes "-473-473") so a chowned copy never takes the place of the world-readable one.
+func ownedPath(client ssh.Connection, contentHash, owner string) string {
+	if owner == "" {
+		return filesDir(client) + "/" + contentHash
+	}
+
+	return filesDir(client) + "/" + contentHash + "-" + strings.ReplaceAll(owner, ":", "-")
+}
+
+// uploadDirectory uploads a directory to the remote host as a tar stream over a single session,
+// instead of one SFTP round trip per file. The archive is extracted next to remotePath and moved
+// into place, so an interrupted upload never leaves a partial directory behind for the existence
+// check in UploadMount to mistake for a complete one. Files an earlier upload of the directory left on
+// the host unchanged are copied there instead of sent (see reusableFiles). With an owner, the extracted
+// tree is chowned to it and closed to everyone else before the move; without one, a manifest of the
+// file checksums is written next to it for later uploads to reuse from.
+func (e *Executor) uploadDirectory(client ssh.Connection, localDir, remotePath, owner string) error {
+	sums, err := hash.Files(localDir)
+	if err != nil {
+		return fmt.Errorf("failed to hash mount files: %w", err)
+	}
+
+	// Reuse is only an optimization: without it, every file is sent
+	reused, err := reusableFiles(client, sums)
+	if err != nil {
+		e.logger.Warn().Err(err).Msg("Failed to look up reusable mount files, uploading all of them")
+
+		reused = nil
+	}
+
+	staging := remotePath + ".tmp"
+	cleanup := "rm -rf " + staging
+
+	var reuse string
+
+	if len(reused) > 0 {
+		e.logger.Debug().Int("reused", len(reused)).Int("files", len(sums)).Msg("Reusing unchanged mount files")
+
+		// NUL-separated source and destination pairs, so file names need no quoting
+		var pairs bytes.Buffer
+
+		for _, relPath := range slices.Sorted(maps.Keys(reused)) {
+			pairs.WriteString(reused[relPath] + "\x00" + staging + "/" + relPath + "\x00")
+		}
+
+		list := remotePath + ".reuse"
+		if err := client.UploadData(pairs.Bytes(), list); err != nil {
+			return fmt.Errorf("failed to upload reused file list: %w", err)
+		}
+
+		// Removed whether or not the extraction succeeds
+		defer func() { _, _, _ = client.Execute("rm -f " + list) }()
+
+		reuse = " && xargs -0 -n 2 cp -- < " + list
+	}
+
+	reader, writer := io.Pipe()
+
+	go func() {
+		_ = writer.CloseWithError(writeTar(writer, localDir, reused))
+	}()
+
+	var chown string
+
+	if owner != "" {
+		// A leftover staging directory from an interrupted upload may already belong to owner
+		cleanup = ssh.Privileged(client, cleanup)
+		chown = fmt.Sprintf(" && chmod -R go-rwx %s && %s", staging,
+			ssh.Privileged(client, fmt.Sprintf("chown -R %s %s", owner, staging)))
+	}
+
+	extractCmd := fmt.Sprintf(
+		"%[1]s && mkdir -p %[2]s && tar -xpf - --no-same-owner -C %[2]s%[3]s%[4]s && mv %[2]s %[5]s",
+		cleanup, staging, reuse, chown, remotePath,
+	)
+
+	_, stderr, err := client.ExecuteWithInput(extractCmd, reader)
+
+	// Unblock the tar writer if the remote side stopped reading early
+	_ = reader.CloseWithError(io.ErrClosedPipe)
+
+	if err != nil {
+		return fmt.Errorf("failed to extract directory to %s: %w (stderr: %s)",
+			remotePath, err, strings.TrimSpace(stderr))
+	}
+
+	// Owned copies are closed to the SSH user, so they are never reused and need no manifest
+	if owner == "" {
+		if err := client.UploadData(sumsManifest(sums), remotePath+sumsSuffix); err != nil {
+			e.logger.Warn().Err(err).Str("remote_path", remotePath).Msg("Failed to write mount checksum manifest")
+		}
+	}
+
+	return nil
+}
+
+// sumsSuffix names the manifest written next to an uploaded directory: one sha256sum-style
+// "<sum>  <path>" line per file, read by reusableFiles instead of checksumming every earlier upload.
+const sumsSuffix = ".sums"
+
+// sumsManifest renders sums (by slash-separated relative path) as a checksum manifest. Paths with a
+// line break cannot be listed and are left out, so they are never reused.
+func sumsManifest(sums map[string]string) []byte {
+	var manifest bytes.Buffer
+
+	for _, relPath := range slices.Sorted(maps.Keys(sums)) {
+		if !strings.Contains(relPath, "\n") {
+			manifest.WriteString(sums[relPath] + "  " + relPath + "\n")
+		}
+	}
+
+	return manifest.Bytes()
 }
 
-// uploadDirectory uploads a directory to the remote host.
-// For simplicity, we upload files individually rather than using tar.
-func (e *Executor) uploadDirectory(client ssh.Connection, localDir, remotePath string) error {
-	return e.uploadDirectoryRecursive(client, localDir, remotePath)
+// reusableFiles returns the local files (by slash-separated relative path) whose content is already on
+// the host at the same relative path in an earlier upload of a directory, mapped to that remote file.
+// Earlier versions stay in filesDir at least until the containers mounting them are redeployed, so a
+// changed directory usually only needs its changed files sent. Candidates are found in the manifests
+// earlier uploads left (see sumsSuffix), and only the one chosen per file is checksummed on the host,
+// since a read-write mount may have changed it since.
+func reusableFiles(client ssh.Connection, sums map[string]string) (map[string]string, error) {
+	dir := filesDir(client)
+
+	// Manifests of directories that are gone (collected, or removed by hand) are skipped
+	listCmd := fmt.Sprintf(
+		`cd %s 2>/dev/null && for f in *%[2]s; do [ -d "${f%%%[2]s}" ] && grep -H '' -- "$f"; done; true`,
+		dir, sumsSuffix,
+	)
+
+	stdout, stderr, err := client.Execute(listCmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read uploaded mount manifests: %w (stderr: %s)", err, stderr)
+	}
+
+	candidates := make(map[string]string)
+
+	for _, line := range strings.Split(stdout, "\n") {
+		manifest, entry, _ := strings.Cut(line, ":")
+		sum, relPath, ok := strings.Cut(entry, "  ")
+
+		// Only complete, world-readable uploads: staging directories and owned copies are left alone
+		upload := strings.TrimSuffix(manifest, sumsSuffix)
+		if !ok || !uploadedName.MatchString(upload) || sums[relPath] != sum {
+			continue
+		}
+
+		// sha256sum escapes backslashes in the paths it prints
+		if _, found := candidates[relPath]; !found && !strings.Contains(relPath, "\\") {
+			candidates[relPath] = dir + "/" + upload + "/" + relPath
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	var paths bytes.Buffer
+
+	for _, remotePath := range candidates {
+		paths.WriteString(remotePath + "\x00")
+	}
+
+	// Candidates that are missing or unreadable are left out of the output and sent instead
+	stdout, stderr, err = client.ExecuteWithInput("xargs -0 sha256sum -- 2>/dev/null; true", &paths)
+	if err != nil {
+		return nil, fmt.Errorf("failed to checksum reusable mount files: %w (stderr: %s)", err, stderr)
+	}
+
+	remoteSums := make(map[string]string)
+
+	for _, line := range strings.Split(stdout, "\n") {
+		if sum, remotePath, ok := strings.Cut(line, "  "); ok {
+			remoteSums[remotePath] = sum
+		}
+	}
+
+	reused := make(map[string]string)
+
+	for relPath, remotePath := range candidates {
+		if remoteSums[remotePath] == sums[relPath] {
+			reused[relPath] = remotePath
+		}
+	}
+
+	return reused, nil
 }
 
-// uploadDirectoryRecursive uploads a directory recursively file by file.
-func (*Executor) uploadDirectoryRecursive(client ssh.Connection, localDir, remoteBase string) error {
-	err := filepath.Walk(localDir, func(localPath string, info os.FileInfo, err error) error {
+// writeTar writes localDir as a tar archive, without the paths its hash.IgnoreFile excludes or the files
+// in skip (keyed by slash-separated relative path), which the host fills in itself. Directories are
+// stored as PermPublicDir and files as PermPublicFile so containers running as non-root users can read
+// the mount.
+func writeTar(w io.Writer, localDir string, skip map[string]string) error {
+	archive := tar.NewWriter(w)
+
+	// Walk like the mount's hash does, so the upload holds exactly the hashed files
+	err := hash.Walk(localDir, func(localPath string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -697,32 +1384,68 @@ func (*Executor) uploadDirectoryRecursive(client ssh.Connection, localDir, remot
 			return fmt.Errorf("%w: %w", ErrPathRelative, err)
 		}
 
-		remotePath := filepath.Join(remoteBase, relPath)
+		if relPath == "." {
+			return nil
+		}
 
-		if info.IsDir() {
-			// Create directory on remote
-			mkdirCmd := "mkdir -p " + remotePath
-			if _, _, err := client.Execute(mkdirCmd); err != nil {
-				return fmt.Errorf("failed to create remote directory %s: %w", remotePath, err)
-			}
-		} else {
-			// Upload file
-			if err := client.UploadFile(localPath, remotePath); err != nil {
-				return fmt.Errorf("failed to upload file %s: %w", localPath, err)
+		// Symlinks are followed, matching what a plain file copy would upload
+		if info.Mode()&os.ModeSymlink != 0 {
+			if info, err = os.Stat(localPath); err != nil {
+				return err
 			}
+		}
 
-			// Set permissions
-			chmodCmd := "chmod 644 " + remotePath
-			if _, _, err := client.Execute(chmodCmd); err != nil {
-				return fmt.Errorf("failed to set permissions on %s: %w", remotePath, err)
-			}
+		header := &tar.Header{
+			Name:    filepath.ToSlash(relPath),
+			ModTime: info.ModTime(),
 		}
 
-		return nil
+		if info.IsDir() {
+			header.Typeflag = tar.TypeDir
+			header.Name += "/"
+			header.Mode = int64(PermPublicDir)
+
+			return archive.WriteHeader(header)
+		}
+
+		if _, ok := skip[header.Name]; ok {
+			return nil
+		}
+
+		header.Typeflag = tar.TypeReg
+		header.Mode = int64(PermPublicFile)
+		header.Size = info.Size()
+
+		if err := archive.WriteHeader(header); err != nil {
+			return err
+		}
+
+		return copyFile(archive, localPath)
 	})
 	if err != nil {
 		return fmt.Errorf("%w: %w", ErrFileSystemWalk, err)
 	}
 
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to finish tar archive: %w", err)
+	}
+
+	return nil
+}
+
+// copyFile copies a local file's content to w.
+func copyFile(w io.Writer, localPath string) error {
+	// #nosec G304 -- localPath is controlled by plan author, not external user input
+	file, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", localPath, err)
+	}
+
+	defer func() { _ = file.Close() }()
+
+	if _, err := io.Copy(w, file); err != nil {
+		return fmt.Errorf("failed to read %s: %w", localPath, err)
+	}
+
 	return nil
 }
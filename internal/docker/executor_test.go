@@ -0,0 +1,1041 @@
+package docker_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/the-agent-c-ai/hadron/internal/docker"
+	"github.com/the-agent-c-ai/hadron/internal/testutil"
+	"github.com/the-agent-c-ai/hadron/sdk/ssh"
+)
+
+func TestWaitForFileWaitsUntilFileAppears(t *testing.T) {
+	t.Parallel()
+
+	const appearsAfter = 3
+
+	checks := 0
+	client := &testutil.FakeConnection{
+		Handler: func(_ string) (string, string, error) {
+			checks++
+			if checks < appearsAfter {
+				return "missing\n", "", nil
+			}
+
+			return "exists\n", "", nil
+		},
+	}
+
+	executor := docker.NewExecutor(nil, zerolog.Nop())
+
+	err := executor.WaitForFile(client, "/var/lib/docker/volumes/data/_data/.seeded", time.Second, time.Millisecond)
+	if err != nil {
+		t.Fatalf("expected wait to succeed, got: %v", err)
+	}
+
+	if checks != appearsAfter {
+		t.Errorf("expected %d checks before file appeared, got %d", appearsAfter, checks)
+	}
+}
+
+func TestWaitForFileTimesOut(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.FakeConnection{
+		Handler: func(_ string) (string, string, error) {
+			return "missing\n", "", nil
+		},
+	}
+
+	executor := docker.NewExecutor(nil, zerolog.Nop())
+
+	err := executor.WaitForFile(client, "/data/restore done", 10*time.Millisecond, time.Millisecond)
+	if !errors.Is(err, docker.ErrWaitForFileTimeout) {
+		t.Fatalf("expected ErrWaitForFileTimeout, got: %v", err)
+	}
+
+	if want := "test -e '/data/restore done'"; !strings.Contains(client.Commands[0], want) {
+		t.Errorf("expected the path to be quoted as %q, got %q", want, client.Commands[0])
+	}
+}
+
+func TestRunInitContainerSharesVolumeWithMain(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.FakeConnection{}
+	executor := docker.NewExecutor(nil, zerolog.Nop())
+
+	shared := []docker.VolumeMount{{Source: "app-data", Target: "/data"}}
+
+	err := executor.RunInitContainer(client, docker.ContainerRunOptions{
+		Name:    "app-seed",
+		Image:   "busybox",
+		Command: []string{"sh", "-c", "'echo seeded > /data/seed'"},
+		Volumes: shared,
+	})
+	if err != nil {
+		t.Fatalf("expected init container to succeed, got: %v", err)
+	}
+
+	err = executor.RunContainer(client, docker.ContainerRunOptions{
+		Name:    "app",
+		Image:   "busybox",
+		Command: []string{"cat", "/data/seed"},
+		Volumes: shared,
+	})
+	if err != nil {
+		t.Fatalf("expected main container to start, got: %v", err)
+	}
+
+	var initCmd, mainCmd string
+
+	for _, cmd := range client.Commands {
+		switch {
+		case strings.HasPrefix(cmd, "docker run --rm"):
+			initCmd = cmd
+		case strings.HasPrefix(cmd, "docker run -d"):
+			if initCmd == "" {
+				t.Fatal("expected init container to run before main container")
+			}
+
+			mainCmd = cmd
+		}
+	}
+
+	for _, cmd := range []string{initCmd, mainCmd} {
+		if !strings.Contains(cmd, "-v app-data:/data") {
+			t.Errorf("expected shared volume in command, got: %s", cmd)
+		}
+	}
+
+	if !strings.Contains(initCmd, "busybox sh -c 'echo seeded > /data/seed'") {
+		t.Errorf("expected init command to run in foreground, got: %s", initCmd)
+	}
+}
+
+func TestRunInitContainerFailure(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.FakeConnection{
+		Handler: func(command string) (string, string, error) {
+			if strings.HasPrefix(command, "docker run") {
+				return "", "migration failed", errors.New("exit status 1") //nolint:err113 // simulated remote failure
+			}
+
+			return "", "", nil
+		},
+	}
+
+	executor := docker.NewExecutor(nil, zerolog.Nop())
+
+	err := executor.RunInitContainer(client, docker.ContainerRunOptions{Name: "migrate", Image: "app"})
+	if !errors.Is(err, docker.ErrInitContainerFailed) {
+		t.Fatalf("expected ErrInitContainerFailed, got: %v", err)
+	}
+}
+
+func TestJobScript(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.FakeConnection{}
+
+	script, err := docker.NewExecutor(nil, zerolog.Nop()).JobScript(client, docker.ContainerRunOptions{
+		Name:    "backup",
+		Image:   "restic/restic",
+		Command: []string{"backup", "/data"},
+		Restart: "no",
+	})
+	if err != nil {
+		t.Fatalf("expected job script, got: %v", err)
+	}
+
+	want := "#!/bin/sh\n# Generated by Hadron\ndocker rm -f backup >/dev/null 2>&1 || true\n" +
+		"exec docker run --rm --name backup --restart no restic/restic backup /data\n"
+	if script != want {
+		t.Errorf("unexpected script:\n%s\nwant:\n%s", script, want)
+	}
+
+	if len(client.Commands) != 0 {
+		t.Errorf("expected nothing to run on the host, got %v", client.Commands)
+	}
+
+	if path := docker.JobScriptPath(&testutil.FakeConnection{}, "backup"); path != "/var/lib/hadron/jobs/backup.sh" {
+		t.Errorf("unexpected script path %q", path)
+	}
+}
+
+func TestRunContainerHealthFlags(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.FakeConnection{}
+	executor := docker.NewExecutor(nil, zerolog.Nop())
+
+	err := executor.RunContainer(client, docker.ContainerRunOptions{
+		Name:           "api",
+		Image:          "api:latest",
+		HealthCmd:      "grpc_health_probe -addr=localhost:50051",
+		HealthInterval: 5 * time.Second,
+		HealthTimeout:  30 * time.Second,
+		HealthRetries:  3,
+	})
+	if err != nil {
+		t.Fatalf("expected container to start, got: %v", err)
+	}
+
+	runCmd := client.Commands[len(client.Commands)-1]
+
+	for _, flag := range []string{
+		"--health-cmd 'grpc_health_probe -addr=localhost:50051'",
+		"--health-interval 5s",
+		"--health-timeout 30s",
+		"--health-retries 3",
+	} {
+		if !strings.Contains(runCmd, flag) {
+			t.Errorf("expected %q in run command, got: %s", flag, runCmd)
+		}
+	}
+}
+
+func TestRunContainerShmSize(t *testing.T) {
+	t.Parallel()
+
+	executor := docker.NewExecutor(nil, zerolog.Nop())
+
+	run := func(shmSize string) string {
+		client := &testutil.FakeConnection{}
+
+		err := executor.RunContainer(client, docker.ContainerRunOptions{
+			Name:    "renderer",
+			Image:   "renderer:latest",
+			ShmSize: shmSize,
+		})
+		if err != nil {
+			t.Fatalf("expected container to start, got: %v", err)
+		}
+
+		return client.Commands[len(client.Commands)-1]
+	}
+
+	if runCmd := run(""); strings.Contains(runCmd, "--shm-size") {
+		t.Errorf("expected no --shm-size without a size, got: %s", runCmd)
+	}
+
+	if runCmd := run("1g"); !strings.Contains(runCmd, " --shm-size 1g") {
+		t.Errorf("expected --shm-size 1g in run command, got: %s", runCmd)
+	}
+}
+
+func TestRunContainerOOMScoreAdj(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.FakeConnection{}
+	executor := docker.NewExecutor(nil, zerolog.Nop())
+
+	err := executor.RunContainer(client, docker.ContainerRunOptions{
+		Name:        "db",
+		Image:       "postgres:17",
+		Restart:     "on-failure:5",
+		OOMScoreAdj: -500,
+	})
+	if err != nil {
+		t.Fatalf("expected container to start, got: %v", err)
+	}
+
+	runCmd := client.Commands[len(client.Commands)-1]
+
+	for _, flag := range []string{" --oom-score-adj -500", " --restart on-failure:5"} {
+		if !strings.Contains(runCmd, flag) {
+			t.Errorf("expected %q in run command, got: %s", flag, runCmd)
+		}
+	}
+}
+
+func TestRunContainerLogging(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.FakeConnection{}
+	executor := docker.NewExecutor(nil, zerolog.Nop())
+
+	err := executor.RunContainer(client, docker.ContainerRunOptions{
+		Name:      "worker",
+		Image:     "worker:latest",
+		LogDriver: "local",
+		LogOpts:   map[string]string{"max-size": "10m", "max-file": "3"},
+	})
+	if err != nil {
+		t.Fatalf("expected container to start, got: %v", err)
+	}
+
+	runCmd := client.Commands[len(client.Commands)-1]
+
+	if want := " --log-driver local --log-opt 'max-file=3' --log-opt 'max-size=10m'"; !strings.Contains(runCmd, want) {
+		t.Errorf("expected %q in run command, got: %s", want, runCmd)
+	}
+}
+
+func TestCreateNetworkWithSubnet(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.FakeConnection{}
+	executor := docker.NewExecutor(nil, zerolog.Nop())
+
+	err := executor.CreateNetwork(client, "app-net", "bridge", nil, docker.NetworkOptions{
+		Subnet:  "172.28.0.0/16",
+		Gateway: "172.28.0.1",
+		IPRange: "172.28.5.0/24",
+	})
+	if err != nil {
+		t.Fatalf("expected network creation to succeed, got: %v", err)
+	}
+
+	want := "docker network create -d bridge" +
+		" --subnet 172.28.0.0/16 --gateway 172.28.0.1 --ip-range 172.28.5.0/24 app-net"
+	if len(client.Commands) != 1 || client.Commands[0] != want {
+		t.Errorf("expected %q, got %v", want, client.Commands)
+	}
+}
+
+func TestCreateNFSVolume(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.FakeConnection{}
+	executor := docker.NewExecutor(nil, zerolog.Nop())
+
+	err := executor.CreateVolume(client, "shared", "local", nil, map[string]string{
+		"type":   "nfs",
+		"o":      "addr=10.0.0.2,rw",
+		"device": ":/exports/shared",
+	})
+	if err != nil {
+		t.Fatalf("expected volume creation to succeed, got: %v", err)
+	}
+
+	want := "docker volume create --driver local" +
+		" --opt 'device=:/exports/shared' --opt 'o=addr=10.0.0.2,rw' --opt 'type=nfs' shared"
+	if len(client.Commands) != 1 || client.Commands[0] != want {
+		t.Errorf("expected %q, got %v", want, client.Commands)
+	}
+}
+
+func TestCreateInternalNetwork(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.FakeConnection{}
+	executor := docker.NewExecutor(nil, zerolog.Nop())
+
+	err := executor.CreateNetwork(client, "db-net", "bridge", nil, docker.NetworkOptions{Internal: true})
+	if err != nil {
+		t.Fatalf("expected network creation to succeed, got: %v", err)
+	}
+
+	if len(client.Commands) != 1 || !strings.Contains(client.Commands[0], " --internal ") {
+		t.Errorf("expected --internal flag, got %v", client.Commands)
+	}
+}
+
+func TestCreateDualStackNetwork(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.FakeConnection{}
+	executor := docker.NewExecutor(nil, zerolog.Nop())
+
+	err := executor.CreateNetwork(client, "dual", "bridge", nil, docker.NetworkOptions{
+		Subnet: "172.28.0.0/16",
+		IPv6:   "fd00:dead:beef::/48",
+	})
+	if err != nil {
+		t.Fatalf("expected network creation to succeed, got: %v", err)
+	}
+
+	want := "docker network create -d bridge --subnet 172.28.0.0/16 --ipv6 --subnet fd00:dead:beef::/48 dual"
+	if len(client.Commands) != 1 || client.Commands[0] != want {
+		t.Errorf("expected %q, got %v", want, client.Commands)
+	}
+}
+
+func TestNetworkContainers(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.FakeConnection{
+		Handler: func(_ string) (string, string, error) {
+			return "web worker \n", "", nil
+		},
+	}
+
+	executor := docker.NewExecutor(nil, zerolog.Nop())
+
+	attached, err := executor.NetworkContainers(client, "app-net")
+	if err != nil {
+		t.Fatalf("expected inspect to succeed, got: %v", err)
+	}
+
+	if len(attached) != 2 || attached[0] != "web" || attached[1] != "worker" {
+		t.Errorf("expected [web worker], got %v", attached)
+	}
+}
+
+func TestRegistryLoginSendsPasswordOnStdin(t *testing.T) {
+	t.Parallel()
+
+	const password = "it's-a-secret"
+
+	client := &testutil.FakeConnection{}
+
+	err := docker.NewExecutor(nil, zerolog.Nop()).RegistryLogin(client, "ghcr.io", "deploy", password)
+	if err != nil {
+		t.Fatalf("expected login to succeed, got: %v", err)
+	}
+
+	if want := "docker login -u 'deploy' --password-stdin 'ghcr.io'"; len(client.Commands) != 1 ||
+		client.Commands[0] != want {
+		t.Fatalf("expected %q, got %v", want, client.Commands)
+	}
+
+	// A quote in the password can neither break nor show up in the command line
+	if string(client.Stdin) != password {
+		t.Errorf("expected the password on stdin, got %q", client.Stdin)
+	}
+}
+
+func TestRegistryLogout(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.FakeConnection{}
+	executor := docker.NewExecutor(nil, zerolog.Nop())
+
+	if err := executor.RegistryLogout(client, "ghcr.io"); err != nil {
+		t.Fatalf("expected logout to succeed, got: %v", err)
+	}
+
+	if len(client.Commands) != 1 || client.Commands[0] != "docker logout 'ghcr.io'" {
+		t.Errorf("expected a single docker logout command, got %v", client.Commands)
+	}
+}
+
+// writeTree creates a directory tree of count small files spread over subdirectories.
+func writeTree(tb testing.TB, count int) string {
+	tb.Helper()
+
+	dir := tb.TempDir()
+
+	for i := range count {
+		path := filepath.Join(dir, fmt.Sprintf("conf-%d", i%10), fmt.Sprintf("file-%d.yml", i))
+
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			tb.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(fmt.Sprintf("id: %d\n", i)), 0o600); err != nil {
+			tb.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+// errMissing fails existence checks in FakeConnection handlers.
+var errMissing = errors.New("missing")
+
+// missingMount fails the mount existence check so the directory is uploaded.
+func missingMount(command string) (string, string, error) {
+	if strings.HasPrefix(command, "test -e") {
+		return "", "", errMissing
+	}
+
+	return "", "", nil
+}
+
+func TestUploadMountStreamsDirectoryAsTar(t *testing.T) {
+	t.Parallel()
+
+	const fileCount = 500
+
+	dir := writeTree(t, fileCount)
+	client := &testutil.FakeConnection{Handler: missingMount}
+	executor := docker.NewExecutor(nil, zerolog.Nop())
+
+	remotePath, err := executor.UploadMount(client, dir, "")
+	if err != nil {
+		t.Fatalf("expected upload to succeed, got: %v", err)
+	}
+
+	// The file-by-file upload needed a mkdir or chmod per entry; the tar stream needs one session
+	if len(client.Commands) != 3 {
+		t.Fatalf("expected existence check, checksums, and a single extract command, got %d commands",
+			len(client.Commands))
+	}
+
+	if !strings.Contains(client.Commands[2], "tar -xpf -") || !strings.HasSuffix(client.Commands[2], remotePath) {
+		t.Errorf("expected tar extraction into %s, got %q", remotePath, client.Commands[2])
+	}
+
+	files := 0
+	archive := tar.NewReader(bytes.NewReader(client.Stdin))
+
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			t.Fatalf("invalid tar stream: %v", err)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeReg:
+			files++
+
+			if os.FileMode(header.Mode) != docker.PermPublicFile {
+				t.Errorf("expected %s to have mode %o, got %o", header.Name, docker.PermPublicFile, header.Mode)
+			}
+		case tar.TypeDir:
+			if os.FileMode(header.Mode) != docker.PermPublicDir {
+				t.Errorf("expected %s to have mode %o, got %o", header.Name, docker.PermPublicDir, header.Mode)
+			}
+		}
+	}
+
+	if files != fileCount {
+		t.Errorf("expected %d files in the archive, got %d", fileCount, files)
+	}
+}
+
+func TestUploadMountSkipsExistingDirectory(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.FakeConnection{
+		Handler: func(_ string) (string, string, error) { return "exists\n", "", nil },
+	}
+
+	if _, err := docker.NewExecutor(nil, zerolog.Nop()).UploadMount(client, writeTree(t, 3), ""); err != nil {
+		t.Fatalf("expected upload to succeed, got: %v", err)
+	}
+
+	if len(client.Commands) != 1 || client.Stdin != nil {
+		t.Errorf("expected only the existence check, got %v", client.Commands)
+	}
+}
+
+func TestUploadMountReusesUnchangedFiles(t *testing.T) {
+	t.Parallel()
+
+	const previous = "/var/lib/hadron/files/4444444444444444444444444444444444444444444444444444444444444444"
+
+	sum := func(content string) string { return fmt.Sprintf("%x", sha256.Sum256([]byte(content))) }
+
+	client := &testutil.FakeConnection{}
+	client.Handler = func(command string) (string, string, error) {
+		switch {
+		case strings.HasPrefix(command, "test -e"):
+			return "", "", errMissing
+		case strings.Contains(command, "grep -H"):
+			// file-1.yml changed since the previous upload, file-0.yml and file-2.yml did not
+			name := filepath.Base(previous) + ".sums:"
+
+			return name + sum("id: 0\n") + "  conf-0/file-0.yml\n" +
+				name + sum("id: old\n") + "  conf-1/file-1.yml\n" +
+				name + sum("id: 2\n") + "  conf-2/file-2.yml\n", "", nil
+		case strings.Contains(command, "sha256sum"):
+			// A read-write mount changed file-2.yml on the host after the manifest was written
+			return sum("id: 0\n") + "  " + previous + "/conf-0/file-0.yml\n" +
+				sum("id: edited\n") + "  " + previous + "/conf-2/file-2.yml\n", "", nil
+		}
+
+		return "", "", nil
+	}
+
+	remotePath, err := docker.NewExecutor(nil, zerolog.Nop()).UploadMount(client, writeTree(t, 3), "")
+	if err != nil {
+		t.Fatalf("expected upload to succeed, got: %v", err)
+	}
+
+	if want := "xargs -0 -n 2 cp -- < " + remotePath + ".reuse"; !strings.Contains(client.Commands[3], want) {
+		t.Errorf("expected the host to copy reused files, got %q", client.Commands[3])
+	}
+
+	if want := "rm -f " + remotePath + ".reuse"; client.Commands[len(client.Commands)-1] != want {
+		t.Errorf("expected the reused file list to be removed, got %v", client.Commands)
+	}
+
+	if want := previous + "/conf-0/file-0.yml\x00" + remotePath + ".tmp/conf-0/file-0.yml\x00"; string(
+		client.Uploads[remotePath+".reuse"]) != want {
+		t.Errorf("expected only file-0.yml to be reused, got %q", client.Uploads[remotePath+".reuse"])
+	}
+
+	manifest := sum("id: 0\n") + "  conf-0/file-0.yml\n" + sum("id: 1\n") + "  conf-1/file-1.yml\n" +
+		sum("id: 2\n") + "  conf-2/file-2.yml\n"
+	if got := string(client.Uploads[remotePath+".sums"]); got != manifest {
+		t.Errorf("expected a checksum manifest next to the upload, got %q", got)
+	}
+
+	archive := tar.NewReader(bytes.NewReader(client.Stdin))
+
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			t.Fatalf("invalid tar stream: %v", err)
+		}
+
+		if header.Name == "conf-0/file-0.yml" {
+			t.Error("expected the unchanged file to be left out of the archive")
+		}
+	}
+}
+
+func TestUploadMountWithoutReuseLookup(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.FakeConnection{}
+	client.Handler = func(command string) (string, string, error) {
+		switch {
+		case strings.HasPrefix(command, "test -e"):
+			return "", "", errMissing
+		case strings.Contains(command, "grep -H"):
+			return "", "", ssh.ErrCommandTimeout
+		}
+
+		return "", "", nil
+	}
+
+	// Reuse only saves bandwidth, so a failed lookup sends every file instead of failing the upload
+	if _, err := docker.NewExecutor(nil, zerolog.Nop()).UploadMount(client, writeTree(t, 3), ""); err != nil {
+		t.Fatalf("expected upload to succeed, got: %v", err)
+	}
+
+	if strings.Contains(client.Commands[2], "xargs") {
+		t.Errorf("expected no files to be reused, got %q", client.Commands[2])
+	}
+}
+
+func TestUploadDataMountSkipsMatchingChecksum(t *testing.T) {
+	t.Parallel()
+
+	data := []byte("scrape_interval: 15s\n")
+
+	for _, tc := range []struct {
+		name   string
+		remote string
+		upload bool
+	}{
+		{"missing", "", true},
+		{"incomplete", fmt.Sprintf("%x", sha256.Sum256(data[:5])), true},
+		{"matching", fmt.Sprintf("%x", sha256.Sum256(data)), false},
+	} {
+		client := &testutil.FakeConnection{
+			Handler: func(command string) (string, string, error) {
+				if strings.HasPrefix(command, "sha256sum") {
+					return tc.remote + "\n", "", nil
+				}
+
+				return "", "", nil
+			},
+		}
+
+		if _, err := docker.NewExecutor(nil, zerolog.Nop()).UploadDataMount(client, data, ""); err != nil {
+			t.Fatalf("%s: expected upload to succeed, got: %v", tc.name, err)
+		}
+
+		if uploaded := len(client.Commands) > 1; uploaded != tc.upload {
+			t.Errorf("%s: expected upload %v, got commands %v", tc.name, tc.upload, client.Commands)
+		}
+	}
+}
+
+func TestUploadMountChownsDirectoryToOwner(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.FakeConnection{Handler: missingMount}
+
+	remotePath, err := docker.NewExecutor(nil, zerolog.Nop()).UploadMount(client, writeTree(t, 3), "473:473")
+	if err != nil {
+		t.Fatalf("expected upload to succeed, got: %v", err)
+	}
+
+	if !strings.HasSuffix(remotePath, "-473-473") {
+		t.Errorf("expected the owner in the remote path, got %s", remotePath)
+	}
+
+	staging := remotePath + ".tmp"
+	want := fmt.Sprintf("chmod -R go-rwx %[1]s && sudo chown -R 473:473 %[1]s && mv %[1]s %[2]s", staging, remotePath)
+
+	if len(client.Commands) != 3 || !strings.HasSuffix(client.Commands[2], want) {
+		t.Errorf("expected the extracted tree to be chowned before the move, got %v", client.Commands)
+	}
+}
+
+func TestUploadDataMountChownsFileToOwner(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.FakeConnection{}
+	client.Handler = func(command string) (string, string, error) {
+		if strings.HasPrefix(command, "test -e") {
+			return "", "", errMissing
+		}
+
+		return "", "", nil
+	}
+
+	remotePath, err := docker.NewExecutor(nil, zerolog.Nop()).UploadDataMount(client, []byte("token"), "473")
+	if err != nil {
+		t.Fatalf("expected upload to succeed, got: %v", err)
+	}
+
+	if !strings.HasSuffix(remotePath, "-473") {
+		t.Errorf("expected the owner in the remote path, got %s", remotePath)
+	}
+
+	want := fmt.Sprintf("sudo chown 473 %[1]s.tmp && mv %[1]s.tmp %[1]s", remotePath)
+	if last := client.Commands[len(client.Commands)-1]; last != want {
+		t.Errorf("expected %q, got %q", want, last)
+	}
+}
+
+func BenchmarkUploadMount500Files(b *testing.B) {
+	dir := writeTree(b, 500)
+	executor := docker.NewExecutor(nil, zerolog.Nop())
+
+	for b.Loop() {
+		client := &testutil.FakeConnection{Handler: missingMount}
+
+		if _, err := executor.UploadMount(client, dir, ""); err != nil {
+			b.Fatal(err)
+		}
+
+		b.ReportMetric(float64(len(client.Commands)), "commands/op")
+	}
+}
+
+func TestCollectGarbageRemovesOnlyUnmountedManagedFiles(t *testing.T) {
+	t.Parallel()
+
+	const (
+		mounted  = "1111111111111111111111111111111111111111111111111111111111111111"
+		inDir    = "2222222222222222222222222222222222222222222222222222222222222222"
+		orphaned = "3333333333333333333333333333333333333333333333333333333333333333"
+	)
+
+	client := &testutil.FakeConnection{
+		Handler: func(command string) (string, string, error) {
+			switch {
+			case strings.HasPrefix(command, "find "):
+				return strings.Join([]string{mounted, inDir, orphaned, "notes.txt"}, "\n"), "", nil
+			case strings.HasPrefix(command, "docker ps"):
+				return "/var/lib/hadron/files/" + mounted + " /var/lib/hadron/files/" + inDir + "/nginx.conf" +
+					" /srv/data\n", "", nil
+			}
+
+			return "", "", nil
+		},
+	}
+
+	removed, err := docker.NewExecutor(nil, zerolog.Nop()).CollectGarbage(client)
+	if err != nil {
+		t.Fatalf("expected garbage collection to succeed, got: %v", err)
+	}
+
+	want := "/var/lib/hadron/files/" + orphaned
+	if len(removed) != 1 || removed[0] != want {
+		t.Fatalf("expected only %s to be removed, got %v", want, removed)
+	}
+
+	// The directory's checksum manifest goes with it
+	if last := client.Commands[len(client.Commands)-1]; last != "sudo rm -rf "+want+" "+want+".sums" {
+		t.Errorf("expected 'sudo rm -rf %[1]s %[1]s.sums', got %q", want, last)
+	}
+}
+
+func TestCollectGarbageRemovesStaleStaging(t *testing.T) {
+	t.Parallel()
+
+	const (
+		upload = "6666666666666666666666666666666666666666666666666666666666666666"
+		owned  = "7777777777777777777777777777777777777777777777777777777777777777-473-473"
+	)
+
+	client := &testutil.FakeConnection{
+		Handler: func(command string) (string, string, error) {
+			switch {
+			case strings.HasPrefix(command, "find "):
+				return upload + "\n" + upload + ".tmp\n" + upload + ".reuse\n" + owned + ".tmp\nnotes.tmp\n", "", nil
+			case strings.HasPrefix(command, "docker ps"):
+				return "/var/lib/hadron/files/" + upload + "\n", "", nil
+			}
+
+			return "", "", nil
+		},
+	}
+
+	removed, err := docker.NewExecutor(nil, zerolog.Nop()).CollectGarbage(client)
+	if err != nil {
+		t.Fatalf("expected garbage collection to succeed, got: %v", err)
+	}
+
+	// Leftovers of interrupted uploads go even when the finished upload is mounted
+	want := []string{
+		"/var/lib/hadron/files/" + upload + ".tmp",
+		"/var/lib/hadron/files/" + upload + ".reuse",
+		"/var/lib/hadron/files/" + owned + ".tmp",
+	}
+	if !slices.Equal(removed, want) {
+		t.Errorf("expected %v to be removed, got %v", want, removed)
+	}
+
+	// Entries a concurrent deploy may have just written are not even listed
+	if !strings.Contains(client.Commands[0], "-mmin +60") {
+		t.Errorf("expected only old entries to be listed, got %q", client.Commands[0])
+	}
+}
+
+func TestCollectGarbageKeepsJobFiles(t *testing.T) {
+	t.Parallel()
+
+	const (
+		envFile = "4444444444444444444444444444444444444444444444444444444444444444"
+		mount   = "5555555555555555555555555555555555555555555555555555555555555555"
+	)
+
+	client := &testutil.FakeConnection{
+		Handler: func(command string) (string, string, error) {
+			switch {
+			case strings.HasPrefix(command, "find "):
+				return envFile + "\n" + mount, "", nil
+			case strings.HasPrefix(command, "cat /var/lib/hadron/jobs/"):
+				return "exec docker run --rm --name backup -v /var/lib/hadron/files/" + mount + ":/etc/backup.conf:ro" +
+					" --env-file /var/lib/hadron/files/" + envFile + " restic\n", "", nil
+			}
+
+			return "", "", nil
+		},
+	}
+
+	removed, err := docker.NewExecutor(nil, zerolog.Nop()).CollectGarbage(client)
+	if err != nil || removed != nil {
+		t.Fatalf("expected files used by job scripts to be kept, got %v (err: %v)", removed, err)
+	}
+}
+
+func TestCollectGarbageWithNothingOrphaned(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.FakeConnection{}
+
+	removed, err := docker.NewExecutor(nil, zerolog.Nop()).CollectGarbage(client)
+	if err != nil || removed != nil {
+		t.Fatalf("expected nothing removed, got %v (err: %v)", removed, err)
+	}
+
+	for _, command := range client.Commands {
+		if strings.HasPrefix(command, "rm ") {
+			t.Errorf("expected no removal, got %q", command)
+		}
+	}
+}
+
+func TestExecCommandQuotesArguments(t *testing.T) {
+	t.Parallel()
+
+	got := docker.ExecCommand("caddy", []string{"sh", "-lc", "echo 'hi' && caddy version"}, true)
+
+	want := `docker exec -i -t caddy 'sh' '-lc' 'echo '\''hi'\'' && caddy version'`
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	if got := docker.ExecCommand("caddy", []string{"ls"}, false); got != "docker exec -i caddy 'ls'" {
+		t.Errorf("expected no -t without a terminal, got %q", got)
+	}
+}
+
+func TestPullImageStreamsProgressAtDebug(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.FakeConnection{
+		Handler: func(_ string) (string, string, error) {
+			return "latest: Pulling from library/nginx\nDigest: sha256:abc\n" +
+				"Status: Downloaded newer image for nginx:latest", "", nil
+		},
+	}
+
+	var logs bytes.Buffer
+
+	executor := docker.NewExecutor(nil, zerolog.New(&logs).Level(zerolog.DebugLevel))
+
+	pulled, err := executor.PullImage(client, "nginx:latest")
+	if err != nil {
+		t.Fatalf("expected pull to succeed, got: %v", err)
+	}
+
+	if !pulled {
+		t.Error("expected a newer image to be reported as pulled")
+	}
+
+	for _, line := range []string{"latest: Pulling from library/nginx", "Status: Downloaded newer image"} {
+		if !strings.Contains(logs.String(), line) {
+			t.Errorf("expected pull output %q in the debug log, got:\n%s", line, logs.String())
+		}
+	}
+}
+
+func TestWaitHealthy(t *testing.T) {
+	t.Parallel()
+
+	states := []string{"running starting", "running starting", "running healthy"}
+	client := &testutil.FakeConnection{
+		Handler: func(_ string) (string, string, error) {
+			state := states[0]
+			if len(states) > 1 {
+				states = states[1:]
+			}
+
+			return state + "\n", "", nil
+		},
+	}
+
+	executor := docker.NewExecutor(nil, zerolog.Nop())
+
+	if err := executor.WaitHealthy(client, "app", time.Second, time.Millisecond); err != nil {
+		t.Fatalf("expected container to become healthy, got: %v", err)
+	}
+
+	if len(client.Commands) != 3 {
+		t.Errorf("expected 3 state checks, got %d", len(client.Commands))
+	}
+
+	// A container that exited never becomes healthy, so the wait stops right away
+	exited := &testutil.FakeConnection{
+		Handler: func(_ string) (string, string, error) {
+			return "exited \n", "", nil
+		},
+	}
+
+	err := executor.WaitHealthy(exited, "app", time.Second, time.Millisecond)
+	if !errors.Is(err, docker.ErrContainerUnhealthy) {
+		t.Fatalf("expected ErrContainerUnhealthy, got: %v", err)
+	}
+
+	if len(exited.Commands) != 1 {
+		t.Errorf("expected a single state check, got %d", len(exited.Commands))
+	}
+}
+
+func TestExecFailure(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.FakeConnection{
+		Handler: func(_ string) (string, string, error) {
+			return "", "relation already exists", errors.New("exit status 1") //nolint:err113 // simulated remote failure
+		},
+	}
+
+	executor := docker.NewExecutor(nil, zerolog.Nop())
+
+	_, err := executor.Exec(client, "app", []string{"migrate", "up"})
+	if !errors.Is(err, docker.ErrExecFailed) {
+		t.Fatalf("expected ErrExecFailed, got: %v", err)
+	}
+
+	if want := "docker exec -i app 'migrate' 'up'"; client.Commands[0] != want {
+		t.Errorf("expected %q, got %q", want, client.Commands[0])
+	}
+}
+
+func TestPruneImages(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.FakeConnection{
+		Handler: func(command string) (string, string, error) {
+			switch {
+			case strings.HasPrefix(command, "docker image ls"):
+				return "sha256:aaa\nsha256:bbb\n", "", nil
+			case strings.HasPrefix(command, "docker image inspect"):
+				return "sha256:ccc\n", "", nil
+			case strings.HasPrefix(command, "docker image prune"):
+				return "Deleted Images:\ndeleted: sha256:aaa\n\nTotal reclaimed space: 1.2GB\n", "", nil
+			}
+
+			return "", "", nil
+		},
+	}
+
+	executor := docker.NewExecutor(nil, zerolog.Nop())
+
+	reclaimed, err := executor.PruneImages(client, []string{"app:1"}, "72h")
+	if err != nil {
+		t.Fatalf("expected prune to succeed, got: %v", err)
+	}
+
+	if reclaimed != "1.2GB" {
+		t.Errorf("expected 1.2GB reclaimed, got %q", reclaimed)
+	}
+
+	if want := "docker image prune -f --filter 'until=72h'"; client.Commands[len(client.Commands)-1] != want {
+		t.Errorf("expected %q, got %q", want, client.Commands[len(client.Commands)-1])
+	}
+}
+
+func TestPruneImagesKeepsPlanImages(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.FakeConnection{
+		Handler: func(command string) (string, string, error) {
+			if strings.HasPrefix(command, "docker image inspect") {
+				return "sha256:bbb\n", "", nil
+			}
+
+			return "sha256:aaa\nsha256:bbb\n", "", nil
+		},
+	}
+
+	executor := docker.NewExecutor(nil, zerolog.Nop())
+
+	_, err := executor.PruneImages(client, []string{"app:1"}, "")
+	if !errors.Is(err, docker.ErrImageInUse) {
+		t.Fatalf("expected ErrImageInUse, got: %v", err)
+	}
+
+	for _, command := range client.Commands {
+		if strings.HasPrefix(command, "docker image prune") {
+			t.Errorf("expected no prune while a plan image is dangling, got %q", command)
+		}
+	}
+}
+
+func TestBackupVolume(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.FakeConnection{}
+	executor := docker.NewExecutor(nil, zerolog.Nop())
+
+	archive, err := executor.BackupVolume(client, "pgdata", "busybox:stable")
+	if err != nil {
+		t.Fatalf("expected backup to succeed, got: %v", err)
+	}
+
+	if !strings.HasPrefix(archive, "/var/lib/hadron/backups/pgdata-") || !strings.HasSuffix(archive, ".tar.gz") {
+		t.Errorf("expected a timestamped archive under /var/lib/hadron/backups, got %q", archive)
+	}
+
+	want := "sudo mkdir -p /var/lib/hadron/backups && docker run --rm --network none " +
+		"-v pgdata:/volume:ro -v /var/lib/hadron/backups:/backup busybox:stable " +
+		"tar -czf /backup/" + filepath.Base(archive) + " -C /volume ."
+	if client.Commands[0] != want {
+		t.Errorf("expected %q, got %q", want, client.Commands[0])
+	}
+}
@@ -0,0 +1,204 @@
+package docker
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/the-agent-c-ai/hadron/sdk/ssh"
+)
+
+// filesDir is where uploaded env files, mounts, and secrets are stored, named by content hash.
+func filesDir(client ssh.Connection) string {
+	return ssh.WorkPath(client, "files")
+}
+
+// jobsDir holds the scripts systemd timers run for scheduled jobs (see JobScript).
+func jobsDir(client ssh.Connection) string {
+	return ssh.WorkPath(client, "jobs")
+}
+
+// backupsDir holds volume archives taken before a volume is recreated (see BackupVolume).
+func backupsDir(client ssh.Connection) string {
+	return ssh.WorkPath(client, "backups")
+}
+
+// uploadedName matches the name of a world-readable upload: its content hash alone.
+var uploadedName = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// managedFileName matches the sha256 names hadron gives uploaded files, with the owner suffix of chowned
+// mounts (see ownedPath); anything else in filesDir was not written by hadron and is never removed.
+var managedFileName = regexp.MustCompile(`^[0-9a-f]{64}(-[0-9]+){0,2}$`)
+
+// stagingFileName matches what an interrupted upload leaves behind next to a managed name: its staging
+// file or directory, or the list of files it reused (see uploadDirectory).
+var stagingFileName = regexp.MustCompile(`^[0-9a-f]{64}(-[0-9]+){0,2}\.(tmp|reuse)$`)
+
+// gcMinAgeMinutes is how long an entry of filesDir is kept after it was last written, whether or not
+// a container mounts it: a deploy of another plan sharing the host may have just uploaded it for a
+// container it has not started yet, or still be writing its staging copy.
+const gcMinAgeMinutes = 60
+
+// reclaimedSpace matches the summary line of docker image prune.
+var reclaimedSpace = regexp.MustCompile(`Total reclaimed space:\s*(\S+)`)
+
+// jobFileReference matches an uploaded file in a job script's env file or volume arguments, below
+// /files/ in whichever work directory the job was written for.
+var jobFileReference = regexp.MustCompile(`/files/([0-9a-f]{64}(?:-[0-9]+){0,2})`)
+
+// CollectGarbage removes uploaded files in filesDir that no container on the host mounts anymore,
+// and the staging leftovers of interrupted uploads, and returns the removed paths. Mounts are read
+// from every container on the host, not only the plan's, so files used by other plans sharing the
+// host are kept. Env files are only read by docker run, so they are always collectable once their
+// container exists, unless a scheduled job's script still passes them to its next run. Entries
+// written in the last gcMinAgeMinutes are never removed.
+func (e *Executor) CollectGarbage(client ssh.Connection) ([]string, error) {
+	dir := filesDir(client)
+
+	listCmd := fmt.Sprintf("find %s -mindepth 1 -maxdepth 1 -mmin +%d -printf '%%f\\n' 2>/dev/null || true",
+		dir, gcMinAgeMinutes)
+
+	stdout, stderr, err := client.Execute(listCmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w (stderr: %s)", dir, err, stderr)
+	}
+
+	inUse, err := mountedFiles(client)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduled, err := jobFiles(client)
+	if err != nil {
+		return nil, err
+	}
+
+	inUse = append(inUse, scheduled...)
+
+	var orphaned []string
+
+	for _, name := range strings.Fields(stdout) {
+		stale := stagingFileName.MatchString(name)
+		if stale || managedFileName.MatchString(name) && !slices.Contains(inUse, name) {
+			orphaned = append(orphaned, path.Join(dir, name))
+		}
+	}
+
+	if len(orphaned) == 0 {
+		e.logger.Debug().Msg("No orphaned files to remove")
+
+		return nil, nil
+	}
+
+	// An uploaded directory's checksum manifest goes with it
+	removed := slices.Clone(orphaned)
+	for _, orphan := range orphaned {
+		if managedFileName.MatchString(path.Base(orphan)) {
+			removed = append(removed, orphan+sumsSuffix)
+		}
+	}
+
+	// Chowned mounts belong to their container's user, so only root can remove them
+	if _, stderr, err := ssh.RunPrivileged(client, "rm -rf "+strings.Join(removed, " ")); err != nil {
+		return nil, fmt.Errorf("failed to remove orphaned files: %w (stderr: %s)", err, stderr)
+	}
+
+	e.logger.Info().Int("count", len(orphaned)).Msg("Removed orphaned files")
+
+	return orphaned, nil
+}
+
+// mountedFiles returns the names of filesDir entries bind-mounted into any container on the host,
+// running or stopped.
+func mountedFiles(client ssh.Connection) ([]string, error) {
+	cmd := `docker ps -aq | xargs -r docker container inspect -f '{{range .Mounts}}{{.Source}} {{end}}'`
+
+	stdout, stderr, err := client.Execute(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect container mounts: %w (stderr: %s)", err, stderr)
+	}
+
+	var names []string
+
+	for _, source := range strings.Fields(stdout) {
+		rel, ok := strings.CutPrefix(source, filesDir(client)+"/")
+		if !ok {
+			continue
+		}
+
+		// Keep the whole entry even if only part of an uploaded directory is mounted
+		name, _, _ := strings.Cut(rel, "/")
+		names = append(names, name)
+	}
+
+	return names, nil
+}
+
+// jobFiles returns the names of filesDir entries referenced by scheduled job scripts, which use
+// them on every run although no container exists between runs.
+func jobFiles(client ssh.Connection) ([]string, error) {
+	cmd := fmt.Sprintf("cat %s/*.sh 2>/dev/null || true", jobsDir(client))
+
+	stdout, stderr, err := client.Execute(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job scripts: %w (stderr: %s)", err, stderr)
+	}
+
+	matches := jobFileReference.FindAllStringSubmatch(stdout, -1)
+
+	names := make([]string, 0, len(matches))
+	for _, match := range matches {
+		names = append(names, match[1])
+	}
+
+	return names, nil
+}
+
+// PruneImages removes dangling images with docker image prune, optionally only those created before
+// until (a docker duration or timestamp, e.g. "24h"), and returns the reclaimed space as docker
+// reports it (e.g. "1.2GB"). It refuses with ErrImageInUse when one of keep (the images plan
+// containers use) is dangling, rather than trusting prune to leave it alone.
+func (e *Executor) PruneImages(client ssh.Connection, keep []string, until string) (string, error) {
+	stdout, stderr, err := client.Execute("docker image ls -q --no-trunc -f dangling=true")
+	if err != nil {
+		return "", fmt.Errorf("failed to list dangling images: %w (stderr: %s)", err, stderr)
+	}
+
+	dangling := strings.Fields(stdout)
+
+	if len(dangling) == 0 {
+		e.logger.Debug().Msg("No dangling images to prune")
+
+		return "0B", nil
+	}
+
+	for _, image := range keep {
+		id, _, err := client.Execute(fmt.Sprintf("docker image inspect -f '{{.Id}}' %s 2>/dev/null || true", image))
+		if err != nil {
+			return "", fmt.Errorf("failed to inspect image %s: %w", image, err)
+		}
+
+		if id = strings.TrimSpace(id); id != "" && slices.Contains(dangling, id) {
+			return "", fmt.Errorf("%w: %s (%s)", ErrImageInUse, image, id)
+		}
+	}
+
+	cmd := "docker image prune -f"
+	if until != "" {
+		cmd += " --filter " + shellQuote("until="+until)
+	}
+
+	stdout, stderr, err = client.Execute(cmd)
+	if err != nil {
+		return "", fmt.Errorf("failed to prune images: %w (stderr: %s)", err, stderr)
+	}
+
+	reclaimed := "0B"
+	if match := reclaimedSpace.FindStringSubmatch(stdout); match != nil {
+		reclaimed = match[1]
+	}
+
+	return reclaimed, nil
+}
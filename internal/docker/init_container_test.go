@@ -0,0 +1,94 @@
+package docker_test
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/the-agent-c-ai/hadron/internal/docker"
+	"github.com/the-agent-c-ai/hadron/internal/testutil"
+)
+
+func TestInitContainerRunsToCompletion(t *testing.T) { //nolint:paralleltest // Integration tests use shared container
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testutil.StartDockerSSHContainer(t)
+	client := container.Client()
+	executor := docker.NewExecutor(nil, zerolog.Nop())
+
+	prefix := fmt.Sprintf("hadron-test-init-%d", time.Now().Unix())
+	volume := prefix + "-data"
+
+	// Containers run on the test machine's daemon, next to the SSH container rather than inside it
+	//nolint:gosec // Names are test-generated
+	t.Cleanup(func() {
+		_ = exec.Command("docker", "rm", "-f", prefix+"-seed", prefix+"-fail", prefix).Run()
+		_ = exec.Command("docker", "volume", "rm", "-f", volume).Run()
+	})
+
+	shared := []docker.VolumeMount{{Source: volume, Target: "/data"}}
+
+	t.Run("main container sees the init container's work", func(t *testing.T) { //nolint:paralleltest // Shared host
+		// The seed sleeps first, so a main container started before it exits would find nothing
+		err := executor.RunInitContainer(client, docker.ContainerRunOptions{
+			Name:    prefix + "-seed",
+			Image:   "busybox",
+			Command: []string{"sh", "-c", "'sleep 2 && echo seeded > /data/seed'"},
+			Volumes: shared,
+		})
+		if err != nil {
+			t.Fatalf("expected init container to succeed, got: %v", err)
+		}
+
+		// --rm removed the init container once it exited
+		if stdout, _, _ := client.Execute("docker ps -aq -f name=^" + prefix + "-seed$"); stdout != "" {
+			t.Errorf("expected the init container to be removed, got %q", stdout)
+		}
+
+		err = executor.RunContainer(client, docker.ContainerRunOptions{
+			Name:    prefix,
+			Image:   "busybox",
+			Command: []string{"sh", "-c", "'cat /data/seed && sleep 300'"},
+			Volumes: shared,
+		})
+		if err != nil {
+			t.Fatalf("expected main container to start, got: %v", err)
+		}
+
+		var logs string
+
+		for range 10 {
+			if logs, _, _ = client.Execute("docker logs " + prefix); logs != "" {
+				break
+			}
+
+			time.Sleep(time.Second)
+		}
+
+		if strings.TrimSpace(logs) != "seeded" {
+			t.Errorf("expected the main container to read the seed, got %q", logs)
+		}
+	})
+
+	t.Run("failing init container is reported", func(t *testing.T) { //nolint:paralleltest // Shared host
+		err := executor.RunInitContainer(client, docker.ContainerRunOptions{
+			Name:    prefix + "-fail",
+			Image:   "busybox",
+			Command: []string{"sh", "-c", "'echo migration failed >&2; exit 3'"},
+		})
+		if !errors.Is(err, docker.ErrInitContainerFailed) {
+			t.Fatalf("expected ErrInitContainerFailed, got: %v", err)
+		}
+
+		if !strings.Contains(err.Error(), "migration failed") {
+			t.Errorf("expected the error to include the container's output, got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package docker
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// lineLogger is an io.Writer that logs each complete line of a streamed command's output at debug level.
+// Flush logs a trailing line without a newline.
+type lineLogger struct {
+	logger zerolog.Logger
+	field  string
+	value  string
+	buf    bytes.Buffer
+}
+
+// newLineLogger returns a writer logging output lines tagged with field=value.
+func newLineLogger(logger zerolog.Logger, field, value string) *lineLogger {
+	return &lineLogger{logger: logger, field: field, value: value}
+}
+
+func (l *lineLogger) Write(p []byte) (int, error) {
+	l.buf.Write(p)
+
+	for {
+		line, err := l.buf.ReadString('\n')
+		if err != nil {
+			// Incomplete line: keep it buffered until the rest arrives
+			l.buf.Reset()
+			l.buf.WriteString(line)
+
+			return len(p), nil
+		}
+
+		l.log(line)
+	}
+}
+
+// Flush logs any buffered partial line.
+func (l *lineLogger) Flush() {
+	if l.buf.Len() > 0 {
+		l.log(l.buf.String())
+		l.buf.Reset()
+	}
+}
+
+func (l *lineLogger) log(line string) {
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return
+	}
+
+	l.logger.Debug().Str(l.field, l.value).Msg(line)
+}
@@ -15,4 +15,8 @@ const (
 	// PermSecretDir is the permission for secret directories (owner read/write/execute only).
 	// Used for directories containing sensitive data.
 	PermSecretDir os.FileMode = 0o700
+
+	// PermPublicDir is the permission for public directories (owner full access, others read/traverse).
+	// Used for uploaded mount directories that containers read.
+	PermPublicDir os.FileMode = 0o755
 )
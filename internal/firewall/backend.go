@@ -0,0 +1,95 @@
+package firewall
+
+import (
+	"fmt"
+
+	"github.com/the-agent-c-ai/hadron/internal/packages"
+	"github.com/the-agent-c-ai/hadron/sdk/ssh"
+)
+
+const (
+	// BackendUFW manages the firewall with ufw (default).
+	BackendUFW = "ufw"
+
+	// BackendNftables manages the firewall with a dedicated nftables table.
+	BackendNftables = "nftables"
+)
+
+// Backend manages a host firewall. Implementations must be safe to apply repeatedly.
+type Backend interface {
+	IsInstalled(client ssh.Connection) (bool, error)
+	Install(client ssh.Connection, manager packages.Manager) error
+	IsEnabled(client ssh.Connection) (bool, error)
+	Enable(client ssh.Connection) error
+	Disable(client ssh.Connection) error
+	GetDefaults(client ssh.Connection) (incoming, outgoing string, err error)
+	SetDefaults(client ssh.Connection, incoming, outgoing string) error
+	GetRules(client ssh.Connection) ([]Rule, error)
+	AddRule(client ssh.Connection, rule Rule) error
+	RemoveRule(client ssh.Connection, rule Rule) error
+}
+
+// NewBackend returns the backend with the given name. An empty name selects ufw.
+func NewBackend(name string) (Backend, error) {
+	switch name {
+	case "", BackendUFW:
+		return UFW{}, nil
+	case BackendNftables:
+		return Nftables{}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownBackend, name)
+	}
+}
+
+// UFW is the ufw Backend. It delegates to the package-level ufw functions.
+type UFW struct{}
+
+// IsInstalled checks if ufw is installed.
+func (UFW) IsInstalled(client ssh.Connection) (bool, error) {
+	return IsInstalled(client)
+}
+
+// Install installs ufw.
+func (UFW) Install(client ssh.Connection, manager packages.Manager) error {
+	return Install(client, manager)
+}
+
+// IsEnabled checks if ufw is active.
+func (UFW) IsEnabled(client ssh.Connection) (bool, error) {
+	return IsEnabled(client)
+}
+
+// Enable enables ufw.
+func (UFW) Enable(client ssh.Connection) error {
+	return Enable(client)
+}
+
+// Disable disables ufw.
+func (UFW) Disable(client ssh.Connection) error {
+	return Disable(client)
+}
+
+// GetDefaults retrieves the ufw default policies.
+func (UFW) GetDefaults(client ssh.Connection) (string, string, error) {
+	return GetDefaults(client)
+}
+
+// SetDefaults sets the ufw default policies.
+func (UFW) SetDefaults(client ssh.Connection, incoming, outgoing string) error {
+	return SetDefaults(client, incoming, outgoing)
+}
+
+// GetRules retrieves the ufw rules.
+func (UFW) GetRules(client ssh.Connection) ([]Rule, error) {
+	return GetRules(client)
+}
+
+// AddRule adds a ufw rule.
+func (UFW) AddRule(client ssh.Connection, rule Rule) error {
+	return AddRule(client, rule)
+}
+
+// RemoveRule removes a ufw rule.
+func (UFW) RemoveRule(client ssh.Connection, rule Rule) error {
+	return RemoveRule(client, rule)
+}
@@ -2,5 +2,10 @@ package firewall
 
 import "errors"
 
-// ErrParseDefaults indicates failed to parse UFW default policies.
-var ErrParseDefaults = errors.New("failed to parse ufw defaults")
+var (
+	// ErrParseDefaults indicates failed to parse the firewall default policies.
+	ErrParseDefaults = errors.New("failed to parse firewall defaults")
+
+	// ErrUnknownBackend indicates an unsupported firewall backend was selected.
+	ErrUnknownBackend = errors.New("unknown firewall backend")
+)
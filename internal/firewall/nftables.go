@@ -0,0 +1,351 @@
+package firewall
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/the-agent-c-ai/hadron/internal/packages"
+	"github.com/the-agent-c-ai/hadron/sdk/ssh"
+)
+
+const (
+	// nftTable is the table Hadron manages; other tables on the host are left alone.
+	nftTable = "inet hadron"
+
+	// nftPersistPath holds the managed table so it is restored on boot by the nftables service.
+	nftPersistPath = "/etc/nftables.d/hadron.nft"
+	nftConfigPath  = "/etc/nftables.conf"
+
+	// nftRateLimit approximates ufw's LIMIT (6 new connections per 30 seconds).
+	nftRateLimit = "ct state new limit rate 6/minute"
+)
+
+// nftBaseChains creates the managed chains with an accept policy (SetDefaults tightens it) and the rules
+// every firewall needs: established traffic, loopback, and ICMP are always allowed, invalid packets dropped.
+var nftBaseChains = []string{
+	"add table " + nftTable,
+	"add chain " + nftTable + " input { type filter hook input priority filter; policy accept; }",
+	"add rule " + nftTable + " input ct state established,related accept",
+	"add rule " + nftTable + " input ct state invalid drop",
+	"add rule " + nftTable + ` input iifname "lo" accept`,
+	"add rule " + nftTable + " input meta l4proto { icmp, ipv6-icmp } accept",
+	"add chain " + nftTable + " output { type filter hook output priority filter; policy accept; }",
+	"add rule " + nftTable + " output ct state established,related accept",
+	"add rule " + nftTable + ` output oifname "lo" accept`,
+}
+
+// Nftables is a Backend that manages its own nftables table ("inet hadron"), for hosts without ufw or
+// already using nftables directly. Rules apply to IPv4 and IPv6 alike. Unlike ufw, changes are live
+// immediately; the table is persisted to /etc/nftables.d and included from /etc/nftables.conf.
+type Nftables struct{}
+
+// nftRule is a rule parsed from the managed chain together with its nftables handle.
+type nftRule struct {
+	Rule
+
+	handle int
+}
+
+// nft runs an nft command, quoting it for the shell.
+func nft(client ssh.Connection, command string) (string, error) {
+	stdout, stderr, err := ssh.RunPrivileged(client, "nft "+quote(command))
+	if err != nil {
+		return "", fmt.Errorf("nft %s: %w (stderr: %s)", command, err, stderr)
+	}
+
+	return stdout, nil
+}
+
+// quote wraps a string in single quotes for the shell.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// IsInstalled checks if nft is installed on the remote host.
+func (Nftables) IsInstalled(client ssh.Connection) (bool, error) {
+	_, _, err := client.Execute("which nft")
+	if err != nil {
+		// which returns non-zero if not found
+		return false, nil //nolint:nilerr // exit code used for logic, not error indication
+	}
+
+	return true, nil
+}
+
+// Install installs nftables with the host's package manager.
+func (Nftables) Install(client ssh.Connection, manager packages.Manager) error {
+	if err := manager.EnsureInstalled(client, "nftables"); err != nil {
+		return fmt.Errorf("failed to install nftables: %w", err)
+	}
+
+	return nil
+}
+
+// IsEnabled checks if the managed table exists and the nftables service restores it on boot.
+func (Nftables) IsEnabled(client ssh.Connection) (bool, error) {
+	cmd := ssh.Privileged(client, "nft list table "+nftTable) +
+		" >/dev/null 2>&1 && systemctl is-enabled --quiet nftables"
+
+	if _, _, err := client.Execute(cmd); err != nil {
+		return false, nil //nolint:nilerr // exit code used for logic, not error indication
+	}
+
+	return true, nil
+}
+
+// Enable ensures the managed table exists, persists it, and enables the nftables service.
+func (n Nftables) Enable(client ssh.Connection) error {
+	if err := n.ensureTable(client); err != nil {
+		return err
+	}
+
+	if err := n.persist(client); err != nil {
+		return err
+	}
+
+	include := fmt.Sprintf(`include \"%s\"`, nftPersistPath)
+	includeCmd := fmt.Sprintf(`sh -c 'grep -qxF "%[1]s" %[2]s || echo "%[1]s" >> %[2]s'`, include, nftConfigPath)
+
+	for _, cmd := range []string{includeCmd, "systemctl enable nftables"} {
+		if _, stderr, err := ssh.RunPrivileged(client, cmd); err != nil {
+			return fmt.Errorf("failed to enable nftables: %w (stderr: %s)", err, stderr)
+		}
+	}
+
+	return nil
+}
+
+// Disable deletes the managed table and its persisted copy. Other nftables tables are untouched.
+func (Nftables) Disable(client ssh.Connection) error {
+	cmd := ssh.Privileged(client, "nft delete table "+nftTable) + " 2>/dev/null; " +
+		ssh.Privileged(client, "rm -f "+nftPersistPath)
+
+	if _, stderr, err := client.Execute(cmd); err != nil {
+		return fmt.Errorf("failed to disable nftables firewall: %w (stderr: %s)", err, stderr)
+	}
+
+	return nil
+}
+
+// GetDefaults retrieves the input and output chain policies as ufw-style "deny"/"allow".
+func (Nftables) GetDefaults(client ssh.Connection) (incoming, outgoing string, err error) {
+	stdout, err := nft(client, "list table "+nftTable)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %w", ErrParseDefaults, err)
+	}
+
+	const policyMatchIndex = 1
+
+	policies := make(map[string]string, 2)
+
+	for _, chain := range []string{"input", "output"} {
+		policyRegex := regexp.MustCompile(`chain ` + chain + ` \{[^}]*?policy (\w+);`)
+
+		matches := policyRegex.FindStringSubmatch(stdout)
+		if matches == nil {
+			return "", "", ErrParseDefaults
+		}
+
+		policies[chain] = fromNftPolicy(matches[policyMatchIndex])
+	}
+
+	return policies["input"], policies["output"], nil
+}
+
+// SetDefaults sets the input and output chain policies ("deny"/"reject" drop, "allow" accepts).
+func (n Nftables) SetDefaults(client ssh.Connection, incoming, outgoing string) error {
+	if err := n.ensureTable(client); err != nil {
+		return err
+	}
+
+	for _, chain := range []struct{ name, policy string }{{"input", incoming}, {"output", outgoing}} {
+		cmd := fmt.Sprintf("chain %s %s { policy %s; }", nftTable, chain.name, toNftPolicy(chain.policy))
+		if _, err := nft(client, cmd); err != nil {
+			return fmt.Errorf("failed to set default %s policy: %w", chain.name, err)
+		}
+	}
+
+	return n.persist(client)
+}
+
+// GetRules retrieves the port rules of the managed input chain. Base rules are not reported.
+func (n Nftables) GetRules(client ssh.Connection) ([]Rule, error) {
+	parsed, err := n.listRules(client)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]Rule, 0, len(parsed))
+	for _, rule := range parsed {
+		rules = append(rules, rule.Rule)
+	}
+
+	return rules, nil
+}
+
+// AddRule appends a rule to the managed input chain.
+func (n Nftables) AddRule(client ssh.Connection, rule Rule) error {
+	if err := n.ensureTable(client); err != nil {
+		return err
+	}
+
+	if _, err := nft(client, fmt.Sprintf("add rule %s input %s", nftTable, rule.nftExpression())); err != nil {
+		return fmt.Errorf("failed to add rule %s: %w", rule, err)
+	}
+
+	return n.persist(client)
+}
+
+// RemoveRule removes every rule in the managed input chain matching the rule's port, protocol,
+// source, and interface.
+func (n Nftables) RemoveRule(client ssh.Connection, rule Rule) error {
+	parsed, err := n.listRules(client)
+	if err != nil {
+		return err
+	}
+
+	for _, existing := range parsed {
+		if FindRule([]Rule{existing.Rule}, rule) == nil {
+			continue
+		}
+
+		if _, err := nft(client, fmt.Sprintf("delete rule %s input handle %d", nftTable, existing.handle)); err != nil {
+			return fmt.Errorf("failed to remove rule %s: %w", rule, err)
+		}
+	}
+
+	return n.persist(client)
+}
+
+// ensureTable creates the managed table with its base chains and rules if it does not exist yet.
+func (Nftables) ensureTable(client ssh.Connection) error {
+	cmd := ssh.Privileged(client, "nft list table "+nftTable) + " >/dev/null 2>&1"
+	if _, _, err := client.Execute(cmd); err == nil {
+		return nil
+	}
+
+	for _, cmd := range nftBaseChains {
+		if _, err := nft(client, cmd); err != nil {
+			return fmt.Errorf("failed to create nftables table: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// persist saves the managed table so the nftables service restores it on boot.
+func (Nftables) persist(client ssh.Connection) error {
+	cmd := fmt.Sprintf("sh -c 'mkdir -p /etc/nftables.d && nft list table %s > %s'", nftTable, nftPersistPath)
+
+	if _, stderr, err := ssh.RunPrivileged(client, cmd); err != nil {
+		return fmt.Errorf("failed to persist nftables table: %w (stderr: %s)", err, stderr)
+	}
+
+	return nil
+}
+
+// listRules parses the port rules of the managed input chain, with their handles.
+func (Nftables) listRules(client ssh.Connection) ([]nftRule, error) {
+	stdout, _, err := ssh.RunPrivileged(client, "nft -a list chain "+nftTable+" input")
+	if err != nil {
+		// No managed table yet means no rules
+		return nil, nil //nolint:nilerr // a missing table is not an error
+	}
+
+	// Example line: iifname "eth1" ip saddr 10.0.0.0/8 tcp dport 5432 accept comment "postgres" # handle 7
+	// Example with limit: tcp dport 22 ct state new limit rate 6/minute burst 5 packets accept comment "SSH" # handle 6
+	const (
+		interfaceMatchIndex = 1
+		sourceMatchIndex    = 2
+		protocolMatchIndex  = 3
+		portMatchIndex      = 4
+		limitMatchIndex     = 5
+		commentMatchIndex   = 6
+		handleMatchIndex    = 7
+	)
+
+	ruleRegex := regexp.MustCompile(
+		`^\s*(?:iifname "([^"]+)" )?(?:ip6? saddr (\S+) )?(tcp|udp) dport (\d+) ` +
+			`(ct state new limit rate \S+(?: burst \d+ packets)? )?accept(?: comment "([^"]*)")? # handle (\d+)\s*$`,
+	)
+
+	var rules []nftRule
+
+	for line := range strings.SplitSeq(stdout, "\n") {
+		matches := ruleRegex.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+
+		port, _ := strconv.Atoi(matches[portMatchIndex])
+		handle, _ := strconv.Atoi(matches[handleMatchIndex])
+
+		rules = append(rules, nftRule{
+			Rule: Rule{
+				Port:      port,
+				Protocol:  matches[protocolMatchIndex],
+				Comment:   matches[commentMatchIndex],
+				RateLimit: matches[limitMatchIndex] != "",
+				Source:    matches[sourceMatchIndex],
+				Interface: matches[interfaceMatchIndex],
+			},
+			handle: handle,
+		})
+	}
+
+	return rules, nil
+}
+
+// nftExpression returns the nftables rule expression, e.g.
+// `iifname "eth1" ip saddr 10.0.0.0/8 tcp dport 5432 accept comment "postgres"`.
+func (r Rule) nftExpression() string {
+	var parts []string
+
+	if r.Interface != "" {
+		parts = append(parts, fmt.Sprintf("iifname %q", r.Interface))
+	}
+
+	if r.Source != "" {
+		family := "ip"
+		if strings.Contains(r.Source, ":") {
+			family = "ip6"
+		}
+
+		parts = append(parts, family+" saddr "+r.Source)
+	}
+
+	parts = append(parts, fmt.Sprintf("%s dport %d", r.Protocol, r.Port))
+
+	if r.RateLimit {
+		parts = append(parts, nftRateLimit)
+	}
+
+	parts = append(parts, "accept")
+
+	if r.Comment != "" {
+		parts = append(parts, fmt.Sprintf("comment %q", r.Comment))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// toNftPolicy maps a ufw-style policy to an nftables chain policy. nftables chains cannot reject,
+// so "reject" drops.
+func toNftPolicy(policy string) string {
+	if policy == "allow" {
+		return "accept"
+	}
+
+	return "drop"
+}
+
+// fromNftPolicy maps an nftables chain policy back to a ufw-style policy.
+func fromNftPolicy(policy string) string {
+	if policy == "accept" {
+		return "allow"
+	}
+
+	return "deny"
+}
@@ -0,0 +1,106 @@
+package firewall_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/the-agent-c-ai/hadron/internal/firewall"
+	"github.com/the-agent-c-ai/hadron/internal/testutil"
+)
+
+func TestNftablesAddRule(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.FakeConnection{}
+
+	rule := firewall.Rule{
+		Port:      5432,
+		Protocol:  "tcp",
+		Comment:   "postgres",
+		Source:    "10.0.0.0/8",
+		Interface: "eth1",
+	}
+
+	if err := (firewall.Nftables{}).AddRule(client, rule); err != nil {
+		t.Fatalf("expected rule to be added, got: %v", err)
+	}
+
+	want := `sudo nft 'add rule inet hadron input ` +
+		`iifname "eth1" ip saddr 10.0.0.0/8 tcp dport 5432 accept comment "postgres"'`
+	if !slices.Contains(client.Commands, want) {
+		t.Errorf("expected command %q, got %v", want, client.Commands)
+	}
+}
+
+func TestNftablesGetRules(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.FakeConnection{Respond: output(`table inet hadron {
+	chain input { # handle 1
+		type filter hook input priority filter; policy drop;
+		ct state established,related accept # handle 2
+		ct state invalid drop # handle 3
+		iifname "lo" accept # handle 4
+		meta l4proto { icmp, ipv6-icmp } accept # handle 5
+		tcp dport 22 ct state new limit rate 6/minute burst 5 packets accept comment "SSH" # handle 6
+		iifname "eth1" ip saddr 10.0.0.0/8 tcp dport 5432 accept comment "postgres" # handle 7
+		udp dport 51820 accept # handle 8
+	}
+}
+`)}
+
+	rules, err := (firewall.Nftables{}).GetRules(client)
+	if err != nil {
+		t.Fatalf("expected rules to parse, got: %v", err)
+	}
+
+	want := []firewall.Rule{
+		{Port: 22, Protocol: "tcp", Comment: "SSH", RateLimit: true},
+		{Port: 5432, Protocol: "tcp", Comment: "postgres", Source: "10.0.0.0/8", Interface: "eth1"},
+		{Port: 51820, Protocol: "udp"},
+	}
+	if !slices.Equal(rules, want) {
+		t.Errorf("expected %+v, got %+v", want, rules)
+	}
+}
+
+func TestNftablesGetDefaults(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.FakeConnection{Respond: output(`table inet hadron {
+	chain input {
+		type filter hook input priority filter; policy drop;
+	}
+	chain output {
+		type filter hook output priority filter; policy accept;
+	}
+}
+`)}
+
+	incoming, outgoing, err := (firewall.Nftables{}).GetDefaults(client)
+	if err != nil {
+		t.Fatalf("expected defaults to parse, got: %v", err)
+	}
+
+	if incoming != "deny" || outgoing != "allow" {
+		t.Errorf("expected deny/allow, got %s/%s", incoming, outgoing)
+	}
+}
+
+func TestNewBackend(t *testing.T) {
+	t.Parallel()
+
+	backend, err := firewall.NewBackend("")
+	if err != nil {
+		t.Fatalf("expected default backend, got: %v", err)
+	}
+
+	if _, ok := backend.(firewall.UFW); !ok {
+		t.Errorf("expected ufw as the default backend, got %T", backend)
+	}
+
+	if _, err := firewall.NewBackend("iptables"); !errors.Is(err, firewall.ErrUnknownBackend) {
+		t.Errorf("expected ErrUnknownBackend, got: %v", err)
+	}
+}
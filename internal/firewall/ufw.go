@@ -7,7 +7,7 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/the-agent-c-ai/hadron/internal/debian"
+	"github.com/the-agent-c-ai/hadron/internal/packages"
 	"github.com/the-agent-c-ai/hadron/sdk/ssh"
 )
 
@@ -17,6 +17,46 @@ type Rule struct {
 	Protocol  string // "tcp" or "udp"
 	Comment   string
 	RateLimit bool
+	Source    string // source IP or CIDR (empty: anywhere)
+	Interface string // incoming network interface, e.g. "eth1" (empty: all interfaces)
+	IPv6      bool   // IPv6-only entry from anywhere, without its IPv4 counterpart
+}
+
+// anywhere is how ufw shows a rule without a source restriction.
+const anywhere = "Anywhere"
+
+// spec returns the ufw rule specification following the action, e.g. "22/tcp",
+// "from 10.0.0.0/8 to any port 5432 proto tcp", or "in on eth1 to any port 5432 proto tcp".
+func (r Rule) spec() string {
+	if r.Source == "" && r.Interface == "" {
+		return fmt.Sprintf("%d/%s", r.Port, r.Protocol)
+	}
+
+	var spec string
+	if r.Interface != "" {
+		spec = "in on " + r.Interface + " "
+	}
+
+	if r.Source != "" {
+		spec += "from " + r.Source + " "
+	}
+
+	return spec + fmt.Sprintf("to any port %d proto %s", r.Port, r.Protocol)
+}
+
+// String returns a short description of the rule for error messages.
+func (r Rule) String() string {
+	description := fmt.Sprintf("%d/%s", r.Port, r.Protocol)
+
+	if r.Interface != "" {
+		description += " on " + r.Interface
+	}
+
+	if r.Source != "" {
+		description += " from " + r.Source
+	}
+
+	return description
 }
 
 // Config represents the complete firewall configuration.
@@ -39,7 +79,7 @@ func IsInstalled(client ssh.Connection) (bool, error) {
 
 // IsEnabled checks if ufw is currently enabled.
 func IsEnabled(client ssh.Connection) (bool, error) {
-	stdout, _, err := client.Execute("sudo ufw status")
+	stdout, _, err := ssh.RunPrivileged(client, "ufw status")
 	if err != nil {
 		return false, fmt.Errorf("failed to check ufw status: %w", err)
 	}
@@ -49,24 +89,32 @@ func IsEnabled(client ssh.Connection) (bool, error) {
 
 // GetRules retrieves the current firewall rules.
 func GetRules(client ssh.Connection) ([]Rule, error) {
-	stdout, _, err := client.Execute("sudo ufw status numbered")
+	stdout, _, err := ssh.RunPrivileged(client, "ufw status numbered")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get ufw rules: %w", err)
 	}
 
-	var rules []Rule
+	var rules, v6Rules []Rule
 
 	lines := strings.Split(stdout, "\n")
 
 	// Example line: [ 1] 22/tcp                     ALLOW IN    Anywhere                   # SSH
 	// Example with LIMIT: [ 2] 22/tcp                     LIMIT IN    Anywhere
+	// Example with source: [ 3] 5432/tcp                   ALLOW IN    10.0.0.0/8
+	// Example IPv6: [ 4] 22/tcp (v6)                LIMIT IN    Anywhere (v6)              # SSH
+	// Example with interface: [ 5] 5432/tcp on eth1           ALLOW IN    Anywhere
 	const (
-		portMatchIndex     = 1
-		protocolMatchIndex = 2
-		actionMatchIndex   = 3
+		portMatchIndex      = 1
+		protocolMatchIndex  = 2
+		v6MatchIndex        = 3
+		interfaceMatchIndex = 4
+		actionMatchIndex    = 5
+		sourceMatchIndex    = 6
 	)
 
-	ruleRegex := regexp.MustCompile(`\[\s*\d+\]\s+(\d+)/(tcp|udp)\s+(ALLOW|LIMIT)\s+IN`)
+	ruleRegex := regexp.MustCompile(
+		`\[\s*\d+\]\s+(\d+)/(tcp|udp)(\s+\(v6\))?(?:\s+on\s+(\S+))?\s+(ALLOW|LIMIT)\s+IN\s+(\S+)`,
+	)
 	commentRegex := regexp.MustCompile(`#\s*(.+)$`)
 
 	for _, line := range lines {
@@ -79,6 +127,11 @@ func GetRules(client ssh.Connection) ([]Rule, error) {
 				Port:      port,
 				Protocol:  protocol,
 				RateLimit: action == "LIMIT",
+				Interface: matches[interfaceMatchIndex],
+			}
+
+			if source := matches[sourceMatchIndex]; source != anywhere {
+				rule.Source = source
 			}
 
 			// Extract comment if present
@@ -86,16 +139,44 @@ func GetRules(client ssh.Connection) ([]Rule, error) {
 				rule.Comment = strings.TrimSpace(commentMatches[1])
 			}
 
+			// IPv6 entries from anywhere are collected separately and merged with their IPv4 twins below.
+			// Entries with an IPv6 source are ordinary rules: the source already says they are IPv6.
+			if matches[v6MatchIndex] != "" && rule.Source == "" {
+				rule.IPv6 = true
+				v6Rules = append(v6Rules, rule)
+
+				continue
+			}
+
 			rules = append(rules, rule)
 		}
 	}
 
-	return rules, nil
+	return mergeIPv6Rules(rules, v6Rules), nil
+}
+
+// mergeIPv6Rules folds ufw's "(v6)" duplicates into their IPv4 rules. With IPv6 enabled, a single
+// "ufw allow 22/tcp" creates both entries, so the duplicate is not a separate rule and must not be seen
+// as drift. IPv6 entries without an IPv4 twin are kept (marked IPv6) so reconciliation recreates the pair.
+func mergeIPv6Rules(rules, v6Rules []Rule) []Rule {
+	for _, v6 := range v6Rules {
+		twin := v6
+		twin.IPv6 = false
+
+		if existing := FindRule(rules, twin); existing != nil &&
+			RulesEqual(*existing, twin) {
+			continue
+		}
+
+		rules = append(rules, v6)
+	}
+
+	return rules
 }
 
 // GetDefaults retrieves the current default policies.
 func GetDefaults(client ssh.Connection) (incoming, outgoing string, err error) {
-	stdout, _, err := client.Execute("sudo ufw status verbose")
+	stdout, _, err := ssh.RunPrivileged(client, "ufw status verbose")
 	if err != nil {
 		return "", "", fmt.Errorf("failed to get ufw defaults: %w", err)
 	}
@@ -115,9 +196,9 @@ func GetDefaults(client ssh.Connection) (incoming, outgoing string, err error) {
 	return "", "", ErrParseDefaults
 }
 
-// Install installs ufw on the remote host using debian package manager.
-func Install(client ssh.Connection) error {
-	if err := debian.EnsureInstalled(client, "ufw"); err != nil {
+// Install installs ufw on the remote host with the host's package manager.
+func Install(client ssh.Connection, manager packages.Manager) error {
+	if err := manager.EnsureInstalled(client, "ufw"); err != nil {
 		return fmt.Errorf("failed to install ufw: %w", err)
 	}
 
@@ -127,14 +208,14 @@ func Install(client ssh.Connection) error {
 // SetDefaults sets the default policies for incoming and outgoing traffic.
 func SetDefaults(client ssh.Connection, incoming, outgoing string) error {
 	// Set default incoming
-	cmd := fmt.Sprintf("sudo ufw default %s incoming", incoming)
-	if _, stderr, err := client.Execute(cmd); err != nil {
+	cmd := fmt.Sprintf("ufw default %s incoming", incoming)
+	if _, stderr, err := ssh.RunPrivileged(client, cmd); err != nil {
 		return fmt.Errorf("failed to set default incoming: %w (stderr: %s)", err, stderr)
 	}
 
 	// Set default outgoing
-	cmd = fmt.Sprintf("sudo ufw default %s outgoing", outgoing)
-	if _, stderr, err := client.Execute(cmd); err != nil {
+	cmd = fmt.Sprintf("ufw default %s outgoing", outgoing)
+	if _, stderr, err := ssh.RunPrivileged(client, cmd); err != nil {
 		return fmt.Errorf("failed to set default outgoing: %w (stderr: %s)", err, stderr)
 	}
 
@@ -145,30 +226,30 @@ func SetDefaults(client ssh.Connection, incoming, outgoing string) error {
 func AddRule(client ssh.Connection, rule Rule) error {
 	var cmd string
 	if rule.RateLimit {
-		cmd = fmt.Sprintf("sudo ufw limit %d/%s", rule.Port, rule.Protocol)
+		cmd = "ufw limit " + rule.spec()
 	} else {
-		cmd = fmt.Sprintf("sudo ufw allow %d/%s", rule.Port, rule.Protocol)
+		cmd = "ufw allow " + rule.spec()
 	}
 
 	if rule.Comment != "" {
 		cmd += fmt.Sprintf(" comment '%s'", rule.Comment)
 	}
 
-	_, stderr, err := client.Execute(cmd)
+	_, stderr, err := ssh.RunPrivileged(client, cmd)
 	if err != nil {
-		return fmt.Errorf("failed to add rule %d/%s: %w (stderr: %s)", rule.Port, rule.Protocol, err, stderr)
+		return fmt.Errorf("failed to add rule %s: %w (stderr: %s)", rule, err, stderr)
 	}
 
 	return nil
 }
 
-// RemoveRule removes a firewall rule by port and protocol.
-func RemoveRule(client ssh.Connection, port int, protocol string) error {
-	cmd := fmt.Sprintf("sudo ufw delete allow %d/%s", port, protocol)
+// RemoveRule removes a firewall rule by port, protocol, and source.
+func RemoveRule(client ssh.Connection, rule Rule) error {
+	cmd := "ufw delete allow " + rule.spec()
 
-	_, stderr, err := client.Execute(cmd)
+	_, stderr, err := ssh.RunPrivileged(client, cmd)
 	if err != nil {
-		return fmt.Errorf("failed to remove rule %d/%s: %w (stderr: %s)", port, protocol, err, stderr)
+		return fmt.Errorf("failed to remove rule %s: %w (stderr: %s)", rule, err, stderr)
 	}
 
 	return nil
@@ -177,9 +258,9 @@ func RemoveRule(client ssh.Connection, port int, protocol string) error {
 // Enable enables the firewall (non-interactively).
 func Enable(client ssh.Connection) error {
 	// Use --force to avoid interactive prompt
-	cmd := "sudo ufw --force enable"
+	cmd := "ufw --force enable"
 
-	_, stderr, err := client.Execute(cmd)
+	_, stderr, err := ssh.RunPrivileged(client, cmd)
 	if err != nil {
 		return fmt.Errorf("failed to enable ufw: %w (stderr: %s)", err, stderr)
 	}
@@ -187,17 +268,43 @@ func Enable(client ssh.Connection) error {
 	return nil
 }
 
+// Disable turns the firewall off (non-interactively). Rules are kept and apply again once re-enabled.
+func Disable(client ssh.Connection) error {
+	_, stderr, err := ssh.RunPrivileged(client, "ufw --force disable")
+	if err != nil {
+		return fmt.Errorf("failed to disable ufw: %w (stderr: %s)", err, stderr)
+	}
+
+	return nil
+}
+
+// Reset disables the firewall and deletes all rules, restoring ufw's installation defaults
+// (non-interactively). ufw keeps backups of the previous rules in /etc/ufw.
+func Reset(client ssh.Connection) error {
+	_, stderr, err := ssh.RunPrivileged(client, "ufw --force reset")
+	if err != nil {
+		return fmt.Errorf("failed to reset ufw: %w (stderr: %s)", err, stderr)
+	}
+
+	return nil
+}
+
 // RulesEqual checks if two rules are equivalent (ignoring comment differences).
 func RulesEqual(r1, r2 Rule) bool {
 	return r1.Port == r2.Port &&
 		r1.Protocol == r2.Protocol &&
-		r1.RateLimit == r2.RateLimit
+		r1.RateLimit == r2.RateLimit &&
+		r1.Source == r2.Source &&
+		r1.Interface == r2.Interface &&
+		r1.IPv6 == r2.IPv6
 }
 
-// FindRule finds a rule in a slice by port and protocol.
-func FindRule(rules []Rule, port int, protocol string) *Rule {
+// FindRule finds the rule in a slice that matches the given rule's port, protocol, source, and interface.
+// Comment, rate limiting, and IPv6 may differ; use RulesEqual to check whether the found rule needs updating.
+func FindRule(rules []Rule, match Rule) *Rule {
 	for _, rule := range rules {
-		if rule.Port == port && rule.Protocol == protocol {
+		if rule.Port == match.Port && rule.Protocol == match.Protocol &&
+			rule.Source == match.Source && rule.Interface == match.Interface {
 			return &rule
 		}
 	}
@@ -0,0 +1,254 @@
+package firewall_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/the-agent-c-ai/hadron/internal/firewall"
+	"github.com/the-agent-c-ai/hadron/internal/packages"
+	"github.com/the-agent-c-ai/hadron/internal/testutil"
+	"github.com/the-agent-c-ai/hadron/sdk/ssh"
+)
+
+// output answers every command with the same stdout, such as a firewall status listing.
+func output(stdout string) func(command string) string {
+	return func(string) string { return stdout }
+}
+
+func TestGetRulesParsesSource(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.FakeConnection{Respond: output(`Status: active
+
+     To                         Action      From
+     --                         ------      ----
+[ 1] 22/tcp                     LIMIT IN    Anywhere                   # SSH
+[ 2] 5432/tcp                   ALLOW IN    10.0.0.0/8                 # postgres
+[ 3] 22/tcp (v6)                LIMIT IN    Anywhere (v6)              # SSH
+`)}
+
+	rules, err := firewall.GetRules(client)
+	if err != nil {
+		t.Fatalf("expected rules to parse, got: %v", err)
+	}
+
+	if len(rules) != 2 {
+		t.Fatalf("expected 2 rules, got %d: %+v", len(rules), rules)
+	}
+
+	if rules[0].Source != "" || !rules[0].RateLimit {
+		t.Errorf("expected rate-limited SSH rule from anywhere, got %+v", rules[0])
+	}
+
+	want := firewall.Rule{Port: 5432, Protocol: "tcp", Comment: "postgres", Source: "10.0.0.0/8"}
+	if rules[1] != want {
+		t.Errorf("expected %+v, got %+v", want, rules[1])
+	}
+
+	if firewall.FindRule(rules, firewall.Rule{Port: 5432, Protocol: "tcp"}) != nil {
+		t.Error("expected source-restricted rule not to match a rule from anywhere")
+	}
+
+	if found := firewall.FindRule(rules, want); found == nil || !firewall.RulesEqual(*found, want) {
+		t.Errorf("expected to find the postgres rule, got %+v", found)
+	}
+}
+
+func TestAddRuleWithSource(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.FakeConnection{}
+
+	rule := firewall.Rule{Port: 5432, Protocol: "tcp", Comment: "postgres", Source: "10.0.0.0/8"}
+	if err := firewall.AddRule(client, rule); err != nil {
+		t.Fatalf("expected rule to be added, got: %v", err)
+	}
+
+	if err := firewall.RemoveRule(client, rule); err != nil {
+		t.Fatalf("expected rule to be removed, got: %v", err)
+	}
+
+	want := []string{
+		"sudo ufw allow from 10.0.0.0/8 to any port 5432 proto tcp comment 'postgres'",
+		"sudo ufw delete allow from 10.0.0.0/8 to any port 5432 proto tcp",
+	}
+	for i, command := range want {
+		if i >= len(client.Commands) || client.Commands[i] != command {
+			t.Errorf("expected %q, got %v", command, client.Commands)
+		}
+	}
+}
+
+func TestGetRulesMergesIPv6Duplicates(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.FakeConnection{Respond: output(`Status: active
+
+     To                         Action      From
+     --                         ------      ----
+[ 1] 22/tcp                     LIMIT IN    Anywhere                   # SSH
+[ 2] 80/tcp                     ALLOW IN    Anywhere                   # HTTP
+[ 3] 5432/tcp                   ALLOW IN    10.0.0.0/8                 # postgres
+[ 4] 22/tcp (v6)                LIMIT IN    Anywhere (v6)              # SSH
+[ 5] 80/tcp (v6)                ALLOW IN    Anywhere (v6)              # HTTP
+[ 6] 5432/tcp (v6)              ALLOW IN    2001:db8::/32              # postgres
+`)}
+
+	current, err := firewall.GetRules(client)
+	if err != nil {
+		t.Fatalf("expected rules to parse, got: %v", err)
+	}
+
+	desired := []firewall.Rule{
+		{Port: 22, Protocol: "tcp", Comment: "SSH", RateLimit: true},
+		{Port: 80, Protocol: "tcp", Comment: "HTTP"},
+		{Port: 5432, Protocol: "tcp", Comment: "postgres", Source: "10.0.0.0/8"},
+		{Port: 5432, Protocol: "tcp", Comment: "postgres", Source: "2001:db8::/32"},
+	}
+
+	if len(current) != len(desired) {
+		t.Fatalf("expected %d rules after merging (v6) duplicates, got %d: %+v", len(desired), len(current), current)
+	}
+
+	// Reconciliation must neither add nor remove anything
+	for _, rule := range desired {
+		existing := firewall.FindRule(current, rule)
+		if existing == nil || !firewall.RulesEqual(*existing, rule) {
+			t.Errorf("expected %s to be in sync, got %+v", rule, existing)
+		}
+	}
+
+	for _, rule := range current {
+		if firewall.FindRule(desired, rule) == nil {
+			t.Errorf("expected %s not to be removed as unwanted", rule)
+		}
+	}
+}
+
+func TestGetRulesKeepsOrphanIPv6Rule(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.FakeConnection{Respond: output(`Status: active
+
+     To                         Action      From
+     --                         ------      ----
+[ 1] 443/tcp (v6)               ALLOW IN    Anywhere (v6)              # HTTPS
+`)}
+
+	current, err := firewall.GetRules(client)
+	if err != nil {
+		t.Fatalf("expected rules to parse, got: %v", err)
+	}
+
+	if len(current) != 1 || !current[0].IPv6 {
+		t.Fatalf("expected a single IPv6-only rule, got %+v", current)
+	}
+
+	// The IPv4 half is missing, so the rule differs from the desired one and is recreated as a pair
+	desired := firewall.Rule{Port: 443, Protocol: "tcp", Comment: "HTTPS"}
+	if firewall.RulesEqual(current[0], desired) {
+		t.Error("expected IPv6-only rule to differ from the desired dual-stack rule")
+	}
+}
+
+func TestRulesOnInterface(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.FakeConnection{Respond: output(`Status: active
+
+     To                         Action      From
+     --                         ------      ----
+[ 1] 5432/tcp on eth1           ALLOW IN    Anywhere                   # postgres
+[ 2] 5432/tcp on eth2           ALLOW IN    10.0.0.0/8
+[ 3] 5432/tcp (v6) on eth1      ALLOW IN    Anywhere (v6)              # postgres
+`)}
+
+	rules, err := firewall.GetRules(client)
+	if err != nil {
+		t.Fatalf("expected rules to parse, got: %v", err)
+	}
+
+	eth1 := firewall.Rule{Port: 5432, Protocol: "tcp", Comment: "postgres", Interface: "eth1"}
+	eth2 := firewall.Rule{Port: 5432, Protocol: "tcp", Source: "10.0.0.0/8", Interface: "eth2"}
+
+	if len(rules) != 2 || rules[0] != eth1 || rules[1] != eth2 {
+		t.Fatalf("expected %+v and %+v, got %+v", eth1, eth2, rules)
+	}
+
+	if firewall.RulesEqual(eth1, firewall.Rule{Port: 5432, Protocol: "tcp", Interface: "eth2"}) {
+		t.Error("expected rules on different interfaces to differ")
+	}
+
+	if firewall.FindRule(rules, firewall.Rule{Port: 5432, Protocol: "tcp"}) != nil {
+		t.Error("expected interface-scoped rules not to match a rule on all interfaces")
+	}
+
+	if err := firewall.AddRule(client, eth1); err != nil {
+		t.Fatalf("expected rule to be added, got: %v", err)
+	}
+
+	if err := firewall.AddRule(client, eth2); err != nil {
+		t.Fatalf("expected rule to be added, got: %v", err)
+	}
+
+	want := []string{
+		"sudo ufw allow in on eth1 to any port 5432 proto tcp comment 'postgres'",
+		"sudo ufw allow in on eth2 from 10.0.0.0/8 to any port 5432 proto tcp",
+	}
+	for i, command := range want {
+		if got := client.Commands[i+1]; got != command {
+			t.Errorf("expected %q, got %q", command, got)
+		}
+	}
+}
+
+func TestDisableAndReset(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.FakeConnection{}
+
+	if err := firewall.Disable(client); err != nil {
+		t.Fatalf("expected firewall to be disabled, got: %v", err)
+	}
+
+	if err := firewall.Reset(client); err != nil {
+		t.Fatalf("expected firewall to be reset, got: %v", err)
+	}
+
+	want := []string{"sudo ufw --force disable", "sudo ufw --force reset"}
+	if len(client.Commands) != len(want) || client.Commands[0] != want[0] || client.Commands[1] != want[1] {
+		t.Errorf("expected %v, got %v", want, client.Commands)
+	}
+}
+
+// recordingManager is a packages.Manager that records the packages it was asked to install.
+type recordingManager struct {
+	packages.Apt
+
+	installed []string
+}
+
+func (m *recordingManager) EnsureInstalled(_ ssh.Connection, packageName string) error {
+	m.installed = append(m.installed, packageName)
+
+	return nil
+}
+
+func TestInstallUsesHostPackageManager(t *testing.T) {
+	t.Parallel()
+
+	// Backends install through the manager of the host's distribution, so RHEL hosts get dnf
+	manager := &recordingManager{}
+
+	if err := (firewall.UFW{}).Install(&testutil.FakeConnection{}, manager); err != nil {
+		t.Fatalf("expected ufw install to succeed, got: %v", err)
+	}
+
+	if err := (firewall.Nftables{}).Install(&testutil.FakeConnection{}, manager); err != nil {
+		t.Fatalf("expected nftables install to succeed, got: %v", err)
+	}
+
+	if !slices.Equal(manager.installed, []string{"ufw", "nftables"}) {
+		t.Errorf("expected ufw and nftables to be installed, got %v", manager.installed)
+	}
+}
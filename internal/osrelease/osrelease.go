@@ -0,0 +1,99 @@
+// Package osrelease detects the Linux distribution of a remote host from /etc/os-release.
+package osrelease
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/the-agent-c-ai/hadron/sdk/ssh"
+)
+
+const osReleasePath = "/etc/os-release"
+
+// Family groups distributions that share a package manager.
+type Family string
+
+const (
+	// FamilyDebian covers Debian and its derivatives (Ubuntu, Raspbian, ...), managed with apt.
+	FamilyDebian Family = "debian"
+	// FamilyRHEL covers RHEL, Fedora, and their rebuilds (Rocky, Alma, CentOS Stream), managed with dnf.
+	FamilyRHEL Family = "rhel"
+	// FamilyUnknown is any other distribution.
+	FamilyUnknown Family = ""
+)
+
+// Info holds the fields of /etc/os-release Hadron uses.
+type Info struct {
+	PrettyName      string   // e.g., "Debian GNU/Linux 12 (bookworm)"
+	ID              string   // e.g., "debian", "rocky", "fedora"
+	IDLike          []string // e.g., ["rhel", "centos", "fedora"]
+	VersionID       string   // e.g., "12", "9.4"
+	VersionCodename string   // e.g., "bookworm" (Debian family only)
+}
+
+// Parse parses the contents of an os-release file. Unknown keys and malformed lines are ignored.
+func Parse(content string) Info {
+	var info Info
+
+	for line := range strings.SplitSeq(content, "\n") {
+		key, value, found := strings.Cut(strings.TrimSpace(line), "=")
+		if !found || strings.HasPrefix(key, "#") {
+			continue
+		}
+
+		value = strings.Trim(value, `"'`)
+
+		switch key {
+		case "PRETTY_NAME":
+			info.PrettyName = value
+		case "ID":
+			info.ID = value
+		case "ID_LIKE":
+			info.IDLike = strings.Fields(value)
+		case "VERSION_ID":
+			info.VersionID = value
+		case "VERSION_CODENAME":
+			info.VersionCodename = value
+		}
+	}
+
+	return info
+}
+
+// Detect reads and parses /etc/os-release on the remote host.
+func Detect(client ssh.Connection) (Info, error) {
+	stdout, stderr, err := client.Execute("cat " + osReleasePath)
+	if err != nil {
+		return Info{}, fmt.Errorf("failed to read %s: %w (stderr: %s)", osReleasePath, err, stderr)
+	}
+
+	return Parse(stdout), nil
+}
+
+// String returns the distribution's pretty name, or its ID and version.
+func (i Info) String() string {
+	if i.PrettyName != "" {
+		return i.PrettyName
+	}
+
+	if i.ID == "" {
+		return "an unknown distribution"
+	}
+
+	return strings.TrimSpace(i.ID + " " + i.VersionID)
+}
+
+// Family returns the distribution family, from ID or, for derivatives, ID_LIKE.
+func (i Info) Family() Family {
+	ids := append([]string{i.ID}, i.IDLike...)
+
+	switch {
+	case slices.ContainsFunc(ids, func(id string) bool { return id == "debian" || id == "ubuntu" }):
+		return FamilyDebian
+	case slices.ContainsFunc(ids, func(id string) bool { return id == "rhel" || id == "fedora" || id == "centos" }):
+		return FamilyRHEL
+	default:
+		return FamilyUnknown
+	}
+}
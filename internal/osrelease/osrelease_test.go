@@ -0,0 +1,66 @@
+package osrelease_test
+
+import (
+	"testing"
+
+	"github.com/the-agent-c-ai/hadron/internal/osrelease"
+)
+
+func TestParseAndFamily(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+		id      string
+		version string
+		family  osrelease.Family
+	}{
+		{
+			name: "debian",
+			content: `PRETTY_NAME="Debian GNU/Linux 12 (bookworm)"
+NAME="Debian GNU/Linux"
+VERSION_ID="12"
+VERSION_CODENAME=bookworm
+ID=debian
+`,
+			id: "debian", version: "12", family: osrelease.FamilyDebian,
+		},
+		{
+			name:    "ubuntu",
+			content: "ID=ubuntu\nID_LIKE=debian\nVERSION_ID=\"24.04\"\n",
+			id:      "ubuntu", version: "24.04", family: osrelease.FamilyDebian,
+		},
+		{
+			name:    "rocky",
+			content: "NAME=\"Rocky Linux\"\nID=\"rocky\"\nID_LIKE=\"rhel centos fedora\"\nVERSION_ID=\"9.4\"\n",
+			id:      "rocky", version: "9.4", family: osrelease.FamilyRHEL,
+		},
+		{
+			name:    "fedora",
+			content: "# comment\nID=fedora\nVERSION_ID=40\n",
+			id:      "fedora", version: "40", family: osrelease.FamilyRHEL,
+		},
+		{
+			name:    "alpine",
+			content: "ID=alpine\nVERSION_ID=3.20.0\n",
+			id:      "alpine", version: "3.20.0", family: osrelease.FamilyUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			info := osrelease.Parse(tt.content)
+
+			if info.ID != tt.id || info.VersionID != tt.version {
+				t.Errorf("expected %s %s, got %s %s", tt.id, tt.version, info.ID, info.VersionID)
+			}
+
+			if info.Family() != tt.family {
+				t.Errorf("expected family %q, got %q", tt.family, info.Family())
+			}
+		})
+	}
+}
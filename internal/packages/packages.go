@@ -0,0 +1,84 @@
+// Package packages selects the package manager for a host's distribution, so package operations
+// work on Debian-family (apt) and RHEL-family (dnf) hosts alike.
+package packages
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/the-agent-c-ai/hadron/internal/debian"
+	"github.com/the-agent-c-ai/hadron/internal/osrelease"
+	"github.com/the-agent-c-ai/hadron/internal/rhel"
+	"github.com/the-agent-c-ai/hadron/sdk/ssh"
+)
+
+// ErrUnsupportedOS indicates the host runs a distribution without a supported package manager.
+var ErrUnsupportedOS = errors.New("unsupported operating system")
+
+// Manager manages the packages of a host. All operations are idempotent.
+type Manager interface {
+	EnsureInstalled(client ssh.Connection, packageName string) error
+	EnsureRemoved(client ssh.Connection, packageName string) error
+	EnsureHeld(client ssh.Connection, packageName string) error
+	EnsureUnheld(client ssh.Connection, packageName string) error
+}
+
+// ForFamily returns the package manager for a distribution family.
+func ForFamily(family osrelease.Family) (Manager, error) {
+	switch family {
+	case osrelease.FamilyDebian:
+		return Apt{}, nil
+	case osrelease.FamilyRHEL:
+		return Dnf{}, nil
+	case osrelease.FamilyUnknown:
+		return nil, fmt.Errorf("%w: only Debian and RHEL-family distributions are supported", ErrUnsupportedOS)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedOS, family)
+	}
+}
+
+// Apt is the Debian-family Manager. It delegates to the debian package.
+type Apt struct{}
+
+// EnsureInstalled installs a package with apt-get if necessary.
+func (Apt) EnsureInstalled(client ssh.Connection, packageName string) error {
+	return debian.EnsureInstalled(client, packageName)
+}
+
+// EnsureRemoved removes a package with apt-get if necessary.
+func (Apt) EnsureRemoved(client ssh.Connection, packageName string) error {
+	return debian.EnsureRemoved(client, packageName)
+}
+
+// EnsureHeld holds a package with apt-mark if necessary.
+func (Apt) EnsureHeld(client ssh.Connection, packageName string) error {
+	return debian.EnsureHeld(client, packageName)
+}
+
+// EnsureUnheld releases an apt-mark hold if necessary.
+func (Apt) EnsureUnheld(client ssh.Connection, packageName string) error {
+	return debian.EnsureUnheld(client, packageName)
+}
+
+// Dnf is the RHEL-family Manager. It delegates to the rhel package.
+type Dnf struct{}
+
+// EnsureInstalled installs a package with dnf if necessary.
+func (Dnf) EnsureInstalled(client ssh.Connection, packageName string) error {
+	return rhel.EnsureInstalled(client, packageName)
+}
+
+// EnsureRemoved removes a package with dnf if necessary.
+func (Dnf) EnsureRemoved(client ssh.Connection, packageName string) error {
+	return rhel.EnsureRemoved(client, packageName)
+}
+
+// EnsureHeld locks a package version with dnf versionlock if necessary.
+func (Dnf) EnsureHeld(client ssh.Connection, packageName string) error {
+	return rhel.EnsureHeld(client, packageName)
+}
+
+// EnsureUnheld releases a dnf version lock if necessary.
+func (Dnf) EnsureUnheld(client ssh.Connection, packageName string) error {
+	return rhel.EnsureUnheld(client, packageName)
+}
@@ -0,0 +1,25 @@
+package packages_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/the-agent-c-ai/hadron/internal/osrelease"
+	"github.com/the-agent-c-ai/hadron/internal/packages"
+)
+
+func TestForFamily(t *testing.T) {
+	t.Parallel()
+
+	if manager, err := packages.ForFamily(osrelease.FamilyDebian); err != nil || manager != (packages.Apt{}) {
+		t.Errorf("expected apt for Debian, got %T (err: %v)", manager, err)
+	}
+
+	if manager, err := packages.ForFamily(osrelease.FamilyRHEL); err != nil || manager != (packages.Dnf{}) {
+		t.Errorf("expected dnf for RHEL, got %T (err: %v)", manager, err)
+	}
+
+	if _, err := packages.ForFamily(osrelease.FamilyUnknown); !errors.Is(err, packages.ErrUnsupportedOS) {
+		t.Errorf("expected ErrUnsupportedOS, got: %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package rhel
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/the-agent-c-ai/hadron/internal/osrelease"
+	"github.com/the-agent-c-ai/hadron/sdk/ssh"
+)
+
+const (
+	dockerRepoBaseURL = "https://download.docker.com/linux"
+	dockerRepoFile    = "/etc/yum.repos.d/docker-ce.repo"
+)
+
+var errCreateDockerGroup = errors.New("failed to create docker group")
+
+// installDocker installs Docker CE following the official RHEL/Fedora/CentOS installation procedure.
+// See: https://docs.docker.com/engine/install/rhel/
+func installDocker(client ssh.Connection) error {
+	info, err := osrelease.Detect(client)
+	if err != nil {
+		return fmt.Errorf("failed to detect distribution: %w", err)
+	}
+
+	// Step 1: Install prerequisites
+	if err := installDockerPrerequisites(client); err != nil {
+		return fmt.Errorf("failed to install Docker prerequisites: %w", err)
+	}
+
+	// Step 2: Set up Docker repository (the repository metadata is GPG-signed, dnf imports the key)
+	if err := setupDockerRepository(client, info); err != nil {
+		return fmt.Errorf("failed to setup Docker repository: %w", err)
+	}
+
+	// Step 3: Install Docker packages
+	if err := installDockerPackages(client); err != nil {
+		return fmt.Errorf("failed to install Docker packages: %w", err)
+	}
+
+	// Step 4: Start Docker (unlike on Debian, the packages do not enable the service)
+	if _, stderr, err := ssh.RunPrivileged(client, "systemctl enable --now docker"); err != nil {
+		return fmt.Errorf("%w: %s", ErrDockerServiceStart, stderr)
+	}
+
+	return nil
+}
+
+// installDockerPrerequisites installs ca-certificates and curl.
+func installDockerPrerequisites(client ssh.Connection) error {
+	cmd := "dnf install -y -q ca-certificates curl"
+
+	_, stderr, err := ssh.RunPrivileged(client, cmd)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrDockerPrereqFailed, stderr)
+	}
+
+	return nil
+}
+
+// dockerRepoURL returns the Docker repository file for the distribution. Docker publishes
+// repositories for Fedora and RHEL; the RHEL rebuilds (Rocky, Alma, CentOS Stream) use CentOS's.
+func dockerRepoURL(info osrelease.Info) string {
+	distribution := "centos"
+
+	switch info.ID {
+	case "fedora", "rhel":
+		distribution = info.ID
+	}
+
+	return fmt.Sprintf("%s/%s/docker-ce.repo", dockerRepoBaseURL, distribution)
+}
+
+// setupDockerRepository adds Docker's dnf repository to yum.repos.d. The file is downloaded directly
+// rather than with dnf config-manager, whose syntax differs between dnf4 and dnf5.
+func setupDockerRepository(client ssh.Connection, info osrelease.Info) error {
+	cmd := fmt.Sprintf("curl -fsSL %s -o %s", dockerRepoURL(info), dockerRepoFile)
+
+	_, stderr, err := ssh.RunPrivileged(client, cmd)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrDockerRepoWrite, stderr)
+	}
+
+	return nil
+}
+
+// installDockerPackages installs Docker CE and related packages.
+func installDockerPackages(client ssh.Connection) error {
+	// Create docker group with predictable GID 900 before package installation
+	// This ensures the docker group exists with a known GID for container access
+	createGroupCmd := "groupadd --system --gid 900 docker 2>/dev/null || true"
+
+	_, stderr, err := ssh.RunPrivileged(client, createGroupCmd)
+	if err != nil {
+		return fmt.Errorf("%w: %s", errCreateDockerGroup, stderr)
+	}
+
+	packages := []string{
+		"docker-ce",
+		"docker-ce-cli",
+		"containerd.io",
+	}
+
+	installCmd := "dnf install -y -q " + strings.Join(packages, " ")
+
+	_, stderr, err = ssh.RunPrivileged(client, installCmd)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrDockerPackageInstall, stderr)
+	}
+
+	return nil
+}
@@ -0,0 +1,25 @@
+package rhel
+
+import "errors"
+
+// Package management errors.
+var (
+	// ErrPackageInstallFailed indicates a package installation failed.
+	ErrPackageInstallFailed = errors.New("package installation failed")
+	// ErrPackageRemoveFailed indicates a package removal failed.
+	ErrPackageRemoveFailed = errors.New("package removal failed")
+	// ErrPackageHoldFailed indicates locking or unlocking a package version failed.
+	ErrPackageHoldFailed = errors.New("package hold failed")
+)
+
+// Docker installation errors.
+var (
+	// ErrDockerPrereqFailed indicates Docker prerequisite installation failed.
+	ErrDockerPrereqFailed = errors.New("docker prerequisite installation failed")
+	// ErrDockerRepoWrite indicates failed to write Docker repository file.
+	ErrDockerRepoWrite = errors.New("failed to write docker repository file")
+	// ErrDockerPackageInstall indicates Docker package installation failed.
+	ErrDockerPackageInstall = errors.New("docker package installation failed")
+	// ErrDockerServiceStart indicates the Docker service could not be enabled and started.
+	ErrDockerServiceStart = errors.New("failed to start docker service")
+)
@@ -0,0 +1,153 @@
+// Package rhel provides RHEL-family (RHEL, Fedora, Rocky, Alma, CentOS Stream) package management
+// operations with dnf, paralleling the debian package.
+package rhel
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/the-agent-c-ai/hadron/sdk/ssh"
+)
+
+// customInstaller is a function that performs custom package installation.
+type customInstaller func(client ssh.Connection) error
+
+// getCustomInstaller returns a custom installer for the given package name, if one exists.
+func getCustomInstaller(packageName string) (customInstaller, bool) {
+	// Map of package names to custom installation functions
+	customInstallers := map[string]customInstaller{
+		"docker-ce": installDocker,
+	}
+
+	installer, exists := customInstallers[packageName]
+
+	return installer, exists
+}
+
+// isInstalled checks if an RPM package is installed on the system.
+func isInstalled(client ssh.Connection, packageName string) bool {
+	_, _, err := client.Execute(fmt.Sprintf("rpm -q %s >/dev/null 2>&1", packageName))
+
+	// rpm -q returns non-zero if the package is not installed
+	return err == nil
+}
+
+// install installs a package using dnf.
+// If a custom installer exists for the package, it will be used instead.
+func install(client ssh.Connection, packageName string) error {
+	// Check if custom installer exists
+	if installer, exists := getCustomInstaller(packageName); exists {
+		return installer(client)
+	}
+
+	// Install package with:
+	// -y: assume yes to all prompts
+	// -q: quiet output
+	// install_weak_deps=False: only install dependencies, not weak (recommended) packages
+	installCmd := "dnf install -y -q --setopt=install_weak_deps=False " + packageName
+
+	_, stderr, err := ssh.RunPrivileged(client, installCmd)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %s", ErrPackageInstallFailed, packageName, stderr)
+	}
+
+	return nil
+}
+
+// remove removes a package using dnf. Unused dependencies are removed with it
+// (dnf's clean_requirements_on_remove default).
+func remove(client ssh.Connection, packageName string) error {
+	removeCmd := "dnf remove -y -q " + packageName
+
+	_, stderr, err := ssh.RunPrivileged(client, removeCmd)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %s", ErrPackageRemoveFailed, packageName, stderr)
+	}
+
+	return nil
+}
+
+// EnsureInstalled ensures a package is installed, installing it if necessary.
+func EnsureInstalled(client ssh.Connection, packageName string) error {
+	if isInstalled(client, packageName) {
+		return nil // Already installed
+	}
+
+	return install(client, packageName)
+}
+
+// EnsureRemoved ensures a package is not installed, removing it if necessary.
+func EnsureRemoved(client ssh.Connection, packageName string) error {
+	if !isInstalled(client, packageName) {
+		return nil // Already removed
+	}
+
+	return remove(client, packageName)
+}
+
+// ensureVersionlock makes the dnf versionlock command available. It is built into dnf5 (Fedora 41+)
+// and a plugin package on dnf4.
+func ensureVersionlock(client ssh.Connection) error {
+	cmd := "dnf versionlock list >/dev/null 2>&1 || " +
+		ssh.Privileged(client, "dnf install -y -q python3-dnf-plugin-versionlock")
+
+	_, stderr, err := client.Execute(cmd)
+	if err != nil {
+		return fmt.Errorf("%w: versionlock plugin: %s", ErrPackageHoldFailed, stderr)
+	}
+
+	return nil
+}
+
+// isHeld checks if a package version is locked (dnf versionlock list).
+// Entries look like "curl-0:7.76.1-26.el9.*".
+func isHeld(client ssh.Connection, packageName string) (bool, error) {
+	if err := ensureVersionlock(client); err != nil {
+		return false, err
+	}
+
+	stdout, stderr, err := client.Execute("dnf versionlock list")
+	if err != nil {
+		return false, fmt.Errorf("%w: dnf versionlock list failed: %s", ErrPackageHoldFailed, stderr)
+	}
+
+	for line := range strings.SplitSeq(stdout, "\n") {
+		version, found := strings.CutPrefix(strings.TrimSpace(line), packageName+"-")
+		if found && version != "" && version[0] >= '0' && version[0] <= '9' {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// EnsureHeld ensures a package is locked at its installed version (dnf versionlock), so
+// neither dnf upgrade nor dnf-automatic upgrades it.
+func EnsureHeld(client ssh.Connection, packageName string) error {
+	held, err := isHeld(client, packageName)
+	if err != nil || held {
+		return err
+	}
+
+	_, stderr, err := ssh.RunPrivileged(client, "dnf versionlock add "+packageName)
+	if err != nil {
+		return fmt.Errorf("%w: dnf versionlock add %s: %s", ErrPackageHoldFailed, packageName, stderr)
+	}
+
+	return nil
+}
+
+// EnsureUnheld ensures a package is not version-locked, releasing a previous lock if necessary.
+func EnsureUnheld(client ssh.Connection, packageName string) error {
+	held, err := isHeld(client, packageName)
+	if err != nil || !held {
+		return err
+	}
+
+	_, stderr, err := ssh.RunPrivileged(client, "dnf versionlock delete "+packageName)
+	if err != nil {
+		return fmt.Errorf("%w: dnf versionlock delete %s: %s", ErrPackageHoldFailed, packageName, stderr)
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package rhel_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/the-agent-c-ai/hadron/internal/rhel"
+	"github.com/the-agent-c-ai/hadron/internal/testutil"
+)
+
+// outputs answers each command with its stdout in the table, and nothing for the others.
+func outputs(stdout map[string]string) func(command string) string {
+	return func(command string) string { return stdout[command] }
+}
+
+func TestEnsureInstalled(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.FakeConnection{Failing: []string{"rpm -q curl >/dev/null 2>&1"}}
+
+	if err := rhel.EnsureInstalled(client, "curl"); err != nil {
+		t.Fatalf("expected EnsureInstalled to succeed, got error: %v", err)
+	}
+
+	want := []string{
+		"rpm -q curl >/dev/null 2>&1",
+		"sudo dnf install -y -q --setopt=install_weak_deps=False curl",
+	}
+	if !slices.Equal(client.Commands, want) {
+		t.Errorf("expected %v, got %v", want, client.Commands)
+	}
+
+	// Installed packages are left alone
+	client = &testutil.FakeConnection{}
+
+	if err := rhel.EnsureInstalled(client, "curl"); err != nil || len(client.Commands) != 1 {
+		t.Errorf("expected installed package to be skipped, got %v (err: %v)", client.Commands, err)
+	}
+}
+
+func TestEnsureInstalledDocker(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.FakeConnection{
+		Failing: []string{"rpm -q docker-ce >/dev/null 2>&1"},
+		Respond: outputs(map[string]string{"cat /etc/os-release": "ID=\"rocky\"\nID_LIKE=\"rhel centos fedora\"\n"}),
+	}
+
+	if err := rhel.EnsureInstalled(client, "docker-ce"); err != nil {
+		t.Fatalf("expected docker install to succeed, got error: %v", err)
+	}
+
+	for _, want := range []string{
+		"sudo curl -fsSL https://download.docker.com/linux/centos/docker-ce.repo -o /etc/yum.repos.d/docker-ce.repo",
+		"sudo dnf install -y -q docker-ce docker-ce-cli containerd.io",
+		"sudo systemctl enable --now docker",
+	} {
+		if !slices.Contains(client.Commands, want) {
+			t.Errorf("expected command %q, got %v", want, client.Commands)
+		}
+	}
+}
+
+func TestEnsureHeld(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.FakeConnection{
+		Respond: outputs(map[string]string{"dnf versionlock list": "curl-minimal-0:7.76.1-26.el9.*\n"}),
+	}
+
+	if err := rhel.EnsureHeld(client, "curl"); err != nil {
+		t.Fatalf("expected EnsureHeld to succeed, got error: %v", err)
+	}
+
+	if !slices.Contains(client.Commands, "sudo dnf versionlock add curl") {
+		t.Errorf("expected curl to be locked (curl-minimal is another package), got %v", client.Commands)
+	}
+
+	client = &testutil.FakeConnection{Respond: outputs(map[string]string{"dnf versionlock list": "curl-0:7.76.1-26.el9.*\n"})}
+
+	if err := rhel.EnsureUnheld(client, "curl"); err != nil {
+		t.Fatalf("expected EnsureUnheld to succeed, got error: %v", err)
+	}
+
+	if !slices.Contains(client.Commands, "sudo dnf versionlock delete curl") {
+		t.Errorf("expected curl lock to be released, got %v", client.Commands)
+	}
+}
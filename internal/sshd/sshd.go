@@ -194,40 +194,40 @@ func Apply(client ssh.Connection) error {
 	config := SecureConfig()
 
 	// Backup existing config
-	backupCmd := fmt.Sprintf("sudo cp %s %s.bak.$(date +%%s)", sshdConfigPath, sshdConfigPath)
+	backupCmd := fmt.Sprintf("cp %s %s.bak.$(date +%%s)", sshdConfigPath, sshdConfigPath)
 
-	_, stderr, err := client.Execute(backupCmd)
+	_, stderr, err := ssh.RunPrivileged(client, backupCmd)
 	if err != nil {
 		return fmt.Errorf("failed to backup sshd_config: %w (stderr: %s)", err, stderr)
 	}
 
 	// Write new config via temp file (avoids shell escaping issues)
-	tempPath := "/tmp/hadron-sshd_config"
+	tempPath := ssh.TempPath(client, "hadron-sshd_config")
 	if err := client.UploadData([]byte(config), tempPath); err != nil {
 		return fmt.Errorf("failed to write temp sshd_config: %w", err)
 	}
 
-	// Move temp file to final location with sudo
-	moveCmd := fmt.Sprintf("sudo mv %s %s", tempPath, sshdConfigPath)
+	// Move temp file to final location as root
+	moveCmd := fmt.Sprintf("mv %s %s", tempPath, sshdConfigPath)
 
-	_, stderr, err = client.Execute(moveCmd)
+	_, stderr, err = ssh.RunPrivileged(client, moveCmd)
 	if err != nil {
 		return fmt.Errorf("failed to move sshd_config: %w (stderr: %s)", err, stderr)
 	}
 
 	// Test configuration before restarting
-	testCmd := "sudo sshd -t"
+	testCmd := "sshd -t"
 
-	_, stderr, err = client.Execute(testCmd)
+	_, stderr, err = ssh.RunPrivileged(client, testCmd)
 	if err != nil {
 		return fmt.Errorf("sshd config test failed: %w (stderr: %s)", err, stderr)
 	}
 
 	// Reload SSH daemon (reload, not restart, to keep current connections alive)
 	// Debian uses 'ssh' as the service name, not 'sshd'
-	reloadCmd := "sudo systemctl reload ssh"
+	reloadCmd := "systemctl reload ssh"
 
-	_, stderr, err = client.Execute(reloadCmd)
+	_, stderr, err = ssh.RunPrivileged(client, reloadCmd)
 	if err != nil {
 		return fmt.Errorf("failed to reload ssh: %w (stderr: %s)", err, stderr)
 	}
@@ -132,25 +132,19 @@ func Apply(client ssh.Connection) error {
 	config := SecurityConfig()
 
 	// Write to temp file first (SFTP can't write directly to /etc/ as non-root)
-	tempPath := "/tmp/hadron-sysctl.conf"
+	tempPath := ssh.TempPath(client, "hadron-sysctl.conf")
 	if err := client.UploadData([]byte(config), tempPath); err != nil {
 		return fmt.Errorf("failed to write temp sysctl config: %w", err)
 	}
 
-	// Move temp file to final location with sudo
-	moveCmd := fmt.Sprintf("sudo mv %s %s", tempPath, sysctlConfigPath)
-
-	_, stderr, err := client.Execute(moveCmd)
-	if err != nil {
-		return fmt.Errorf("failed to move sysctl config: %w (stderr: %s)", err, stderr)
+	// Move temp file to final location as root and apply it immediately, in one SSH session
+	commands := []string{
+		ssh.Privileged(client, fmt.Sprintf("mv %s %s", tempPath, sysctlConfigPath)),
+		ssh.Privileged(client, "sysctl -p "+sysctlConfigPath),
 	}
 
-	// Apply configuration immediately
-	applyCmd := "sudo sysctl -p " + sysctlConfigPath
-
-	_, stderr, err = client.Execute(applyCmd)
-	if err != nil {
-		return fmt.Errorf("failed to apply sysctl config: %w (stderr: %s)", err, stderr)
+	if _, err := client.Batch(commands); err != nil {
+		return fmt.Errorf("failed to install sysctl config: %w", err)
 	}
 
 	return nil
@@ -0,0 +1,48 @@
+package sysctl_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/the-agent-c-ai/hadron/internal/sysctl"
+	"github.com/the-agent-c-ai/hadron/internal/testutil"
+)
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.FakeConnection{}
+
+	if err := sysctl.Apply(client); err != nil {
+		t.Fatalf("expected apply to succeed, got: %v", err)
+	}
+
+	want := []string{
+		"sudo mv /tmp/hadron-sysctl.conf /etc/sysctl.d/99-hadron-security.conf",
+		"sudo sysctl -p /etc/sysctl.d/99-hadron-security.conf",
+	}
+	if !slices.Equal(client.Commands, want) {
+		t.Errorf("expected commands %v, got %v", want, client.Commands)
+	}
+
+	if config := string(client.Uploads["/tmp/hadron-sysctl.conf"]); !strings.Contains(config, "kernel.") {
+		t.Errorf("expected the security config to be uploaded, got %q", config)
+	}
+}
+
+func TestApplyStopsWhenMoveFails(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.FakeConnection{
+		Failing: []string{"sudo mv /tmp/hadron-sysctl.conf /etc/sysctl.d/99-hadron-security.conf"},
+	}
+
+	if err := sysctl.Apply(client); err == nil {
+		t.Fatal("expected an error when the config cannot be moved into place")
+	}
+
+	if len(client.Commands) != 1 {
+		t.Errorf("expected sysctl -p not to run, got %v", client.Commands)
+	}
+}
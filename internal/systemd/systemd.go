@@ -0,0 +1,218 @@
+// Package systemd writes systemd units on remote hosts and manages their state via systemctl.
+package systemd
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/the-agent-c-ai/hadron/sdk/ssh"
+)
+
+// unitDir is where locally administered unit files live.
+const unitDir = "/etc/systemd/system"
+
+// File is a file written by WriteFiles, e.g. a unit or a script a unit runs.
+type File struct {
+	Path    string
+	Content string
+	Mode    string // octal permissions (e.g., "644")
+}
+
+// Service is a .service unit.
+type Service struct {
+	Description string
+	Type        string            // e.g., "oneshot" or "simple" (empty: systemd's default)
+	After       []string          // units to start after (and want)
+	ExecStart   string            // command line, already quoted (see CommandLine)
+	Environment map[string]string // written as escaped Environment= lines in key order
+	User        string            // empty: root
+	Restart     string            // e.g., "on-failure" (empty: never restart)
+	WantedBy    string            // install target (e.g., "multi-user.target"), empty for units only started by timers
+}
+
+// Timer is a .timer unit starting the service of the same name.
+type Timer struct {
+	Description string
+	OnCalendar  string // systemd calendar expression (e.g., "daily", "*-*-* 03:00:00")
+}
+
+// UnitPath returns the path of a unit file (e.g., "backup.timer").
+func UnitPath(unit string) string {
+	return path.Join(unitDir, unit)
+}
+
+// Render returns the unit file content.
+func (s Service) Render() string {
+	var b strings.Builder
+
+	b.WriteString("# Generated by Hadron\n")
+	b.WriteString("[Unit]\n")
+	fmt.Fprintf(&b, "Description=%s\n", s.Description)
+
+	if len(s.After) > 0 {
+		fmt.Fprintf(&b, "Wants=%s\n", strings.Join(s.After, " "))
+		fmt.Fprintf(&b, "After=%s\n", strings.Join(s.After, " "))
+	}
+
+	b.WriteString("\n[Service]\n")
+
+	if s.Type != "" {
+		fmt.Fprintf(&b, "Type=%s\n", s.Type)
+	}
+
+	if s.User != "" {
+		fmt.Fprintf(&b, "User=%s\n", s.User)
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(s.Environment)) {
+		fmt.Fprintf(&b, "Environment=%s\n", quote(key+"="+s.Environment[key]))
+	}
+
+	fmt.Fprintf(&b, "ExecStart=%s\n", s.ExecStart)
+
+	if s.Restart != "" {
+		fmt.Fprintf(&b, "Restart=%s\n", s.Restart)
+	}
+
+	if s.WantedBy != "" {
+		b.WriteString("\n[Install]\n")
+		fmt.Fprintf(&b, "WantedBy=%s\n", s.WantedBy)
+	}
+
+	return b.String()
+}
+
+// CommandLine returns an ExecStart= command line running args. Each argument is quoted as
+// systemd.syntax(7) and systemd.service(5) require, so spaces, quotes, backslashes, newlines, and
+// the "%" specifier and "$" variable prefixes reach the program literally.
+func CommandLine(args ...string) string {
+	quoted := make([]string, len(args))
+
+	for i, arg := range args {
+		quoted[i] = quoteArg(strings.ReplaceAll(arg, "$", "$$"))
+	}
+
+	return strings.Join(quoted, " ")
+}
+
+// quoteArg quotes a command line argument only when it needs it, keeping plain paths readable.
+func quoteArg(arg string) string {
+	if arg != "" && arg != ";" && !strings.ContainsAny(arg, " \t\n\"'\\%") {
+		return arg
+	}
+
+	return quote(arg)
+}
+
+// quote returns value as a double-quoted systemd string: backslashes, quotes, and newlines are
+// escaped, and "%" is doubled so it is not expanded as a specifier.
+func quote(value string) string {
+	escaped := strings.NewReplacer(
+		"\\", "\\\\",
+		"\"", "\\\"",
+		"\n", "\\n",
+		"%", "%%",
+	).Replace(value)
+
+	return "\"" + escaped + "\""
+}
+
+// Render returns the unit file content. Missed runs (e.g., while the host was down) are caught up
+// on the next boot.
+func (t Timer) Render() string {
+	var b strings.Builder
+
+	b.WriteString("# Generated by Hadron\n")
+	b.WriteString("[Unit]\n")
+	fmt.Fprintf(&b, "Description=%s\n", t.Description)
+	b.WriteString("\n[Timer]\n")
+	fmt.Fprintf(&b, "OnCalendar=%s\n", t.OnCalendar)
+	b.WriteString("Persistent=true\n")
+	b.WriteString("\n[Install]\n")
+	b.WriteString("WantedBy=timers.target\n")
+
+	return b.String()
+}
+
+// WriteFiles writes the files whose content differs from what is on the host, and reloads systemd
+// if any of them changed. Returns true if anything changed.
+func WriteFiles(client ssh.Connection, files []File) (bool, error) {
+	changed := false
+
+	for _, file := range files {
+		// Skip files that are already in place
+		if current, _, err := ssh.RunPrivileged(client, "cat "+file.Path); err == nil && current == file.Content {
+			continue
+		}
+
+		// Write via temp file (avoids shell escaping issues)
+		tempPath := ssh.TempPath(client, "hadron-"+path.Base(file.Path))
+		if err := client.UploadData([]byte(file.Content), tempPath); err != nil {
+			return changed, fmt.Errorf("failed to write temp file for %s: %w", file.Path, err)
+		}
+
+		cmd := ssh.Privileged(client, fmt.Sprintf("install -D -m %s %s %s", file.Mode, tempPath, file.Path)) +
+			" && rm -f " + tempPath
+		if _, stderr, err := client.Execute(cmd); err != nil {
+			return changed, fmt.Errorf("failed to install %s: %w (stderr: %s)", file.Path, err, stderr)
+		}
+
+		changed = true
+	}
+
+	if changed {
+		if err := systemctl(client, "daemon-reload"); err != nil {
+			return changed, err
+		}
+	}
+
+	return changed, nil
+}
+
+// Enable enables and starts a unit. A changed unit that is already running is restarted, so it
+// picks up its new definition.
+func Enable(client ssh.Connection, unit string, changed bool) error {
+	if err := systemctl(client, "enable --now "+unit); err != nil {
+		return err
+	}
+
+	if changed {
+		return systemctl(client, "restart "+unit)
+	}
+
+	return nil
+}
+
+// Remove stops and disables the units, deletes their files (and any extra files, such as
+// scripts), and reloads systemd. Units that do not exist are ignored.
+func Remove(client ssh.Connection, units []string, extraFiles ...string) error {
+	paths := make([]string, 0, len(units)+len(extraFiles))
+
+	for _, unit := range units {
+		// Disabling a unit that was never installed fails; there is nothing to stop then
+		_, _, _ = ssh.RunPrivileged(client, "systemctl disable --now "+unit)
+
+		paths = append(paths, UnitPath(unit))
+	}
+
+	paths = append(paths, extraFiles...)
+
+	if _, stderr, err := ssh.RunPrivileged(client, "rm -f "+strings.Join(paths, " ")); err != nil {
+		return fmt.Errorf("failed to remove unit files: %w (stderr: %s)", err, stderr)
+	}
+
+	return systemctl(client, "daemon-reload")
+}
+
+// systemctl runs a systemctl command as root.
+func systemctl(client ssh.Connection, args string) error {
+	cmd := "systemctl " + args
+	if _, stderr, err := ssh.RunPrivileged(client, cmd); err != nil {
+		return fmt.Errorf("failed to run %s: %w (stderr: %s)", cmd, err, stderr)
+	}
+
+	return nil
+}
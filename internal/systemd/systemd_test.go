@@ -0,0 +1,68 @@
+package systemd_test
+
+import (
+	"testing"
+
+	"github.com/the-agent-c-ai/hadron/internal/systemd"
+)
+
+func TestRenderService(t *testing.T) {
+	t.Parallel()
+
+	unit := systemd.Service{
+		Description: "Hadron job backup",
+		Type:        "oneshot",
+		After:       []string{"docker.service"},
+		ExecStart:   "/bin/sh /var/lib/hadron/jobs/backup.sh",
+	}.Render()
+
+	want := `# Generated by Hadron
+[Unit]
+Description=Hadron job backup
+Wants=docker.service
+After=docker.service
+
+[Service]
+Type=oneshot
+ExecStart=/bin/sh /var/lib/hadron/jobs/backup.sh
+`
+	if unit != want {
+		t.Errorf("unexpected unit:\n%s\nwant:\n%s", unit, want)
+	}
+}
+
+func TestRenderTimer(t *testing.T) {
+	t.Parallel()
+
+	unit := systemd.Timer{Description: "Hadron job backup", OnCalendar: "*-*-* 03:00:00"}.Render()
+
+	want := `# Generated by Hadron
+[Unit]
+Description=Hadron job backup
+
+[Timer]
+OnCalendar=*-*-* 03:00:00
+Persistent=true
+
+[Install]
+WantedBy=timers.target
+`
+	if unit != want {
+		t.Errorf("unexpected unit:\n%s\nwant:\n%s", unit, want)
+	}
+
+	if path := systemd.UnitPath("hadron-job-backup.timer"); path != "/etc/systemd/system/hadron-job-backup.timer" {
+		t.Errorf("unexpected unit path %q", path)
+	}
+}
+
+func TestCommandLine(t *testing.T) {
+	t.Parallel()
+
+	got := systemd.CommandLine("/bin/echo", "plain", "two words", `back\slash`, "", ";", "$PATH", "50%")
+
+	want := `/bin/echo plain "two words" "back\\slash" "" ";" $$PATH "50%%"`
+	if got != want {
+		t.Errorf("unexpected command line:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,141 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/the-agent-c-ai/hadron/sdk/ssh"
+)
+
+// ErrExitStatus is the error FakeConnection returns for commands listed in Failing.
+var ErrExitStatus = errors.New("process exited with status 1")
+
+// FakeConnection is an in-memory ssh.Connection for unit tests that records every command it is asked
+// to run. Commands listed in Failing exit non-zero with "check failed" on stderr; the others succeed
+// with the output of Respond, if set. Handler, if set, answers every command instead, for tests that
+// need control over stderr and errors.
+type FakeConnection struct {
+	Commands []string
+	Failing  []string
+	Respond  func(command string) string
+	Handler  func(command string) (stdout, stderr string, err error)
+
+	Stdin   []byte            // input of the last ExecuteWithInput
+	Uploads map[string][]byte // UploadData contents by remote path
+}
+
+// Execute records the command and answers it through Handler, Failing, or Respond.
+func (f *FakeConnection) Execute(command string) (string, string, error) {
+	f.Commands = append(f.Commands, command)
+
+	switch {
+	case f.Handler != nil:
+		return f.Handler(command)
+	case slices.Contains(f.Failing, command):
+		return "", "check failed", ErrExitStatus
+	case f.Respond != nil:
+		return f.Respond(command), "", nil
+	default:
+		return "", "", nil
+	}
+}
+
+// ExecuteWithInput keeps the input in Stdin and runs the command like Execute.
+func (f *FakeConnection) ExecuteWithInput(command string, stdin io.Reader) (string, string, error) {
+	data, err := io.ReadAll(stdin)
+	if err != nil {
+		return "", "", err
+	}
+
+	f.Stdin = data
+
+	return f.Execute(command)
+}
+
+// StreamCommand runs the command like Execute and writes its stdout.
+func (f *FakeConnection) StreamCommand(_ context.Context, command string, stdout, _ io.Writer) error {
+	out, _, err := f.Execute(command)
+	_, _ = io.WriteString(stdout, out)
+
+	return err
+}
+
+// Interactive runs the command like StreamCommand.
+func (f *FakeConnection) Interactive(command string, _ io.Reader, stdout, stderr io.Writer, _ *ssh.PTY) error {
+	return f.StreamCommand(context.Background(), command, stdout, stderr)
+}
+
+// ExecuteAs records the command prefixed with sudo -u.
+func (f *FakeConnection) ExecuteAs(user, command string) (string, string, error) {
+	return f.Execute("sudo -u " + user + " " + command)
+}
+
+// Batch runs the commands in order like Execute, stopping at the first failing one as the real
+// connection does.
+func (f *FakeConnection) Batch(commands []string) ([]ssh.Result, error) {
+	results := make([]ssh.Result, 0, len(commands))
+
+	for _, command := range commands {
+		stdout, stderr, err := f.Execute(command)
+		if err != nil {
+			return results, err
+		}
+
+		results = append(results, ssh.Result{Command: command, Stdout: stdout, Stderr: stderr})
+	}
+
+	return results, nil
+}
+
+// UploadFile does nothing.
+func (*FakeConnection) UploadFile(_, _ string) error {
+	return nil
+}
+
+// UploadData keeps the data in Uploads.
+func (f *FakeConnection) UploadData(data []byte, remotePath string) error {
+	if f.Uploads == nil {
+		f.Uploads = make(map[string][]byte)
+	}
+
+	f.Uploads[remotePath] = data
+
+	return nil
+}
+
+// DownloadFile does nothing.
+func (*FakeConnection) DownloadFile(_, _ string) error {
+	return nil
+}
+
+// ReadRemoteFile returns no content.
+func (*FakeConnection) ReadRemoteFile(_ string) ([]byte, error) {
+	return nil, nil
+}
+
+// FileExists runs a "test -e" command, so tests mark paths missing by failing it.
+func (f *FakeConnection) FileExists(remotePath string) (bool, error) {
+	_, _, err := f.Execute("test -e " + remotePath)
+
+	return err == nil, nil
+}
+
+// RemoteSHA256 runs a "sha256sum" command and returns its stdout as the checksum.
+func (f *FakeConnection) RemoteSHA256(remotePath string) (string, error) {
+	stdout, _, err := f.Execute("sha256sum " + remotePath)
+
+	return strings.TrimSpace(stdout), err
+}
+
+// IsRoot reports an unprivileged user, so privileged commands are prefixed with sudo.
+func (*FakeConnection) IsRoot() bool {
+	return false
+}
+
+// WorkDir returns no configured work directory, so paths use ssh.DefaultWorkDir and /tmp.
+func (*FakeConnection) WorkDir() string {
+	return ""
+}
@@ -16,8 +16,9 @@ import (
 )
 
 const (
-	sshWaitRetries   = 30
-	sshRetryDelaySec = 1
+	sshWaitRetries       = 30
+	dockerSSHWaitRetries = 180
+	sshRetryDelaySec     = 1
 )
 
 // getTestKeyPath returns the absolute path to the test SSH key.
@@ -49,6 +50,55 @@ func (c *SSHContainer) Client() ssh.Connection {
 func StartDebianSSHContainer(t *testing.T) *SSHContainer {
 	t.Helper()
 
+	return StartDebianSSHContainerWithHostKey(t, "", ssh.ClientOptions{})
+}
+
+// StartDebianSSHContainerWithHostKey starts an ephemeral Debian container whose sshd only presents a host
+// key of the given type ("rsa", "ecdsa", or "ed25519"; empty keeps every default key), and connects to it
+// with the given client options.
+func StartDebianSSHContainerWithHostKey(t *testing.T, keyType string, opts ssh.ClientOptions) *SSHContainer {
+	t.Helper()
+
+	hostKeySetup := ""
+	if keyType != "" {
+		hostKeySetup = "rm -f /etc/ssh/ssh_host_*_key* && " +
+			"ssh-keygen -q -N '' -t " + keyType + " -f /etc/ssh/ssh_host_" + keyType + "_key && "
+	}
+
+	return startSSHContainer(t, containerSetup{command: hostKeySetup}, opts)
+}
+
+// StartDockerSSHContainer starts an ephemeral Debian container with SSH enabled and the docker CLI
+// talking to the test machine's Docker daemon through its socket, for tests that run containers over
+// SSH. Containers they start are siblings of the SSH container, not children, so tests must remove
+// them themselves.
+func StartDockerSSHContainer(t *testing.T) *SSHContainer {
+	t.Helper()
+
+	return startSSHContainer(t, containerSetup{
+		runArgs: []string{"-v", "/var/run/docker.sock:/var/run/docker.sock"},
+		command: "apt-get install -y -qq docker.io && ",
+		// docker.io is large, so sshd starts well after the containers of the other tests
+		sshWaitRetries: dockerSSHWaitRetries,
+	}, ssh.ClientOptions{})
+}
+
+// containerSetup adjusts the container startSSHContainer starts.
+type containerSetup struct {
+	runArgs        []string // extra docker run arguments
+	command        string   // shell command ending in "&& ", run before sshd starts
+	sshWaitRetries int      // seconds to wait for sshd, sshWaitRetries when zero
+}
+
+// startSSHContainer starts an ephemeral Debian container with SSH enabled.
+func startSSHContainer(t *testing.T, setup containerSetup, opts ssh.ClientOptions) *SSHContainer {
+	t.Helper()
+
+	retries := setup.sshWaitRetries
+	if retries == 0 {
+		retries = sshWaitRetries
+	}
+
 	// Container configuration
 	containerName := fmt.Sprintf("hadron-test-debian-%d", time.Now().Unix())
 
@@ -65,8 +115,8 @@ func StartDebianSSHContainer(t *testing.T) *SSHContainer {
 
 	// Start Debian container with SSH server
 	// Using debian image, install openssh-server and sudo, inject public key
-	startCmd := exec.Command("docker", "run", "-d", "--rm", //nolint:gosec // Test container with test-generated key
-		"--name", containerName,
+	args := append([]string{"run", "-d", "--rm", "--name", containerName}, setup.runArgs...)
+	args = append(args,
 		"debian:bookworm-slim",
 		"sh", "-c",
 		"apt-get update -qq && "+
@@ -75,9 +125,12 @@ func StartDebianSSHContainer(t *testing.T) *SSHContainer {
 			"chmod 700 /root/.ssh && "+
 			"echo '"+pubKey+"' > /root/.ssh/authorized_keys && "+
 			"chmod 600 /root/.ssh/authorized_keys && "+
+			setup.command+
 			"/usr/sbin/sshd -D",
 	)
 
+	startCmd := exec.Command("docker", args...) //nolint:gosec // Test container with test-generated key
+
 	output, err := startCmd.CombinedOutput()
 	if err != nil {
 		t.Fatalf("failed to start container: %v\noutput: %s", err, output)
@@ -107,7 +160,7 @@ func StartDebianSSHContainer(t *testing.T) *SSHContainer {
 	// Wait for SSH port to be open before scanning keys
 	sshReady := false
 
-	for range sshWaitRetries {
+	for range retries {
 		ncCmd := exec.Command("nc", "-z", containerIP, "22") //nolint:gosec // containerIP is from Docker inspect
 		if ncCmd.Run() == nil {
 			sshReady = true
@@ -179,7 +232,7 @@ func StartDebianSSHContainer(t *testing.T) *SSHContainer {
 
 	var client ssh.Connection
 	for range sshWaitRetries {
-		client, err = pool.GetClient(endpoint)
+		client, err = pool.GetClientWithOptions(endpoint, opts)
 		if err == nil {
 			// Connection successful, verify SSH works
 			_, _, testErr := client.Execute("echo test")
@@ -0,0 +1,144 @@
+// Package wireguard provides WireGuard overlay networking between hosts via wg-quick.
+package wireguard
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/the-agent-c-ai/hadron/internal/packages"
+	"github.com/the-agent-c-ai/hadron/sdk/ssh"
+)
+
+const (
+	// DefaultInterface is the WireGuard interface managed by Hadron.
+	DefaultInterface = "wg0"
+
+	// DefaultListenPort is the conventional WireGuard UDP port.
+	DefaultListenPort = 51820
+
+	configDir = "/etc/wireguard"
+
+	// Keeps NAT mappings alive so peers behind NAT stay reachable.
+	persistentKeepalive = 25
+)
+
+// Peer is another host on the overlay.
+type Peer struct {
+	Name       string   // informational, written as a comment
+	PublicKey  string   // base64 WireGuard public key
+	Endpoint   string   // host:port the peer listens on
+	AllowedIPs []string // overlay addresses routed to this peer (e.g., "10.100.0.2/32")
+}
+
+// Config is the WireGuard configuration of a single host.
+type Config struct {
+	Interface  string // e.g., "wg0"
+	Address    string // overlay address with prefix (e.g., "10.100.0.1/24")
+	ListenPort int
+	Peers      []Peer
+}
+
+// configPath returns the wg-quick configuration path for an interface.
+func configPath(iface string) string {
+	return fmt.Sprintf("%s/%s.conf", configDir, iface)
+}
+
+// keyPath returns the path of the interface's private key on the host.
+func keyPath(iface string) string {
+	return fmt.Sprintf("%s/%s.key", configDir, iface)
+}
+
+// Render returns the wg-quick configuration. The private key is not part of it: wg-quick loads it from
+// the key file generated on the host (see EnsureKey), so it never leaves the host.
+func Render(cfg Config) string {
+	var b strings.Builder
+
+	b.WriteString("# Generated by Hadron\n")
+	b.WriteString("[Interface]\n")
+	fmt.Fprintf(&b, "Address = %s\n", cfg.Address)
+	fmt.Fprintf(&b, "ListenPort = %d\n", cfg.ListenPort)
+	fmt.Fprintf(&b, "PostUp = wg set %%i private-key %s\n", keyPath(cfg.Interface))
+
+	for _, peer := range cfg.Peers {
+		b.WriteString("\n")
+
+		if peer.Name != "" {
+			fmt.Fprintf(&b, "# %s\n", peer.Name)
+		}
+
+		b.WriteString("[Peer]\n")
+		fmt.Fprintf(&b, "PublicKey = %s\n", peer.PublicKey)
+		fmt.Fprintf(&b, "Endpoint = %s\n", peer.Endpoint)
+		fmt.Fprintf(&b, "AllowedIPs = %s\n", strings.Join(peer.AllowedIPs, ", "))
+		fmt.Fprintf(&b, "PersistentKeepalive = %d\n", persistentKeepalive)
+	}
+
+	return b.String()
+}
+
+// Install installs the WireGuard tools on the remote host with the host's package manager.
+func Install(client ssh.Connection, manager packages.Manager) error {
+	if err := manager.EnsureInstalled(client, "wireguard-tools"); err != nil {
+		return fmt.Errorf("failed to install wireguard: %w", err)
+	}
+
+	return nil
+}
+
+// EnsureKey generates the interface's private key on the host if it does not exist yet and returns
+// the matching public key. Existing keys are kept, so peers stay valid across deploys.
+func EnsureKey(client ssh.Connection, iface string) (string, error) {
+	cmd := fmt.Sprintf(
+		"sh -c 'umask 077 && mkdir -p %[1]s && { test -f %[2]s || wg genkey > %[2]s; } && wg pubkey < %[2]s'",
+		configDir, keyPath(iface),
+	)
+
+	stdout, stderr, err := ssh.RunPrivileged(client, cmd)
+	if err != nil {
+		return "", fmt.Errorf("failed to ensure wireguard key for %s: %w (stderr: %s)", iface, err, stderr)
+	}
+
+	return strings.TrimSpace(stdout), nil
+}
+
+// PublicKey returns the public key of the interface's existing private key without changing the host.
+func PublicKey(client ssh.Connection, iface string) (string, error) {
+	stdout, stderr, err := ssh.RunPrivileged(client, fmt.Sprintf("sh -c 'wg pubkey < %s'", keyPath(iface)))
+	if err != nil {
+		return "", fmt.Errorf("failed to read wireguard key for %s: %w (stderr: %s)", iface, err, stderr)
+	}
+
+	return strings.TrimSpace(stdout), nil
+}
+
+// Apply writes the configuration and restarts the interface if it changed. Returns true if it changed.
+func Apply(client ssh.Connection, cfg Config) (bool, error) {
+	config := Render(cfg)
+	path := configPath(cfg.Interface)
+
+	// Skip if the configuration is already in place
+	if current, _, err := ssh.RunPrivileged(client, "cat "+path); err == nil && current == config {
+		return false, nil
+	}
+
+	// Write new config via temp file (avoids shell escaping issues)
+	tempPath := ssh.TempPath(client, "hadron-"+cfg.Interface+".conf")
+	if err := client.UploadData([]byte(config), tempPath); err != nil {
+		return false, fmt.Errorf("failed to write temp wireguard config: %w", err)
+	}
+
+	service := "wg-quick@" + cfg.Interface
+
+	for _, cmd := range []string{
+		fmt.Sprintf("mv %s %s", tempPath, path),
+		"chmod 600 " + path,
+		"systemctl enable " + service,
+		"systemctl restart " + service,
+	} {
+		if _, stderr, err := ssh.RunPrivileged(client, cmd); err != nil {
+			return false, fmt.Errorf("failed to apply wireguard config (%s): %w (stderr: %s)", cmd, err, stderr)
+		}
+	}
+
+	return true, nil
+}
@@ -0,0 +1,40 @@
+package wireguard_test
+
+import (
+	"testing"
+
+	"github.com/the-agent-c-ai/hadron/internal/wireguard"
+)
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	config := wireguard.Render(wireguard.Config{
+		Interface:  "wg0",
+		Address:    "10.100.0.1/24",
+		ListenPort: wireguard.DefaultListenPort,
+		Peers: []wireguard.Peer{{
+			Name:       "deploy@203.0.113.7",
+			PublicKey:  "cGVlcnB1YmxpY2tleQ==",
+			Endpoint:   "203.0.113.7:51820",
+			AllowedIPs: []string{"10.100.0.2/32"},
+		}},
+	})
+
+	want := `# Generated by Hadron
+[Interface]
+Address = 10.100.0.1/24
+ListenPort = 51820
+PostUp = wg set %i private-key /etc/wireguard/wg0.key
+
+# deploy@203.0.113.7
+[Peer]
+PublicKey = cGVlcnB1YmxpY2tleQ==
+Endpoint = 203.0.113.7:51820
+AllowedIPs = 10.100.0.2/32
+PersistentKeepalive = 25
+`
+	if config != want {
+		t.Errorf("unexpected config:\n%s\nwant:\n%s", config, want)
+	}
+}
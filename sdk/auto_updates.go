@@ -0,0 +1,113 @@
+package sdk
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/the-agent-c-ai/hadron/internal/debian"
+)
+
+// rebootTimePattern matches a 24-hour "HH:MM" time.
+var rebootTimePattern = regexp.MustCompile(`^([01]\d|2[0-3]):[0-5]\d$`)
+
+// AutoUpdatesConfig represents unattended-upgrades settings for a host. Automatic security
+// updates are enabled unless the host opts out; these only adjust what happens around them.
+type AutoUpdatesConfig struct {
+	RebootTime string   // reboot automatically at this time when an update requires it (default: never)
+	Email      string   // address mailed a report whenever packages are upgraded
+	Blacklist  []string // packages never upgraded automatically
+}
+
+// AutoUpdatesBuilder builds unattended-upgrades configuration with a fluent API.
+type AutoUpdatesBuilder struct {
+	host   *HostBuilder
+	config *AutoUpdatesConfig
+}
+
+// AutoUpdates starts unattended-upgrades configuration. Settings are written to
+// /etc/apt/apt.conf.d/52hadron-unattended-upgrades, next to the distribution's 50unattended-upgrades,
+// which keeps choosing the origins to upgrade from.
+//
+// Example:
+//
+//	host := plan.Host("user@example.com").
+//	    AutoUpdates().
+//	        RebootTime("02:00").
+//	        Email("ops@example.com").
+//	        Blacklist("docker-ce").
+//	        Done().
+//	    Build()
+func (hb *HostBuilder) AutoUpdates() *AutoUpdatesBuilder {
+	if hb.autoUpdates == nil {
+		hb.autoUpdates = &AutoUpdatesConfig{}
+	}
+
+	return &AutoUpdatesBuilder{
+		host:   hb,
+		config: hb.autoUpdates,
+	}
+}
+
+// DisableAutoUpdates opts the host out of automatic security updates, for environments with their own
+// patching cadence. Hadron then leaves unattended-upgrades alone: an existing installation is neither
+// configured nor removed.
+func (hb *HostBuilder) DisableAutoUpdates() *HostBuilder {
+	hb.noAutoUpdates = true
+
+	return hb
+}
+
+// RebootTime reboots the host automatically at the given time ("HH:MM") when an update requires it.
+func (ab *AutoUpdatesBuilder) RebootTime(at string) *AutoUpdatesBuilder {
+	if !rebootTimePattern.MatchString(at) {
+		ab.host.plan.logger.Fatal().
+			Str("host", ab.host.endpoint).
+			Str("time", at).
+			Msg("invalid reboot time, expected HH:MM")
+	}
+
+	ab.config.RebootTime = at
+
+	return ab
+}
+
+// Email mails a report to the address whenever packages are upgraded (requires a working mailer).
+func (ab *AutoUpdatesBuilder) Email(address string) *AutoUpdatesBuilder {
+	if !strings.Contains(address, "@") || strings.ContainsAny(address, " \t\"") {
+		ab.host.plan.logger.Fatal().Str("host", ab.host.endpoint).Str("email", address).Msg("invalid email address")
+	}
+
+	ab.config.Email = address
+
+	return ab
+}
+
+// Blacklist excludes a package from automatic upgrades. Names are regular expressions
+// matched against the start of the package name (e.g., "postgresql-" matches every version).
+func (ab *AutoUpdatesBuilder) Blacklist(pkg string) *AutoUpdatesBuilder {
+	if pkg == "" || strings.ContainsAny(pkg, " \t\"") {
+		ab.host.plan.logger.Fatal().Str("host", ab.host.endpoint).Str("package", pkg).Msg("invalid blacklist package")
+	}
+
+	ab.config.Blacklist = append(ab.config.Blacklist, pkg)
+
+	return ab
+}
+
+// Done finalizes unattended-upgrades configuration and returns to host builder.
+func (ab *AutoUpdatesBuilder) Done() *HostBuilder {
+	return ab.host
+}
+
+// autoUpdatesConfig converts the host's settings to the debian package's configuration.
+func autoUpdatesConfig(config *AutoUpdatesConfig) debian.AutoUpdatesConfig {
+	if config == nil {
+		return debian.AutoUpdatesConfig{}
+	}
+
+	return debian.AutoUpdatesConfig{
+		RebootTime: config.RebootTime,
+		Email:      config.Email,
+		Blacklist:  config.Blacklist,
+	}
+}
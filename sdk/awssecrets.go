@@ -0,0 +1,131 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// awsSecretsScheme prefixes secret references resolved from AWS Secrets Manager: "awssm://secret-id#key".
+const awsSecretsScheme = "awssm://"
+
+// AWSSecretsProvider resolves "awssm://secret-id" and "awssm://secret-id#key" references from AWS
+// Secrets Manager, e.g. "awssm://production/database#password". The secret ID may be a name or an
+// ARN. Use it with Plan.WithSecretProvider, or rely on the default provider, which sends awssm://
+// references here.
+type AWSSecretsProvider struct{}
+
+// GetSecret parses the reference and resolves it with GetAWSSecret.
+func (AWSSecretsProvider) GetSecret(ctx context.Context, reference string) (string, error) {
+	if reference == "" {
+		return "", ErrSecretReferenceEmpty
+	}
+
+	if !strings.HasPrefix(reference, awsSecretsScheme) {
+		return "", fmt.Errorf("%w: %q", ErrAWSSecretReferenceInvalidPrefix, reference)
+	}
+
+	secretID, jsonKey, hasKey := strings.Cut(strings.TrimPrefix(reference, awsSecretsScheme), "#")
+	if secretID == "" || (hasKey && jsonKey == "") {
+		return "", fmt.Errorf("%w (expected 'awssm://secret-id' or 'awssm://secret-id#key'): %q",
+			ErrAWSSecretReferenceInvalidFormat, reference)
+	}
+
+	if hasKey {
+		return GetAWSSecret(ctx, secretID, jsonKey)
+	}
+
+	return GetAWSSecret(ctx, secretID)
+}
+
+// GetAWSSecret retrieves a secret from AWS Secrets Manager by name or ARN. With a jsonKey, the
+// secret value is parsed as a JSON object and that key is returned, the way the console stores
+// key/value secrets.
+//
+// Example:
+//
+//	password, err := GetAWSSecret(ctx, "production/database", "password")
+//	token, err := GetAWSSecret(ctx, "arn:aws:secretsmanager:eu-west-1:123456789012:secret:deploy-token")
+//
+// Credentials and region come from the AWS SDK default chain (environment, shared config and
+// credentials files, SSO, instance and container roles). The region of an ARN takes precedence.
+func GetAWSSecret(ctx context.Context, secretID string, jsonKey ...string) (string, error) {
+	if secretID == "" {
+		return "", ErrSecretReferenceEmpty
+	}
+
+	if len(jsonKey) > 1 {
+		return "", fmt.Errorf("%w: %q", ErrAWSSecretReferenceInvalidFormat, secretID)
+	}
+
+	reference := secretID
+	if len(jsonKey) == 1 {
+		reference += "#" + jsonKey[0]
+	}
+
+	var options []func(*config.LoadOptions) error
+	if region := arnRegion(secretID); region != "" {
+		options = append(options, config.WithRegion(region))
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, options...)
+	if err != nil {
+		return "", fmt.Errorf("failed to load AWS configuration for secret %q: %w", reference, err)
+	}
+
+	output, err := secretsmanager.NewFromConfig(cfg).GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(secretID),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get secret %q: %w", reference, err)
+	}
+
+	secret := string(output.SecretBinary)
+	if output.SecretString != nil {
+		secret = *output.SecretString
+	}
+
+	if len(jsonKey) == 1 {
+		secret, err = awsSecretKey(secret, reference, jsonKey[0])
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if secret == "" {
+		return "", fmt.Errorf("%w: %q", ErrSecretEmpty, reference)
+	}
+
+	return secret, nil
+}
+
+// awsSecretKey extracts a key from a secret stored as a JSON object.
+func awsSecretKey(secret, reference, key string) (string, error) {
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(secret), &fields); err != nil {
+		return "", fmt.Errorf("%w: %q: %w", ErrAWSSecretNotJSON, reference, err)
+	}
+
+	value, ok := fields[key]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrAWSSecretKeyNotFound, reference)
+	}
+
+	return jsonSecretValue(value)
+}
+
+// arnRegion returns the region of a Secrets Manager ARN, or "" for secret names.
+func arnRegion(secretID string) string {
+	// arn:partition:secretsmanager:region:account:secret:name
+	parts := strings.SplitN(secretID, ":", 5)
+	if len(parts) < 5 || parts[0] != "arn" {
+		return ""
+	}
+
+	return parts[3]
+}
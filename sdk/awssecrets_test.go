@@ -0,0 +1,123 @@
+package sdk_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/the-agent-c-ai/hadron/sdk"
+)
+
+// newSecretsManagerServer serves GetSecretValue for a fixed set of secrets and points the AWS SDK at it.
+func newSecretsManagerServer(t *testing.T) {
+	t.Helper()
+
+	secrets := map[string]string{
+		"production/database": `{"username":"app","password":"s3cret","port":5432}`,
+		"deploy-token":        "plain-token",
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var input struct {
+			SecretID string `json:"SecretId"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+
+		secret, ok := secrets[input.SecretID]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"ResourceNotFoundException","message":"not found"}`))
+
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]string{"Name": input.SecretID, "SecretString": secret})
+	}))
+	t.Cleanup(server.Close)
+
+	empty := filepath.Join(t.TempDir(), "empty")
+
+	t.Setenv("AWS_ENDPOINT_URL_SECRETS_MANAGER", server.URL)
+	t.Setenv("AWS_REGION", "eu-west-1")
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_CONFIG_FILE", empty)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", empty)
+}
+
+func TestGetAWSSecret(t *testing.T) {
+	newSecretsManagerServer(t)
+
+	ctx := context.Background()
+
+	tests := []struct {
+		secretID string
+		jsonKey  []string
+		want     string
+	}{
+		{secretID: "deploy-token", want: "plain-token"},
+		{secretID: "production/database", jsonKey: []string{"password"}, want: "s3cret"},
+		{secretID: "production/database", jsonKey: []string{"port"}, want: "5432"},
+	}
+
+	for _, tt := range tests {
+		got, err := sdk.GetAWSSecret(ctx, tt.secretID, tt.jsonKey...)
+		if err != nil {
+			t.Errorf("%s%v: expected secret, got: %v", tt.secretID, tt.jsonKey, err)
+
+			continue
+		}
+
+		if got != tt.want {
+			t.Errorf("%s%v: expected %q, got %q", tt.secretID, tt.jsonKey, tt.want, got)
+		}
+	}
+
+	_, err := sdk.GetAWSSecret(ctx, "production/database", "token")
+	if !errors.Is(err, sdk.ErrAWSSecretKeyNotFound) {
+		t.Errorf("expected ErrAWSSecretKeyNotFound, got: %v", err)
+	}
+
+	_, err = sdk.GetAWSSecret(ctx, "deploy-token", "password")
+	if !errors.Is(err, sdk.ErrAWSSecretNotJSON) {
+		t.Errorf("expected ErrAWSSecretNotJSON, got: %v", err)
+	}
+
+	if _, err := sdk.GetAWSSecret(ctx, "missing"); err == nil {
+		t.Error("expected an error for a missing secret")
+	}
+}
+
+func TestAWSSecretsProviderReferences(t *testing.T) {
+	newSecretsManagerServer(t)
+
+	var provider sdk.AWSSecretsProvider
+
+	ctx := context.Background()
+
+	got, err := provider.GetSecret(ctx, "awssm://production/database#username")
+	if err != nil || got != "app" {
+		t.Errorf("expected app, got %q (%v)", got, err)
+	}
+
+	_, err = provider.GetSecret(ctx, "vault://secret/data/app#password")
+	if !errors.Is(err, sdk.ErrAWSSecretReferenceInvalidPrefix) {
+		t.Errorf("expected ErrAWSSecretReferenceInvalidPrefix, got: %v", err)
+	}
+
+	_, err = provider.GetSecret(ctx, "awssm://production/database#")
+	if !errors.Is(err, sdk.ErrAWSSecretReferenceInvalidFormat) {
+		t.Errorf("expected ErrAWSSecretReferenceInvalidFormat, got: %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package sdk
+
+import (
+	"fmt"
+	"strings"
+)
+
+// capabilityAll adds or drops every capability.
+const capabilityAll = "ALL"
+
+// linuxCapabilities are the capability names docker accepts (capabilities(7), without the "CAP_" prefix).
+var linuxCapabilities = map[string]bool{
+	"AUDIT_CONTROL":      true,
+	"AUDIT_READ":         true,
+	"AUDIT_WRITE":        true,
+	"BLOCK_SUSPEND":      true,
+	"BPF":                true,
+	"CHECKPOINT_RESTORE": true,
+	"CHOWN":              true,
+	"DAC_OVERRIDE":       true,
+	"DAC_READ_SEARCH":    true,
+	"FOWNER":             true,
+	"FSETID":             true,
+	"IPC_LOCK":           true,
+	"IPC_OWNER":          true,
+	"KILL":               true,
+	"LEASE":              true,
+	"LINUX_IMMUTABLE":    true,
+	"MAC_ADMIN":          true,
+	"MAC_OVERRIDE":       true,
+	"MKNOD":              true,
+	"NET_ADMIN":          true,
+	"NET_BIND_SERVICE":   true,
+	"NET_BROADCAST":      true,
+	"NET_RAW":            true,
+	"PERFMON":            true,
+	"SETFCAP":            true,
+	"SETGID":             true,
+	"SETPCAP":            true,
+	"SETUID":             true,
+	"SYS_ADMIN":          true,
+	"SYS_BOOT":           true,
+	"SYS_CHROOT":         true,
+	"SYS_MODULE":         true,
+	"SYS_NICE":           true,
+	"SYS_PACCT":          true,
+	"SYS_PTRACE":         true,
+	"SYS_RAWIO":          true,
+	"SYS_RESOURCE":       true,
+	"SYS_TIME":           true,
+	"SYS_TTY_CONFIG":     true,
+	"SYSLOG":             true,
+	"WAKE_ALARM":         true,
+}
+
+// normalizeCapability upper-cases a capability name and strips its "CAP_" prefix, as docker does.
+func normalizeCapability(capability string) string {
+	return strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(capability)), "CAP_")
+}
+
+// validateCapabilities returns ErrUnknownCapability for the first name that is neither "ALL" nor a
+// Linux capability, so a typo fails the build instead of the deploy (or silently doing nothing).
+func validateCapabilities(capabilities []string) error {
+	for _, capability := range capabilities {
+		name := normalizeCapability(capability)
+		if name != capabilityAll && !linuxCapabilities[name] {
+			return fmt.Errorf("%w: %q", ErrUnknownCapability, capability)
+		}
+	}
+
+	return nil
+}
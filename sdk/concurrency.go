@@ -0,0 +1,72 @@
+package sdk
+
+import (
+	"errors"
+	"sync"
+)
+
+// defaultResourceConcurrency bounds concurrent network/volume operations per host. Every docker command
+// opens its own SSH session, so this stays well below sshd's default MaxSessions (10).
+const defaultResourceConcurrency = 4
+
+// forEachPerHost runs deploy for every resource, at most limit at a time on each host, and returns once
+// all of them have finished. Resources on a host are started in plan order. Networks and volumes are
+// independent of each other, so only the containers deployed afterwards need to wait for them.
+// All errors are returned, joined.
+func forEachPerHost[T deployableResource](resources []T, limit int, deploy func(T) error) error {
+	var hosts []*Host
+
+	byHost := make(map[*Host][]int)
+
+	for i, resource := range resources {
+		if _, ok := byHost[resource.Host()]; !ok {
+			hosts = append(hosts, resource.Host())
+		}
+
+		byHost[resource.Host()] = append(byHost[resource.Host()], i)
+	}
+
+	errs := make([]error, len(resources))
+
+	var wg sync.WaitGroup
+
+	for _, host := range hosts {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			semaphore := make(chan struct{}, max(limit, 1))
+
+			var hostWG sync.WaitGroup
+
+			for _, i := range byHost[host] {
+				semaphore <- struct{}{}
+
+				hostWG.Add(1)
+
+				go func() {
+					defer hostWG.Done()
+					defer func() { <-semaphore }()
+
+					errs[i] = deploy(resources[i])
+				}()
+			}
+
+			hostWG.Wait()
+		}()
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// confirmDestructive asks for approval like Plan.confirmDestructive, one action at a time: resources on
+// different hosts are deployed concurrently, and their prompts must not interleave.
+func (e *executor) confirmDestructive(action string) error {
+	e.confirmMu.Lock()
+	defer e.confirmMu.Unlock()
+
+	return e.plan.confirmDestructive(action)
+}
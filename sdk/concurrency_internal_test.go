@@ -0,0 +1,167 @@
+package sdk
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+var (
+	errNotConcurrent = errors.New("volumes were not created concurrently")
+	errBroken        = errors.New("broken")
+)
+
+func TestForEachPerHostCreatesVolumesConcurrently(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop())
+	host := plan.Host("user@192.168.1.1").Build()
+
+	volumes := []*Volume{
+		plan.Volume("data").Host(host).Build(),
+		plan.Volume("logs").Host(host).Build(),
+		plan.Volume("cache").Host(host).Build(),
+	}
+
+	// Every deploy blocks until all of them have started, which only succeeds if they run concurrently
+	var started sync.WaitGroup
+
+	started.Add(len(volumes))
+
+	var created atomic.Int32
+
+	err := forEachPerHost(volumes, defaultResourceConcurrency, func(_ *Volume) error {
+		started.Done()
+
+		done := make(chan struct{})
+		go func() {
+			started.Wait()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			return errNotConcurrent
+		}
+
+		created.Add(1)
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected volumes to be created, got: %v", err)
+	}
+
+	// Containers are deployed after forEachPerHost returns, so every volume must exist by then
+	if got := created.Load(); got != int32(len(volumes)) {
+		t.Errorf("expected %d volumes created before returning, got %d", len(volumes), got)
+	}
+}
+
+func TestForEachPerHostRespectsLimit(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop())
+	host := plan.Host("user@192.168.1.1").Build()
+
+	volumes := []*Volume{
+		plan.Volume("first").Host(host).Build(),
+		plan.Volume("second").Host(host).Build(),
+		plan.Volume("third").Host(host).Build(),
+	}
+
+	var (
+		mu      sync.Mutex
+		order   []string
+		running atomic.Int32
+	)
+
+	err := forEachPerHost(volumes, 1, func(volume *Volume) error {
+		if running.Add(1) > 1 {
+			t.Error("expected at most one volume operation at a time")
+		}
+
+		defer running.Add(-1)
+
+		mu.Lock()
+		order = append(order, volume.Name())
+		mu.Unlock()
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected volumes to be created, got: %v", err)
+	}
+
+	if len(order) != 3 || order[0] != "first" || order[1] != "second" || order[2] != "third" {
+		t.Errorf("expected plan order with a limit of 1, got %v", order)
+	}
+}
+
+func TestForEachPerHostJoinsErrors(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop())
+	host := plan.Host("user@192.168.1.1").Build()
+
+	volumes := []*Volume{
+		plan.Volume("ok").Host(host).Build(),
+		plan.Volume("broken").Host(host).Build(),
+	}
+
+	err := forEachPerHost(volumes, defaultResourceConcurrency, func(volume *Volume) error {
+		if volume.Name() == "broken" {
+			return errBroken
+		}
+
+		return nil
+	})
+	if !errors.Is(err, errBroken) {
+		t.Errorf("expected the failing volume's error, got: %v", err)
+	}
+}
+
+func TestConfirmDestructiveOneAtATime(t *testing.T) {
+	t.Parallel()
+
+	var prompting, overlapped atomic.Bool
+
+	// A prompt that takes a moment to answer, like a user reading it
+	plan := NewPlan("test").WithLogger(zerolog.Nop()).RequireConfirmation(func() bool {
+		if !prompting.CompareAndSwap(false, true) {
+			overlapped.Store(true)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		prompting.Store(false)
+
+		return true
+	})
+
+	exec := newExecutor(plan)
+
+	var wg sync.WaitGroup
+
+	for range 4 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if err := exec.confirmDestructive("recreate volume data"); err != nil {
+				t.Errorf("expected approval, got: %v", err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if overlapped.Load() {
+		t.Error("expected confirmation prompts not to overlap")
+	}
+}
@@ -3,15 +3,42 @@ package sdk
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"regexp"
+	"slices"
 	"sort"
 	"strings"
+	"time"
 
+	"github.com/the-agent-c-ai/hadron/internal/docker"
 	"github.com/the-agent-c-ai/hadron/sdk/hash"
 )
 
 const (
-	commaSeparator = ","
+	commaSeparator            = ","
+	digestMarker              = "@sha256:"
+	defaultWaitForFileTimeout = 5 * time.Minute
+	tmpfsSecurityFlags        = "noexec,nosuid,nodev"
+	canarySuffix              = "-canary"
+	restartOnFailure          = "on-failure"
+	minOOMScoreAdj            = -1000
+	maxOOMScoreAdj            = 1000
+)
+
+// numericUser matches a User that maps to the same owner on the host: UID or UID:GID.
+var numericUser = regexp.MustCompile(`^[0-9]+(:[0-9]+)?$`)
+
+// PullPolicy selects how docker run obtains the container image.
+type PullPolicy string
+
+const (
+	// PullAlways pulls the image on every docker run.
+	PullAlways PullPolicy = "always"
+	// PullMissing pulls the image only if it is not present on the host.
+	PullMissing PullPolicy = "missing"
+	// PullNever never pulls; the image must already be on the host.
+	PullNever PullPolicy = "never"
 )
 
 // Container represents a Docker container.
@@ -26,34 +53,54 @@ type Container struct {
 	cpuShares         int64      // CPU shares (relative weight)
 	cpus              string     // hard CPU limit (e.g., "1.5" for 1.5 CPUs)
 	pidsLimit         int64      // maximum number of PIDs (process limit)
+	oomScoreAdj       int        // --oom-score-adj, -1000 (never OOM-killed) to 1000 (killed first)
+	shmSize           string     // /dev/shm size (e.g., "1g"; empty: docker's 64m)
 	hostname          string     // container hostname
-	networks          []*Network // networks to connect to
+	networks          []*Network // networks to connect to (primary first)
+	primaryNetwork    *Network   // network used at docker run (explicitly chosen, or nil)
 	networkAlias      string
 	ports             []string
 	extraHosts        []string // extra host:ip mappings (e.g., "host.docker.internal:host-gateway")
 	volumes           []VolumeMount
 	mounts            []FileMount
 	dataMounts        []DataMount
+	waitForFiles      []FileWait
+	initContainers    []InitContainer
+	postStart         [][]string        // commands docker exec'd once the container is healthy
 	tmpfs             map[string]string // mount point -> options (e.g., "noexec,size=100m")
 	envFile           string
 	envVars           map[string]string
+	envSecrets        map[string]string // env key -> secret reference
+	envTemplates      map[string]string // env key -> value with ${reference} placeholders
+	secretMounts      []SecretMount
+	chownMounts       []string          // mount targets uploaded as owned by user, see ChownMount
+	resolvedSecrets   map[string]string // secret reference -> value, filled at execute time
 	labels            map[string]string // Docker labels for metadata and service discovery
+	logDriver         string            // docker run --log-driver (empty: daemon default)
+	logOpts           map[string]string // docker run --log-opt key -> value
 	healthCheck       *HealthCheck
 	dependsOn         []*Container
+	restartWithDeps   bool // restart when a dependency was redeployed, even if unchanged itself
 	readOnly          bool
+	privileged        bool // docker run --privileged, see Privileged
 	securityOpts      []string
 	capDrop           []string
 	capAdd            []string
 	groupAdd          []string // additional groups for the container user
 	restart           string
+	pullPolicy        PullPolicy // docker run --pull policy (empty: separate docker pull before deploying)
+	canaryOf          string     // service this container is a canary of (empty: not a canary)
+	canaryWeight      int        // share of the service's traffic (percent) the canary should receive
+	schedule          string     // systemd calendar expression for jobs (empty: long-running container)
 	plan              *Plan
 }
 
 // VolumeMount represents a volume mount in a container.
 type VolumeMount struct {
-	source string // volume name or host path
-	target string // container path
-	mode   string // ro, rw (optional)
+	source string  // volume name or host path
+	target string  // container path
+	mode   string  // ro, rw (optional)
+	volume *Volume // plan volume (nil for host paths)
 }
 
 // FileMount represents a local file or directory mounted into a container.
@@ -63,6 +110,22 @@ type FileMount struct {
 	mode          string // ro, rw (optional)
 }
 
+// InitContainer represents a one-shot container run to completion before the main container starts.
+// It shares the main container's networks, volumes, environment, and security settings.
+type InitContainer struct {
+	name    string
+	image   string
+	command []string
+}
+
+// FileWait represents a file that must exist before the container is started.
+type FileWait struct {
+	source   string // volume name or host path
+	isVolume bool   // whether source is a Docker volume
+	file     string // file path relative to source
+	timeout  time.Duration
+}
+
 // DataMount represents raw data mounted as a file into a container.
 type DataMount struct {
 	data          []byte // raw data to mount
@@ -75,6 +138,7 @@ type ContainerBuilder struct {
 	plan              *Plan
 	name              string
 	host              *Host
+	replicaHosts      []*Host // one container per host, see Hosts
 	image             string
 	command           []string   // optional command arguments to append to docker run
 	user              string     // user:group or UID:GID
@@ -83,26 +147,45 @@ type ContainerBuilder struct {
 	cpuShares         int64      // CPU shares (relative weight)
 	cpus              string     // hard CPU limit (e.g., "1.5" for 1.5 CPUs)
 	pidsLimit         int64      // maximum number of PIDs (process limit)
+	oomScoreAdj       int        // --oom-score-adj, -1000 (never OOM-killed) to 1000 (killed first)
+	shmSize           string     // /dev/shm size (e.g., "1g"; empty: docker's 64m)
 	hostname          string     // container hostname
-	networks          []*Network // networks to connect to
+	networks          []*Network // networks to connect to (primary first)
+	primaryNetwork    *Network   // network used at docker run (explicitly chosen, or nil)
 	networkAlias      string
 	ports             []string
 	extraHosts        []string // extra host:ip mappings (e.g., "host.docker.internal:host-gateway")
 	volumes           []VolumeMount
 	mounts            []FileMount
 	dataMounts        []DataMount
+	waitForFiles      []FileWait
+	initContainers    []InitContainer
+	postStart         [][]string
 	tmpfs             map[string]string // mount point -> options (e.g., "noexec,size=100m")
 	envFile           string
 	envVars           map[string]string
+	envSecrets        map[string]string // env key -> secret reference
+	envTemplates      map[string]string // env key -> value with ${reference} placeholders
+	secretMounts      []SecretMount
+	chownMounts       []string          // mount targets uploaded as owned by user, see ChownMount
 	labels            map[string]string // Docker labels for metadata and service discovery
+	logDriver         string            // docker run --log-driver (empty: daemon default)
+	logOpts           map[string]string // docker run --log-opt key -> value
 	healthCheck       *HealthCheck
 	dependsOn         []*Container
+	restartWithDeps   bool // restart when a dependency was redeployed, even if unchanged itself
 	readOnly          bool
+	privileged        bool // docker run --privileged, see Privileged
 	securityOpts      []string
 	capDrop           []string
 	capAdd            []string
 	groupAdd          []string // additional groups for the container user
 	restart           string
+	restartRetries    int // RestartOnFailure's retry limit, folded into restart by BuildE
+	pullPolicy        PullPolicy
+	canary            bool // set by Canary, so an out-of-range weight is not mistaken for no canary
+	canaryWeight      int
+	schedule          string // systemd calendar expression, see Plan.Job
 }
 
 // Host sets the host where this container will run.
@@ -113,6 +196,7 @@ func (cb *ContainerBuilder) Host(host *Host) *ContainerBuilder {
 }
 
 // Image sets the container image (should include digest for immutability).
+// Images without an "@sha256:" digest produce a warning at Build time, or a fatal if the plan RequireDigests.
 func (cb *ContainerBuilder) Image(image string) *ContainerBuilder {
 	cb.image = image
 
@@ -169,6 +253,14 @@ func (cb *ContainerBuilder) PIDsLimit(limit int64) *ContainerBuilder {
 	return cb
 }
 
+// ShmSize sets the size of the container's /dev/shm (e.g., "1g"), which browsers, Chromium-based
+// renderers, and PostgreSQL outgrow at docker's default of 64m.
+func (cb *ContainerBuilder) ShmSize(size string) *ContainerBuilder {
+	cb.shmSize = size
+
+	return cb
+}
+
 // Hostname sets the hostname for the container.
 func (cb *ContainerBuilder) Hostname(hostname string) *ContainerBuilder {
 	cb.hostname = hostname
@@ -184,6 +276,14 @@ func (cb *ContainerBuilder) Network(network *Network) *ContainerBuilder {
 	return cb
 }
 
+// PrimaryNetwork sets the network the container joins at docker run (--network); the remaining
+// networks are connected afterwards. Defaults to the first network added with Network.
+func (cb *ContainerBuilder) PrimaryNetwork(network *Network) *ContainerBuilder {
+	cb.primaryNetwork = network
+
+	return cb
+}
+
 // NetworkAlias sets a DNS alias for this container on the network.
 func (cb *ContainerBuilder) NetworkAlias(alias string) *ContainerBuilder {
 	cb.networkAlias = alias
@@ -216,6 +316,7 @@ func (cb *ContainerBuilder) Volume(source any, target string, mode ...string) *C
 	switch v := source.(type) {
 	case *Volume:
 		mount.source = v.Name()
+		mount.volume = v
 	case string:
 		mount.source = v
 	default:
@@ -232,7 +333,8 @@ func (cb *ContainerBuilder) Volume(source any, target string, mode ...string) *C
 }
 
 // Mount mounts a local file or directory into the container.
-// The local path is uploaded to the remote host and mounted into the container.
+// The local path is uploaded to the remote host and mounted into the container. Paths listed in a
+// directory's hash.IgnoreFile (.hadronignore) are neither uploaded nor part of the config hash.
 func (cb *ContainerBuilder) Mount(localPath, containerPath string, mode ...string) *ContainerBuilder {
 	mount := FileMount{
 		localPath:     localPath,
@@ -266,6 +368,55 @@ func (cb *ContainerBuilder) MountData(data []byte, containerPath string, mode ..
 	return cb
 }
 
+// InitContainer adds a one-shot container that must run to completion (exit 0) before this container starts.
+// It runs with docker run --rm on the same networks and volumes, with the same environment and security
+// settings (ports and network alias excluded). Useful for migrations and volume seeding.
+// Can be called multiple times; init containers run in the order they were added.
+func (cb *ContainerBuilder) InitContainer(name, image string, cmd ...string) *ContainerBuilder {
+	cb.initContainers = append(cb.initContainers, InitContainer{
+		name:    name,
+		image:   image,
+		command: cmd,
+	})
+
+	return cb
+}
+
+// PostStart runs a command inside the container with docker exec once it is healthy (or running, without
+// a health check), e.g. to run migrations or seed data. The deploy fails if the command exits non-zero.
+// Commands only run when the container was (re)created, never on unchanged deploys. Can be called
+// multiple times; commands run in the order they were added.
+func (cb *ContainerBuilder) PostStart(cmd ...string) *ContainerBuilder {
+	cb.postStart = append(cb.postStart, cmd)
+
+	return cb
+}
+
+// WaitForVolumeFile holds back the container until a file exists in a volume; file is relative to the
+// volume's root. Useful for restore-then-start flows where a volume must be seeded first. The optional
+// timeout defaults to 5 minutes.
+func (cb *ContainerBuilder) WaitForVolumeFile(volume *Volume, file string, timeout ...time.Duration) *ContainerBuilder {
+	return cb.waitForFile(FileWait{source: volume.Name(), isVolume: true, file: file}, timeout)
+}
+
+// WaitForHostFile holds back the container until a file exists below a path on the host; file is
+// relative to hostPath. The optional timeout defaults to 5 minutes.
+func (cb *ContainerBuilder) WaitForHostFile(hostPath, file string, timeout ...time.Duration) *ContainerBuilder {
+	return cb.waitForFile(FileWait{source: hostPath, file: file}, timeout)
+}
+
+// waitForFile adds a file wait with the optional timeout.
+func (cb *ContainerBuilder) waitForFile(wait FileWait, timeout []time.Duration) *ContainerBuilder {
+	wait.timeout = defaultWaitForFileTimeout
+	if len(timeout) > 0 {
+		wait.timeout = timeout[0]
+	}
+
+	cb.waitForFiles = append(cb.waitForFiles, wait)
+
+	return cb
+}
+
 // Tmpfs mounts a tmp filesysten ("/tmp", "size=100m") -> results in "noexec,nosuid,nodev,size=100m".
 func (cb *ContainerBuilder) Tmpfs(mountPoint string, options ...string) *ContainerBuilder {
 	if cb.tmpfs == nil {
@@ -273,12 +424,12 @@ func (cb *ContainerBuilder) Tmpfs(mountPoint string, options ...string) *Contain
 	}
 
 	// ALWAYS enforce security flags
-	securityFlags := "noexec,nosuid,nodev"
+	securityFlags := tmpfsSecurityFlags
 
 	// Append any additional options
 	opts := securityFlags
 	if len(options) > 0 && options[0] != "" {
-		opts = securityFlags + "," + options[0]
+		opts = securityFlags + commaSeparator + options[0]
 	}
 
 	cb.tmpfs[mountPoint] = opts
@@ -286,6 +437,32 @@ func (cb *ContainerBuilder) Tmpfs(mountPoint string, options ...string) *Contain
 	return cb
 }
 
+// TmpfsUnsafe mounts a tmp filesystem with exactly the given options, without forcing
+// noexec,nosuid,nodev. Use it only when a mount legitimately needs one of those flags off,
+// e.g. ("/scratch", "nosuid,nodev,exec,size=1g") for an executable scratch directory.
+// Every flag left out is logged as a warning at build time.
+func (cb *ContainerBuilder) TmpfsUnsafe(mountPoint, options string) *ContainerBuilder {
+	if cb.tmpfs == nil {
+		cb.tmpfs = make(map[string]string)
+	}
+
+	given := strings.Split(options, commaSeparator)
+
+	for _, flag := range strings.Split(tmpfsSecurityFlags, commaSeparator) {
+		if !slices.Contains(given, flag) {
+			cb.plan.logger.Warn().
+				Str("container", cb.name).
+				Str("mount", mountPoint).
+				Str("flag", flag).
+				Msg("Tmpfs mount is missing a security flag, container hardening is weakened")
+		}
+	}
+
+	cb.tmpfs[mountPoint] = options
+
+	return cb
+}
+
 // EnvFile sets the path to an environment file to load.
 func (cb *ContainerBuilder) EnvFile(path string) *ContainerBuilder {
 	cb.envFile = path
@@ -293,13 +470,65 @@ func (cb *ContainerBuilder) EnvFile(path string) *ContainerBuilder {
 	return cb
 }
 
-// Env sets an environment variable.
+// Env sets an environment variable. Values are passed verbatim (spaces, "=", "#", quotes, and
+// unicode included) but must not contain line breaks; mount multi-line values with MountData.
 func (cb *ContainerBuilder) Env(key, value string) *ContainerBuilder {
 	cb.envVars[key] = value
 
 	return cb
 }
 
+// EnvSecret sets an environment variable from a secret reference (e.g., "op://vault/item/field").
+// The secret is resolved through the plan's SecretProvider on every deploy, so rotating it
+// in the provider redeploys the container.
+func (cb *ContainerBuilder) EnvSecret(key, reference string) *ContainerBuilder {
+	if cb.envSecrets == nil {
+		cb.envSecrets = make(map[string]string)
+	}
+
+	cb.envSecrets[key] = reference
+
+	return cb
+}
+
+// EnvFromSecret sets an environment variable whose value embeds secret references as
+// "${reference}" placeholders, interpolated at deploy time. Use it when a secret is only part of
+// the value:
+//
+//	EnvFromSecret("DATABASE_URL", "postgres://app:${op://Production/database/password}@db:5432/app")
+//
+// Like EnvSecret, the resolved value only ever reaches the generated env file, and rotating a
+// referenced secret redeploys the container.
+func (cb *ContainerBuilder) EnvFromSecret(key, template string) *ContainerBuilder {
+	if cb.envTemplates == nil {
+		cb.envTemplates = make(map[string]string)
+	}
+
+	cb.envTemplates[key] = template
+
+	return cb
+}
+
+// MountSecret mounts a secret (resolved on every deploy) as a read-only file at containerPath.
+func (cb *ContainerBuilder) MountSecret(reference, containerPath string) *ContainerBuilder {
+	cb.secretMounts = append(cb.secretMounts, SecretMount{
+		reference:     reference,
+		containerPath: containerPath,
+	})
+
+	return cb
+}
+
+// ChownMount makes the Mount, MountData, or MountSecret at containerPath belong to the container's
+// User on the host, readable by nobody else (files 0600, directories 0700). Without it, uploaded
+// mounts are world-readable so any container user can read them. Requires a numeric User (UID or
+// UID:GID), since user names inside the image mean nothing on the host.
+func (cb *ContainerBuilder) ChownMount(containerPath string) *ContainerBuilder {
+	cb.chownMounts = append(cb.chownMounts, containerPath)
+
+	return cb
+}
+
 // Label sets a Docker label for metadata and service discovery.
 func (cb *ContainerBuilder) Label(key, value string) *ContainerBuilder {
 	cb.labels[key] = value
@@ -307,6 +536,25 @@ func (cb *ContainerBuilder) Label(key, value string) *ContainerBuilder {
 	return cb
 }
 
+// LogDriver overrides the daemon's logging driver for this container (e.g., "local", "journald").
+func (cb *ContainerBuilder) LogDriver(driver string) *ContainerBuilder {
+	cb.logDriver = driver
+
+	return cb
+}
+
+// LogOpt sets a logging driver option for this container (e.g., "max-size", "10m"), such as tighter
+// rotation for a noisy container without changing the daemon default for everyone.
+func (cb *ContainerBuilder) LogOpt(key, value string) *ContainerBuilder {
+	if cb.logOpts == nil {
+		cb.logOpts = make(map[string]string)
+	}
+
+	cb.logOpts[key] = value
+
+	return cb
+}
+
 // HealthCheck sets the health check for this container.
 func (cb *ContainerBuilder) HealthCheck(check *HealthCheck) *ContainerBuilder {
 	cb.healthCheck = check
@@ -322,6 +570,15 @@ func (cb *ContainerBuilder) DependsOn(container *Container) *ContainerBuilder {
 	return cb
 }
 
+// RestartOnDependencyChange restarts this container whenever one of its DependsOn targets was
+// redeployed during the same deploy, even if this container's own configuration is unchanged.
+// Use it for clients that cache a dependency's IP or hold long-lived connections to it.
+func (cb *ContainerBuilder) RestartOnDependencyChange() *ContainerBuilder {
+	cb.restartWithDeps = true
+
+	return cb
+}
+
 // ReadOnly sets the container filesystem to read-only.
 func (cb *ContainerBuilder) ReadOnly() *ContainerBuilder {
 	cb.readOnly = true
@@ -329,6 +586,16 @@ func (cb *ContainerBuilder) ReadOnly() *ContainerBuilder {
 	return cb
 }
 
+// Privileged runs the container with docker run --privileged: every capability, every host device, and
+// no seccomp or AppArmor confinement. It is an escape hatch for the few workloads that need it (e.g.
+// storage or network agents) and defeats every other hardening option of the container, so it is
+// logged as a warning at build and deploy time.
+func (cb *ContainerBuilder) Privileged() *ContainerBuilder {
+	cb.privileged = true
+
+	return cb
+}
+
 // SecurityOpt adds a security option.
 func (cb *ContainerBuilder) SecurityOpt(opt string) *ContainerBuilder {
 	cb.securityOpts = append(cb.securityOpts, opt)
@@ -336,14 +603,14 @@ func (cb *ContainerBuilder) SecurityOpt(opt string) *ContainerBuilder {
 	return cb
 }
 
-// CapDrop drops a Linux capability.
+// CapDrop drops a Linux capability (e.g., "ALL", "NET_RAW"; the "CAP_" prefix and case are optional).
 func (cb *ContainerBuilder) CapDrop(capability string) *ContainerBuilder {
 	cb.capDrop = append(cb.capDrop, capability)
 
 	return cb
 }
 
-// CapAdd adds a Linux capability.
+// CapAdd adds a Linux capability (e.g., "NET_BIND_SERVICE"; the "CAP_" prefix and case are optional).
 func (cb *ContainerBuilder) CapAdd(capability string) *ContainerBuilder {
 	cb.capAdd = append(cb.capAdd, capability)
 
@@ -365,39 +632,123 @@ func (cb *ContainerBuilder) Restart(policy string) *ContainerBuilder {
 	return cb
 }
 
+// RestartOnFailure restarts the container only when it exits with a non-zero status, giving up after
+// maxRetries attempts (0 retries forever). Docker resets the count once the container has run for a while.
+func (cb *ContainerBuilder) RestartOnFailure(maxRetries int) *ContainerBuilder {
+	cb.restart = restartOnFailure
+	cb.restartRetries = maxRetries
+
+	return cb
+}
+
+// OOMScoreAdj tunes how likely the kernel is to kill the container under memory pressure, from -1000
+// (never) to 1000 (first). Lower it for stateful services, raise it for workers that are cheap to restart.
+func (cb *ContainerBuilder) OOMScoreAdj(score int) *ContainerBuilder {
+	cb.oomScoreAdj = score
+
+	return cb
+}
+
+// PullPolicy passes a pull policy to docker run (--pull). PullMissing and PullNever also skip the
+// separate docker pull on every deploy, saving a round trip per container. That pull is what detects
+// updated images behind a mutable tag, so with them updates are only picked up through a changed image
+// reference: pin images by digest, or the container keeps running the old image until its configuration
+// changes. PullAlways keeps the separate pull, so a moved tag still recreates the container.
+func (cb *ContainerBuilder) PullPolicy(policy PullPolicy) *ContainerBuilder {
+	if !slices.Contains([]PullPolicy{PullAlways, PullMissing, PullNever}, policy) {
+		cb.plan.logger.Fatal().Str("container", cb.name).Str("pull", string(policy)).Msg("invalid pull policy")
+	}
+
+	cb.pullPolicy = policy
+
+	return cb
+}
+
+// Canary deploys this container as a canary of the service with the same name: it runs as
+// "<name>-canary" next to the stable "<name>" container, which is left running, and is labeled with
+// the service name (hadron.canary) and the share of traffic in percent (hadron.canary.weight) a
+// proxy should send it. Declare the stable container separately with its current configuration:
+//
+//	plan.Container("app").Image("ghcr.io/org/app@sha256:v1...") /* ... */ .Build()
+//	plan.Container("app").Image("ghcr.io/org/app@sha256:v2...") /* ... */ .Canary(10).Build()
+//
+// Promote the canary by updating the stable container and removing the canary from the plan.
+func (cb *ContainerBuilder) Canary(weight int) *ContainerBuilder {
+	cb.canary = true
+	cb.canaryWeight = weight
+
+	return cb
+}
+
 // Build creates the Container and registers it with the plan.
+// An invalid configuration is fatal; use BuildE to handle it as an error instead.
 func (cb *ContainerBuilder) Build() *Container {
-	if cb.host == nil {
-		cb.plan.logger.Fatal().Str("container", cb.name).Msg("container must be assigned to a host")
+	container, err := cb.BuildE()
+	if err != nil {
+		cb.plan.logger.Fatal().Err(err).Str("container", cb.name).Msg("invalid container")
 	}
 
-	if cb.image == "" {
-		cb.plan.logger.Fatal().Str("container", cb.name).Msg("container image is required")
+	return container
+}
+
+// BuildE creates the Container and registers it with the plan, returning an ErrInvalidContainer
+// (or ErrUnknownCapability) error instead of exiting when the configuration is invalid.
+func (cb *ContainerBuilder) BuildE() (*Container, error) {
+	if err := cb.validate(); err != nil {
+		return nil, err
 	}
 
-	if cb.restart == "" {
-		cb.restart = "unless-stopped"
+	// Hadron detects image updates through digest changes, so mutable tags make idempotency unreliable
+	if !strings.Contains(cb.image, digestMarker) {
+		cb.plan.logger.Warn().
+			Str("container", cb.name).
+			Str("image", cb.image).
+			Msg("Container image is not pinned by digest, deployments may not be reproducible")
 	}
 
-	// Enforce mandatory resource limits (CIS Docker Benchmark compliance)
-	if cb.memory == "" {
-		cb.plan.logger.Fatal().Str("container", cb.name).Msg("memory limit is required (CIS 5.10)")
+	if cb.privileged {
+		cb.plan.logger.Warn().
+			Str("container", cb.name).
+			Msg("Container is privileged, it has full access to the host")
 	}
 
-	if cb.cpuShares == 0 {
-		cb.plan.logger.Fatal().Str("container", cb.name).Msg("cpu-shares is required (CIS 5.11)")
+	if (cb.pullPolicy == PullMissing || cb.pullPolicy == PullNever) && !strings.Contains(cb.image, digestMarker) {
+		cb.plan.logger.Warn().
+			Str("container", cb.name).
+			Str("image", cb.image).
+			Msg("Pull policy without a digest-pinned image, image updates will not trigger a redeploy")
 	}
 
-	if cb.cpus == "" {
-		cb.plan.logger.Fatal().Str("container", cb.name).Msg("cpus limit is required")
+	if cb.restart == restartOnFailure && cb.restartRetries > 0 {
+		cb.restart = fmt.Sprintf("%s:%d", restartOnFailure, cb.restartRetries)
 	}
 
-	if cb.pidsLimit == 0 {
-		cb.plan.logger.Fatal().Str("container", cb.name).Msg("pids-limit is required")
+	if cb.restart == "" {
+		cb.restart = "unless-stopped"
+		if cb.schedule != "" {
+			cb.restart = "no"
+		}
+	}
+
+	cb.extraHosts = append(cb.extraHosts, cb.crossHostEntries()...)
+
+	name, canaryOf := cb.name, ""
+	if cb.canary {
+		name, canaryOf = cb.name+canarySuffix, cb.name
+	}
+
+	if cb.primaryNetwork != nil {
+		index := slices.Index(cb.networks, cb.primaryNetwork)
+
+		// Move the primary network to the front, keeping the order of the others
+		networks := make([]*Network, 0, len(cb.networks))
+		networks = append(networks, cb.primaryNetwork)
+		networks = append(networks, slices.Delete(slices.Clone(cb.networks), index, index+1)...)
+		cb.networks = networks
 	}
 
 	container := &Container{
-		name:              cb.name,
+		name:              name,
 		host:              cb.host,
 		image:             cb.image,
 		command:           cb.command,
@@ -407,32 +758,238 @@ func (cb *ContainerBuilder) Build() *Container {
 		cpuShares:         cb.cpuShares,
 		cpus:              cb.cpus,
 		pidsLimit:         cb.pidsLimit,
+		oomScoreAdj:       cb.oomScoreAdj,
+		shmSize:           cb.shmSize,
 		hostname:          cb.hostname,
 		networks:          cb.networks,
+		primaryNetwork:    cb.primaryNetwork,
 		networkAlias:      cb.networkAlias,
 		ports:             cb.ports,
 		extraHosts:        cb.extraHosts,
 		volumes:           cb.volumes,
 		mounts:            cb.mounts,
 		dataMounts:        cb.dataMounts,
+		waitForFiles:      cb.waitForFiles,
+		initContainers:    cb.initContainers,
+		postStart:         cb.postStart,
 		tmpfs:             cb.tmpfs,
 		envFile:           cb.envFile,
 		envVars:           cb.envVars,
+		envSecrets:        cb.envSecrets,
+		envTemplates:      cb.envTemplates,
+		secretMounts:      cb.secretMounts,
+		chownMounts:       cb.chownMounts,
 		labels:            cb.labels,
+		logDriver:         cb.logDriver,
+		logOpts:           cb.logOpts,
 		healthCheck:       cb.healthCheck,
 		dependsOn:         cb.dependsOn,
+		restartWithDeps:   cb.restartWithDeps,
 		readOnly:          cb.readOnly,
+		privileged:        cb.privileged,
 		securityOpts:      cb.securityOpts,
 		capDrop:           cb.capDrop,
 		capAdd:            cb.capAdd,
 		groupAdd:          cb.groupAdd,
 		restart:           cb.restart,
+		pullPolicy:        cb.pullPolicy,
+		canaryOf:          canaryOf,
+		canaryWeight:      cb.canaryWeight,
+		schedule:          cb.schedule,
 		plan:              cb.plan,
 	}
 
+	if container.schedule != "" {
+		cb.plan.jobs = append(cb.plan.jobs, container)
+
+		return container, nil
+	}
+
 	cb.plan.containers = append(cb.plan.containers, container)
 
-	return container
+	return container, nil
+}
+
+// validate checks the container configuration before it is built.
+func (cb *ContainerBuilder) validate() error {
+	if len(cb.replicaHosts) > 0 {
+		return fmt.Errorf("%w: %s: containers with Hosts are built with BuildReplicas", ErrInvalidContainer, cb.name)
+	}
+
+	if cb.host == nil {
+		return fmt.Errorf("%w: %s: container must be assigned to a host", ErrInvalidContainer, cb.name)
+	}
+
+	if cb.image == "" {
+		return fmt.Errorf("%w: %s: container image is required", ErrInvalidContainer, cb.name)
+	}
+
+	if cb.plan.requireDigests && !strings.Contains(cb.image, digestMarker) {
+		return fmt.Errorf("%w: %s: image %s must be pinned by digest (plan requires digests)",
+			ErrInvalidContainer, cb.name, cb.image)
+	}
+
+	// Enforce mandatory resource limits (CIS Docker Benchmark compliance)
+	if cb.memory == "" {
+		return fmt.Errorf("%w: %s: memory limit is required (CIS 5.10)", ErrInvalidContainer, cb.name)
+	}
+
+	if cb.cpuShares == 0 {
+		return fmt.Errorf("%w: %s: cpu-shares is required (CIS 5.11)", ErrInvalidContainer, cb.name)
+	}
+
+	if cb.cpus == "" {
+		return fmt.Errorf("%w: %s: cpus limit is required", ErrInvalidContainer, cb.name)
+	}
+
+	if cb.pidsLimit == 0 {
+		return fmt.Errorf("%w: %s: pids-limit is required", ErrInvalidContainer, cb.name)
+	}
+
+	if cb.canary && (cb.canaryWeight < 1 || cb.canaryWeight > 100) {
+		return fmt.Errorf("%w: %s: canary weight must be 1-100, got %d", ErrInvalidContainer, cb.name, cb.canaryWeight)
+	}
+
+	if cb.restart == restartOnFailure && cb.restartRetries < 0 {
+		return fmt.Errorf("%w: %s: restart retries must not be negative, got %d",
+			ErrInvalidContainer, cb.name, cb.restartRetries)
+	}
+
+	if cb.oomScoreAdj < minOOMScoreAdj || cb.oomScoreAdj > maxOOMScoreAdj {
+		return fmt.Errorf("%w: %s: oom-score-adj must be between %d and %d, got %d",
+			ErrInvalidContainer, cb.name, minOOMScoreAdj, maxOOMScoreAdj, cb.oomScoreAdj)
+	}
+
+	if cb.primaryNetwork != nil && !slices.Contains(cb.networks, cb.primaryNetwork) {
+		return fmt.Errorf("%w: %s: primary network %s must be one of the container's networks",
+			ErrInvalidContainer, cb.name, cb.primaryNetwork.Name())
+	}
+
+	for key, value := range cb.envVars {
+		if err := docker.ValidateEnv(key, value); err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrInvalidContainer, cb.name, err)
+		}
+	}
+
+	// Secret values are only known at deploy time; their names can be checked now
+	for key := range cb.envSecrets {
+		if err := docker.ValidateEnv(key, ""); err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrInvalidContainer, cb.name, err)
+		}
+	}
+
+	for key, template := range cb.envTemplates {
+		if err := docker.ValidateEnv(key, ""); err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrInvalidContainer, cb.name, err)
+		}
+
+		if len(secretPlaceholders(template)) == 0 {
+			return fmt.Errorf("%w: %s: EnvFromSecret %s references no secret (use Env)",
+				ErrInvalidContainer, cb.name, key)
+		}
+	}
+
+	if cb.schedule != "" {
+		if err := cb.validateJob(); err != nil {
+			return err
+		}
+	}
+
+	if err := cb.validateChownMounts(); err != nil {
+		return err
+	}
+
+	if err := validateCapabilities(cb.capAdd); err != nil {
+		return fmt.Errorf("%w (container %s, cap-add)", err, cb.name)
+	}
+
+	if err := validateCapabilities(cb.capDrop); err != nil {
+		return fmt.Errorf("%w (container %s, cap-drop)", err, cb.name)
+	}
+
+	return nil
+}
+
+// validateChownMounts checks that ChownMount paths are mount targets and that the container runs as a
+// numeric user to chown them to.
+func (cb *ContainerBuilder) validateChownMounts() error {
+	if len(cb.chownMounts) == 0 {
+		return nil
+	}
+
+	if !numericUser.MatchString(cb.user) {
+		return fmt.Errorf("%w: %s: ChownMount requires a numeric User (UID or UID:GID), got %q",
+			ErrInvalidContainer, cb.name, cb.user)
+	}
+
+	targets := make([]string, 0, len(cb.mounts)+len(cb.dataMounts)+len(cb.secretMounts))
+	for _, mount := range cb.mounts {
+		targets = append(targets, mount.containerPath)
+	}
+
+	for _, mount := range cb.dataMounts {
+		targets = append(targets, mount.containerPath)
+	}
+
+	for _, mount := range cb.secretMounts {
+		targets = append(targets, mount.containerPath)
+	}
+
+	for _, containerPath := range cb.chownMounts {
+		if !slices.Contains(targets, containerPath) {
+			return fmt.Errorf("%w: %s: ChownMount %s is not a Mount, MountData, or MountSecret target",
+				ErrInvalidContainer, cb.name, containerPath)
+		}
+	}
+
+	return nil
+}
+
+// mountOwner returns the owner to upload the mount at containerPath as, or "" for a world-readable upload.
+func (c *Container) mountOwner(containerPath string) string {
+	if slices.Contains(c.chownMounts, containerPath) {
+		return c.user
+	}
+
+	return ""
+}
+
+// crossHostEntries returns --add-host entries for dependencies running on other hosts.
+// Network aliases only resolve within a single Docker host, so the dependency's alias (or name)
+// is mapped to the address of the host it runs on.
+func (cb *ContainerBuilder) crossHostEntries() []string {
+	var entries []string
+
+	for _, dep := range cb.dependsOn {
+		if dep.host == cb.host {
+			continue
+		}
+
+		alias := dep.networkAlias
+		if alias == "" {
+			alias = dep.name
+		}
+
+		// Prefer the overlay when both hosts are on it, so traffic stays on private addresses
+		address := dep.host.Address()
+		if cb.host.OverlayAddress() != "" && dep.host.OverlayAddress() != "" {
+			address = dep.host.OverlayAddress()
+		}
+
+		if address == "" {
+			cb.plan.logger.Warn().
+				Str("container", cb.name).
+				Str("dependency", dep.name).
+				Str("host", dep.host.String()).
+				Msg("Cross-host dependency has no known address (set HostBuilder.Address), skipping host entry")
+
+			continue
+		}
+
+		entries = append(entries, alias+":"+address)
+	}
+
+	return entries
 }
 
 // Name returns the container name.
@@ -450,11 +1007,53 @@ func (c *Container) Image() string {
 	return c.image
 }
 
+// validateHosts checks that every network and volume the container uses is created on its host,
+// since Docker networks and volumes only exist on the host they were created on.
+func (c *Container) validateHosts() error {
+	var errs []error
+
+	for _, network := range c.networks {
+		if network.Host().Endpoint() != c.host.Endpoint() {
+			errs = append(errs, fmt.Errorf("%w: container %s on %s uses network %s on %s",
+				ErrCrossHostReference, c.name, c.host, network.Name(), network.Host()))
+		}
+	}
+
+	for _, mount := range c.volumes {
+		if mount.volume != nil && mount.volume.Host().Endpoint() != c.host.Endpoint() {
+			errs = append(errs, fmt.Errorf("%w: container %s on %s uses volume %s on %s",
+				ErrCrossHostReference, c.name, c.host, mount.volume.Name(), mount.volume.Host()))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Networks returns the container's networks, primary network first.
+func (c *Container) Networks() []*Network {
+	return c.networks
+}
+
+// CanaryOf returns the service this container is a canary of, or empty string if it is not a canary.
+func (c *Container) CanaryOf() string {
+	return c.canaryOf
+}
+
+// CanaryWeight returns the share of the service's traffic (percent) the canary should receive, or 0.
+func (c *Container) CanaryWeight() int {
+	return c.canaryWeight
+}
+
 // NetworkAlias returns the DNS alias for this container.
 func (c *Container) NetworkAlias() string {
 	return c.networkAlias
 }
 
+// ExtraHosts returns the host-to-IP mappings, including entries injected for cross-host dependencies.
+func (c *Container) ExtraHosts() []string {
+	return c.extraHosts
+}
+
 // HealthCheck returns the health check configuration.
 func (c *Container) HealthCheck() *HealthCheck {
 	return c.healthCheck
@@ -500,6 +1099,14 @@ func (c *Container) ConfigHash() string {
 		configParts = append(configParts, fmt.Sprintf("pids-limit:%d", c.pidsLimit))
 	}
 
+	if c.shmSize != "" {
+		configParts = append(configParts, "shm-size:"+c.shmSize)
+	}
+
+	if c.oomScoreAdj != 0 {
+		configParts = append(configParts, fmt.Sprintf("oom-score-adj:%d", c.oomScoreAdj))
+	}
+
 	if c.hostname != "" {
 		configParts = append(configParts, c.hostname)
 	}
@@ -515,6 +1122,11 @@ func (c *Container) ConfigHash() string {
 		configParts = append(configParts, strings.Join(networkNames, commaSeparator))
 	}
 
+	// An explicit primary network changes which network is joined at docker run
+	if c.primaryNetwork != nil {
+		configParts = append(configParts, "primary:"+c.primaryNetwork.Name())
+	}
+
 	if c.networkAlias != "" {
 		configParts = append(configParts, c.networkAlias)
 	}
@@ -557,9 +1169,55 @@ func (c *Container) ConfigHash() string {
 		)
 	}
 
-	// Tmpfs mounts
-	for mountPoint, options := range c.tmpfs {
-		configParts = append(configParts, fmt.Sprintf("tmpfs:%s:%s", mountPoint, options))
+	// Ownership decides where mounts are stored on the host and who can read them
+	if len(c.chownMounts) > 0 {
+		owned := slices.Clone(c.chownMounts)
+		sort.Strings(owned)
+		configParts = append(configParts, "chown:"+strings.Join(owned, commaSeparator))
+	}
+
+	// Init containers (order matters)
+	for _, initContainer := range c.initContainers {
+		configParts = append(
+			configParts,
+			fmt.Sprintf(
+				"init:%s:%s:%s",
+				initContainer.name, initContainer.image, strings.Join(initContainer.command, " "),
+			),
+		)
+	}
+
+	// Post-start commands (order matters)
+	for _, cmd := range c.postStart {
+		configParts = append(configParts, "poststart:"+strings.Join(cmd, " "))
+	}
+
+	// Tmpfs mounts with their effective options (sorted for deterministic hash)
+	tmpfsMounts := make([]string, 0, len(c.tmpfs))
+	for mountPoint := range c.tmpfs {
+		tmpfsMounts = append(tmpfsMounts, mountPoint)
+	}
+
+	sort.Strings(tmpfsMounts)
+
+	for _, mountPoint := range tmpfsMounts {
+		configParts = append(configParts, fmt.Sprintf("tmpfs:%s:%s", mountPoint, c.tmpfs[mountPoint]))
+	}
+
+	// Logging configuration (options sorted for deterministic hash)
+	if c.logDriver != "" {
+		configParts = append(configParts, "log-driver:"+c.logDriver)
+	}
+
+	logOptKeys := make([]string, 0, len(c.logOpts))
+	for k := range c.logOpts {
+		logOptKeys = append(logOptKeys, k)
+	}
+
+	sort.Strings(logOptKeys)
+
+	for _, k := range logOptKeys {
+		configParts = append(configParts, fmt.Sprintf("log-opt:%s=%s", k, c.logOpts[k]))
 	}
 
 	// Hash the env file content, not just the path
@@ -590,6 +1248,9 @@ func (c *Container) ConfigHash() string {
 		configParts = append(configParts, fmt.Sprintf("%s=%s", k, c.envVars[k]))
 	}
 
+	// Secrets are hashed by resolved value, so rotation triggers a redeploy
+	configParts = append(configParts, c.secretHashParts()...)
+
 	// Sort label keys for deterministic hash
 	labelKeys := make([]string, 0, len(c.labels))
 	for k := range c.labels {
@@ -602,13 +1263,31 @@ func (c *Container) ConfigHash() string {
 		configParts = append(configParts, fmt.Sprintf("label:%s=%s", k, c.labels[k]))
 	}
 
+	if c.healthCheck != nil {
+		configParts = append(configParts, fmt.Sprintf(
+			"health:%s:%s:%s:%d",
+			c.healthCheck.dockerCommand(), c.healthCheck.interval, c.healthCheck.timeout, c.healthCheck.retries,
+		))
+	}
+
 	configParts = append(configParts, fmt.Sprintf("readonly=%t", c.readOnly))
+
+	// Only added when set, so hashes of existing unprivileged containers do not change
+	if c.privileged {
+		configParts = append(configParts, "privileged")
+	}
+
 	configParts = append(configParts, strings.Join(c.securityOpts, commaSeparator))
 	configParts = append(configParts, strings.Join(c.capDrop, commaSeparator))
 	configParts = append(configParts, strings.Join(c.capAdd, commaSeparator))
 	configParts = append(configParts, strings.Join(c.groupAdd, commaSeparator))
 	configParts = append(configParts, c.restart)
 
+	// The weight is a label, which docker cannot change on a running container
+	if c.canaryOf != "" {
+		configParts = append(configParts, fmt.Sprintf("canary:%s:%d", c.canaryOf, c.canaryWeight))
+	}
+
 	config := strings.Join(configParts, "|")
 	configHash := sha256.Sum256([]byte(config))
 
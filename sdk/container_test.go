@@ -1,6 +1,9 @@
 package sdk_test
 
 import (
+	"bytes"
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/rs/zerolog"
@@ -163,3 +166,331 @@ func TestContainerConfigHash(t *testing.T) {
 		t.Error("expected different containers to have different config hash")
 	}
 }
+
+func TestContainerImageDigestWarning(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	plan := sdk.NewPlan("test").WithLogger(zerolog.New(&buf))
+
+	host := plan.Host("testuser@192.168.1.1").
+		Build()
+
+	newContainer := func(name, image string) {
+		plan.Container(name).
+			Host(host).
+			Image(image).
+			Memory("256m").
+			CPUShares(512).
+			CPUs("0.5").
+			PIDsLimit(100).
+			Build()
+	}
+
+	newContainer("pinned", "nginx@sha256:0000000000000000000000000000000000000000000000000000000000000000")
+
+	if buf.Len() != 0 {
+		t.Errorf("expected no warning for digest-pinned image, got: %s", buf.String())
+	}
+
+	newContainer("mutable", "nginx:latest")
+
+	if !strings.Contains(buf.String(), "not pinned by digest") {
+		t.Errorf("expected warning for mutable image tag, got: %s", buf.String())
+	}
+}
+
+func TestContainerPrivileged(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	plan := sdk.NewPlan("test").WithLogger(zerolog.New(&buf))
+
+	host := plan.Host("testuser@192.168.1.1").
+		Build()
+
+	newBuilder := func() *sdk.ContainerBuilder {
+		return plan.Container("agent").
+			Host(host).
+			Image("agent@sha256:0000000000000000000000000000000000000000000000000000000000000000").
+			Memory("256m").
+			CPUShares(512).
+			CPUs("0.5").
+			PIDsLimit(100)
+	}
+
+	unprivileged := newBuilder().Build()
+
+	if buf.Len() != 0 {
+		t.Errorf("expected no warning for an unprivileged container, got: %s", buf.String())
+	}
+
+	privileged := newBuilder().Privileged().Build()
+
+	if !strings.Contains(buf.String(), "Container is privileged") {
+		t.Errorf("expected a warning for a privileged container, got: %s", buf.String())
+	}
+
+	if unprivileged.ConfigHash() == privileged.ConfigHash() {
+		t.Error("expected Privileged to change the config hash")
+	}
+}
+
+func TestContainerCrossHostDependency(t *testing.T) {
+	t.Parallel()
+
+	plan := sdk.NewPlan("test").WithLogger(zerolog.Nop())
+
+	dbHost := plan.Host("db.example.com").
+		Address("10.0.0.5").
+		Build()
+
+	webHost := plan.Host("deploy@10.0.0.6").
+		Build()
+
+	newContainer := func(name string, host *sdk.Host) *sdk.ContainerBuilder {
+		return plan.Container(name).
+			Host(host).
+			Image("nginx:latest").
+			Memory("256m").
+			CPUShares(512).
+			CPUs("0.5").
+			PIDsLimit(100)
+	}
+
+	database := newContainer("postgres", dbHost).
+		NetworkAlias("db").
+		Build()
+
+	local := newContainer("cache", webHost).
+		Build()
+
+	app := newContainer("app", webHost).
+		DependsOn(database).
+		DependsOn(local).
+		Build()
+
+	hosts := app.ExtraHosts()
+	if len(hosts) != 1 || hosts[0] != "db:10.0.0.5" {
+		t.Errorf("expected only cross-host entry 'db:10.0.0.5', got %v", hosts)
+	}
+
+	if webHost.Address() != "10.0.0.6" {
+		t.Errorf("expected address to fall back to IP endpoint, got '%s'", webHost.Address())
+	}
+}
+
+func TestContainerInitContainerConfigHash(t *testing.T) {
+	t.Parallel()
+
+	plan := sdk.NewPlan("test").WithLogger(zerolog.Nop())
+
+	host := plan.Host("testuser@192.168.1.1").
+		Build()
+
+	newContainer := func() *sdk.ContainerBuilder {
+		return plan.Container("app").
+			Host(host).
+			Image("app:latest").
+			Memory("256m").
+			CPUShares(512).
+			CPUs("0.5").
+			PIDsLimit(100)
+	}
+
+	plain := newContainer().Build()
+	withInit := newContainer().InitContainer("app-migrate", "app:latest", "migrate", "up").Build()
+	changedInit := newContainer().InitContainer("app-migrate", "app:latest", "migrate", "down").Build()
+
+	if plain.ConfigHash() == withInit.ConfigHash() {
+		t.Error("expected init container to change the config hash")
+	}
+
+	if withInit.ConfigHash() == changedInit.ConfigHash() {
+		t.Error("expected init container command to change the config hash")
+	}
+}
+
+func TestContainerTmpfsUnsafe(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	plan := sdk.NewPlan("test").WithLogger(zerolog.New(&buf))
+
+	host := plan.Host("testuser@192.168.1.1").
+		Build()
+
+	newContainer := func(name string) *sdk.ContainerBuilder {
+		return plan.Container(name).
+			Host(host).
+			Image("nginx@sha256:0000000000000000000000000000000000000000000000000000000000000000").
+			Memory("256m").
+			CPUShares(512).
+			CPUs("0.5").
+			PIDsLimit(100)
+	}
+
+	secure := newContainer("scratch").
+		Tmpfs("/scratch", "size=1g").
+		Build()
+
+	if buf.Len() != 0 {
+		t.Errorf("expected no warning for secure tmpfs, got: %s", buf.String())
+	}
+
+	unsafe := newContainer("scratch").
+		TmpfsUnsafe("/scratch", "nosuid,nodev,exec,size=1g").
+		Build()
+
+	if !strings.Contains(buf.String(), "noexec") || !strings.Contains(buf.String(), "security flag") {
+		t.Errorf("expected warning about the dropped noexec flag, got: %s", buf.String())
+	}
+
+	if strings.Contains(buf.String(), `"flag":"nosuid"`) {
+		t.Errorf("expected no warning for flags that were kept, got: %s", buf.String())
+	}
+
+	if secure.ConfigHash() == unsafe.ConfigHash() {
+		t.Error("expected effective tmpfs options to change the config hash")
+	}
+}
+
+func TestContainerCapabilities(t *testing.T) {
+	t.Parallel()
+
+	plan := sdk.NewPlan("test").WithLogger(zerolog.Nop())
+
+	host := plan.Host("testuser@192.168.1.1").
+		Build()
+
+	newContainer := func() *sdk.ContainerBuilder {
+		return plan.Container("app").
+			Host(host).
+			Image("nginx:latest").
+			Memory("256m").
+			CPUShares(512).
+			CPUs("0.5").
+			PIDsLimit(100)
+	}
+
+	valid := newContainer().
+		CapDrop("ALL").
+		CapAdd("NET_BIND_SERVICE").
+		CapAdd("CAP_CHOWN").
+		CapAdd("cap_setuid")
+
+	if _, err := valid.BuildE(); err != nil {
+		t.Errorf("expected valid capabilities to build, got: %v", err)
+	}
+
+	_, err := newContainer().CapDrop("ALL").CapAdd("NET_BIND_SERV").BuildE()
+	if !errors.Is(err, sdk.ErrUnknownCapability) {
+		t.Fatalf("expected ErrUnknownCapability, got: %v", err)
+	}
+
+	if !strings.Contains(err.Error(), "NET_BIND_SERV") {
+		t.Errorf("expected error to name the unknown capability, got: %v", err)
+	}
+
+	if _, err := newContainer().CapDrop("CAP_ALL_THE_THINGS").BuildE(); !errors.Is(err, sdk.ErrUnknownCapability) {
+		t.Errorf("expected ErrUnknownCapability for cap-drop, got: %v", err)
+	}
+}
+
+func TestContainerEnvRejectsLineBreaks(t *testing.T) {
+	t.Parallel()
+
+	plan := sdk.NewPlan("test").WithLogger(zerolog.Nop())
+	host := plan.Host("testuser@192.168.1.1").Build()
+
+	_, err := plan.Container("app").
+		Host(host).
+		Image("nginx:latest").
+		Memory("256m").
+		CPUShares(512).
+		CPUs("0.5").
+		PIDsLimit(100).
+		Env("GREETING", "hello = world # not a comment").
+		Env("CERT", "-----BEGIN CERTIFICATE-----\nMIIB...").
+		BuildE()
+	if !errors.Is(err, sdk.ErrInvalidContainer) {
+		t.Fatalf("expected ErrInvalidContainer, got: %v", err)
+	}
+
+	if !strings.Contains(err.Error(), "CERT") {
+		t.Errorf("expected error to name the variable, got: %v", err)
+	}
+}
+
+func TestContainerRejectsInvalidRestartAndOOMScore(t *testing.T) {
+	t.Parallel()
+
+	plan := sdk.NewPlan("test").WithLogger(zerolog.Nop())
+	host := plan.Host("testuser@192.168.1.1").Build()
+
+	newContainer := func(name string) *sdk.ContainerBuilder {
+		return plan.Container(name).
+			Host(host).
+			Image("worker:1").
+			Memory("256m").
+			CPUShares(512).
+			CPUs("0.5").
+			PIDsLimit(100)
+	}
+
+	if _, err := newContainer("retries").RestartOnFailure(-1).BuildE(); !errors.Is(err, sdk.ErrInvalidContainer) {
+		t.Errorf("expected ErrInvalidContainer for negative restart retries, got: %v", err)
+	}
+
+	for _, score := range []int{-1001, 1001} {
+		if _, err := newContainer("oom").OOMScoreAdj(score).BuildE(); !errors.Is(err, sdk.ErrInvalidContainer) {
+			t.Errorf("expected ErrInvalidContainer for oom-score-adj %d, got: %v", score, err)
+		}
+	}
+
+	if _, err := newContainer("valid").RestartOnFailure(0).OOMScoreAdj(-1000).BuildE(); err != nil {
+		t.Errorf("expected a valid container, got: %v", err)
+	}
+}
+
+func TestContainerChownMount(t *testing.T) {
+	t.Parallel()
+
+	plan := sdk.NewPlan("test").WithLogger(zerolog.Nop())
+	host := plan.Host("testuser@192.168.1.1").Build()
+
+	builder := func(name, user string) *sdk.ContainerBuilder {
+		return plan.Container(name).
+			Host(host).
+			Image("nginx:latest").
+			Memory("256m").
+			CPUShares(512).
+			CPUs("0.5").
+			PIDsLimit(100).
+			User(user).
+			MountData([]byte("token"), "/run/token", "ro")
+	}
+
+	_, err := builder("named", "nobody").ChownMount("/run/token").BuildE()
+	if !errors.Is(err, sdk.ErrInvalidContainer) {
+		t.Errorf("expected ErrInvalidContainer for a non-numeric user, got: %v", err)
+	}
+
+	_, err = builder("unmounted", "473").ChownMount("/run/other").BuildE()
+	if !errors.Is(err, sdk.ErrInvalidContainer) {
+		t.Errorf("expected ErrInvalidContainer for a path that is not mounted, got: %v", err)
+	}
+
+	owned, err := builder("owned", "473:473").ChownMount("/run/token").BuildE()
+	if err != nil {
+		t.Fatalf("expected a valid container, got: %v", err)
+	}
+
+	shared := builder("owned", "473:473").Build()
+	if owned.ConfigHash() == shared.ConfigHash() {
+		t.Error("expected ChownMount to change the config hash")
+	}
+}
@@ -0,0 +1,62 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// dockerCLI is the local Docker CLI command name.
+const dockerCLI = "docker"
+
+// ResolveDigest looks up the digest a tag currently points to in its registry and returns the image
+// pinned to it ("repo:tag" becomes "repo@sha256:..."), so plans can pin images when they are built:
+//
+//	image, err := sdk.ResolveDigest(ctx, "ghcr.io/org/app:v1.2.3")
+//
+// For multi-platform images the digest is the image index's, so every platform stays pullable.
+// Images that already carry a digest are returned unchanged.
+//
+// This is a convenience for plan authors, run on the machine executing the plan with the local
+// `docker buildx imagetools` and its registry credentials. It is unrelated to the pull Hadron runs
+// on hosts at deploy time, which never changes the image a container was declared with.
+func ResolveDigest(ctx context.Context, image string) (string, error) {
+	if strings.Contains(image, digestMarker) {
+		return image, nil
+	}
+
+	//nolint:gosec // G204: the image is passed as a single argument, not through a shell
+	cmd := exec.CommandContext(ctx, dockerCLI, "buildx", "imagetools", "inspect", image,
+		"--format", "{{.Manifest.Digest}}")
+
+	output, err := cmd.Output()
+	if err != nil {
+		var stderr string
+
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			stderr = strings.TrimSpace(string(exitErr.Stderr))
+		}
+
+		return "", fmt.Errorf("%w: %s: %w (stderr: %s)", ErrDigestResolve, image, err, stderr)
+	}
+
+	digest := strings.TrimSpace(string(output))
+	if !strings.HasPrefix(digest, "sha256:") {
+		return "", fmt.Errorf("%w: %s: unexpected digest %q", ErrDigestResolve, image, digest)
+	}
+
+	return imageRepository(image) + "@" + digest, nil
+}
+
+// imageRepository returns an image reference without its tag ("localhost:5000/app:1" -> "localhost:5000/app").
+func imageRepository(image string) string {
+	name := image[strings.LastIndex(image, "/")+1:]
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		return image[:len(image)-len(name)+i]
+	}
+
+	return image
+}
@@ -0,0 +1,37 @@
+package sdk
+
+import (
+	"context"
+	"testing"
+)
+
+func TestImageRepository(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"nginx:1.27":                  "nginx",
+		"nginx":                       "nginx",
+		"ghcr.io/org/app:v1.2.3":      "ghcr.io/org/app",
+		"localhost:5000/app:1":        "localhost:5000/app",
+		"localhost:5000/team/app":     "localhost:5000/team/app",
+		"registry.example.com/app:v2": "registry.example.com/app",
+	}
+
+	for image, want := range tests {
+		if got := imageRepository(image); got != want {
+			t.Errorf("imageRepository(%q) = %q, want %q", image, got, want)
+		}
+	}
+}
+
+func TestResolveDigestKeepsPinnedImages(t *testing.T) {
+	t.Parallel()
+
+	// Pinned images are returned without a registry lookup
+	image := "ghcr.io/org/app@sha256:3b1f9e0c8d2a4f6e1b7c9d0a2e4f6b8c1d3e5f7a9b0c2d4e6f8a1b3c5d7e9f0a"
+
+	got, err := ResolveDigest(context.Background(), image)
+	if err != nil || got != image {
+		t.Errorf("expected %q unchanged, got %q (err: %v)", image, got, err)
+	}
+}
@@ -0,0 +1,124 @@
+package sdk
+
+// AddressPool represents a pool of subnets Docker allocates user-defined networks from.
+type AddressPool struct {
+	Base string // CIDR the pool is carved from (e.g., "10.10.0.0/16")
+	Size int    // prefix length of each allocated subnet (e.g., 24)
+}
+
+// DockerDaemonConfig represents adjustments folded onto the secure Docker daemon defaults.
+type DockerDaemonConfig struct {
+	RegistryMirrors    []string
+	InsecureRegistries []string
+	DNS                []string
+	AddressPools       []AddressPool
+	LogMaxSize         string // per-container log file size before rotation (default: "10m")
+	LogMaxFile         int    // number of rotated log files kept per container (default: 3)
+}
+
+// DockerDaemonBuilder builds Docker daemon configuration with a fluent API.
+type DockerDaemonBuilder struct {
+	host   *HostBuilder
+	config *DockerDaemonConfig
+}
+
+// DockerDaemon starts Docker daemon configuration, enabling hardening (see HardenDocker).
+// Settings are merged onto the secure defaults, and the daemon is only restarted when
+// the effective configuration changes.
+//
+// Example:
+//
+//	host := plan.Host("user@example.com").
+//	    DockerDaemon().
+//	        RegistryMirror("https://mirror.example.com").
+//	        LogMaxSize("50m").
+//	        LogMaxFile(5).
+//	        Done().
+//	    Build()
+func (hb *HostBuilder) DockerDaemon() *DockerDaemonBuilder {
+	hb.hardenDocker = true
+
+	if hb.dockerDaemon == nil {
+		hb.dockerDaemon = &DockerDaemonConfig{}
+	}
+
+	return &DockerDaemonBuilder{
+		host:   hb,
+		config: hb.dockerDaemon,
+	}
+}
+
+// RegistryMirror adds a Docker registry mirror (pull-through cache) for this host, enabling daemon hardening.
+// Shorthand for DockerDaemon().RegistryMirror(url).Done(). Needed for air-gapped or rate-limited environments.
+func (hb *HostBuilder) RegistryMirror(url string) *HostBuilder {
+	return hb.DockerDaemon().RegistryMirror(url).Done()
+}
+
+// LogMaxSize overrides the maximum size of a container log file before rotation (default: "10m"), enabling
+// daemon hardening. Shorthand for DockerDaemon().LogMaxSize(size).Done(), e.g. for hosts running chatty
+// services:
+//
+//	host := plan.Host("user@example.com").HardenDocker().LogMaxSize("50m").LogMaxFile(5).Build()
+func (hb *HostBuilder) LogMaxSize(size string) *HostBuilder {
+	return hb.DockerDaemon().LogMaxSize(size).Done()
+}
+
+// LogMaxFile overrides the number of rotated log files kept per container (default: 3), enabling daemon
+// hardening. Shorthand for DockerDaemon().LogMaxFile(count).Done().
+func (hb *HostBuilder) LogMaxFile(count int) *HostBuilder {
+	return hb.DockerDaemon().LogMaxFile(count).Done()
+}
+
+// RegistryMirror adds a registry mirror (pull-through cache) URL.
+func (db *DockerDaemonBuilder) RegistryMirror(url string) *DockerDaemonBuilder {
+	db.config.RegistryMirrors = append(db.config.RegistryMirrors, url)
+
+	return db
+}
+
+// InsecureRegistry allows a registry (host:port) served over plain HTTP or with a self-signed certificate,
+// enabling daemon hardening. Shorthand for DockerDaemon().InsecureRegistry(host).Done().
+// The daemon is configured before registry logins, so Registry() credentials work against it.
+func (hb *HostBuilder) InsecureRegistry(host string) *HostBuilder {
+	return hb.DockerDaemon().InsecureRegistry(host).Done()
+}
+
+// InsecureRegistry adds a registry (host:port) that may be reached over plain HTTP or with an untrusted certificate.
+func (db *DockerDaemonBuilder) InsecureRegistry(host string) *DockerDaemonBuilder {
+	db.config.InsecureRegistries = append(db.config.InsecureRegistries, host)
+
+	return db
+}
+
+// DNS adds a DNS server used by containers.
+func (db *DockerDaemonBuilder) DNS(server string) *DockerDaemonBuilder {
+	db.config.DNS = append(db.config.DNS, server)
+
+	return db
+}
+
+// AddressPool adds a default address pool for user-defined networks (e.g., "10.10.0.0/16", 24).
+func (db *DockerDaemonBuilder) AddressPool(cidr string, size int) *DockerDaemonBuilder {
+	db.config.AddressPools = append(db.config.AddressPools, AddressPool{Base: cidr, Size: size})
+
+	return db
+}
+
+// LogMaxSize overrides the maximum size of a container log file before rotation (default: "10m").
+func (db *DockerDaemonBuilder) LogMaxSize(size string) *DockerDaemonBuilder {
+	db.config.LogMaxSize = size
+
+	return db
+}
+
+// LogMaxFile overrides the number of rotated log files kept per container (default: 3).
+func (db *DockerDaemonBuilder) LogMaxFile(count int) *DockerDaemonBuilder {
+	db.config.LogMaxFile = count
+
+	return db
+}
+
+// Done finalizes Docker daemon configuration and returns to host builder.
+func (db *DockerDaemonBuilder) Done() *HostBuilder {
+	return db.host
+}
@@ -0,0 +1,55 @@
+package sdk
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestDockerDaemonLogRotationOverrides(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop())
+	chatty := plan.Host("user@192.168.1.1").HardenDocker().LogMaxSize("50m").LogMaxFile(5).Build()
+	quiet := plan.Host("user@192.168.1.2").HardenDocker().Build()
+	mirrored := plan.Host("user@192.168.1.3").RegistryMirror("https://mirror.internal").Build()
+
+	render := func(host *Host) map[string]any {
+		t.Helper()
+
+		jsonBytes, err := json.Marshal(desiredDaemonConfig(host))
+		if err != nil {
+			t.Fatalf("failed to marshal daemon config: %v", err)
+		}
+
+		config := make(map[string]any)
+		if err := json.Unmarshal(jsonBytes, &config); err != nil {
+			t.Fatalf("failed to parse daemon config: %v", err)
+		}
+
+		return config
+	}
+
+	config := render(chatty)
+
+	logOpts, _ := config["log-opts"].(map[string]any)
+	if logOpts["max-size"] != "50m" || logOpts["max-file"] != "5" {
+		t.Errorf("expected log rotation overrides, got %v", config["log-opts"])
+	}
+
+	// The other secure settings stay as they are
+	if config["live-restore"] != true || config["icc"] != false || config["no-new-privileges"] != true {
+		t.Errorf("expected secure defaults to be kept, got %v", config)
+	}
+
+	logOpts, _ = render(quiet)["log-opts"].(map[string]any)
+	if logOpts["max-size"] != "10m" || logOpts["max-file"] != "3" {
+		t.Errorf("expected default log rotation without overrides, got %v", logOpts)
+	}
+
+	mirrors, _ := render(mirrored)["registry-mirrors"].([]any)
+	if len(mirrors) != 1 || mirrors[0] != "https://mirror.internal" {
+		t.Errorf("expected the registry mirror shorthand to configure the daemon, got %v", mirrors)
+	}
+}
@@ -0,0 +1,167 @@
+package sdk
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/the-agent-c-ai/hadron/internal/docker"
+	"github.com/the-agent-c-ai/hadron/sdk/ssh"
+)
+
+// envForce is set to "true" by the hadron CLI when --force allows overwriting drifted containers.
+const envForce = "HADRON_FORCE"
+
+// DriftMode controls what a deploy does with containers that were changed by hand on the host.
+type DriftMode string
+
+const (
+	// DriftWarn logs how a drifted container differs from the plan, then recreates it as declared.
+	DriftWarn DriftMode = "warn"
+	// DriftRefuse fails the deploy with ErrDrift instead of recreating a drifted container, unless
+	// the CLI was invoked with --force.
+	DriftRefuse DriftMode = "refuse"
+)
+
+// WithDriftDetection inspects existing containers before a deploy touches them and compares them with
+// the plan. A container is drifted when it was not created by Hadron, belongs to another plan, or was
+// changed in place (e.g. docker update) while its configuration label still matches the plan. Without
+// drift detection such containers are silently recreated, or left as they are when the label matches.
+//
+// Differences from the plan are logged for every container about to be recreated, drifted or not.
+func (p *Plan) WithDriftDetection(mode DriftMode) *Plan {
+	p.driftMode = mode
+
+	return p
+}
+
+// forceFromEnv allows overwriting drifted containers when the CLI was invoked with --force.
+func forceFromEnv() bool {
+	return os.Getenv(envForce) == "true"
+}
+
+// detectDrift compares a live container with the plan and reports whether it was changed by hand.
+// In DriftRefuse mode a drifted container fails the deploy unless forced.
+func (e *executor) detectDrift(client ssh.Connection, container *Container, existingHash string) (bool, error) {
+	info, err := e.dockerExec.InspectContainer(client, container.Name())
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", ErrContainerCheck, err)
+	}
+
+	diff := e.containerDiff(info, container)
+
+	drifted := existingHash == "" ||
+		info.Config.Labels[labelPlan] != e.plan.name ||
+		(existingHash == container.ConfigHash() && len(diff) > 0)
+
+	if !drifted {
+		if len(diff) > 0 {
+			e.plan.logger.Info().
+				Str("container", container.Name()).
+				Strs("diff", diff).
+				Msg("Container differs from plan")
+		}
+
+		return false, nil
+	}
+
+	if existingHash == "" {
+		diff = append(diff, "not deployed by Hadron")
+	}
+
+	e.plan.logger.Warn().
+		Str("container", container.Name()).
+		Str("host", container.host.String()).
+		Strs("diff", diff).
+		Msg("Container was modified outside of Hadron")
+
+	if e.plan.driftMode == DriftRefuse && !e.plan.force {
+		return true, fmt.Errorf("%w: %s on %s: %s (re-run with --force to overwrite)",
+			ErrDrift, container.Name(), container.host, strings.Join(diff, "; "))
+	}
+
+	return true, nil
+}
+
+// containerDiff lists the inspected fields in which a live container differs from the plan,
+// as "field: live value, plan value" entries.
+func (e *executor) containerDiff(info *docker.ContainerInfo, container *Container) []string {
+	var diff []string
+
+	compare := func(field string, live, planned any) {
+		if live != planned {
+			diff = append(diff, fmt.Sprintf("%s: live %v, plan %v", field, live, planned))
+		}
+	}
+
+	restart := container.restart
+	if restart == "" {
+		restart = "no"
+	}
+
+	// docker inspect reports "on-failure:5" as the policy name and its retry count separately
+	restart, maxRetries, _ := strings.Cut(restart, ":")
+	retries, _ := strconv.Atoi(maxRetries)
+
+	compare("plan", info.Config.Labels[labelPlan], e.plan.name)
+	compare("image", info.Config.Image, container.image)
+	compare("user", info.Config.User, container.user)
+	compare("memory", info.HostConfig.Memory, memoryBytes(container.memory))
+	compare("memory reservation", info.HostConfig.MemoryReservation, memoryBytes(container.memoryReservation))
+	compare("cpus", info.HostConfig.NanoCPUs, nanoCPUs(container.cpus))
+	compare("cpu shares", info.HostConfig.CPUShares, container.cpuShares)
+	compare("pids limit", info.HostConfig.PidsLimit, container.pidsLimit)
+	compare("read-only", info.HostConfig.ReadonlyRootfs, container.readOnly)
+	compare("privileged", info.HostConfig.Privileged, container.privileged)
+	compare("oom score adj", info.HostConfig.OomScoreAdj, container.oomScoreAdj)
+	compare("restart", info.HostConfig.RestartPolicy.Name, restart)
+	compare("restart retries", info.HostConfig.RestartPolicy.MaximumRetryCount, retries)
+
+	return diff
+}
+
+// memorySize matches a docker memory limit: a decimal size with an optional binary unit, e.g. "512m",
+// "1.5g", "512mb", "1 GiB", or "1t".
+var memorySize = regexp.MustCompile(`^(\d+(?:\.\d+)?) ?([kmgtp])?i?b?$`)
+
+// memoryBytes converts a docker memory limit to bytes, as docker inspect reports it, parsing it the way
+// docker does (units are powers of 1024, fractions are truncated). Unparsable limits are returned as -1
+// so they always show up in a diff.
+func memoryBytes(limit string) int64 {
+	if limit == "" {
+		return 0
+	}
+
+	match := memorySize.FindStringSubmatch(strings.ToLower(limit))
+	if match == nil {
+		return -1
+	}
+
+	size, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return -1
+	}
+
+	multiplier := int64(1)
+	if match[2] != "" {
+		multiplier = int64(1) << (10 * (strings.Index("kmgtp", match[2]) + 1))
+	}
+
+	return int64(size * float64(multiplier))
+}
+
+// nanoCPUs converts a docker --cpus value ("0.5") to the NanoCpus docker inspect reports.
+func nanoCPUs(cpus string) int64 {
+	if cpus == "" {
+		return 0
+	}
+
+	n, err := strconv.ParseFloat(cpus, 64)
+	if err != nil {
+		return -1
+	}
+
+	return int64(n * 1e9)
+}
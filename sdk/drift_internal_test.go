@@ -0,0 +1,145 @@
+package sdk
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/the-agent-c-ai/hadron/internal/docker"
+	"github.com/the-agent-c-ai/hadron/internal/testutil"
+)
+
+func TestDetectDrift(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop()).WithDriftDetection(DriftRefuse)
+	host := plan.Host("user@192.168.1.1").Build()
+
+	container := plan.Container("app").
+		Host(host).
+		Image("app:1").
+		Memory("512m").
+		CPUShares(512).
+		CPUs("0.5").
+		PIDsLimit(100).
+		Restart("unless-stopped").
+		Build()
+
+	inspect := func(memory string) *testutil.FakeConnection {
+		return &testutil.FakeConnection{Respond: func(string) string {
+			return `{"Config":{"Image":"app:1","Labels":{"hadron.plan":"test"}},"HostConfig":{"Memory":` + memory +
+				`,"NanoCpus":500000000,"CpuShares":512,"PidsLimit":100,"RestartPolicy":{"Name":"unless-stopped"}}}`
+		}}
+	}
+
+	exec := newExecutor(plan)
+
+	// A container matching the plan is not drifted
+	drifted, err := exec.detectDrift(inspect("536870912"), container, container.ConfigHash())
+	if err != nil || drifted {
+		t.Fatalf("expected no drift, got %t (err: %v)", drifted, err)
+	}
+
+	// Its memory limit was changed with docker update while the label still matches the plan
+	drifted, err = exec.detectDrift(inspect("1073741824"), container, container.ConfigHash())
+	if !drifted || !errors.Is(err, ErrDrift) {
+		t.Fatalf("expected ErrDrift, got %t (err: %v)", drifted, err)
+	}
+
+	if !strings.Contains(err.Error(), "memory: live 1073741824, plan 536870912") {
+		t.Errorf("expected the memory difference in the error, got: %v", err)
+	}
+
+	// A plan change is not drift, whatever the live container looks like
+	drifted, err = exec.detectDrift(inspect("1073741824"), container, "previous-hash")
+	if err != nil || drifted {
+		t.Errorf("expected a changed plan not to count as drift, got %t (err: %v)", drifted, err)
+	}
+
+	// --force lets the deploy overwrite the drifted container
+	plan.force = true
+
+	drifted, err = exec.detectDrift(inspect("1073741824"), container, "")
+	if err != nil || !drifted {
+		t.Errorf("expected a forced overwrite of a container not deployed by Hadron, got %t (err: %v)", drifted, err)
+	}
+}
+
+func TestContainerDiffRestartOnFailure(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop())
+	host := plan.Host("user@192.168.1.1").Build()
+
+	container := plan.Container("worker").
+		Host(host).
+		Image("worker:1").
+		Memory("256m").
+		CPUShares(512).
+		CPUs("0.5").
+		PIDsLimit(100).
+		RestartOnFailure(5).
+		OOMScoreAdj(500).
+		Build()
+
+	if container.restart != "on-failure:5" {
+		t.Fatalf("expected restart policy on-failure:5, got %q", container.restart)
+	}
+
+	var info docker.ContainerInfo
+
+	info.Config.Image = "worker:1"
+	info.Config.Labels = map[string]string{labelPlan: "test"}
+	info.HostConfig.Memory = 256 << 20
+	info.HostConfig.NanoCPUs = 5e8
+	info.HostConfig.CPUShares = 512
+	info.HostConfig.PidsLimit = 100
+	info.HostConfig.OomScoreAdj = 500
+	info.HostConfig.RestartPolicy.Name = "on-failure"
+	info.HostConfig.RestartPolicy.MaximumRetryCount = 5
+
+	if diff := newExecutor(plan).containerDiff(&info, container); len(diff) != 0 {
+		t.Errorf("expected no difference, got: %v", diff)
+	}
+
+	info.HostConfig.RestartPolicy.MaximumRetryCount = 3
+
+	diff := newExecutor(plan).containerDiff(&info, container)
+	if !slices.Equal(diff, []string{"restart retries: live 3, plan 5"}) {
+		t.Errorf("expected a retry count difference, got: %v", diff)
+	}
+}
+
+func TestMemoryBytes(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		limit string
+		want  int64
+	}{
+		{"", 0},
+		{"1048576", 1 << 20},
+		{"512b", 512},
+		{"64k", 64 << 10},
+		{"512m", 512 << 20},
+		{"512mb", 512 << 20},
+		{"512MiB", 512 << 20},
+		{"1g", 1 << 30},
+		{"1.5g", 3 << 29},
+		{"1 GB", 1 << 30},
+		{"1t", 1 << 40},
+		{"0.5k", 512},
+		{"lots", -1},
+		{"1x", -1},
+		{"-1g", -1},
+	}
+
+	for _, tc := range cases {
+		if got := memoryBytes(tc.limit); got != tc.want {
+			t.Errorf("memoryBytes(%q): expected %d, got %d", tc.limit, tc.want, got)
+		}
+	}
+}
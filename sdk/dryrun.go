@@ -0,0 +1,109 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/the-agent-c-ai/hadron/internal/docker"
+	"github.com/the-agent-c-ai/hadron/internal/systemd"
+	"github.com/the-agent-c-ai/hadron/sdk/ssh"
+)
+
+// executorMode decides whether an executor changes the hosts it connects to.
+type executorMode int
+
+const (
+	// modeApply deploys: the default for Execute and Destroy.
+	modeApply executorMode = iota
+	// modeDryRun inspects the hosts and reports the action each deploy step would take.
+	modeDryRun
+	// modeStatus only inspects the hosts, for Status.
+	modeStatus
+)
+
+// mutates reports whether the executor may change the hosts. Deploy steps check it right before
+// their first change, so everything up to that point (existence, config hash, drift, volume data)
+// is evaluated the same way in every mode.
+func (e *executor) mutates() bool {
+	return e.mode == modeApply
+}
+
+// planned logs the action a dry run found for a resource and returns it in place of performing it.
+func (e *executor) planned(resourceType string, resource hostedResource, action Action) (Action, error) {
+	e.plan.logger.Info().
+		Str("type", resourceType).
+		Str("name", resource.Name()).
+		Str("host", resource.Host().String()).
+		Str("action", string(action)).
+		Msg("Planned change")
+
+	return action, nil
+}
+
+// dryRun connects to the plan's hosts and walks the systemd unit, network, volume, container, and
+// job deploy steps without changing anything, collecting what each would do in the result. Images
+// are not pulled, so containers whose image tag moved upstream are reported as unchanged.
+func (e *executor) dryRun(ctx context.Context) error {
+	defer func() {
+		if err := e.sshPool.CloseAll(); err != nil {
+			e.plan.logger.Warn().Err(err).Msg("Failed to close SSH connections")
+		}
+	}()
+
+	defer e.logSummary()
+
+	if _, err := e.plan.selectedContainers(); err != nil {
+		return err
+	}
+
+	e.plan.logger.Info().Msg("Host configuration (packages, hardening, firewalls, WireGuard) is not previewed")
+
+	for _, step := range []struct {
+		name string
+		run  func() error
+	}{
+		{"systemd units", e.deploySystemdUnits},
+		{"networks", e.deployNetworks},
+		{"volumes", e.deployVolumes},
+		{"containers", func() error { return e.deployContainers(ctx) }},
+		{"jobs", func() error { return e.deployJobs(ctx) }},
+	} {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("dry run stopped before %s: %w", step.name, err)
+		}
+
+		if err := step.run(); err != nil {
+			return fmt.Errorf("failed to plan %s: %w", step.name, err)
+		}
+	}
+
+	return nil
+}
+
+// planJob finds what deploying a job would do: its timer is missing, or its script was generated for
+// another configuration (the script labels the job's container with its config hash).
+func (e *executor) planJob(client ssh.Connection, job *Container) (Action, error) {
+	if _, _, err := client.Execute("test -f " + systemd.UnitPath(jobUnit(job)+".timer")); err != nil {
+		return e.planned("job", job, ActionCreate)
+	}
+
+	script := docker.JobScriptPath(client, job.Name())
+	check := fmt.Sprintf("grep -qF %s %s", labelConfigSHA+"="+job.ConfigHash(), script)
+	if _, _, err := client.Execute(check); err != nil {
+		return e.planned("job", job, ActionUpdate)
+	}
+
+	return e.planned("job", job, ActionSkip)
+}
+
+// planSystemdUnit finds what deploying a systemd unit would do by comparing its file on the host.
+func (e *executor) planSystemdUnit(client ssh.Connection, unit *SystemdUnit, action Action) (Action, error) {
+	if action == ActionUpdate {
+		current, _, err := ssh.RunPrivileged(client, "cat "+systemd.UnitPath(unit.unitFile()))
+		if err == nil && current == unit.service.Render() {
+			action = ActionSkip
+		}
+	}
+
+	return e.planned("systemd_unit", unit, action)
+}
@@ -0,0 +1,78 @@
+package sdk
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/the-agent-c-ai/hadron/internal/docker"
+	"github.com/the-agent-c-ai/hadron/internal/testutil"
+)
+
+func TestDryRunSystemdUnit(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop())
+	host := plan.Host("user@192.168.1.1").Build()
+	unit := plan.SystemdUnit("node-agent").Host(host).ExecStart("/usr/local/bin/node-agent").Build()
+
+	exec := newExecutor(plan)
+	exec.mode = modeDryRun
+
+	cases := []struct {
+		name   string
+		client *testutil.FakeConnection
+		want   Action
+	}{
+		{"missing", &testutil.FakeConnection{Failing: []string{"test -f /etc/systemd/system/hadron-node-agent.service"}},
+			ActionCreate},
+		{"changed", &testutil.FakeConnection{Respond: func(string) string { return "[Unit]\n" }}, ActionUpdate},
+		{"unchanged", &testutil.FakeConnection{Respond: func(string) string { return unit.service.Render() }}, ActionSkip},
+	}
+
+	for _, tc := range cases {
+		action, err := exec.applySystemdUnit(tc.client, unit)
+		if err != nil || action != tc.want {
+			t.Errorf("%s: expected %q, got %q (err: %v)", tc.name, tc.want, action, err)
+		}
+
+		for _, command := range tc.client.Commands {
+			if strings.Contains(command, "install") || strings.Contains(command, "systemctl") {
+				t.Errorf("%s: dry run changed the host with %q", tc.name, command)
+			}
+		}
+	}
+}
+
+func TestDryRunJob(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop())
+	host := plan.Host("user@192.168.1.1").Build()
+	job := newTestJob(plan, host, "daily").Build()
+
+	exec := newExecutor(plan)
+	exec.mode = modeDryRun
+
+	script := docker.JobScriptPath(&testutil.FakeConnection{}, job.Name())
+	check := "grep -qF " + labelConfigSHA + "=" + job.ConfigHash() + " " + script
+
+	cases := []struct {
+		name   string
+		client *testutil.FakeConnection
+		want   Action
+	}{
+		{"missing", &testutil.FakeConnection{Failing: []string{"test -f /etc/systemd/system/hadron-job-backup.timer"}},
+			ActionCreate},
+		{"changed", &testutil.FakeConnection{Failing: []string{check}}, ActionUpdate},
+		{"unchanged", &testutil.FakeConnection{}, ActionSkip},
+	}
+
+	for _, tc := range cases {
+		action, err := exec.planJob(tc.client, job)
+		if err != nil || action != tc.want {
+			t.Errorf("%s: expected %q, got %q (err: %v)", tc.name, tc.want, action, err)
+		}
+	}
+}
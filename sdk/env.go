@@ -1,7 +1,10 @@
 package sdk
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
 
 	"github.com/joho/godotenv"
 	"github.com/rs/zerolog/log"
@@ -9,15 +12,56 @@ import (
 
 // LoadEnv loads environment variables from the specified .env file.
 // Values in the .env file will override existing environment variables.
-// If the file doesn't exist or cannot be loaded, it logs a fatal error and exits.
+// If the file doesn't exist or cannot be loaded, the error is returned and the caller decides;
+// plans that require the file should stop on it.
 func LoadEnv(path string) error {
+	return TryLoadEnv(path)
+}
+
+// TryLoadEnv loads environment variables from the specified .env file like LoadEnv, returning
+// an error instead of exiting. A missing file matches fs.ErrNotExist, so optional env files can
+// be skipped:
+//
+//	if err := sdk.TryLoadEnv(".env.local"); err != nil && !errors.Is(err, fs.ErrNotExist) {
+//		return err
+//	}
+func TryLoadEnv(path string) error {
 	if err := godotenv.Overload(path); err != nil {
-		log.Fatal().Err(err).Str("path", path).Msg("Failed to load .env file")
+		return fmt.Errorf("failed to load env file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// LoadEnvFiles loads several .env files in order, each overriding the environment and the files
+// before it (e.g. base.env, then prod.env). It stops at the first file that fails to load.
+func LoadEnvFiles(paths ...string) error {
+	for _, path := range paths {
+		if err := TryLoadEnv(path); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+// LoadEnvDir loads every *.env file in dir with LoadEnvFiles, in lexical order, so prefixes such as
+// "00-base.env" and "10-prod.env" control precedence. A directory without env files loads nothing.
+func LoadEnvDir(dir string) error {
+	if _, err := os.Stat(dir); err != nil {
+		return fmt.Errorf("failed to load env directory %s: %w", dir, err)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.env"))
+	if err != nil {
+		return fmt.Errorf("failed to list env files in %s: %w", dir, err)
+	}
+
+	sort.Strings(paths)
+
+	return LoadEnvFiles(paths...)
+}
+
 // GetEnv retrieves an environment variable value.
 // If the variable is not set, it logs a fatal error and exits.
 func GetEnv(key string) string {
@@ -0,0 +1,72 @@
+package sdk_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/the-agent-c-ai/hadron/sdk"
+)
+
+func TestTryLoadEnv(t *testing.T) {
+	// Registered so the variable is restored once the test ends
+	t.Setenv("HADRON_TEST_ENV", "before")
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".env")
+
+	if err := os.WriteFile(path, []byte("HADRON_TEST_ENV=from-file\n"), 0o600); err != nil {
+		t.Fatalf("failed to write env file: %v", err)
+	}
+
+	if err := sdk.TryLoadEnv(path); err != nil {
+		t.Fatalf("expected env file to load, got: %v", err)
+	}
+
+	if got := os.Getenv("HADRON_TEST_ENV"); got != "from-file" {
+		t.Errorf("expected the file to override the environment, got %q", got)
+	}
+
+	err := sdk.LoadEnv(filepath.Join(dir, "missing.env"))
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected a missing file to be returned as fs.ErrNotExist, got: %v", err)
+	}
+}
+
+func TestLoadEnvDirOverridesInOrder(t *testing.T) {
+	t.Setenv("HADRON_TEST_ENV", "before")
+	t.Setenv("HADRON_TEST_BASE", "before")
+
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"10-prod.env":  "HADRON_TEST_ENV=prod\n",
+		"00-base.env":  "HADRON_TEST_ENV=base\nHADRON_TEST_BASE=base\n",
+		"notes.txt":    "HADRON_TEST_ENV=ignored\n",
+		"20-local.bak": "HADRON_TEST_ENV=ignored\n",
+	}
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	if err := sdk.LoadEnvDir(dir); err != nil {
+		t.Fatalf("expected env directory to load, got: %v", err)
+	}
+
+	if got := os.Getenv("HADRON_TEST_ENV"); got != "prod" {
+		t.Errorf("expected the later file to win, got %q", got)
+	}
+
+	if got := os.Getenv("HADRON_TEST_BASE"); got != "base" {
+		t.Errorf("expected values only in the base file to be kept, got %q", got)
+	}
+
+	if err := sdk.LoadEnvDir(filepath.Join(dir, "missing")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected a missing directory to fail with fs.ErrNotExist, got: %v", err)
+	}
+}
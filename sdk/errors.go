@@ -18,6 +18,12 @@ var (
 	// ErrContainerCheck indicates failure checking if Docker container exists.
 	ErrContainerCheck = errors.New("failed to check container existence")
 
+	// ErrInvalidSudoPassword indicates a sudo password that cannot be fed to sudo through stdin.
+	ErrInvalidSudoPassword = errors.New("invalid sudo password")
+
+	// ErrMetricsPush indicates failure pushing deploy metrics to a Pushgateway.
+	ErrMetricsPush = errors.New("failed to push deploy metrics")
+
 	// 1Password errors.
 
 	// ErrDocumentReferenceEmpty indicates document reference is empty.
@@ -49,4 +55,106 @@ var (
 
 	// ErrSecretEmpty indicates secret resolved to empty value.
 	ErrSecretEmpty = errors.New("secret resolved to empty value")
+
+	// ErrInjectTemplateEmpty indicates InjectSecrets was given an empty template.
+	ErrInjectTemplateEmpty = errors.New("inject template cannot be empty")
+
+	// Vault errors.
+
+	// ErrVaultNotConfigured indicates VAULT_ADDR or VAULT_TOKEN is not set.
+	ErrVaultNotConfigured = errors.New("vault requires VAULT_ADDR and VAULT_TOKEN")
+
+	// ErrVaultReferenceInvalidPrefix indicates a Vault secret reference missing the 'vault://' prefix.
+	ErrVaultReferenceInvalidPrefix = errors.New("vault secret reference must start with 'vault://'")
+
+	// ErrVaultReferenceInvalidFormat indicates a Vault secret reference without a path or field.
+	ErrVaultReferenceInvalidFormat = errors.New("invalid vault secret reference format")
+
+	// ErrVaultRequest indicates Vault rejected the read or returned an unreadable response.
+	ErrVaultRequest = errors.New("vault request failed")
+
+	// ErrVaultFieldNotFound indicates the Vault secret exists but has no such field.
+	ErrVaultFieldNotFound = errors.New("vault secret has no such field")
+
+	// AWS Secrets Manager errors.
+
+	// ErrAWSSecretReferenceInvalidPrefix indicates a secret reference missing the 'awssm://' prefix.
+	ErrAWSSecretReferenceInvalidPrefix = errors.New("aws secret reference must start with 'awssm://'")
+
+	// ErrAWSSecretReferenceInvalidFormat indicates an AWS secret reference without a secret ID or with an empty key.
+	ErrAWSSecretReferenceInvalidFormat = errors.New("invalid aws secret reference format")
+
+	// ErrAWSSecretNotJSON indicates a key was requested from a secret that is not a JSON object.
+	ErrAWSSecretNotJSON = errors.New("aws secret is not a JSON object")
+
+	// ErrAWSSecretKeyNotFound indicates the AWS secret's JSON object has no such key.
+	ErrAWSSecretKeyNotFound = errors.New("aws secret has no such key")
+
+	// ErrConfirmationRequired indicates a destructive action was not confirmed.
+	ErrConfirmationRequired = errors.New("destructive action requires confirmation")
+
+	// ErrManifestInvalid indicates a plan manifest could not be parsed or has dangling references.
+	ErrManifestInvalid = errors.New("invalid plan manifest")
+
+	// ErrDuplicateResource indicates two networks, volumes, or containers share a name on one host.
+	ErrDuplicateResource = errors.New("duplicate resource name")
+
+	// ErrCrossHostReference indicates a container uses a network or volume created on another host.
+	ErrCrossHostReference = errors.New("container references a resource on another host")
+
+	// ErrWireGuardPeerAddress indicates a WireGuard host has no address its peers can reach it at.
+	ErrWireGuardPeerAddress = errors.New("wireguard host has no known address")
+
+	// ErrUnsupportedOS indicates a host runs a distribution Hadron cannot manage.
+	ErrUnsupportedOS = errors.New("unsupported operating system")
+
+	// ErrPostDeployCheck indicates a post-deploy check command exited non-zero.
+	ErrPostDeployCheck = errors.New("post-deploy check failed")
+
+	// ErrDigestResolve indicates the registry digest of an image tag could not be looked up.
+	ErrDigestResolve = errors.New("failed to resolve image digest")
+
+	// ErrDrift indicates a container was modified outside of Hadron and drift detection refused to overwrite it.
+	ErrDrift = errors.New("container drifted from plan")
+
+	// ErrVolumeHasData indicates a changed volume holds data and was not recreated without AllowRecreate.
+	ErrVolumeHasData = errors.New("volume contains data, manual migration required")
+
+	// ErrVolumeBackup indicates a volume could not be archived before being recreated.
+	ErrVolumeBackup = errors.New("volume backup failed")
+
+	// ErrPostStart indicates a container's PostStart command failed or it never became healthy to run it.
+	ErrPostStart = errors.New("post-start command failed")
+
+	// ErrHook indicates a deploy or destroy hook command exited non-zero.
+	ErrHook = errors.New("hook failed")
+
+	// ErrUnknownContainer indicates a container name does not identify exactly one plan container.
+	ErrUnknownContainer = errors.New("unknown container")
+
+	// ErrUnknownHost indicates a targeted host endpoint or address is not one of the plan's hosts.
+	ErrUnknownHost = errors.New("unknown host")
+
+	// ErrInvalidContainer indicates a container configuration is incomplete or inconsistent.
+	ErrInvalidContainer = errors.New("invalid container")
+
+	// ErrInvalidNetwork indicates a network configuration is incomplete or inconsistent.
+	ErrInvalidNetwork = errors.New("invalid network")
+
+	// ErrInvalidSystemdUnit indicates a systemd unit configuration is incomplete, inconsistent, or has an
+	// invalid name.
+	ErrInvalidSystemdUnit = errors.New("invalid systemd unit")
+
+	// ErrInvalidVolume indicates a volume configuration is incomplete or inconsistent.
+	ErrInvalidVolume = errors.New("invalid volume")
+
+	// ErrUnknownCapability indicates CapAdd or CapDrop was given a name that is not a Linux capability.
+	ErrUnknownCapability = errors.New("unknown Linux capability")
+
+	// ErrNetworkInUse indicates a network cannot be recreated because containers are attached.
+	ErrNetworkInUse = errors.New("network has attached containers")
+
+	// ErrModeNotHandled indicates Execute was called while the CLI asked for another mode (status,
+	// logs, destroy, ...), so the plan would have deployed instead of doing what was asked.
+	ErrModeNotHandled = errors.New("plan does not handle the requested CLI mode")
 )
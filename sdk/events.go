@@ -0,0 +1,107 @@
+package sdk
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Action is what a deploy did with a resource, or what a dry run found it would do.
+type Action string
+
+// Actions reported in the deploy Result and deploy events.
+const (
+	ActionCreate  Action = "create"
+	ActionUpdate  Action = "update"
+	ActionSkip    Action = "skip"
+	ActionRestart Action = "restart"
+)
+
+// deployEvent is one resource action, emitted as a JSON line by WithJSONOutput.
+type deployEvent struct {
+	Time     time.Time `json:"time"`
+	Plan     string    `json:"plan"`
+	Type     string    `json:"type"`
+	Name     string    `json:"name"`
+	Host     string    `json:"host"`
+	Action   Action    `json:"action,omitempty"`
+	Duration float64   `json:"duration_seconds"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// eventWriter serializes deploy events; networks and volumes deploy concurrently.
+type eventWriter struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// WithJSONOutput writes one JSON object per line to w for every network, volume, and container
+// action during Execute, independent of the human-readable log:
+//
+//	{"time":"...","plan":"web","type":"container","name":"app","host":"deploy@10.0.0.5",
+//	 "action":"update","duration_seconds":4.2}
+//
+// Action is create, update, skip, or restart; failed actions carry an error field.
+func (p *Plan) WithJSONOutput(w io.Writer) *Plan {
+	p.eventOutput = w
+
+	return p
+}
+
+// tracked wraps a deploy function so each call is timed, added to the deploy Result, and reported
+// to the plan's JSON output.
+func tracked[T hostedResource](e *executor, resourceType string, deploy func(T) (Action, error)) func(T) error {
+	return func(resource T) error {
+		start := time.Now()
+		action, err := deploy(resource)
+
+		outcome := ResourceResult{
+			Type:     resourceType,
+			Name:     resource.Name(),
+			Host:     resource.Host().String(),
+			Action:   action,
+			Duration: time.Since(start),
+			Err:      err,
+		}
+
+		e.record(outcome)
+		e.emit(deployEvent{
+			Type:     outcome.Type,
+			Name:     outcome.Name,
+			Host:     outcome.Host,
+			Action:   outcome.Action,
+			Duration: outcome.Duration.Seconds(),
+			Error:    errorString(err),
+		})
+
+		return err
+	}
+}
+
+// emit writes an event to the plan's JSON output, if one is configured.
+// Write failures are logged rather than returned: the deploy must not depend on its observers.
+func (e *executor) emit(event deployEvent) {
+	if e.events == nil {
+		return
+	}
+
+	event.Time = time.Now().UTC()
+	event.Plan = e.plan.name
+
+	e.events.mu.Lock()
+	defer e.events.mu.Unlock()
+
+	if err := e.events.encoder.Encode(event); err != nil {
+		e.plan.logger.Warn().Err(err).Msg("Failed to write deploy event")
+	}
+}
+
+// errorString returns the error message, or empty string for a nil error.
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	return err.Error()
+}
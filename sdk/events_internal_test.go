@@ -0,0 +1,78 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+var errCreateFailed = errors.New("create failed")
+
+func TestJSONOutput(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+
+	plan := NewPlan("web").WithLogger(zerolog.Nop()).WithJSONOutput(&out)
+	host := plan.Host("deploy@10.0.0.5").Build()
+	network := plan.Network("app-net").Host(host).Build()
+	volume := plan.Volume("app-data").Host(host).Build()
+
+	exec := newExecutor(plan)
+
+	if err := tracked(exec, "network", func(*Network) (Action, error) {
+		return ActionSkip, nil
+	})(network); err != nil {
+		t.Fatalf("expected tracked deploy to succeed, got: %v", err)
+	}
+
+	if err := tracked(exec, "volume", func(*Volume) (Action, error) {
+		return ActionCreate, errCreateFailed
+	})(volume); !errors.Is(err, errCreateFailed) {
+		t.Fatalf("expected the deploy error to be returned, got: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected one JSON line per action, got: %q", out.String())
+	}
+
+	var skipped, failed deployEvent
+	if err := json.Unmarshal([]byte(lines[0]), &skipped); err != nil {
+		t.Fatalf("invalid JSON event: %v", err)
+	}
+
+	if err := json.Unmarshal([]byte(lines[1]), &failed); err != nil {
+		t.Fatalf("invalid JSON event: %v", err)
+	}
+
+	if skipped.Plan != "web" || skipped.Type != "network" || skipped.Name != "app-net" ||
+		skipped.Host != "deploy@10.0.0.5" || skipped.Action != ActionSkip || skipped.Error != "" {
+		t.Errorf("unexpected network event: %+v", skipped)
+	}
+
+	if failed.Type != "volume" || failed.Action != ActionCreate || failed.Error != "create failed" {
+		t.Errorf("unexpected volume event: %+v", failed)
+	}
+}
+
+func TestJSONOutputDisabled(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("web").WithLogger(zerolog.Nop())
+	host := plan.Host("deploy@10.0.0.5").Build()
+
+	exec := newExecutor(plan)
+
+	// Without WithJSONOutput, tracking only passes the result through
+	err := tracked(exec, "network", func(*Network) (Action, error) {
+		return ActionCreate, nil
+	})(plan.Network("app-net").Host(host).Build())
+	if err != nil || exec.events != nil {
+		t.Errorf("expected no event writer and no error, got %v (err: %v)", exec.events, err)
+	}
+}
@@ -0,0 +1,70 @@
+package sdk
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/the-agent-c-ai/hadron/internal/docker"
+	"github.com/the-agent-c-ai/hadron/sdk/ssh"
+)
+
+var errExecCommandRequired = errors.New("exec requires a command")
+
+// Exec runs a command in a plan container with docker exec, resolving its host from the plan and
+// attaching it to the local terminal. When stdin is a terminal, a remote PTY is allocated and the
+// local terminal is switched to raw mode until the command exits, so interactive shells work;
+// otherwise input and output are piped:
+//
+//	plan.Exec("caddy", "sh", "-l")
+func (p *Plan) Exec(container string, command ...string) error {
+	if len(command) == 0 {
+		return errExecCommandRequired
+	}
+
+	target, err := p.findContainer(container)
+	if err != nil {
+		return err
+	}
+
+	exec := newExecutor(p)
+
+	defer func() {
+		if err := exec.sshPool.CloseAll(); err != nil {
+			p.logger.Warn().Err(err).Msg("Failed to close SSH connections")
+		}
+	}()
+
+	client, err := exec.getSSHClient(target.host)
+	if err != nil {
+		return fmt.Errorf(errFailedSSHClient, target.host, err)
+	}
+
+	pty, restore, err := attachTerminal(os.Stdin, os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	defer restore()
+
+	return execInContainer(client, target, command, os.Stdin, os.Stdout, os.Stderr, pty)
+}
+
+// execInContainer runs docker exec for the container, with a terminal if pty is set.
+func execInContainer(
+	client ssh.Connection,
+	container *Container,
+	command []string,
+	stdin io.Reader,
+	stdout, stderr io.Writer,
+	pty *ssh.PTY,
+) error {
+	cmd := docker.ExecCommand(container.Name(), command, pty != nil)
+
+	if err := client.Interactive(cmd, stdin, stdout, stderr, pty); err != nil {
+		return fmt.Errorf("failed to exec in %s on %s: %w", container.Name(), container.host, err)
+	}
+
+	return nil
+}
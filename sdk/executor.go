@@ -2,23 +2,35 @@ package sdk
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/the-agent-c-ai/hadron/internal/debian"
 	"github.com/the-agent-c-ai/hadron/internal/docker"
 	"github.com/the-agent-c-ai/hadron/internal/firewall"
+	"github.com/the-agent-c-ai/hadron/internal/osrelease"
 	"github.com/the-agent-c-ai/hadron/internal/sshd"
 	"github.com/the-agent-c-ai/hadron/internal/sysctl"
 	"github.com/the-agent-c-ai/hadron/sdk/ssh"
 )
 
 const (
-	labelConfigSHA     = "hadron.config.sha"
-	labelPlan          = "hadron.plan"
-	errFailedSSHClient = "failed to get SSH client for %s: %w"
-	dockerReadyTimeout = 30 * time.Second
+	labelConfigSHA      = "hadron.config.sha"
+	labelPlan           = "hadron.plan"
+	labelCanary         = "hadron.canary"
+	labelCanaryWeight   = "hadron.canary.weight"
+	errFailedSSHClient  = "failed to get SSH client for %s: %w"
+	dockerReadyTimeout  = 30 * time.Second
+	waitForFileInterval = 2 * time.Second
+	postStartTimeout    = 5 * time.Minute // how long PostStart waits for the container to become healthy
+	healthPollInterval  = 2 * time.Second
 )
 
 var errConnectToNetwork = errors.New("failed to connect container to network")
@@ -40,6 +52,10 @@ type resourceOperations struct {
 	create       func(ssh.Connection, string, string, map[string]string) error
 	existsError  error
 	createError  error
+	destructive  bool // recreating loses data, so it needs confirmation
+
+	// guard may refuse to recreate a changed resource before confirmation is asked (optional)
+	guard func(ssh.Connection, string) error
 }
 
 // executor implements plan execution logic.
@@ -47,123 +63,296 @@ type executor struct {
 	plan       *Plan
 	sshPool    *ssh.Pool
 	dockerExec *docker.Executor
+	changed    map[*Container]bool // containers (re)deployed or restarted during this run
+	metrics    *deployMetrics
+	events     *eventWriter // nil unless the plan has JSON output
+
+	resultMu sync.Mutex
+	result   Result // resource outcomes, returned by Plan.ExecuteWithResult
+
+	osMu   sync.Mutex
+	osInfo map[*Host]osrelease.Info // distribution per host, detected once per run
+
+	sudoMu        sync.Mutex
+	sudoPasswords map[*Host]string // SudoPasswordFromSecret references, resolved once per run
+
+	confirmMu sync.Mutex // one confirmation prompt at a time, see confirmDestructive
+
+	mode executorMode // modeApply unless the executor runs a dry run or status
 }
 
 // newExecutor creates a new plan executor.
 func newExecutor(plan *Plan) *executor {
-	sshPool := ssh.NewPool(plan.logger)
+	sshPool := ssh.NewPool(plan.logger).WithCommandTimeout(plan.commandTimeout)
 	dockerExec := docker.NewExecutor(sshPool, plan.logger)
 
-	return &executor{
+	exec := &executor{
 		plan:       plan,
 		sshPool:    sshPool,
 		dockerExec: dockerExec,
+		changed:    make(map[*Container]bool),
+		metrics:    newDeployMetrics(),
+		osInfo:     make(map[*Host]osrelease.Info),
+
+		sudoPasswords: make(map[*Host]string),
 	}
+
+	if plan.eventOutput != nil {
+		exec.events = &eventWriter{encoder: json.NewEncoder(plan.eventOutput)}
+	}
+
+	return exec
 }
 
 // getSSHClient returns an SSH client for the given host, using SSH key and/or fingerprint verification if configured.
+// Connection failures are logged with a remediation hint, since the cause is usually local setup.
 //
 //nolint:wrapcheck
 func (e *executor) getSSHClient(host *Host) (ssh.Connection, error) {
-	return e.sshPool.GetClientWithKey(host.Endpoint(), host.SSHFingerprint(), host.SSHKeyContent())
+	sudoPassword, err := e.sudoPassword(host)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := e.sshPool.GetClientWithOptions(host.Endpoint(), ssh.ClientOptions{
+		Fingerprint:       host.SSHFingerprint(),
+		KeyContent:        host.SSHKeyContent(),
+		TrustOnFirstUse:   host.trustOnFirst,
+		HostKeyAlgorithms: host.hostKeyAlgos,
+		SudoPassword:      sudoPassword,
+		WorkDir:           host.workDir,
+	})
+	if err != nil {
+		if hint := connectionHint(host, err); hint != "" {
+			e.plan.logger.Error().Err(err).Str("host", host.String()).Str("hint", hint).Msg("SSH connection failed")
+		}
+
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// sudoPassword returns the host's sudo password, resolving SudoPasswordFromSecret on first use. The
+// secret is resolved without a deadline, like the SSH connection it is needed for.
+func (e *executor) sudoPassword(host *Host) (string, error) {
+	if host.sudoSecretRef == "" {
+		return host.sudoPassword, nil
+	}
+
+	e.sudoMu.Lock()
+	defer e.sudoMu.Unlock()
+
+	if password, ok := e.sudoPasswords[host]; ok {
+		return password, nil
+	}
+
+	password, err := e.plan.secrets.GetSecret(context.Background(), host.sudoSecretRef)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve sudo password for %s: %w", host, err)
+	}
+
+	if strings.ContainsAny(password, "\r\n") {
+		return "", fmt.Errorf("%w: sudo password for %s contains a line break", ErrInvalidSudoPassword, host)
+	}
+
+	e.sudoPasswords[host] = password
+
+	return password, nil
+}
+
+// connectionHint suggests how to fix an SSH connection failure, or returns "" for unexpected errors.
+func connectionHint(host *Host, err error) string {
+	switch {
+	case errors.Is(err, ssh.ErrHostNotInKnownHosts):
+		return fmt.Sprintf("verify the host key, then run: ssh-keyscan -H %s >> ~/.ssh/known_hosts "+
+			"(or pin it with Host.Fingerprint)", host.Address())
+	case errors.Is(err, ssh.ErrHostKeyMismatch):
+		return fmt.Sprintf("if the host was rebuilt, remove its old key with: ssh-keygen -R %s "+
+			"(or update Host.Fingerprint); otherwise do not connect", host.Address())
+	case errors.Is(err, ssh.ErrHostKeyAlgorithm):
+		return "add an Ed25519 host key on the host (ssh-keygen -A), or accept its key type with " +
+			"Host.AllowHostKeyAlgorithms"
+	case errors.Is(err, ssh.ErrAuthFailed):
+		return "check the SSH user and that its key is loaded (ssh-add -l) or set with Host.SSHKey"
+	case errors.Is(err, ssh.ErrHostUnreachable):
+		return "check the host address and port, and that sshd is reachable from this machine"
+	default:
+		return ""
+	}
 }
 
 // execute performs the actual deployment.
-func (e *executor) execute(ctx context.Context) error {
+func (e *executor) execute(ctx context.Context) (err error) {
 	defer func() {
 		if err := e.sshPool.CloseAll(); err != nil {
 			e.plan.logger.Warn().Err(err).Msg("Failed to close SSH connections")
 		}
 	}()
 
+	defer func() {
+		e.publishMetrics(ctx, err == nil)
+	}()
+
+	// Recap what changed, whether the deploy succeeded or not
+	defer e.logSummary()
+
 	// Check if context is already cancelled
 	if err := ctx.Err(); err != nil {
 		return fmt.Errorf("execution cancelled before start: %w", err)
 	}
 
+	// Reject unknown --only/--skip names before anything on the hosts changes
+	if _, err := e.plan.selectedContainers(); err != nil {
+		return err
+	}
+
 	e.plan.logger.Info().Msg("Starting deployment")
 
+	// Check every host runs a supported distribution before changing anything
+	if err := e.runPhase(ctx, "preflight", e.checkHostOS); err != nil {
+		return err
+	}
+
+	// Run the plan's own preparation (e.g. draining load balancers) once the hosts are known to be usable
+	if err := e.runPhase(ctx, "pre_deploy_hooks", func() error { return e.runHooks(stagePreDeploy) }); err != nil {
+		return err
+	}
+
 	// Deploy packages first (install then remove)
-	if err := e.deployPackages(); err != nil {
+	if err := e.runPhase(ctx, "packages", e.deployPackages); err != nil {
 		return fmt.Errorf("failed to deploy packages: %w", err)
 	}
 
 	// Apply OS-level hardening (sysctl) after packages but before Docker
-	if err := e.deployOSHardening(); err != nil {
+	if err := e.runPhase(ctx, "os_hardening", e.deployOSHardening); err != nil {
 		return fmt.Errorf("failed to deploy OS hardening: %w", err)
 	}
 
 	// Apply SSH hardening before Docker (in case Docker breaks SSH somehow)
-	if err := e.deploySSHHardening(); err != nil {
+	if err := e.runPhase(ctx, "ssh_hardening", e.deploySSHHardening); err != nil {
 		return fmt.Errorf("failed to deploy SSH hardening: %w", err)
 	}
 
 	// Configure Docker daemon after packages (Docker must be installed)
-	if err := e.deployDockerDaemon(); err != nil {
+	if err := e.runPhase(ctx, "docker_daemon", e.deployDockerDaemon); err != nil {
 		return fmt.Errorf("failed to deploy docker daemon config: %w", err)
 	}
 
-	// Configure automatic security updates (always enabled)
-	if err := e.deployAutoUpdates(); err != nil {
+	// Install node_exporter once Docker is running (it listens on the docker0 address)
+	if err := e.runPhase(ctx, "node_exporter", e.deployNodeExporter); err != nil {
+		return fmt.Errorf("failed to deploy node_exporter: %w", err)
+	}
+
+	// Configure automatic security updates (enabled unless a host opts out)
+	if err := e.runPhase(ctx, "auto_updates", e.deployAutoUpdates); err != nil {
 		return fmt.Errorf("failed to deploy automatic updates: %w", err)
 	}
 
-	// Configure firewalls after packages (ufw may need to be installed)
-	if err := e.deployFirewalls(); err != nil {
+	// Configure firewalls after packages (ufw or nftables may need to be installed)
+	if err := e.runPhase(ctx, "firewalls", e.deployFirewalls); err != nil {
 		return fmt.Errorf("failed to deploy firewalls: %w", err)
 	}
 
+	// Set up the WireGuard overlay after firewalls (the WireGuard port must be open)
+	if err := e.runPhase(ctx, "wireguard", e.deployWireGuard); err != nil {
+		return fmt.Errorf("failed to deploy wireguard: %w", err)
+	}
+
+	// Start host-level services once the host is configured (they may need the overlay or Docker)
+	if err := e.runPhase(ctx, "systemd_units", e.deploySystemdUnits); err != nil {
+		return fmt.Errorf("failed to deploy systemd units: %w", err)
+	}
+
 	// Login to registries after Docker is available
-	if err := e.loginRegistries(); err != nil {
+	if err := e.runPhase(ctx, "registries", func() error { return e.loginRegistries(ctx) }); err != nil {
 		return fmt.Errorf("failed to login to registries: %w", err)
 	}
 
 	// Deploy networks
-	if err := e.deployNetworks(); err != nil {
+	if err := e.runPhase(ctx, "networks", e.deployNetworks); err != nil {
 		return fmt.Errorf("failed to deploy networks: %w", err)
 	}
 
 	// Deploy volumes
-	if err := e.deployVolumes(); err != nil {
+	if err := e.runPhase(ctx, "volumes", e.deployVolumes); err != nil {
 		return fmt.Errorf("failed to deploy volumes: %w", err)
 	}
 
 	// Deploy containers (respecting dependencies)
-	if err := e.deployContainers(); err != nil {
+	if err := e.runPhase(ctx, "containers", func() error { return e.deployContainers(ctx) }); err != nil {
 		return fmt.Errorf("failed to deploy containers: %w", err)
 	}
 
+	// Schedule jobs once the containers they may talk to are running
+	if err := e.runPhase(ctx, "jobs", func() error { return e.deployJobs(ctx) }); err != nil {
+		return fmt.Errorf("failed to deploy jobs: %w", err)
+	}
+
+	// Verify the deployment with the plan's own checks once everything is running
+	if err := e.runPhase(ctx, "post_deploy_checks", e.runPostDeployChecks); err != nil {
+		return err
+	}
+
+	// Post-deploy hooks only run against a verified deploy
+	if err := e.runPhase(ctx, "post_deploy_hooks", func() error { return e.runHooks(stagePostDeploy) }); err != nil {
+		return err
+	}
+
+	// Only clean up once the deploy is verified, so the files of a failed deploy stay inspectable
+	if e.plan.collectGarbage {
+		e.collectGarbage()
+	}
+
+	if e.plan.pruneImages {
+		e.pruneImages()
+	}
+
 	e.plan.logger.Info().Msg("Deployment completed successfully")
 
 	return nil
 }
 
-// deployNetworks deploys all networks in the plan.
-func (e *executor) deployNetworks() error {
-	for _, network := range e.plan.networks {
-		if err := e.deployNetwork(network); err != nil {
-			return err
-		}
+// runPhase runs one deploy phase and records its duration for the deploy metrics.
+// A phase does not start once ctx is done, e.g. when the plan's DeployTimeout expired.
+func (e *executor) runPhase(ctx context.Context, name string, run func() error) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("deployment stopped before %s: %w", name, err)
 	}
 
-	return nil
+	start := time.Now()
+	err := run()
+
+	e.metrics.phase(name, time.Since(start))
+
+	return err
+}
+
+// deployNetworks deploys all networks in the plan, concurrently per host.
+func (e *executor) deployNetworks() error {
+	return forEachPerHost(e.plan.networks, e.plan.resourceConcurrency, tracked(e, "network", e.deployNetwork))
 }
 
 // deployResource is a generic function to deploy a resource (network or volume).
 // This eliminates code duplication between deployNetwork and deployVolume.
-func (e *executor) deployResource(resource deployableResource, ops resourceOperations) error {
+// Returns the action taken (or attempted, on error) for the deploy events.
+func (e *executor) deployResource(resource deployableResource, ops resourceOperations) (Action, error) {
 	client, err := e.getSSHClient(resource.Host())
 	if err != nil {
-		return fmt.Errorf(errFailedSSHClient, resource.Host(), err)
+		return "", fmt.Errorf(errFailedSSHClient, resource.Host(), err)
 	}
 
 	// Check if resource exists
 	exists, err := ops.exists(client, resource.Name())
 	if err != nil {
-		return fmt.Errorf("%w: %w", ops.existsError, err)
+		return "", fmt.Errorf("%w: %w", ops.existsError, err)
 	}
 
+	action := ActionCreate
+
 	if exists {
+		action = ActionUpdate
+
 		// Check config hash to see if update needed
 		existingHash, err := ops.getLabel(client, resource.Name(), labelConfigSHA)
 		if err != nil {
@@ -173,7 +362,7 @@ func (e *executor) deployResource(resource deployableResource, ops resourceOpera
 		} else if existingHash == resource.ConfigHash() {
 			e.plan.logger.Info().Str(ops.resourceType, resource.Name()).Msg(ops.resourceType + " unchanged, skipping")
 
-			return nil
+			return ActionSkip, nil
 		}
 
 		// Config changed or missing, need to recreate
@@ -181,66 +370,173 @@ func (e *executor) deployResource(resource deployableResource, ops resourceOpera
 			Str(ops.resourceType, resource.Name()).
 			Msg(ops.resourceType + " config changed, recreating")
 
+		if ops.guard != nil {
+			if err := ops.guard(client, resource.Name()); err != nil {
+				return action, err
+			}
+		}
+
+		if !e.mutates() {
+			return e.planned(ops.resourceType, resource, action)
+		}
+
+		if ops.destructive {
+			if err := e.confirmDestructive("recreate " + ops.resourceType + " " + resource.Name()); err != nil {
+				return action, err
+			}
+		}
+
 		if err := ops.remove(client, resource.Name()); err != nil {
-			return fmt.Errorf("failed to remove old %s: %w", ops.resourceType, err)
+			return action, fmt.Errorf("failed to remove old %s: %w", ops.resourceType, err)
 		}
 	}
 
+	if !e.mutates() {
+		return e.planned(ops.resourceType, resource, action)
+	}
+
 	labels := map[string]string{
 		labelConfigSHA: resource.ConfigHash(),
 		labelPlan:      e.plan.name,
 	}
 
 	if err := ops.create(client, resource.Name(), resource.Driver(), labels); err != nil {
-		return fmt.Errorf("%w: %w", ops.createError, err)
+		return action, fmt.Errorf("%w: %w", ops.createError, err)
 	}
 
-	return nil
+	e.metrics.resourceChanged(ops.resourceType)
+
+	return action, nil
 }
 
 // deployNetwork deploys a single network.
-func (e *executor) deployNetwork(network *Network) error {
+func (e *executor) deployNetwork(network *Network) (Action, error) {
 	return e.deployResource(network, resourceOperations{
 		resourceType: "network",
 		exists:       e.dockerExec.NetworkExists,
 		getLabel:     e.dockerExec.GetNetworkLabel,
-		remove:       e.dockerExec.RemoveNetwork,
-		create:       e.dockerExec.CreateNetwork,
-		existsError:  ErrNetworkCheck,
-		createError:  ErrNetworkCreate,
+		remove:       e.removeNetwork,
+		create: func(client ssh.Connection, name, driver string, labels map[string]string) error {
+			return e.dockerExec.CreateNetwork(client, name, driver, labels, docker.NetworkOptions{
+				Subnet:   network.subnet,
+				Gateway:  network.gateway,
+				IPRange:  network.ipRange,
+				Internal: network.internal,
+				IPv6:     network.ipv6,
+			})
+		},
+		existsError: ErrNetworkCheck,
+		createError: ErrNetworkCreate,
 	})
 }
 
-// deployVolumes deploys all volumes in the plan.
-func (e *executor) deployVolumes() error {
-	for _, volume := range e.plan.volumes {
-		if err := e.deployVolume(volume); err != nil {
-			return err
-		}
+// removeNetwork removes a network before it is recreated, refusing while containers are attached
+// because docker would fail with a less helpful "has active endpoints" error.
+//
+//nolint:wrapcheck
+func (e *executor) removeNetwork(client ssh.Connection, name string) error {
+	attached, err := e.dockerExec.NetworkContainers(client, name)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	if len(attached) > 0 {
+		return fmt.Errorf("%w: %s is used by %s (stop or destroy them first)",
+			ErrNetworkInUse, name, strings.Join(attached, ", "))
+	}
+
+	return e.dockerExec.RemoveNetwork(client, name)
+}
+
+// deployVolumes deploys all volumes in the plan.
+func (e *executor) deployVolumes() error {
+	return forEachPerHost(e.plan.volumes, e.plan.resourceConcurrency, tracked(e, "volume", e.deployVolume))
 }
 
 // deployVolume deploys a single volume.
-func (e *executor) deployVolume(volume *Volume) error {
+func (e *executor) deployVolume(volume *Volume) (Action, error) {
 	return e.deployResource(volume, resourceOperations{
 		resourceType: "volume",
 		exists:       e.dockerExec.VolumeExists,
 		getLabel:     e.dockerExec.GetVolumeLabel,
-		remove:       e.dockerExec.RemoveVolume,
-		create:       e.dockerExec.CreateVolume,
-		existsError:  ErrVolumeCheck,
-		createError:  ErrVolumeCreate,
+		remove: func(client ssh.Connection, name string) error {
+			if volume.backup != "" {
+				if _, err := e.dockerExec.BackupVolume(client, name, volume.backup); err != nil {
+					return fmt.Errorf("%w, not recreating it: %w", ErrVolumeBackup, err)
+				}
+			}
+
+			return e.dockerExec.RemoveVolume(client, name)
+		},
+		create: func(client ssh.Connection, name, driver string, labels map[string]string) error {
+			// User labels never override hadron's own labels
+			for k, v := range volume.labels {
+				if _, ok := labels[k]; !ok {
+					labels[k] = v
+				}
+			}
+
+			return e.dockerExec.CreateVolume(client, name, driver, labels, volume.driverOpts)
+		},
+		existsError: ErrVolumeCheck,
+		createError: ErrVolumeCreate,
+		destructive: true,
+		guard: func(client ssh.Connection, _ string) error {
+			return e.guardVolumeRecreate(client, volume)
+		},
 	})
 }
 
+// guardVolumeRecreate refuses to recreate a volume that holds data unless it AllowRecreate, since
+// docker cannot change a volume's driver, options, or labels in place and recreating wipes it.
+func (e *executor) guardVolumeRecreate(client ssh.Connection, volume *Volume) error {
+	if volume.recreate {
+		return nil
+	}
+
+	hasData, err := e.dockerExec.VolumeHasData(client, volume.Name())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrVolumeCheck, err)
+	}
+
+	if !hasData {
+		return nil
+	}
+
+	e.plan.logger.Error().
+		Str("volume", volume.Name()).
+		Str("host", volume.host.String()).
+		Msg("Volume configuration changed but it contains data: migrate it manually, or set AllowRecreate to wipe it")
+
+	return fmt.Errorf("%w: %s on %s", ErrVolumeHasData, volume.Name(), volume.host)
+}
+
 // deployContainers deploys all containers in the plan, respecting dependencies.
-func (e *executor) deployContainers() error {
+func (e *executor) deployContainers(ctx context.Context) error {
 	// TODO: Implement dependency resolution and ordering
 	// For MVP, deploy in order defined in plan
-	for _, container := range e.plan.containers {
-		if err := e.deployContainer(container); err != nil {
+	deploy := tracked(e, "container", e.deployContainer)
+
+	containers, err := e.plan.selectedContainers()
+	if err != nil {
+		return err
+	}
+
+	if err := e.plan.checkForceRecreate(); err != nil {
+		return err
+	}
+
+	for _, container := range containers {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("deployment stopped before container %s: %w", container.Name(), err)
+		}
+
+		// Resolve secrets before the config hash is compared, so rotated secrets redeploy
+		if err := e.resolveSecrets(ctx, container); err != nil {
+			return err
+		}
+
+		if err := deploy(container); err != nil {
 			return err
 		}
 	}
@@ -248,49 +544,78 @@ func (e *executor) deployContainers() error {
 	return nil
 }
 
-// deployContainer deploys a single container.
-func (e *executor) deployContainer(container *Container) error {
+// deployContainer deploys a single container and returns the action taken (or attempted, on error).
+func (e *executor) deployContainer(container *Container) (Action, error) {
 	client, err := e.getSSHClient(container.host)
 	if err != nil {
-		return fmt.Errorf(errFailedSSHClient, container.host, err)
+		return "", fmt.Errorf(errFailedSSHClient, container.host, err)
 	}
 
-	// Always pull the latest image to detect updates
-	// This ensures that even if the config hash is unchanged, we redeploy if the image changed
-	e.plan.logger.Info().
-		Str("container", container.Name()).
-		Str("image", container.Image()).
-		Msg("Pulling latest image")
+	return e.applyContainer(client, container)
+}
 
-	imagePulled, err := e.dockerExec.PullImage(client, container.Image())
+// applyContainer deploys a container over an established connection and returns the action taken.
+func (e *executor) applyContainer(client ssh.Connection, container *Container) (Action, error) {
+	imagePulled, err := e.pullImage(client, container)
 	if err != nil {
-		return fmt.Errorf("failed to pull image: %w", err)
+		return "", err
 	}
 
 	// Check if container exists
 	exists, err := e.dockerExec.ContainerExists(client, container.Name())
 	if err != nil {
-		return fmt.Errorf("%w: %w", ErrContainerCheck, err)
+		return "", fmt.Errorf("%w: %w", ErrContainerCheck, err)
 	}
 
+	action := ActionCreate
+
 	if exists {
+		action = ActionUpdate
+
 		// Check config hash
 		existingHash, err := e.dockerExec.GetContainerLabel(client, container.Name(), labelConfigSHA)
 
+		drifted := false
+		if err == nil && e.plan.driftMode != "" {
+			if drifted, err = e.detectDrift(client, container, existingHash); err != nil {
+				return action, err
+			}
+		}
+
 		switch {
 		case err != nil:
 			e.plan.logger.Warn().Str("container", container.Name()).Msg("Could not get existing config hash")
+		case drifted:
+			e.plan.logger.Info().Str("container", container.Name()).Msg("Restoring drifted container to plan")
+		case e.plan.recreates(container):
+			e.plan.logger.Info().Str("container", container.Name()).Msg("Forcing container recreation")
 		case existingHash == container.ConfigHash() && !imagePulled:
 			// Config unchanged AND image wasn't updated (already had latest)
+			if e.dependencyChanged(container) {
+				if !e.mutates() {
+					e.changed[container] = true
+
+					return e.planned("container", container, ActionRestart)
+				}
+
+				return ActionRestart, e.restartForDependency(client, container)
+			}
+
 			e.plan.logger.Info().Str("container", container.Name()).Msg("Container unchanged, skipping")
 
-			return nil
+			return ActionSkip, nil
 		case imagePulled:
 			e.plan.logger.Info().
 				Str("container", container.Name()).
 				Msg("Image updated, redeploying container")
 		}
 
+		if !e.mutates() {
+			e.changed[container] = true
+
+			return e.planned("container", container, action)
+		}
+
 		// Need to update container
 		e.plan.logger.Info().Str("container", container.Name()).Msg("Container config changed, updating")
 
@@ -301,12 +626,113 @@ func (e *executor) deployContainer(container *Container) error {
 		}
 
 		if err := e.dockerExec.RemoveContainer(client, container.Name(), true); err != nil {
-			return fmt.Errorf("failed to remove old container: %w", err)
+			return action, fmt.Errorf("failed to remove old container: %w", err)
 		}
 	}
 
+	if !e.mutates() {
+		e.changed[container] = true
+
+		return e.planned("container", container, action)
+	}
+
+	// Hold back the container until required files exist (e.g., seeded volumes)
+	if err := e.waitForFiles(client, container); err != nil {
+		return action, err
+	}
+
+	volumes, err := e.prepareVolumes(client, container)
+	if err != nil {
+		return action, err
+	}
+
+	opts := e.runOptions(container, volumes)
+
+	// Run init containers to completion before starting the main container
+	for _, initContainer := range container.initContainers {
+		e.plan.logger.Info().
+			Str("container", container.Name()).
+			Str("init", initContainer.name).
+			Str("image", initContainer.image).
+			Msg("Running init container")
+
+		if err := e.dockerExec.RunInitContainer(client, initOptions(opts, initContainer)); err != nil {
+			return action, fmt.Errorf("failed to run init container for %s: %w", container.Name(), err)
+		}
+	}
+
+	if container.privileged {
+		e.plan.logger.Warn().
+			Str("container", container.Name()).
+			Str("host", container.host.String()).
+			Msg("Starting privileged container, it has full access to the host")
+	}
+
+	// Run container
+	if err := e.dockerExec.RunContainer(client, opts); err != nil {
+		return action, fmt.Errorf("failed to run container: %w", err)
+	}
+
+	if err := e.connectNetworks(client, container); err != nil {
+		return action, err
+	}
+
+	if err := e.runPostStart(client, container); err != nil {
+		// The container already carries the new config hash, so left running it would be skipped
+		// as unchanged on the next deploy and its hooks never retried
+		if rmErr := e.dockerExec.RemoveContainer(client, container.Name(), true); rmErr != nil {
+			e.plan.logger.Warn().Err(rmErr).Str("container", container.Name()).
+				Msg("Failed to remove container after its post-start commands failed")
+		}
+
+		return action, err
+	}
+
+	e.changed[container] = true
+	e.metrics.resourceChanged("container")
+
+	// TODO: Perform health check if configured
+
+	return action, nil
+}
+
+// runPostStart waits for a freshly started container to become healthy, then runs its PostStart
+// commands in it.
+func (e *executor) runPostStart(client ssh.Connection, container *Container) error {
+	if len(container.postStart) == 0 {
+		return nil
+	}
+
+	if err := e.dockerExec.WaitHealthy(client, container.Name(), postStartTimeout, healthPollInterval); err != nil {
+		return fmt.Errorf("%w: %w", ErrPostStart, err)
+	}
+
+	for _, cmd := range container.postStart {
+		e.plan.logger.Info().
+			Str("container", container.Name()).
+			Str("command", strings.Join(cmd, " ")).
+			Msg("Running post-start command")
+
+		output, err := e.dockerExec.Exec(client, container.Name(), cmd)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrPostStart, err)
+		}
+
+		e.plan.logger.Info().
+			Str("container", container.Name()).
+			Str("output", strings.TrimSpace(output)).
+			Msg("Post-start command completed")
+	}
+
+	return nil
+}
+
+// prepareVolumes returns the container's docker run volumes, uploading its file, data, and secret
+// mounts to the host first.
+func (e *executor) prepareVolumes(client ssh.Connection, container *Container) ([]docker.VolumeMount, error) {
 	// Prepare volumes - pre-allocate capacity for all volume types to avoid reallocations
-	totalCapacity := len(container.volumes) + len(container.mounts) + len(container.dataMounts)
+	totalCapacity := len(container.volumes) + len(container.mounts) +
+		len(container.dataMounts) + len(container.secretMounts)
 	volumes := make([]docker.VolumeMount, 0, totalCapacity)
 
 	// Add container volumes
@@ -326,9 +752,9 @@ func (e *executor) deployContainer(container *Container) error {
 			Str("container_path", mount.containerPath).
 			Msg("Uploading mount")
 
-		remotePath, err := e.dockerExec.UploadMount(client, mount.localPath)
+		remotePath, err := e.dockerExec.UploadMount(client, mount.localPath, container.mountOwner(mount.containerPath))
 		if err != nil {
-			return fmt.Errorf("failed to upload mount %s: %w", mount.localPath, err)
+			return nil, fmt.Errorf("failed to upload mount %s: %w", mount.localPath, err)
 		}
 
 		// Add to volumes list
@@ -353,9 +779,9 @@ func (e *executor) deployContainer(container *Container) error {
 			Str("container_path", mount.containerPath).
 			Msg("Uploading data mount")
 
-		remotePath, err := e.dockerExec.UploadDataMount(client, mount.data)
+		remotePath, err := e.dockerExec.UploadDataMount(client, mount.data, container.mountOwner(mount.containerPath))
 		if err != nil {
-			return fmt.Errorf("failed to upload data mount to %s: %w", mount.containerPath, err)
+			return nil, fmt.Errorf("failed to upload data mount to %s: %w", mount.containerPath, err)
 		}
 
 		// Add to volumes list
@@ -372,6 +798,32 @@ func (e *executor) deployContainer(container *Container) error {
 			Msg("Data mount uploaded successfully")
 	}
 
+	// Handle secret mounts - upload resolved secrets as read-only files
+	for _, mount := range container.secretMounts {
+		e.plan.logger.Info().
+			Str("container", container.Name()).
+			Str("container_path", mount.containerPath).
+			Msg("Uploading secret mount")
+
+		secret := []byte(container.resolvedSecrets[mount.reference])
+
+		remotePath, err := e.dockerExec.UploadDataMount(client, secret, container.mountOwner(mount.containerPath))
+		if err != nil {
+			return nil, fmt.Errorf("failed to upload secret mount to %s: %w", mount.containerPath, err)
+		}
+
+		volumes = append(volumes, docker.VolumeMount{
+			Source: remotePath,
+			Target: mount.containerPath,
+			Mode:   "ro",
+		})
+	}
+
+	return volumes, nil
+}
+
+// runOptions builds the docker run options for a container with already-resolved volume sources.
+func (e *executor) runOptions(container *Container, volumes []docker.VolumeMount) docker.ContainerRunOptions {
 	// Prepare labels (merge user labels with system labels)
 	labels := make(map[string]string)
 	for k, v := range container.labels {
@@ -381,6 +833,11 @@ func (e *executor) deployContainer(container *Container) error {
 	labels[labelConfigSHA] = container.ConfigHash()
 	labels[labelPlan] = e.plan.name
 
+	if container.canaryOf != "" {
+		labels[labelCanary] = container.canaryOf
+		labels[labelCanaryWeight] = strconv.Itoa(container.canaryWeight)
+	}
+
 	// Prepare run options
 	opts := docker.ContainerRunOptions{
 		Name:              container.name,
@@ -392,6 +849,8 @@ func (e *executor) deployContainer(container *Container) error {
 		CPUShares:         container.cpuShares,
 		CPUs:              container.cpus,
 		PIDsLimit:         container.pidsLimit,
+		ShmSize:           container.shmSize,
+		OOMScoreAdj:       container.oomScoreAdj,
 		Hostname:          container.hostname,
 		Network:           "",
 		NetworkAlias:      container.networkAlias,
@@ -402,42 +861,176 @@ func (e *executor) deployContainer(container *Container) error {
 		EnvFile:           container.envFile,
 		EnvVars:           container.envVars,
 		Restart:           container.restart,
+		Pull:              string(container.pullPolicy),
 		ReadOnly:          container.readOnly,
+		Privileged:        container.privileged,
 		SecurityOpts:      container.securityOpts,
 		CapDrop:           container.capDrop,
 		CapAdd:            container.capAdd,
 		GroupAdd:          container.groupAdd,
 		Labels:            labels,
+		LogDriver:         container.logDriver,
+		LogOpts:           container.logOpts,
+	}
+
+	// Merge resolved env secrets into the generated env file (never onto the command line)
+	if len(container.envSecrets) > 0 || len(container.envTemplates) > 0 {
+		envVars := make(map[string]string, len(container.envVars)+len(container.envSecrets)+len(container.envTemplates))
+		for k, v := range container.envVars {
+			envVars[k] = v
+		}
+
+		for key, reference := range container.envSecrets {
+			envVars[key] = container.resolvedSecrets[reference]
+		}
+
+		for key, template := range container.envTemplates {
+			envVars[key] = interpolateSecrets(template, func(reference string) string {
+				return container.resolvedSecrets[reference]
+			})
+		}
+
+		opts.EnvVars = envVars
+	}
+
+	// Translate the health check into docker's --health-* flags
+	if container.healthCheck != nil {
+		opts.HealthCmd = container.healthCheck.dockerCommand()
+		opts.HealthInterval = container.healthCheck.interval
+		opts.HealthTimeout = container.healthCheck.timeout
+		opts.HealthRetries = container.healthCheck.retries
 	}
 
-	// Set primary network (first network in list, or empty if none)
+	// Set primary network (first network in list, primary network moved to the front by Build)
 	if len(container.networks) > 0 {
 		opts.Network = container.networks[0].Name()
 	}
 
-	// Run container
-	if err := e.dockerExec.RunContainer(client, opts); err != nil {
-		return fmt.Errorf("failed to run container: %w", err)
+	return opts
+}
+
+// pullImage pulls the container's image and reports whether a newer image was downloaded.
+// Containers pulling only missing images (or never) skip the separate pull and let docker run use
+// what is on the host, so for them image updates are only detected through a changed image reference
+// (digest). PullAlways keeps the pull, since it is what notices a moved tag and recreates the container.
+func (e *executor) pullImage(client ssh.Connection, container *Container) (bool, error) {
+	// A dry run cannot tell whether the tag moved without pulling it
+	if container.pullPolicy == PullMissing || container.pullPolicy == PullNever || !e.mutates() {
+		return false, nil
+	}
+
+	// Always pull the latest image to detect updates
+	// This ensures that even if the config hash is unchanged, we redeploy if the image changed
+	e.plan.logger.Info().
+		Str("container", container.Name()).
+		Str("image", container.Image()).
+		Msg("Pulling latest image")
+
+	imagePulled, err := e.dockerExec.PullImage(client, container.Image())
+	if err != nil {
+		return false, fmt.Errorf("failed to pull image: %w", err)
 	}
 
-	// Connect to additional networks (if more than one network specified)
+	return imagePulled, nil
+}
+
+// connectNetworks connects a running container to its additional networks. The primary network
+// (the first one) was already joined at docker run.
+func (e *executor) connectNetworks(client ssh.Connection, container *Container) error {
 	for i := 1; i < len(container.networks); i++ {
 		networkName := container.networks[i].Name()
 		connectCmd := fmt.Sprintf("docker network connect %s %s", networkName, container.name)
 
 		e.plan.logger.Info().
-			Str("container", container.name).
-			Str("network", networkName).
-			Msg("Connecting container to additional network")
+			Str("container", container.name).
+			Str("network", networkName).
+			Msg("Connecting container to additional network")
+
+		_, stderr, err := client.Execute(connectCmd)
+		if err != nil {
+			return fmt.Errorf("%w %s: %s", errConnectToNetwork, networkName, stderr)
+		}
+	}
+
+	return nil
+}
+
+// initOptions derives the run options of an init container from its main container's options.
+// Init containers share volumes, env, and the primary network, but not ports, aliases, or restarts.
+func initOptions(opts docker.ContainerRunOptions, initContainer InitContainer) docker.ContainerRunOptions {
+	initOpts := opts
+	initOpts.Name = initContainer.name
+	initOpts.Image = initContainer.image
+	initOpts.Command = initContainer.command
+	initOpts.NetworkAlias = ""
+	initOpts.Ports = nil
+	initOpts.Restart = ""
+	initOpts.Hostname = ""
+	initOpts.HealthCmd = ""
+
+	return initOpts
+}
+
+// dependencyChanged reports whether the container asked to follow its dependencies and
+// at least one of them was redeployed earlier in this run.
+func (e *executor) dependencyChanged(container *Container) bool {
+	if !container.restartWithDeps {
+		return false
+	}
+
+	for _, dep := range container.dependsOn {
+		if e.changed[dep] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// restartForDependency restarts an otherwise unchanged container after a dependency was redeployed.
+func (e *executor) restartForDependency(client ssh.Connection, container *Container) error {
+	e.plan.logger.Info().
+		Str("container", container.Name()).
+		Msg("Dependency redeployed, restarting container")
+
+	if err := e.dockerExec.RestartContainer(client, container.Name()); err != nil {
+		return fmt.Errorf("failed to restart container %s: %w", container.Name(), err)
+	}
+
+	// Mark as changed so containers depending on this one restart too
+	e.changed[container] = true
+
+	return nil
+}
+
+// waitForFiles blocks until every file the container waits for exists on the host.
+func (e *executor) waitForFiles(client ssh.Connection, container *Container) error {
+	for _, wait := range container.waitForFiles {
+		base := wait.source
+
+		if wait.isVolume {
+			mountpoint, err := e.dockerExec.GetVolumeMountpoint(client, wait.source)
+			if err != nil {
+				return fmt.Errorf("failed to resolve volume %s: %w", wait.source, err)
+			}
+
+			base = mountpoint
+		}
+
+		filePath := path.Join(base, wait.file)
+
+		e.plan.logger.Info().
+			Str("container", container.Name()).
+			Str("source", wait.source).
+			Str("file", wait.file).
+			Dur("timeout", wait.timeout).
+			Msg("Waiting for file before starting container")
 
-		_, stderr, err := client.Execute(connectCmd)
-		if err != nil {
-			return fmt.Errorf("%w %s: %s", errConnectToNetwork, networkName, stderr)
+		if err := e.dockerExec.WaitForFile(client, filePath, wait.timeout, waitForFileInterval); err != nil {
+			return fmt.Errorf("container %s not started: %w", container.Name(), err)
 		}
 	}
 
-	// TODO: Perform health check if configured
-
 	return nil
 }
 
@@ -453,10 +1046,11 @@ func (e *executor) deployPackages() error {
 	return nil
 }
 
-// deployHostPackages manages packages for a single host (install then remove).
+// deployHostPackages manages packages for a single host (install, remove, then hold and unhold).
 func (e *executor) deployHostPackages(host *Host) error {
 	// Skip if no package operations needed
-	if len(host.packages) == 0 && len(host.removePackages) == 0 {
+	if len(host.packages) == 0 && len(host.removePackages) == 0 &&
+		len(host.holdPackages) == 0 && len(host.unholdPackages) == 0 {
 		return nil
 	}
 
@@ -466,6 +1060,11 @@ func (e *executor) deployHostPackages(host *Host) error {
 		return fmt.Errorf(errFailedSSHClient, host, err)
 	}
 
+	manager, err := e.packageManager(host)
+	if err != nil {
+		return err
+	}
+
 	// Phase 1: Install packages
 	for _, packageName := range host.packages {
 		e.plan.logger.Info().
@@ -473,7 +1072,7 @@ func (e *executor) deployHostPackages(host *Host) error {
 			Str("package", packageName).
 			Msg("Ensuring package is installed")
 
-		if err := debian.EnsureInstalled(client, packageName); err != nil {
+		if err := manager.EnsureInstalled(client, packageName); err != nil {
 			return fmt.Errorf("failed to install package %s on %s: %w", packageName, host, err)
 		}
 
@@ -490,7 +1089,7 @@ func (e *executor) deployHostPackages(host *Host) error {
 			Str("package", packageName).
 			Msg("Ensuring package is removed")
 
-		if err := debian.EnsureRemoved(client, packageName); err != nil {
+		if err := manager.EnsureRemoved(client, packageName); err != nil {
 			return fmt.Errorf("failed to remove package %s from %s: %w", packageName, host, err)
 		}
 
@@ -500,6 +1099,30 @@ func (e *executor) deployHostPackages(host *Host) error {
 			Msg("Package removed successfully")
 	}
 
+	// Phase 3: Hold packages (after installing, so the installed version is the one kept)
+	for _, packageName := range host.holdPackages {
+		e.plan.logger.Info().
+			Str("host", host.String()).
+			Str("package", packageName).
+			Msg("Ensuring package is held")
+
+		if err := manager.EnsureHeld(client, packageName); err != nil {
+			return fmt.Errorf("failed to hold package %s on %s: %w", packageName, host, err)
+		}
+	}
+
+	// Phase 4: Release holds
+	for _, packageName := range host.unholdPackages {
+		e.plan.logger.Info().
+			Str("host", host.String()).
+			Str("package", packageName).
+			Msg("Ensuring package is not held")
+
+		if err := manager.EnsureUnheld(client, packageName); err != nil {
+			return fmt.Errorf("failed to unhold package %s on %s: %w", packageName, host, err)
+		}
+	}
+
 	return nil
 }
 
@@ -532,8 +1155,8 @@ func (e *executor) deployHostDockerDaemon(host *Host) error {
 		Str("host", host.String()).
 		Msg("Configuring Docker daemon security hardening")
 
-	// Get desired configuration
-	desiredConfig := docker.GetSecureDefaults()
+	// Get desired configuration (secure defaults with host overrides folded on top)
+	desiredConfig := desiredDaemonConfig(host)
 
 	// Check if config exists
 	exists, err := docker.DaemonConfigExists(client)
@@ -541,6 +1164,15 @@ func (e *executor) deployHostDockerDaemon(host *Host) error {
 		return fmt.Errorf("failed to check daemon config on %s: %w", host, err)
 	}
 
+	// Keys hadron set last time, so the ones dropped from the plan are removed rather than kept forever
+	previousKeys, err := docker.GetManagedDaemonKeys(client)
+	if err != nil {
+		return fmt.Errorf("failed to read managed daemon keys on %s: %w", host, err)
+	}
+
+	// Start from the current config so operator-set keys (data-root, ...) survive
+	currentConfig := make(map[string]any)
+
 	var needsRestart bool
 
 	switch {
@@ -552,7 +1184,7 @@ func (e *executor) deployHostDockerDaemon(host *Host) error {
 		needsRestart = true
 	case exists:
 		// Read current config
-		currentConfig, err := docker.GetDaemonConfig(client)
+		existingConfig, err := docker.GetDaemonConfig(client)
 
 		switch {
 		case err != nil:
@@ -562,26 +1194,36 @@ func (e *executor) deployHostDockerDaemon(host *Host) error {
 				Msg("Could not read current daemon config, will overwrite")
 
 			needsRestart = true
-		case docker.ConfigsEqual(currentConfig, desiredConfig):
+		case docker.ConfigsEqual(existingConfig, desiredConfig, previousKeys):
 			e.plan.logger.Info().
 				Str("host", host.String()).
 				Msg("Docker daemon config unchanged, skipping")
 
-			return nil
+			return e.recordDaemonKeys(client, host, desiredConfig, previousKeys)
 		default:
 			e.plan.logger.Info().
 				Str("host", host.String()).
 				Msg("Docker daemon config changed, updating")
 
+			currentConfig = existingConfig
 			needsRestart = true
 		}
 	}
 
+	mergedConfig, err := docker.MergeDaemonConfig(currentConfig, desiredConfig, previousKeys)
+	if err != nil {
+		return fmt.Errorf("failed to merge daemon config on %s: %w", host, err)
+	}
+
 	// Write new config
-	if err := docker.WriteDaemonConfig(client, desiredConfig); err != nil {
+	if err := docker.WriteDaemonConfig(client, mergedConfig); err != nil {
 		return fmt.Errorf("failed to write daemon config on %s: %w", host, err)
 	}
 
+	if err := e.recordDaemonKeys(client, host, desiredConfig, previousKeys); err != nil {
+		return err
+	}
+
 	if needsRestart {
 		e.plan.logger.Info().
 			Str("host", host.String()).
@@ -611,6 +1253,61 @@ func (e *executor) deployHostDockerDaemon(host *Host) error {
 	return nil
 }
 
+// recordDaemonKeys records the daemon.json keys desired manages, unless previous already lists them.
+func (e *executor) recordDaemonKeys(
+	client ssh.Connection,
+	host *Host,
+	desired *docker.DaemonConfig,
+	previous []string,
+) error {
+	keys, err := docker.ManagedDaemonKeys(desired)
+	if err != nil {
+		return fmt.Errorf("failed to list managed daemon keys on %s: %w", host, err)
+	}
+
+	if slices.Equal(keys, previous) {
+		return nil
+	}
+
+	e.plan.logger.Debug().Str("host", host.String()).Strs("keys", keys).Msg("Recording managed daemon keys")
+
+	if err := docker.WriteManagedDaemonKeys(client, desired); err != nil {
+		return fmt.Errorf("failed to record managed daemon keys on %s: %w", host, err)
+	}
+
+	return nil
+}
+
+// desiredDaemonConfig returns the daemon configuration a hardened host should have: the secure defaults
+// with the host's overrides folded on top.
+func desiredDaemonConfig(host *Host) *docker.DaemonConfig {
+	return docker.GetSecureDefaults().Apply(daemonOverrides(host.dockerDaemon))
+}
+
+// daemonOverrides converts the host's Docker daemon configuration to docker.DaemonOverrides.
+func daemonOverrides(config *DockerDaemonConfig) docker.DaemonOverrides {
+	if config == nil {
+		return docker.DaemonOverrides{}
+	}
+
+	pools := make([]docker.AddressPool, len(config.AddressPools))
+	for i, pool := range config.AddressPools {
+		pools[i] = docker.AddressPool{
+			Base: pool.Base,
+			Size: pool.Size,
+		}
+	}
+
+	return docker.DaemonOverrides{
+		RegistryMirrors:    config.RegistryMirrors,
+		InsecureRegistries: config.InsecureRegistries,
+		DNS:                config.DNS,
+		AddressPools:       pools,
+		LogMaxSize:         config.LogMaxSize,
+		LogMaxFile:         config.LogMaxFile,
+	}
+}
+
 // deployAutoUpdates configures automatic security updates on all hosts.
 func (e *executor) deployAutoUpdates() error {
 	// Process each host's automatic updates configuration
@@ -624,19 +1321,42 @@ func (e *executor) deployAutoUpdates() error {
 }
 
 // deployHostAutoUpdates configures automatic security updates for a single host.
-// This is always enabled for all hosts - no opt-out.
+// This is enabled for every host that did not opt out with DisableAutoUpdates.
 func (e *executor) deployHostAutoUpdates(host *Host) error {
+	if host.noAutoUpdates {
+		e.plan.logger.Info().
+			Str("host", host.String()).
+			Msg("Automatic security updates disabled for host, skipping")
+
+		return nil
+	}
+
 	// Get SSH client for this host
 	client, err := e.getSSHClient(host)
 	if err != nil {
 		return fmt.Errorf(errFailedSSHClient, host, err)
 	}
 
+	// unattended-upgrades is Debian-only; dnf-automatic is not managed yet
+	info, err := e.hostOS(host)
+	if err != nil {
+		return err
+	}
+
+	if info.Family() != osrelease.FamilyDebian {
+		e.plan.logger.Warn().
+			Str("host", host.String()).
+			Str("os", info.ID).
+			Msg("Automatic security updates are only managed on Debian-family hosts, skipping")
+
+		return nil
+	}
+
 	e.plan.logger.Info().
 		Str("host", host.String()).
 		Msg("Ensuring automatic security updates are enabled")
 
-	if err := debian.EnsureAutoUpdatesEnabled(client); err != nil {
+	if err := debian.EnsureAutoUpdatesEnabled(client, autoUpdatesConfig(host.autoUpdates)); err != nil {
 		return fmt.Errorf("failed to enable automatic updates on %s: %w", host, err)
 	}
 
@@ -666,12 +1386,21 @@ func (e *executor) deployFirewalls() error {
 // deployHostFirewall configures the firewall for a single host.
 func (e *executor) deployHostFirewall(host *Host) error {
 	// Skip if no firewall configuration
-	if host.firewallConfig == nil || !host.firewallConfig.Enabled {
+	if host.firewallConfig == nil {
 		return nil
 	}
 
 	config := host.firewallConfig
 
+	backend, err := firewall.NewBackend(string(config.Backend))
+	if err != nil {
+		return fmt.Errorf("failed to configure firewall on %s: %w", host, err)
+	}
+
+	if !config.Enabled {
+		return e.disableHostFirewall(host, backend)
+	}
+
 	// Get SSH client for this host
 	client, err := e.getSSHClient(host)
 	if err != nil {
@@ -680,30 +1409,36 @@ func (e *executor) deployHostFirewall(host *Host) error {
 
 	e.plan.logger.Info().
 		Str("host", host.String()).
+		Str("backend", config.Backend.String()).
 		Msg("Configuring firewall")
 
-	// Check if ufw is installed
-	installed, err := firewall.IsInstalled(client)
+	// Check if the firewall backend (ufw or nftables) is installed
+	installed, err := backend.IsInstalled(client)
 	if err != nil {
-		return fmt.Errorf("failed to check if ufw is installed on %s: %w", host, err)
+		return fmt.Errorf("failed to check if %s is installed on %s: %w", config.Backend, host, err)
 	}
 
 	if !installed {
 		e.plan.logger.Info().
 			Str("host", host.String()).
-			Msg("ufw not installed, installing")
+			Msgf("%s not installed, installing", config.Backend)
+
+		manager, err := e.packageManager(host)
+		if err != nil {
+			return err
+		}
 
-		if err := firewall.Install(client); err != nil {
-			return fmt.Errorf("failed to install ufw on %s: %w", host, err)
+		if err := backend.Install(client, manager); err != nil {
+			return fmt.Errorf("failed to install %s on %s: %w", config.Backend, host, err)
 		}
 
 		e.plan.logger.Info().
 			Str("host", host.String()).
-			Msg("ufw installed successfully")
+			Msgf("%s installed successfully", config.Backend)
 	}
 
 	// Check current defaults
-	currentIncoming, currentOutgoing, err := firewall.GetDefaults(client)
+	currentIncoming, currentOutgoing, err := backend.GetDefaults(client)
 	if err != nil {
 		e.plan.logger.Warn().
 			Err(err).
@@ -721,13 +1456,13 @@ func (e *executor) deployHostFirewall(host *Host) error {
 			Str("outgoing", config.DefaultOutgoing).
 			Msg("Setting firewall defaults")
 
-		if err := firewall.SetDefaults(client, config.DefaultIncoming, config.DefaultOutgoing); err != nil {
+		if err := backend.SetDefaults(client, config.DefaultIncoming, config.DefaultOutgoing); err != nil {
 			return fmt.Errorf("failed to set firewall defaults on %s: %w", host, err)
 		}
 	}
 
 	// Get current rules
-	currentRules, err := firewall.GetRules(client)
+	currentRules, err := backend.GetRules(client)
 	if err != nil {
 		return fmt.Errorf("failed to get current firewall rules on %s: %w", host, err)
 	}
@@ -740,32 +1475,35 @@ func (e *executor) deployHostFirewall(host *Host) error {
 			Protocol:  rule.Protocol,
 			Comment:   rule.Comment,
 			RateLimit: rule.RateLimit,
+			Source:    rule.Source,
+			Interface: rule.Interface,
 		}
 	}
 
 	// Sync rules (add/update)
-	if err := e.syncFirewallRules(client, host, currentRules, desiredRules); err != nil {
+	if err := e.syncFirewallRules(client, backend, host, currentRules, desiredRules); err != nil {
 		return err
 	}
 
 	// Remove rules not in desired configuration
 	for _, current := range currentRules {
-		if firewall.FindRule(desiredRules, current.Port, current.Protocol) == nil {
+		if firewall.FindRule(desiredRules, current) == nil {
 			e.plan.logger.Info().
 				Str("host", host.String()).
 				Int("port", current.Port).
 				Str("protocol", current.Protocol).
+				Str("source", current.Source).
+				Str("interface", current.Interface).
 				Msg("Removing unwanted firewall rule")
 
-			if err := firewall.RemoveRule(client, current.Port, current.Protocol); err != nil {
-				return fmt.Errorf("failed to remove firewall rule %d/%s on %s: %w",
-					current.Port, current.Protocol, host, err)
+			if err := backend.RemoveRule(client, current); err != nil {
+				return fmt.Errorf("failed to remove firewall rule %s on %s: %w", current, host, err)
 			}
 		}
 	}
 
 	// Check if firewall is enabled
-	enabled, err := firewall.IsEnabled(client)
+	enabled, err := backend.IsEnabled(client)
 	if err != nil {
 		return fmt.Errorf("failed to check if firewall is enabled on %s: %w", host, err)
 	}
@@ -775,7 +1513,7 @@ func (e *executor) deployHostFirewall(host *Host) error {
 			Str("host", host.String()).
 			Msg("Enabling firewall")
 
-		if err := firewall.Enable(client); err != nil {
+		if err := backend.Enable(client); err != nil {
 			return fmt.Errorf("failed to enable firewall on %s: %w", host, err)
 		}
 	}
@@ -787,14 +1525,51 @@ func (e *executor) deployHostFirewall(host *Host) error {
 	return nil
 }
 
+// disableHostFirewall turns the firewall off on a host whose firewall is configured as disabled.
+func (e *executor) disableHostFirewall(host *Host, backend firewall.Backend) error {
+	client, err := e.getSSHClient(host)
+	if err != nil {
+		return fmt.Errorf(errFailedSSHClient, host, err)
+	}
+
+	installed, err := backend.IsInstalled(client)
+	if err != nil {
+		return fmt.Errorf("failed to check if firewall is installed on %s: %w", host, err)
+	}
+
+	if !installed {
+		return nil
+	}
+
+	enabled, err := backend.IsEnabled(client)
+	if err != nil {
+		return fmt.Errorf("failed to check if firewall is enabled on %s: %w", host, err)
+	}
+
+	if !enabled {
+		e.plan.logger.Debug().Str("host", host.String()).Msg("Firewall already disabled")
+
+		return nil
+	}
+
+	e.plan.logger.Warn().Str("host", host.String()).Msg("Disabling firewall")
+
+	if err := backend.Disable(client); err != nil {
+		return fmt.Errorf("failed to disable firewall on %s: %w", host, err)
+	}
+
+	return nil
+}
+
 // syncFirewallRules adds or updates firewall rules to match desired state.
 func (e *executor) syncFirewallRules(
 	client ssh.Connection,
+	backend firewall.Backend,
 	host *Host,
 	currentRules, desiredRules []firewall.Rule,
 ) error {
 	for _, desired := range desiredRules {
-		existing := firewall.FindRule(currentRules, desired.Port, desired.Protocol)
+		existing := firewall.FindRule(currentRules, desired)
 
 		switch {
 		case existing == nil:
@@ -802,13 +1577,14 @@ func (e *executor) syncFirewallRules(
 				Str("host", host.String()).
 				Int("port", desired.Port).
 				Str("protocol", desired.Protocol).
+				Str("source", desired.Source).
+				Str("interface", desired.Interface).
 				Str("comment", desired.Comment).
 				Bool("rate_limit", desired.RateLimit).
 				Msg("Adding firewall rule")
 
-			if err := firewall.AddRule(client, desired); err != nil {
-				return fmt.Errorf("failed to add firewall rule %d/%s on %s: %w",
-					desired.Port, desired.Protocol, host, err)
+			if err := backend.AddRule(client, desired); err != nil {
+				return fmt.Errorf("failed to add firewall rule %s on %s: %w", desired, host, err)
 			}
 		case !firewall.RulesEqual(*existing, desired):
 			// Rule exists but differs (e.g., rate limit changed)
@@ -819,15 +1595,13 @@ func (e *executor) syncFirewallRules(
 				Msg("Firewall rule changed, recreating")
 
 			// Remove old rule
-			if err := firewall.RemoveRule(client, existing.Port, existing.Protocol); err != nil {
-				return fmt.Errorf("failed to remove old firewall rule %d/%s on %s: %w",
-					existing.Port, existing.Protocol, host, err)
+			if err := backend.RemoveRule(client, *existing); err != nil {
+				return fmt.Errorf("failed to remove old firewall rule %s on %s: %w", existing, host, err)
 			}
 
 			// Add new rule
-			if err := firewall.AddRule(client, desired); err != nil {
-				return fmt.Errorf("failed to add firewall rule %d/%s on %s: %w",
-					desired.Port, desired.Protocol, host, err)
+			if err := backend.AddRule(client, desired); err != nil {
+				return fmt.Errorf("failed to add firewall rule %s on %s: %w", desired, host, err)
 			}
 		default:
 			e.plan.logger.Debug().
@@ -842,10 +1616,10 @@ func (e *executor) syncFirewallRules(
 }
 
 // loginRegistries logs into Docker registries on all hosts.
-func (e *executor) loginRegistries() error {
+func (e *executor) loginRegistries(ctx context.Context) error {
 	// Process each host's registry credentials
 	for _, host := range e.plan.hosts {
-		if err := e.loginHostRegistries(host); err != nil {
+		if err := e.loginHostRegistries(ctx, host); err != nil {
 			return err
 		}
 	}
@@ -854,7 +1628,7 @@ func (e *executor) loginRegistries() error {
 }
 
 // loginHostRegistries logs into registries for a single host.
-func (e *executor) loginHostRegistries(host *Host) error {
+func (e *executor) loginHostRegistries(ctx context.Context, host *Host) error {
 	// Skip if no registries configured
 	if len(host.registries) == 0 {
 		return nil
@@ -874,7 +1648,16 @@ func (e *executor) loginHostRegistries(host *Host) error {
 			Str("username", registry.Username).
 			Msg("Logging into registry")
 
-		if err := e.dockerExec.RegistryLogin(client, registry.Registry, registry.Username, registry.Password); err != nil {
+		password := registry.Password
+
+		if registry.SecretRef != "" {
+			password, err = e.plan.secrets.GetSecret(ctx, registry.SecretRef)
+			if err != nil {
+				return fmt.Errorf("failed to resolve password for registry %s on %s: %w", registry.Registry, host, err)
+			}
+		}
+
+		if err := e.dockerExec.RegistryLogin(client, registry.Registry, registry.Username, password); err != nil {
 			return fmt.Errorf("failed to login to registry %s on %s: %w", registry.Registry, host, err)
 		}
 
@@ -968,3 +1751,215 @@ func (e *executor) deployHostSSHHardening(host *Host) error {
 
 	return nil
 }
+
+// destroy removes all plan resources in reverse order (containers, volumes, networks)
+// and logs out of registries so no credentials are left behind on the hosts.
+func (e *executor) destroy(ctx context.Context) error {
+	defer func() {
+		if err := e.sshPool.CloseAll(); err != nil {
+			e.plan.logger.Warn().Err(err).Msg("Failed to close SSH connections")
+		}
+	}()
+
+	if err := e.runHooks(stagePreDestroy); err != nil {
+		return err
+	}
+
+	// Remove jobs first, so no scheduled run starts while their dependencies are removed
+	for _, job := range e.plan.jobs {
+		if err := destroyStopped(ctx, job.Name()); err != nil {
+			return err
+		}
+
+		if err := e.destroyJob(job); err != nil {
+			return err
+		}
+	}
+
+	// Remove containers in reverse order so dependents go before their dependencies
+	for i := len(e.plan.containers) - 1; i >= 0; i-- {
+		if err := destroyStopped(ctx, e.plan.containers[i].Name()); err != nil {
+			return err
+		}
+
+		if err := e.destroyContainer(e.plan.containers[i]); err != nil {
+			return err
+		}
+	}
+
+	for _, volume := range e.plan.volumes {
+		if err := destroyStopped(ctx, volume.Name()); err != nil {
+			return err
+		}
+
+		if err := e.destroyVolume(volume); err != nil {
+			return err
+		}
+	}
+
+	for _, network := range e.plan.networks {
+		if err := destroyStopped(ctx, network.Name()); err != nil {
+			return err
+		}
+
+		if err := e.destroyNetwork(network); err != nil {
+			return err
+		}
+	}
+
+	for _, unit := range e.plan.systemdUnits {
+		if err := destroyStopped(ctx, unit.Name()); err != nil {
+			return err
+		}
+
+		if err := e.destroySystemdUnit(unit); err != nil {
+			return err
+		}
+	}
+
+	if err := e.logoutRegistries(); err != nil {
+		return fmt.Errorf("failed to logout from registries: %w", err)
+	}
+
+	// The plan's containers are gone, so their uploaded files are orphaned unless shared
+	e.collectGarbage()
+
+	if err := e.runHooks(stagePostDestroy); err != nil {
+		return err
+	}
+
+	e.plan.logger.Info().Msg("Destroy completed successfully")
+
+	return nil
+}
+
+// destroyStopped returns an error once ctx is done, e.g. when the plan's DeployTimeout expired, so
+// destroy does not start removing the named resource.
+func destroyStopped(ctx context.Context, name string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("destroy stopped before %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// destroyContainer removes a container if it exists.
+func (e *executor) destroyContainer(container *Container) error {
+	client, err := e.getSSHClient(container.host)
+	if err != nil {
+		return fmt.Errorf(errFailedSSHClient, container.host, err)
+	}
+
+	return e.teardownContainer(client, container)
+}
+
+// teardownContainer removes a container over an established connection, if it exists.
+func (e *executor) teardownContainer(client ssh.Connection, container *Container) error {
+	exists, err := e.dockerExec.ContainerExists(client, container.Name())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrContainerCheck, err)
+	}
+
+	if !exists {
+		e.plan.logger.Info().Str("container", container.Name()).Msg("Container not found, skipping")
+
+		return nil
+	}
+
+	if err := e.dockerExec.RemoveContainer(client, container.Name(), true); err != nil {
+		return fmt.Errorf("failed to remove container %s: %w", container.Name(), err)
+	}
+
+	return nil
+}
+
+// destroyVolume removes a volume if it exists.
+func (e *executor) destroyVolume(volume *Volume) error {
+	client, err := e.getSSHClient(volume.host)
+	if err != nil {
+		return fmt.Errorf(errFailedSSHClient, volume.host, err)
+	}
+
+	return e.teardownVolume(client, volume)
+}
+
+// teardownVolume removes a volume over an established connection, if it exists.
+func (e *executor) teardownVolume(client ssh.Connection, volume *Volume) error {
+	exists, err := e.dockerExec.VolumeExists(client, volume.Name())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrVolumeCheck, err)
+	}
+
+	if !exists {
+		e.plan.logger.Info().Str("volume", volume.Name()).Msg("Volume not found, skipping")
+
+		return nil
+	}
+
+	if err := e.dockerExec.RemoveVolume(client, volume.Name()); err != nil {
+		return fmt.Errorf("failed to remove volume %s: %w", volume.Name(), err)
+	}
+
+	return nil
+}
+
+// destroyNetwork removes a network if it exists.
+func (e *executor) destroyNetwork(network *Network) error {
+	client, err := e.getSSHClient(network.host)
+	if err != nil {
+		return fmt.Errorf(errFailedSSHClient, network.host, err)
+	}
+
+	return e.teardownNetwork(client, network)
+}
+
+// teardownNetwork removes a network over an established connection, if it exists.
+func (e *executor) teardownNetwork(client ssh.Connection, network *Network) error {
+	exists, err := e.dockerExec.NetworkExists(client, network.Name())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrNetworkCheck, err)
+	}
+
+	if !exists {
+		e.plan.logger.Info().Str("network", network.Name()).Msg("Network not found, skipping")
+
+		return nil
+	}
+
+	if err := e.dockerExec.RemoveNetwork(client, network.Name()); err != nil {
+		return fmt.Errorf("failed to remove network %s: %w", network.Name(), err)
+	}
+
+	return nil
+}
+
+// logoutRegistries removes cached registry credentials from every host.
+func (e *executor) logoutRegistries() error {
+	for _, host := range e.plan.hosts {
+		if len(host.registries) == 0 {
+			continue
+		}
+
+		client, err := e.getSSHClient(host)
+		if err != nil {
+			return fmt.Errorf(errFailedSSHClient, host, err)
+		}
+
+		if err := e.logoutHostRegistries(client, host); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// logoutHostRegistries removes a host's cached registry credentials over an established connection.
+func (e *executor) logoutHostRegistries(client ssh.Connection, host *Host) error {
+	for _, registry := range host.registries {
+		if err := e.dockerExec.RegistryLogout(client, registry.Registry); err != nil {
+			return fmt.Errorf("failed to logout from registry %s on %s: %w", registry.Registry, host, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,558 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/the-agent-c-ai/hadron/internal/testutil"
+	"github.com/the-agent-c-ai/hadron/sdk/ssh"
+)
+
+func TestRestartOnDependencyChange(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop())
+	host := plan.Host("user@192.168.1.1").Build()
+
+	newContainer := func(name string) *ContainerBuilder {
+		return plan.Container(name).
+			Host(host).
+			Image("nginx:latest").
+			Memory("256m").
+			CPUShares(512).
+			CPUs("0.5").
+			PIDsLimit(100)
+	}
+
+	database := newContainer("database").Build()
+
+	app := newContainer("app").
+		DependsOn(database).
+		RestartOnDependencyChange().
+		Build()
+
+	worker := newContainer("worker").
+		DependsOn(database).
+		Build()
+
+	exec := newExecutor(plan)
+
+	if exec.dependencyChanged(app) {
+		t.Fatal("expected no restart before the dependency was redeployed")
+	}
+
+	// Simulate the database being recreated because its image was updated
+	exec.changed[database] = true
+
+	if exec.dependencyChanged(worker) {
+		t.Error("expected worker without RestartOnDependencyChange not to restart")
+	}
+
+	if !exec.dependencyChanged(app) {
+		t.Fatal("expected app to restart after its dependency was redeployed")
+	}
+
+	client := &testutil.FakeConnection{}
+
+	if err := exec.restartForDependency(client, app); err != nil {
+		t.Fatalf("expected restart to succeed, got: %v", err)
+	}
+
+	if len(client.Commands) != 1 || client.Commands[0] != "docker restart app" {
+		t.Errorf("expected 'docker restart app', got %v", client.Commands)
+	}
+
+	if !exec.changed[app] {
+		t.Error("expected restarted container to be marked as changed for its own dependents")
+	}
+}
+
+func TestPrimaryNetwork(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop())
+	host := plan.Host("user@192.168.1.1").Build()
+
+	frontend := plan.Network("frontend").Host(host).Build()
+	backend := plan.Network("backend").Host(host).Build()
+	monitoring := plan.Network("monitoring").Host(host).Build()
+
+	app := plan.Container("app").
+		Host(host).
+		Image("nginx:latest").
+		Memory("256m").
+		CPUShares(512).
+		CPUs("0.5").
+		PIDsLimit(100).
+		Network(frontend).
+		Network(backend).
+		Network(monitoring).
+		PrimaryNetwork(backend).
+		Build()
+
+	exec := newExecutor(plan)
+
+	if opts := exec.runOptions(app, nil); opts.Network != "backend" {
+		t.Errorf("expected docker run on primary network 'backend', got %q", opts.Network)
+	}
+
+	client := &testutil.FakeConnection{}
+
+	if err := exec.connectNetworks(client, app); err != nil {
+		t.Fatalf("expected network connect to succeed, got: %v", err)
+	}
+
+	want := []string{
+		"docker network connect frontend app",
+		"docker network connect monitoring app",
+	}
+	if !slices.Equal(client.Commands, want) {
+		t.Errorf("expected %v, got %v", want, client.Commands)
+	}
+}
+
+func TestDisableAutoUpdates(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop())
+	host := plan.Host("user@192.168.1.1").DisableAutoUpdates().Build()
+
+	// An opted-out host is skipped before any SSH connection is made
+	if err := newExecutor(plan).deployHostAutoUpdates(host); err != nil {
+		t.Errorf("expected host without automatic updates to be skipped, got: %v", err)
+	}
+}
+
+func TestPostDeployCheck(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop())
+	host := plan.Host("user@192.168.1.1").Build()
+
+	plan.PostDeployCheck(host, "curl", "-fsS", "http://localhost/health").
+		PostDeployCheck(host, "test $(redis-cli llen jobs) -lt 1000")
+
+	exec := newExecutor(plan)
+	client := &testutil.FakeConnection{Failing: []string{"test $(redis-cli llen jobs) -lt 1000"}}
+
+	if err := exec.runPostDeployCheck(client, plan.postDeployChecks[0]); err != nil {
+		t.Errorf("expected passing check to succeed, got: %v", err)
+	}
+
+	err := exec.runPostDeployCheck(client, plan.postDeployChecks[1])
+	if !errors.Is(err, ErrPostDeployCheck) {
+		t.Fatalf("expected ErrPostDeployCheck, got: %v", err)
+	}
+
+	if !strings.Contains(err.Error(), "check failed") {
+		t.Errorf("expected error to include the check's stderr, got: %v", err)
+	}
+
+	want := []string{"'curl' '-fsS' 'http://localhost/health'", "test $(redis-cli llen jobs) -lt 1000"}
+	if !slices.Equal(client.Commands, want) {
+		t.Errorf("expected %v, got %v", want, client.Commands)
+	}
+}
+
+func TestPullImagePolicies(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop())
+	host := plan.Host("user@192.168.1.1").Build()
+
+	for policy, pulls := range map[PullPolicy]bool{
+		"":          true,
+		PullAlways:  true, // the separate pull is what notices a moved tag
+		PullMissing: false,
+		PullNever:   false,
+	} {
+		builder := plan.Container("app-" + string(policy)).
+			Host(host).
+			Image("nginx@sha256:0123456789abcdef").
+			Memory("256m").
+			CPUShares(512).
+			CPUs("0.5").
+			PIDsLimit(100)
+
+		if policy != "" {
+			builder.PullPolicy(policy)
+		}
+
+		client := &testutil.FakeConnection{Respond: func(string) string {
+			return "Status: Downloaded newer image for nginx\n"
+		}}
+
+		pulled, err := newExecutor(plan).pullImage(client, builder.Build())
+		if err != nil {
+			t.Fatalf("%q: expected pull to succeed, got: %v", policy, err)
+		}
+
+		if pulled != pulls || (len(client.Commands) == 1) != pulls {
+			t.Errorf("%q: expected pull %v, got %v (commands %v)", policy, pulls, pulled, client.Commands)
+		}
+	}
+}
+
+func TestWaitForFiles(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop())
+	host := plan.Host("user@192.168.1.1").Build()
+	data := plan.Volume("data").Host(host).Build()
+
+	container := plan.Container("app").
+		Host(host).
+		Image("nginx:1").
+		Memory("256m").
+		CPUShares(512).
+		CPUs("0.5").
+		PIDsLimit(100).
+		WaitForVolumeFile(data, ".restored").
+		WaitForHostFile("/srv/seed", "dump done.sql").
+		Build()
+
+	client := &testutil.FakeConnection{Respond: func(command string) string {
+		if strings.HasPrefix(command, "docker volume inspect") {
+			return "/var/lib/docker/volumes/data/_data\n"
+		}
+
+		return "exists\n"
+	}}
+
+	if err := newExecutor(plan).waitForFiles(client, container); err != nil {
+		t.Fatalf("expected waits to succeed, got: %v", err)
+	}
+
+	want := []string{
+		"docker volume inspect -f '{{.Mountpoint}}' data",
+		"sudo test -e '/var/lib/docker/volumes/data/_data/.restored' && echo exists || echo missing",
+		"sudo test -e '/srv/seed/dump done.sql' && echo exists || echo missing",
+	}
+	if !slices.Equal(client.Commands, want) {
+		t.Errorf("expected %v, got %v", want, client.Commands)
+	}
+}
+
+func TestPostStart(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop())
+	host := plan.Host("user@192.168.1.1").Build()
+
+	newContainer := func(cmds ...[]string) *Container {
+		builder := NewPlan("test").WithLogger(zerolog.Nop()).Container("app").
+			Host(host).
+			Image("app:1").
+			Memory("256m").
+			CPUShares(512).
+			CPUs("0.5").
+			PIDsLimit(100)
+
+		for _, cmd := range cmds {
+			builder = builder.PostStart(cmd...)
+		}
+
+		return builder.Build()
+	}
+
+	// Post-start commands are part of the configuration, so changing them redeploys the container
+	migrate := newContainer([]string{"migrate", "up"})
+	if migrate.ConfigHash() == newContainer().ConfigHash() {
+		t.Error("expected PostStart to change the config hash")
+	}
+
+	container := newContainer([]string{"migrate", "up"}, []string{"seed", "--if-empty"})
+
+	client := &testutil.FakeConnection{
+		Failing: []string{"docker exec -i app 'seed' '--if-empty'"},
+		Respond: func(command string) string {
+			if strings.HasPrefix(command, "docker container inspect -f") {
+				return "running healthy"
+			}
+
+			return ""
+		},
+	}
+
+	err := newExecutor(plan).runPostStart(client, container)
+	if !errors.Is(err, ErrPostStart) {
+		t.Fatalf("expected ErrPostStart, got: %v", err)
+	}
+
+	want := []string{
+		"docker container inspect -f '{{.State.Status}} {{if .State.Health}}{{.State.Health.Status}}{{end}}' app",
+		"docker exec -i app 'migrate' 'up'",
+		"docker exec -i app 'seed' '--if-empty'",
+	}
+	if !slices.Equal(client.Commands, want) {
+		t.Errorf("expected %v, got %v", want, client.Commands)
+	}
+}
+
+func TestCanary(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop())
+	host := plan.Host("user@192.168.1.1").Build()
+
+	newContainer := func(image string) *ContainerBuilder {
+		return plan.Container("app").
+			Host(host).
+			Image(image).
+			Memory("256m").
+			CPUShares(512).
+			CPUs("0.5").
+			PIDsLimit(100).
+			NetworkAlias("app")
+	}
+
+	stable := newContainer("ghcr.io/org/app:v1").Build()
+	canary := newContainer("ghcr.io/org/app:v2").Canary(10).Build()
+
+	if stable.Name() != "app" || canary.Name() != "app-canary" {
+		t.Fatalf("expected containers 'app' and 'app-canary', got %q and %q", stable.Name(), canary.Name())
+	}
+
+	if canary.CanaryOf() != "app" || canary.CanaryWeight() != 10 {
+		t.Errorf("expected canary of 'app' with weight 10, got %q/%d", canary.CanaryOf(), canary.CanaryWeight())
+	}
+
+	exec := newExecutor(plan)
+
+	labels := exec.runOptions(canary, nil).Labels
+	if labels[labelCanary] != "app" || labels[labelCanaryWeight] != "10" {
+		t.Errorf("expected canary labels, got %v", labels)
+	}
+
+	if _, ok := exec.runOptions(stable, nil).Labels[labelCanary]; ok {
+		t.Error("expected stable container without canary label")
+	}
+
+	if exec.runOptions(canary, nil).NetworkAlias != "app" {
+		t.Error("expected canary to share the service's network alias")
+	}
+
+	for _, weight := range []int{0, 101} {
+		if _, err := newContainer("ghcr.io/org/app:v2").Canary(weight).BuildE(); !errors.Is(err, ErrInvalidContainer) {
+			t.Errorf("expected ErrInvalidContainer for canary weight %d, got: %v", weight, err)
+		}
+	}
+}
+
+func TestRunPhaseStopsAfterDeadline(t *testing.T) {
+	t.Parallel()
+
+	exec := newExecutor(NewPlan("test").WithLogger(zerolog.Nop()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+
+	ran := false
+
+	err := exec.runPhase(ctx, "containers", func() error {
+		ran = true
+
+		return nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got: %v", err)
+	}
+
+	if ran {
+		t.Error("expected the phase not to start after the deadline")
+	}
+}
+
+func TestGuardVolumeRecreate(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop())
+	host := plan.Host("user@192.168.1.1").Build()
+
+	withData := func(data string) *testutil.FakeConnection {
+		return &testutil.FakeConnection{Respond: func(command string) string {
+			if strings.HasPrefix(command, "docker volume inspect") {
+				return "/var/lib/docker/volumes/pgdata/_data\n"
+			}
+
+			return data
+		}}
+	}
+
+	exec := newExecutor(plan)
+	volume := plan.Volume("pgdata").Host(host).Build()
+
+	// An empty volume can be recreated
+	if err := exec.guardVolumeRecreate(withData(""), volume); err != nil {
+		t.Errorf("expected an empty volume to be recreatable, got: %v", err)
+	}
+
+	// A volume holding data needs a manual migration
+	client := withData("/var/lib/docker/volumes/pgdata/_data/PG_VERSION\n")
+
+	err := exec.guardVolumeRecreate(client, volume)
+	if !errors.Is(err, ErrVolumeHasData) {
+		t.Fatalf("expected ErrVolumeHasData, got: %v", err)
+	}
+
+	if want := "sudo find /var/lib/docker/volumes/pgdata/_data -mindepth 1 -print -quit"; client.Commands[1] != want {
+		t.Errorf("expected %q, got %q", want, client.Commands[1])
+	}
+
+	// Unless it explicitly allows being recreated, without even looking
+	allowed := plan.Volume("cache").Host(host).AllowRecreate().Build()
+	client = withData("/var/lib/docker/volumes/cache/_data/entry\n")
+
+	if err := exec.guardVolumeRecreate(client, allowed); err != nil || len(client.Commands) != 0 {
+		t.Errorf("expected AllowRecreate to skip the data check, got %v (err: %v)", client.Commands, err)
+	}
+}
+
+func TestConnectionHint(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop())
+	host := plan.Host("deploy@10.0.0.1").Build()
+
+	unknown := fmt.Errorf("failed to connect to %s: %w", host, ssh.ErrHostNotInKnownHosts)
+	if hint := connectionHint(host, unknown); !strings.Contains(hint, "ssh-keyscan -H 10.0.0.1") {
+		t.Errorf("expected an ssh-keyscan hint, got %q", hint)
+	}
+
+	if hint := connectionHint(host, testutil.ErrExitStatus); hint != "" {
+		t.Errorf("expected no hint for an unrelated error, got %q", hint)
+	}
+}
+
+func TestPostStartFailureRetriedOnRedeploy(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop())
+	host := plan.Host("user@192.168.1.1").Build()
+
+	container := plan.Container("app").
+		Host(host).
+		Image("app:1").
+		Memory("256m").
+		CPUShares(512).
+		CPUs("0.5").
+		PIDsLimit(100).
+		PostStart("migrate", "up").
+		Build()
+
+	// The fake host tracks whether the container exists, so the second deploy sees what the first left
+	running := false
+	client := &testutil.FakeConnection{
+		Failing: []string{"docker exec -i app 'migrate' 'up'"},
+		Respond: func(command string) string {
+			switch {
+			case strings.HasPrefix(command, "docker run "):
+				running = true
+			case command == "docker rm app -f":
+				running = false
+			case strings.HasPrefix(command, "docker container inspect app "):
+				if running {
+					return "exists"
+				}
+
+				return "missing"
+			case strings.HasPrefix(command, "docker container inspect -f '{{index .Config.Labels"):
+				return container.ConfigHash()
+			case strings.HasPrefix(command, "docker container inspect -f '{{.State.Status}}"):
+				return "running healthy"
+			}
+
+			return ""
+		},
+	}
+
+	exec := newExecutor(plan)
+
+	if _, err := exec.applyContainer(client, container); !errors.Is(err, ErrPostStart) {
+		t.Fatalf("expected ErrPostStart, got: %v", err)
+	}
+
+	if running {
+		t.Fatal("expected the container to be removed after its post-start command failed")
+	}
+
+	// Once the command succeeds, the next deploy starts the container again and reruns it
+	client.Failing = nil
+	client.Commands = nil
+
+	action, err := exec.applyContainer(client, container)
+	if err != nil || action != ActionCreate {
+		t.Fatalf("expected the container to be recreated, got %q (err: %v)", action, err)
+	}
+
+	if !slices.Contains(client.Commands, "docker exec -i app 'migrate' 'up'") {
+		t.Errorf("expected the post-start command to be retried, got %v", client.Commands)
+	}
+}
+
+func TestDestroyResources(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop())
+	host := plan.Host("user@192.168.1.1").Registry("ghcr.io", "deploy", "token").Build()
+	network := plan.Network("app-net").Host(host).Build()
+	volume := plan.Volume("app-data").Host(host).Build()
+	container := plan.Container("app").
+		Host(host).
+		Image("app:1").
+		Memory("256m").
+		CPUShares(512).
+		CPUs("0.5").
+		PIDsLimit(100).
+		Build()
+
+	exec := newExecutor(plan)
+
+	// Existing resources are removed and the host's registry credentials dropped
+	client := &testutil.FakeConnection{Respond: func(string) string { return "exists" }}
+
+	if err := exec.teardownContainer(client, container); err != nil {
+		t.Fatalf("expected container removal to succeed, got: %v", err)
+	}
+
+	if err := exec.teardownVolume(client, volume); err != nil {
+		t.Fatalf("expected volume removal to succeed, got: %v", err)
+	}
+
+	if err := exec.teardownNetwork(client, network); err != nil {
+		t.Fatalf("expected network removal to succeed, got: %v", err)
+	}
+
+	if err := exec.logoutHostRegistries(client, host); err != nil {
+		t.Fatalf("expected registry logout to succeed, got: %v", err)
+	}
+
+	for _, want := range []string{
+		"docker rm app -f", "docker volume rm app-data", "docker network rm app-net", "docker logout 'ghcr.io'",
+	} {
+		if !slices.Contains(client.Commands, want) {
+			t.Errorf("expected %q, got %v", want, client.Commands)
+		}
+	}
+
+	// Missing resources are skipped without removing anything
+	missing := &testutil.FakeConnection{Respond: func(string) string { return "missing" }}
+
+	if err := exec.teardownContainer(missing, container); err != nil {
+		t.Fatalf("expected a missing container to be skipped, got: %v", err)
+	}
+
+	if err := exec.teardownVolume(missing, volume); err != nil {
+		t.Fatalf("expected a missing volume to be skipped, got: %v", err)
+	}
+
+	if len(missing.Commands) != 2 {
+		t.Errorf("expected only existence checks, got %v", missing.Commands)
+	}
+}
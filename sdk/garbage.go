@@ -0,0 +1,104 @@
+package sdk
+
+import (
+	"fmt"
+	"os"
+	"slices"
+)
+
+// envCollectGarbage is set to "true" by the hadron CLI when deploy is run with --gc.
+const envCollectGarbage = "HADRON_GC"
+
+// WithGarbageCollection removes uploaded env files, mounts, and secrets that no container on the
+// host mounts anymore after a successful Execute. Destroy always collects garbage. Defaults to the
+// CLI's --gc flag.
+func (p *Plan) WithGarbageCollection() *Plan {
+	p.collectGarbage = true
+
+	return p
+}
+
+// WithImagePrune removes dangling images (old layers left behind by pulls of newer ones) from every
+// host running plan containers after a successful Execute. An optional age (a docker duration such
+// as "72h") only prunes images older than that, keeping recent ones for a quick rollback.
+func (p *Plan) WithImagePrune(olderThan ...string) *Plan {
+	p.pruneImages = true
+
+	if len(olderThan) > 0 {
+		p.pruneOlderThan = olderThan[0]
+	}
+
+	return p
+}
+
+// pruneImages prunes dangling images on every host running plan containers, keeping the images of
+// all plan containers and jobs on it. Failures are logged rather than returned, like garbage collection.
+func (e *executor) pruneImages() {
+	for _, host := range e.containerHosts() {
+		client, err := e.getSSHClient(host)
+		if err != nil {
+			e.plan.logger.Warn().Err(fmt.Errorf(errFailedSSHClient, host, err)).Msg("Skipping image prune")
+
+			continue
+		}
+
+		var keep []string
+
+		for _, container := range slices.Concat(e.plan.all().containers, e.plan.all().jobs) {
+			if container.host == host && !slices.Contains(keep, container.image) {
+				keep = append(keep, container.image)
+			}
+		}
+
+		reclaimed, err := e.dockerExec.PruneImages(client, keep, e.plan.pruneOlderThan)
+		if err != nil {
+			e.plan.logger.Warn().Err(err).Str("host", host.String()).Msg("Failed to prune images")
+
+			continue
+		}
+
+		e.plan.logger.Info().Str("host", host.String()).Str("reclaimed", reclaimed).Msg("Image prune complete")
+	}
+}
+
+// collectGarbage removes orphaned uploaded files from every host running plan containers.
+// Failures are logged rather than returned: leftover files waste disk but break nothing.
+func (e *executor) collectGarbage() {
+	for _, host := range e.containerHosts() {
+		client, err := e.getSSHClient(host)
+		if err != nil {
+			e.plan.logger.Warn().Err(fmt.Errorf(errFailedSSHClient, host, err)).Msg("Skipping garbage collection")
+
+			continue
+		}
+
+		removed, err := e.dockerExec.CollectGarbage(client)
+		if err != nil {
+			e.plan.logger.Warn().Err(err).Str("host", host.String()).Msg("Failed to collect garbage")
+
+			continue
+		}
+
+		e.plan.logger.Info().Str("host", host.String()).Int("removed", len(removed)).Msg("Garbage collection complete")
+	}
+}
+
+// containerHosts returns the hosts running at least one plan container or job, in plan order.
+func (e *executor) containerHosts() []*Host {
+	seen := make(map[*Host]bool)
+	hosts := make([]*Host, 0, len(e.plan.hosts))
+
+	for _, container := range slices.Concat(e.plan.containers, e.plan.jobs) {
+		if !seen[container.host] {
+			seen[container.host] = true
+			hosts = append(hosts, container.host)
+		}
+	}
+
+	return hosts
+}
+
+// collectGarbageFromEnv enables garbage collection when the CLI was invoked with --gc.
+func collectGarbageFromEnv() bool {
+	return os.Getenv(envCollectGarbage) == "true"
+}
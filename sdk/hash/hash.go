@@ -8,24 +8,19 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
 )
 
 // File computes the SHA256 hash of a file.
 func File(path string) (string, error) {
-	//nolint:gosec // Path is from user config, not user input
-	file, err := os.Open(path)
+	sum, err := fileSum(path)
 	if err != nil {
-		return "", fmt.Errorf("%w: %w", ErrFileOpen, err)
+		return "", err
 	}
 
-	defer func() { _ = file.Close() }()
-
-	hash := sha256.New()
-	if _, err := io.Copy(hash, file); err != nil {
-		return "", fmt.Errorf("%w: %w", ErrFileRead, err)
-	}
-
-	return hex.EncodeToString(hash.Sum(nil)), nil
+	return hex.EncodeToString(sum), nil
 }
 
 // Path computes the SHA256 hash of a file or directory.
@@ -46,12 +41,65 @@ func Path(path string) (string, error) {
 	return Directory(path)
 }
 
-// Directory recursively hashes a directory tree.
+// maxDirectoryWorkers bounds how many files Directory hashes at once, and so how many it holds open.
+const maxDirectoryWorkers = 8
+
+// treeEntry is a file or directory below the root Directory hashes.
+type treeEntry struct {
+	relPath string
+	path    string
+	isDir   bool
+	sum     []byte // content hash, files only
+}
+
+// Directory recursively hashes a directory tree, leaving out paths excluded by its IgnoreFile. Files
+// are hashed concurrently, then their hashes are folded into the tree hash together with every path,
+// sorted by relative path, so the result does not depend on which file finished first.
+//
+// The hash ends up in container config hashes, so changing this format recreates every container
+// that mounts a directory on the next deploy; TestDirectoryFormat pins it.
 func Directory(dirPath string) (string, error) {
+	entries, err := tree(dirPath)
+	if err != nil {
+		return "", err
+	}
+
 	hash := sha256.New()
 
-	//nolint:gosec // Path is from user config, not user input
-	err := filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
+	for _, entry := range entries {
+		// The NUL separator keeps a path from running into the next file's hash
+		hash.Write([]byte(entry.relPath))
+		hash.Write([]byte{0})
+		hash.Write(entry.sum)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Files returns the hex SHA256 of every file below dirPath that Directory hashes, keyed by its
+// slash-separated path relative to dirPath.
+func Files(dirPath string) (map[string]string, error) {
+	entries, err := tree(dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	sums := make(map[string]string, len(entries))
+
+	for _, entry := range entries {
+		if !entry.isDir {
+			sums[entry.relPath] = hex.EncodeToString(entry.sum)
+		}
+	}
+
+	return sums, nil
+}
+
+// tree walks dirPath and hashes its files, returning the entries sorted by relative path.
+func tree(dirPath string) ([]*treeEntry, error) {
+	var entries []*treeEntry
+
+	err := Walk(dirPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -62,31 +110,75 @@ func Directory(dirPath string) (string, error) {
 			return fmt.Errorf("%w: %w", ErrPathRelative, err)
 		}
 
-		// Write path to hash (for directory structure)
-		if _, err := hash.Write([]byte(relPath)); err != nil {
-			return fmt.Errorf("failed to write path to hash: %w", err)
+		entries = append(entries, &treeEntry{relPath: filepath.ToSlash(relPath), path: path, isDir: info.IsDir()})
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDirectoryWalk, err)
+	}
+
+	if err := hashFiles(entries); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDirectoryWalk, err)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].relPath < entries[j].relPath
+	})
+
+	return entries, nil
+}
+
+// hashFiles computes the content hash of every file entry, at most maxDirectoryWorkers at a time.
+// The first failing entry in walk order is returned, so errors are as stable as the hash.
+func hashFiles(entries []*treeEntry) error {
+	errs := make([]error, len(entries))
+	semaphore := make(chan struct{}, min(runtime.GOMAXPROCS(0), maxDirectoryWorkers))
+
+	var wg sync.WaitGroup
+
+	for i, entry := range entries {
+		if entry.isDir {
+			continue
 		}
 
-		// If it's a file, hash its content
-		if !info.IsDir() {
-			//nolint:gosec // Path is from user config, not user input
-			file, err := os.Open(path)
-			if err != nil {
-				return fmt.Errorf("%w: %w", ErrFileOpen, err)
-			}
+		semaphore <- struct{}{}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			entry.sum, errs[i] = fileSum(entry.path)
+		}()
+	}
 
-			defer func() { _ = file.Close() }()
+	wg.Wait()
 
-			if _, err := io.Copy(hash, file); err != nil {
-				return fmt.Errorf("%w: %w", ErrFileRead, err)
-			}
+	for _, err := range errs {
+		if err != nil {
+			return err
 		}
+	}
 
-		return nil
-	})
+	return nil
+}
+
+// fileSum returns the raw SHA256 of a file's content.
+func fileSum(path string) ([]byte, error) {
+	//nolint:gosec // Path is from user config, not user input
+	file, err := os.Open(path)
 	if err != nil {
-		return "", fmt.Errorf("%w: %w", ErrDirectoryWalk, err)
+		return nil, fmt.Errorf("%w: %w", ErrFileOpen, err)
 	}
 
-	return hex.EncodeToString(hash.Sum(nil)), nil
+	defer func() { _ = file.Close() }()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFileRead, err)
+	}
+
+	return hash.Sum(nil), nil
 }
@@ -0,0 +1,117 @@
+package hash_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/the-agent-c-ai/hadron/sdk/hash"
+)
+
+// writeTree creates a directory tree with enough files to keep every hashing worker busy.
+func writeTree(t *testing.T) string {
+	t.Helper()
+
+	root := t.TempDir()
+
+	for i := range 200 {
+		path := filepath.Join(root, fmt.Sprintf("dir-%d", i%7), fmt.Sprintf("file-%03d.txt", i))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(fmt.Sprintf("content %d\n", i)), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return root
+}
+
+func TestDirectoryIsDeterministic(t *testing.T) {
+	t.Parallel()
+
+	root := writeTree(t)
+
+	want, err := hash.Directory(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range 50 {
+		got, err := hash.Directory(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got != want {
+			t.Fatalf("expected a stable hash %s, got %s", want, got)
+		}
+	}
+}
+
+func TestDirectoryDetectsChanges(t *testing.T) {
+	t.Parallel()
+
+	root := writeTree(t)
+
+	before, err := hash.Directory(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := filepath.Join(root, "dir-3", "file-010.txt")
+
+	if err := os.WriteFile(file, []byte("changed\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	changed, err := hash.Directory(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if changed == before {
+		t.Error("expected a content change to change the hash")
+	}
+
+	if err := os.Rename(file, filepath.Join(root, "dir-3", "renamed.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	renamed, err := hash.Directory(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if renamed == changed {
+		t.Error("expected a rename to change the hash")
+	}
+}
+
+func TestDirectoryFormat(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+
+	if err := os.MkdirAll(filepath.Join(root, "conf.d"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	for path, content := range map[string]string{"index.html": "<h1>hello</h1>\n", "conf.d/site.conf": "listen 80;\n"} {
+		if err := os.WriteFile(filepath.Join(root, path), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := hash.Directory(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A new value recreates every container mounting a directory once; document it in the README first
+	if want := "9373a76815ffe5680b4619d77dfeae08168282ae2b74806e2dc66728834023f3"; got != want {
+		t.Errorf("expected the directory hash format to be unchanged, got %s", got)
+	}
+}
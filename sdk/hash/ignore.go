@@ -0,0 +1,148 @@
+package hash
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFile lists paths, relative to the directory holding it, that Walk skips: one pattern per line,
+// in .dockerignore syntax. Blank lines and lines starting with # are ignored, "*" and "?" match within
+// one path segment, "**" matches any number of segments, and a leading "!" re-includes paths an
+// earlier pattern excluded. Excluding a directory excludes everything below it.
+const IgnoreFile = ".hadronignore"
+
+// ignorePattern is one parsed IgnoreFile line.
+type ignorePattern struct {
+	segments []string
+	negate   bool
+}
+
+// ignoreRules are the patterns of one IgnoreFile; the last pattern matching a path decides.
+type ignoreRules struct {
+	patterns  []ignorePattern
+	negations bool // some pattern re-includes paths, so excluded directories must still be walked
+}
+
+// Walk walks root like filepath.Walk, leaving out the paths root's IgnoreFile excludes. Directory and
+// mount uploads both walk through it, so a mount's hash always covers exactly the uploaded files.
+func Walk(root string, fn filepath.WalkFunc) error {
+	rules, err := loadIgnore(root)
+	if err != nil {
+		return err
+	}
+
+	//nolint:gosec // Path is from user config, not user input
+	return filepath.Walk(root, func(walkPath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return fn(walkPath, info, err)
+		}
+
+		relPath, err := filepath.Rel(root, walkPath)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrPathRelative, err)
+		}
+
+		if relPath != "." && rules.excludes(filepath.ToSlash(relPath)) {
+			if info.IsDir() && !rules.negations {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		return fn(walkPath, info, nil)
+	})
+}
+
+// loadIgnore reads the IgnoreFile in dir. A missing file excludes nothing.
+func loadIgnore(dir string) (*ignoreRules, error) {
+	rules := &ignoreRules{}
+
+	//nolint:gosec // Path is from user config, not user input
+	file, err := os.Open(filepath.Join(dir, IgnoreFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return rules, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFileOpen, err)
+	}
+
+	defer func() { _ = file.Close() }()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		pattern := ignorePattern{}
+		if rest, ok := strings.CutPrefix(line, "!"); ok {
+			pattern.negate = true
+			rules.negations = true
+			line = rest
+		}
+
+		line = strings.Trim(path.Clean(strings.TrimPrefix(line, "/")), "/")
+		pattern.segments = strings.Split(line, "/")
+		rules.patterns = append(rules.patterns, pattern)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrFileRead, IgnoreFile, err)
+	}
+
+	return rules, nil
+}
+
+// excludes reports whether a slash-separated relative path is excluded, itself or through a parent.
+func (r *ignoreRules) excludes(relPath string) bool {
+	segments := strings.Split(relPath, "/")
+	excluded := false
+
+	for _, pattern := range r.patterns {
+		for i := 1; i <= len(segments); i++ {
+			if matchSegments(pattern.segments, segments[:i]) {
+				excluded = !pattern.negate
+
+				break
+			}
+		}
+	}
+
+	return excluded
+}
+
+// matchSegments matches path segments against pattern segments, "**" standing for any number of them.
+func matchSegments(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := range len(segments) + 1 {
+				if matchSegments(pattern[1:], segments[i:]) {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		if len(segments) == 0 {
+			return false
+		}
+
+		if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+			return false
+		}
+
+		pattern, segments = pattern[1:], segments[1:]
+	}
+
+	return len(segments) == 0
+}
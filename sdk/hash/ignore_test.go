@@ -0,0 +1,90 @@
+package hash_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/the-agent-c-ai/hadron/sdk/hash"
+)
+
+// writeFiles creates the given files, with their path as content, below root.
+func writeFiles(t *testing.T, root string, files ...string) {
+	t.Helper()
+
+	for _, file := range files {
+		path := filepath.Join(root, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestWalkRespectsIgnoreFile(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	writeFiles(t, root,
+		"index.html", "assets/app.js", "assets/app.js.map", ".git/HEAD",
+		"node_modules/lib/index.js", "node_modules/keep/LICENSE", "docs/a/b/notes.tmp")
+
+	ignore := "# local junk\n.git\nnode_modules\n!node_modules/keep\n**/*.map\ndocs/**/*.tmp\n"
+	if err := os.WriteFile(filepath.Join(root, hash.IgnoreFile), []byte(ignore), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var files []string
+
+	err := hash.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() {
+			rel, _ := filepath.Rel(root, path)
+			files = append(files, filepath.ToSlash(rel))
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{hash.IgnoreFile, "assets/app.js", "index.html", "node_modules/keep/LICENSE"}
+	if !slices.Equal(files, want) {
+		t.Errorf("expected %v, got %v", want, files)
+	}
+}
+
+func TestDirectoryIgnoresExcludedChanges(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	writeFiles(t, root, "index.html", ".git/HEAD")
+
+	if err := os.WriteFile(filepath.Join(root, hash.IgnoreFile), []byte(".git\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	before, err := hash.Directory(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeFiles(t, root, ".git/ORIG_HEAD")
+
+	after, err := hash.Directory(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if after != before {
+		t.Error("expected changes to excluded paths to leave the hash unchanged")
+	}
+}
@@ -2,6 +2,7 @@ package sdk
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -17,6 +18,7 @@ type HealthCheck struct {
 	path      string   // for HTTP checks
 	port      int      // for HTTP and TCP checks
 	command   []string // for command checks
+	service   string   // for gRPC checks (empty checks overall server health)
 	timeout   time.Duration
 	interval  time.Duration
 	retries   int
@@ -32,6 +34,8 @@ const (
 	HealthCheckTCP HealthCheckType = "tcp"
 	// HealthCheckCommand executes a command inside the container.
 	HealthCheckCommand HealthCheckType = "command"
+	// HealthCheckGRPC queries the standard gRPC health service with grpc_health_probe.
+	HealthCheckGRPC HealthCheckType = "grpc"
 )
 
 // HTTPCheck creates an HTTP health check.
@@ -71,6 +75,25 @@ func CommandCheck(command string, args ...string) *HealthCheck {
 	}
 }
 
+// GRPCCheck creates a gRPC health check using the standard grpc.health.v1 protocol.
+// The image must ship grpc_health_probe (or grpc-health-probe). An optional service name
+// checks that specific service instead of overall server health.
+func GRPCCheck(port int, service ...string) *HealthCheck {
+	check := &HealthCheck{
+		checkType: HealthCheckGRPC,
+		port:      port,
+		timeout:   defaultHealthCheckTimeout,
+		interval:  defaultHealthCheckInterval,
+		retries:   defaultHealthCheckRetries,
+	}
+
+	if len(service) > 0 {
+		check.service = service[0]
+	}
+
+	return check
+}
+
 // WithTimeout sets the total timeout for the health check.
 func (hc *HealthCheck) WithTimeout(timeout time.Duration) *HealthCheck {
 	hc.timeout = timeout
@@ -92,6 +115,31 @@ func (hc *HealthCheck) WithRetries(retries int) *HealthCheck {
 	return hc
 }
 
+// Type returns the kind of health check.
+func (hc *HealthCheck) Type() HealthCheckType {
+	return hc.checkType
+}
+
+// Path returns the request path of an HTTP check.
+func (hc *HealthCheck) Path() string {
+	return hc.path
+}
+
+// Port returns the port probed by HTTP, TCP, and gRPC checks.
+func (hc *HealthCheck) Port() int {
+	return hc.port
+}
+
+// Timeout returns how long a single probe may take.
+func (hc *HealthCheck) Timeout() time.Duration {
+	return hc.timeout
+}
+
+// Interval returns the time between probes.
+func (hc *HealthCheck) Interval() time.Duration {
+	return hc.interval
+}
+
 // String returns a string representation of the health check.
 func (hc *HealthCheck) String() string {
 	switch hc.checkType {
@@ -101,7 +149,39 @@ func (hc *HealthCheck) String() string {
 		return fmt.Sprintf("TCP %s:%d", "localhost", hc.port)
 	case HealthCheckCommand:
 		return fmt.Sprintf("Command: %v", hc.command)
+	case HealthCheckGRPC:
+		if hc.service != "" {
+			return fmt.Sprintf("gRPC %s:%d/%s", "localhost", hc.port, hc.service)
+		}
+
+		return fmt.Sprintf("gRPC %s:%d", "localhost", hc.port)
 	default:
 		return "unknown"
 	}
 }
+
+// dockerCommand returns the shell command docker runs inside the container for --health-cmd.
+func (hc *HealthCheck) dockerCommand() string {
+	switch hc.checkType {
+	case HealthCheckHTTP:
+		url := fmt.Sprintf("http://localhost:%d%s", hc.port, hc.path)
+
+		return fmt.Sprintf("wget -q --spider %s || curl -fsS -o /dev/null %s", url, url)
+	case HealthCheckTCP:
+		return fmt.Sprintf("nc -z localhost %d", hc.port)
+	case HealthCheckCommand:
+		return strings.Join(hc.command, " ")
+	case HealthCheckGRPC:
+		args := fmt.Sprintf("-addr=localhost:%d", hc.port)
+		if hc.service != "" {
+			args += " -service=" + hc.service
+		}
+
+		return fmt.Sprintf(
+			"if command -v grpc-health-probe >/dev/null 2>&1; then grpc-health-probe %s; else grpc_health_probe %s; fi",
+			args, args,
+		)
+	default:
+		return ""
+	}
+}
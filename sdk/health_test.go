@@ -11,6 +11,7 @@ import (
 const (
 	testHTTPPort                     = 8080
 	testMySQLPort                    = 3306
+	testGRPCPort                     = 50051
 	errMsgExpectedHealthCheckCreated = "expected health check to be created"
 )
 
@@ -60,6 +61,26 @@ func TestCommandCheck(t *testing.T) {
 	}
 }
 
+func TestGRPCCheck(t *testing.T) {
+	t.Parallel()
+
+	check := sdk.GRPCCheck(testGRPCPort)
+
+	if check == nil {
+		t.Fatal(errMsgExpectedHealthCheckCreated)
+	}
+
+	str := check.String()
+	if !strings.Contains(str, "gRPC") || !strings.Contains(str, "50051") {
+		t.Errorf("expected health check string to contain gRPC and port, got: %s", str)
+	}
+
+	withService := sdk.GRPCCheck(testGRPCPort, "orders.v1.Orders").String()
+	if !strings.Contains(withService, "orders.v1.Orders") {
+		t.Errorf("expected health check string to contain service name, got: %s", withService)
+	}
+}
+
 func TestHealthCheckWithTimeout(t *testing.T) {
 	t.Parallel()
 
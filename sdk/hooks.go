@@ -0,0 +1,126 @@
+package sdk
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/the-agent-c-ai/hadron/internal/docker"
+	"github.com/the-agent-c-ai/hadron/sdk/ssh"
+)
+
+// hookStage is the point of a deploy or destroy a hook runs at.
+type hookStage string
+
+const (
+	stagePreDeploy   hookStage = "pre-deploy"
+	stagePostDeploy  hookStage = "post-deploy"
+	stagePreDestroy  hookStage = "pre-destroy"
+	stagePostDestroy hookStage = "post-destroy"
+
+	// stagePostDeployCheck runs post-deploy checks, before the post-deploy hooks (see PostDeployCheck).
+	stagePostDeployCheck hookStage = "post-deploy-check"
+)
+
+// hook is a command run on a host at a fixed stage of a deploy or destroy.
+type hook struct {
+	stage   hookStage
+	host    *Host
+	command []string
+}
+
+// String returns the shell command run on the host: a single argument is a shell command line, so
+// pipes and substitutions work, while several are quoted so each reaches the program unchanged.
+func (h hook) String() string {
+	if len(h.command) == 1 {
+		return h.command[0]
+	}
+
+	return docker.ShellCommand(h.command...)
+}
+
+// Host returns the host the command runs on.
+func (h hook) Host() *Host {
+	return h.host
+}
+
+// PreDeploy runs a command on the host before anything on it changes, once every host passed the
+// OS preflight check, e.g. to drain it from a load balancer:
+//
+//	plan.PreDeploy(web, "curl", "-fsS", "-X", "POST", "http://lb.internal/drain/web")
+//
+// Hooks of a stage run in the order they were added. A single argument is run as a shell command
+// line; several are passed to the program as they are. A non-zero exit fails the deploy with ErrHook.
+func (p *Plan) PreDeploy(host *Host, cmd ...string) *Plan {
+	return p.addHook(stagePreDeploy, host, cmd)
+}
+
+// PostDeploy runs a command on the host after everything is deployed and the post-deploy checks
+// passed, e.g. to warm a cache or add the host back to a load balancer.
+func (p *Plan) PostDeploy(host *Host, cmd ...string) *Plan {
+	return p.addHook(stagePostDeploy, host, cmd)
+}
+
+// PreDestroy runs a command on the host before Destroy removes anything.
+func (p *Plan) PreDestroy(host *Host, cmd ...string) *Plan {
+	return p.addHook(stagePreDestroy, host, cmd)
+}
+
+// PostDestroy runs a command on the host after Destroy removed the plan's resources.
+func (p *Plan) PostDestroy(host *Host, cmd ...string) *Plan {
+	return p.addHook(stagePostDestroy, host, cmd)
+}
+
+// addHook registers a hook for a stage.
+func (p *Plan) addHook(stage hookStage, host *Host, cmd []string) *Plan {
+	if host == nil || len(cmd) == 0 {
+		p.logger.Fatal().Str("stage", string(stage)).Msg("hook requires a host and a command")
+	}
+
+	p.hooks = append(p.hooks, hook{stage: stage, host: host, command: cmd})
+
+	return p
+}
+
+// runHooks runs the plan's hooks for a stage, stopping at the first failure.
+func (e *executor) runHooks(stage hookStage) error {
+	for _, h := range e.plan.hooks {
+		if h.stage != stage {
+			continue
+		}
+
+		client, err := e.getSSHClient(h.host)
+		if err != nil {
+			return fmt.Errorf(errFailedSSHClient, h.host, err)
+		}
+
+		if err := e.runHook(client, h); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runHook runs a single hook on its host and logs its output.
+func (e *executor) runHook(client ssh.Connection, h hook) error {
+	e.plan.logger.Info().
+		Str("host", h.host.String()).
+		Str("stage", string(h.stage)).
+		Str("command", h.String()).
+		Msg("Running hook")
+
+	stdout, stderr, err := client.Execute(h.String())
+	if err != nil {
+		return fmt.Errorf("%w: %s on %s: %s: %w (stdout: %s, stderr: %s)",
+			ErrHook, h.stage, h.host, h, err, strings.TrimSpace(stdout), strings.TrimSpace(stderr))
+	}
+
+	e.plan.logger.Info().
+		Str("host", h.host.String()).
+		Str("stage", string(h.stage)).
+		Str("command", h.String()).
+		Str("output", strings.TrimSpace(stdout)).
+		Msg("Hook completed")
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package sdk
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/the-agent-c-ai/hadron/internal/testutil"
+)
+
+func TestHooks(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop())
+	host := plan.Host("user@192.168.1.1").Build()
+
+	plan.PreDeploy(host, "curl", "-fsS", "-d", "reason=it's a deploy", "http://lb.internal/drain").
+		PostDeploy(host, "warm-cache").
+		PreDestroy(host, "test -f /etc/maintenance")
+
+	stages := make([]hookStage, 0, len(plan.hooks))
+	for _, h := range plan.hooks {
+		stages = append(stages, h.stage)
+	}
+
+	if want := []hookStage{stagePreDeploy, stagePostDeploy, stagePreDestroy}; !slices.Equal(stages, want) {
+		t.Fatalf("expected stages %v, got %v", want, stages)
+	}
+
+	exec := newExecutor(plan)
+	client := &testutil.FakeConnection{Failing: []string{"test -f /etc/maintenance"}}
+
+	if err := exec.runHook(client, plan.hooks[0]); err != nil {
+		t.Errorf("expected passing hook to succeed, got: %v", err)
+	}
+
+	err := exec.runHook(client, plan.hooks[2])
+	if !errors.Is(err, ErrHook) {
+		t.Fatalf("expected ErrHook, got: %v", err)
+	}
+
+	if !strings.Contains(err.Error(), "pre-destroy") {
+		t.Errorf("expected error to name the stage, got: %v", err)
+	}
+
+	// Several arguments reach the program unchanged; a single one is a shell command line
+	want := []string{
+		`'curl' '-fsS' '-d' 'reason=it'\''s a deploy' 'http://lb.internal/drain'`,
+		"test -f /etc/maintenance",
+	}
+	if !slices.Equal(client.Commands, want) {
+		t.Errorf("expected %v, got %v", want, client.Commands)
+	}
+}
@@ -1,10 +1,25 @@
 package sdk
 
+import (
+	"net"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/the-agent-c-ai/hadron/internal/wireguard"
+	"github.com/the-agent-c-ai/hadron/sdk/ssh"
+)
+
+// workDirPattern restricts work directories to paths that need no quoting in remote commands.
+var workDirPattern = regexp.MustCompile(`^/[A-Za-z0-9._/-]+$`)
+
 // RegistryCredential represents credentials for a Docker registry.
 type RegistryCredential struct {
-	Registry string
-	Username string
-	Password string
+	Registry  string
+	Username  string
+	Password  string
+	SecretRef string // 1Password reference ("op://vault/item/field") resolved at execute time
 }
 
 // FirewallRule represents a single firewall rule.
@@ -13,10 +28,29 @@ type FirewallRule struct {
 	Protocol  string // "tcp" or "udp"
 	Comment   string
 	RateLimit bool
+	Source    string // source IP or CIDR allowed to connect (empty: anywhere)
+	Interface string // incoming network interface (empty: all interfaces)
+}
+
+// FirewallBackend selects the tool that manages a host's firewall.
+type FirewallBackend string
+
+const (
+	// FirewallUFW manages the firewall with ufw (default).
+	FirewallUFW FirewallBackend = "ufw"
+	// FirewallNftables manages the firewall with a dedicated nftables table ("inet hadron"), for minimal
+	// images without ufw or hosts already using nftables directly.
+	FirewallNftables FirewallBackend = "nftables"
+)
+
+// String returns the backend name.
+func (b FirewallBackend) String() string {
+	return string(b)
 }
 
 // FirewallConfig represents firewall configuration for a host.
 type FirewallConfig struct {
+	Backend         FirewallBackend
 	Enabled         bool
 	DefaultIncoming string // "deny" or "allow"
 	DefaultOutgoing string // "deny" or "allow"
@@ -28,13 +62,26 @@ type Host struct {
 	endpoint       string
 	packages       []string
 	removePackages []string
+	holdPackages   []string
+	unholdPackages []string
 	registries     []RegistryCredential
 	firewallConfig *FirewallConfig
 	hardenDocker   bool
+	dockerDaemon   *DockerDaemonConfig
+	autoUpdates    *AutoUpdatesConfig
+	noAutoUpdates  bool
 	hardenOS       bool
 	hardenSSH      bool
+	nodeExporter   bool
 	sshFingerprint string
 	sshKeyContent  string
+	trustOnFirst   bool     // record an unknown host key in known_hosts, see TrustOnFirstUse
+	hostKeyAlgos   []string // host key algorithms accepted besides Ed25519, see AllowHostKeyAlgorithms
+	sudoPassword   string   // password sudo asks for, see SudoPassword
+	sudoSecretRef  string   // secret reference resolving to the sudo password, see SudoPasswordFromSecret
+	workDir        string   // remote directory for uploads and staged files, see WorkDir
+	address        string
+	wireGuard      string // overlay address with prefix (e.g., "10.100.0.1/24"), empty if not on the overlay
 	plan           *Plan
 }
 
@@ -44,13 +91,26 @@ type HostBuilder struct {
 	endpoint       string
 	packages       []string
 	removePackages []string
+	holdPackages   []string
+	unholdPackages []string
 	registries     []RegistryCredential
 	firewallConfig *FirewallConfig
 	hardenDocker   bool
+	dockerDaemon   *DockerDaemonConfig
+	autoUpdates    *AutoUpdatesConfig
+	noAutoUpdates  bool
 	hardenOS       bool
 	hardenSSH      bool
+	nodeExporter   bool
 	sshFingerprint string
 	sshKeyContent  string
+	trustOnFirst   bool     // record an unknown host key in known_hosts, see TrustOnFirstUse
+	hostKeyAlgos   []string // host key algorithms accepted besides Ed25519, see AllowHostKeyAlgorithms
+	sudoPassword   string   // password sudo asks for, see SudoPassword
+	sudoSecretRef  string   // secret reference resolving to the sudo password, see SudoPasswordFromSecret
+	workDir        string   // remote directory for uploads and staged files, see WorkDir
+	address        string
+	wireGuard      string
 }
 
 // FirewallBuilder builds firewall configuration with a fluent API.
@@ -73,6 +133,22 @@ func (hb *HostBuilder) RemovePackage(name string) *HostBuilder {
 	return hb
 }
 
+// HoldPackage holds a Debian package at its installed version (apt-mark hold), so neither manual
+// upgrades nor unattended-upgrades touch it. Use it for packages such as the kernel or a pinned Docker
+// version whose upgrade would restart workloads.
+func (hb *HostBuilder) HoldPackage(name string) *HostBuilder {
+	hb.holdPackages = append(hb.holdPackages, name)
+
+	return hb
+}
+
+// UnholdPackage releases a hold placed with HoldPackage, so the package is upgraded normally again.
+func (hb *HostBuilder) UnholdPackage(name string) *HostBuilder {
+	hb.unholdPackages = append(hb.unholdPackages, name)
+
+	return hb
+}
+
 // Registry adds Docker registry credentials for this host.
 func (hb *HostBuilder) Registry(registry, username, password string) *HostBuilder {
 	hb.registries = append(hb.registries, RegistryCredential{
@@ -84,6 +160,19 @@ func (hb *HostBuilder) Registry(registry, username, password string) *HostBuilde
 	return hb
 }
 
+// RegistryFromSecret adds Docker registry credentials whose password is a 1Password secret reference
+// ("op://vault/item/field"). The reference is resolved with GetSecret when the plan executes, not when
+// it is built, so the secret is held in memory no longer than needed.
+func (hb *HostBuilder) RegistryFromSecret(registry, username, secretRef string) *HostBuilder {
+	hb.registries = append(hb.registries, RegistryCredential{
+		Registry:  registry,
+		Username:  username,
+		SecretRef: secretRef,
+	})
+
+	return hb
+}
+
 // HardenDocker enables Docker daemon security hardening.
 // Applies recommended security settings from deploy-security.md:
 // - live-restore: true (containers survive daemon restarts)
@@ -91,6 +180,9 @@ func (hb *HostBuilder) Registry(registry, username, password string) *HostBuilde
 // - no-new-privileges: true (prevents privilege escalation)
 // - icc: false (containers can't talk unless explicitly networked)
 // - log-driver limits (prevents disk exhaustion).
+//
+// Use DockerDaemon() to adjust settings (registry mirrors, log rotation, address pools) on top of these defaults,
+// or the LogMaxSize and LogMaxFile shorthands for log rotation.
 func (hb *HostBuilder) HardenDocker() *HostBuilder {
 	hb.hardenDocker = true
 
@@ -168,6 +260,100 @@ func (hb *HostBuilder) SSHKey(keyContent string) *HostBuilder {
 	return hb
 }
 
+// TrustOnFirstUse accepts the host key of a host missing from ~/.ssh/known_hosts and records it there,
+// for ephemeral hosts (e.g. CI runners) where neither a pinned Fingerprint nor a pre-seeded known_hosts is
+// practical. A key that differs from a recorded one still fails the connection with ssh.ErrHostKeyMismatch,
+// so only the very first connection is unverified. Accepted keys are logged as warnings with their
+// fingerprint; Fingerprint takes precedence when both are set.
+func (hb *HostBuilder) TrustOnFirstUse() *HostBuilder {
+	hb.trustOnFirst = true
+
+	return hb
+}
+
+// AllowHostKeyAlgorithms accepts host keys of the given algorithms in addition to Ed25519, for hosts that
+// only present RSA or ECDSA host keys (e.g. "rsa-sha2-512", "ecdsa-sha2-nistp256"). Ed25519 stays the
+// only accepted algorithm by default; adding one to the host (ssh-keygen -A) is the better fix.
+func (hb *HostBuilder) AllowHostKeyAlgorithms(algorithms ...string) *HostBuilder {
+	for _, algorithm := range algorithms {
+		if !slices.Contains(ssh.SupportedHostKeyAlgorithms(), algorithm) {
+			hb.plan.logger.Fatal().
+				Str("host", hb.endpoint).
+				Str("algorithm", algorithm).
+				Strs("supported", ssh.SupportedHostKeyAlgorithms()).
+				Msg("unsupported host key algorithm")
+		}
+	}
+
+	hb.hostKeyAlgos = append(hb.hostKeyAlgos, algorithms...)
+
+	return hb
+}
+
+// SudoPassword sets the password sudo asks for on hosts without passwordless sudo. Every command Hadron
+// runs with sudo then receives it through stdin (sudo -S), never on the command line. Prefer
+// SudoPasswordFromSecret, so the password is not part of the plan.
+func (hb *HostBuilder) SudoPassword(password string) *HostBuilder {
+	if strings.ContainsAny(password, "\r\n") {
+		hb.plan.logger.Fatal().Str("host", hb.endpoint).Msg("sudo password must not contain line breaks")
+	}
+
+	hb.sudoPassword = password
+
+	return hb
+}
+
+// SudoPasswordFromSecret is SudoPassword with a secret reference (e.g., "op://vault/item/field"),
+// resolved with the plan's secret provider when Hadron first connects to the host.
+func (hb *HostBuilder) SudoPasswordFromSecret(secretRef string) *HostBuilder {
+	hb.sudoSecretRef = secretRef
+
+	return hb
+}
+
+// WorkDir sets the directory Hadron keeps uploaded env files, mounts, secrets, job scripts, and volume
+// backups in on the host (default /var/lib/hadron), and stages configuration files in (below its tmp
+// subdirectory, instead of /tmp). Use it on hosts where /var/lib is restricted or /tmp is read-only.
+// The directory is created, owned by the SSH user, when Hadron first connects.
+func (hb *HostBuilder) WorkDir(dir string) *HostBuilder {
+	if !workDirPattern.MatchString(dir) || path.Clean(dir) != dir {
+		hb.plan.logger.Fatal().
+			Str("host", hb.endpoint).
+			Str("dir", dir).
+			Msg("work directory must be a clean absolute path of letters, digits, '.', '_', '-', and '/'")
+	}
+
+	hb.workDir = dir
+
+	return hb
+}
+
+// Address sets the IP address other hosts in the plan use to reach this host.
+// Containers depending on a container on this host get an automatic --add-host entry
+// mapping the dependency's network alias (or name) to this address.
+// If not set, the endpoint is used when it is an IP address.
+func (hb *HostBuilder) Address(ip string) *HostBuilder {
+	hb.address = ip
+
+	return hb
+}
+
+// WireGuard puts this host on a WireGuard overlay with the plan's other WireGuard hosts, using the given
+// overlay address with prefix (e.g., "10.100.0.1/24"). Hadron installs WireGuard, generates a key on
+// the host (the private key never leaves it), and peers every WireGuard host with every other one,
+// reaching them at their Address. Cross-host dependencies between overlay hosts resolve to overlay
+// addresses, and the WireGuard port is opened in the host's firewall.
+func (hb *HostBuilder) WireGuard(address string) *HostBuilder {
+	ip, _, err := net.ParseCIDR(address)
+	if err != nil || ip.To4() == nil {
+		hb.plan.logger.Fatal().Str("host", hb.endpoint).Str("address", address).Msg("invalid WireGuard address CIDR")
+	}
+
+	hb.wireGuard = address
+
+	return hb
+}
+
 const (
 	// Standard service ports.
 	portSSH   = 22
@@ -181,8 +367,19 @@ const (
 
 // Firewall starts firewall configuration with defaults.
 // Default: deny incoming, allow outgoing, SSH (22) allowed with rate limiting.
-func (hb *HostBuilder) Firewall() *FirewallBuilder {
+// An optional backend selects ufw (default) or nftables.
+func (hb *HostBuilder) Firewall(backend ...FirewallBackend) *FirewallBuilder {
+	selected := FirewallUFW
+	if len(backend) > 0 {
+		selected = backend[0]
+	}
+
+	if selected != FirewallUFW && selected != FirewallNftables {
+		hb.plan.logger.Fatal().Str("host", hb.endpoint).Str("backend", string(selected)).Msg("unknown firewall backend")
+	}
+
 	config := &FirewallConfig{
+		Backend:         selected,
 		Enabled:         true,
 		DefaultIncoming: "deny",
 		DefaultOutgoing: "allow",
@@ -224,6 +421,15 @@ func (fb *FirewallBuilder) DefaultOutgoing(policy string) *FirewallBuilder {
 	return fb
 }
 
+// Disabled turns the host's firewall off: ufw is disabled on deploy instead of being configured.
+// The rules on the host are left in place (and apply again if the firewall is re-enabled), and no
+// SSH safety rule is added since nothing is being filtered.
+func (fb *FirewallBuilder) Disabled() *FirewallBuilder {
+	fb.config.Enabled = false
+
+	return fb
+}
+
 // ClearDefaultRules removes the default SSH/HTTP/HTTPS rules.
 // Useful if you want full control over rules.
 func (fb *FirewallBuilder) ClearDefaultRules() *FirewallBuilder {
@@ -234,6 +440,12 @@ func (fb *FirewallBuilder) ClearDefaultRules() *FirewallBuilder {
 
 // Done finalizes firewall configuration and returns to host builder.
 func (fb *FirewallBuilder) Done() *HostBuilder {
+	if !fb.config.Enabled {
+		fb.host.firewallConfig = fb.config
+
+		return fb.host
+	}
+
 	// Ensure SSH is always allowed (safety check)
 	hasSSH := false
 
@@ -277,6 +489,37 @@ func (frb *FirewallRuleBuilder) RateLimit() *FirewallRuleBuilder {
 	return frb
 }
 
+// From restricts the rule to connections from a source IP or CIDR (e.g., "10.0.0.0/8").
+func (frb *FirewallRuleBuilder) From(cidr string) *FirewallRuleBuilder {
+	if net.ParseIP(cidr) == nil {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			frb.firewall.host.plan.logger.Fatal().
+				Int("port", frb.rule.Port).
+				Str("source", cidr).
+				Msg("invalid firewall rule source IP or CIDR")
+		}
+	}
+
+	frb.rule.Source = cidr
+
+	return frb
+}
+
+// OnInterface restricts the rule to traffic arriving on a network interface (e.g., "eth1"), for
+// multi-homed hosts that should only expose a port on their private network.
+func (frb *FirewallRuleBuilder) OnInterface(iface string) *FirewallRuleBuilder {
+	if iface == "" || strings.ContainsAny(iface, " \t'\"") {
+		frb.firewall.host.plan.logger.Fatal().
+			Int("port", frb.rule.Port).
+			Str("interface", iface).
+			Msg("invalid firewall rule interface")
+	}
+
+	frb.rule.Interface = iface
+
+	return frb
+}
+
 // Done finalizes this rule and returns to firewall builder.
 func (frb *FirewallRuleBuilder) Done() *FirewallBuilder {
 	frb.firewall.config.Rules = append(frb.firewall.config.Rules, frb.rule)
@@ -290,17 +533,50 @@ func (hb *HostBuilder) Build() *Host {
 		hb.plan.logger.Fatal().Msg("host endpoint is required")
 	}
 
+	if hb.wireGuard != "" {
+		hb.allowWireGuard()
+	}
+
+	if hb.nodeExporter {
+		hb.allowNodeExporter()
+	}
+
+	if hb.trustOnFirst && hb.sshFingerprint == "" {
+		hb.plan.logger.Warn().
+			Str("host", hb.endpoint).
+			Msg("Host key is trusted on first use, the first connection is not protected against MITM")
+	}
+
+	if hb.noAutoUpdates && hb.autoUpdates != nil {
+		hb.plan.logger.Warn().
+			Str("host", hb.endpoint).
+			Msg("Automatic updates are disabled, AutoUpdates() settings are ignored")
+	}
+
 	host := &Host{
 		endpoint:       hb.endpoint,
 		packages:       hb.packages,
 		removePackages: hb.removePackages,
+		holdPackages:   hb.holdPackages,
+		unholdPackages: hb.unholdPackages,
 		registries:     hb.registries,
 		firewallConfig: hb.firewallConfig,
 		hardenDocker:   hb.hardenDocker,
+		dockerDaemon:   hb.dockerDaemon,
+		autoUpdates:    hb.autoUpdates,
+		noAutoUpdates:  hb.noAutoUpdates,
 		hardenOS:       hb.hardenOS,
 		hardenSSH:      hb.hardenSSH,
+		nodeExporter:   hb.nodeExporter,
 		sshFingerprint: hb.sshFingerprint,
 		sshKeyContent:  hb.sshKeyContent,
+		trustOnFirst:   hb.trustOnFirst,
+		hostKeyAlgos:   hb.hostKeyAlgos,
+		sudoPassword:   hb.sudoPassword,
+		sudoSecretRef:  hb.sudoSecretRef,
+		workDir:        hb.workDir,
+		address:        hb.address,
+		wireGuard:      hb.wireGuard,
 		plan:           hb.plan,
 	}
 
@@ -324,6 +600,54 @@ func (h *Host) SSHKeyContent() string {
 	return h.sshKeyContent
 }
 
+// Address returns the IP address other hosts use to reach this host.
+// Falls back to the endpoint when it is an IP address, or empty string if unknown.
+func (h *Host) Address() string {
+	if h.address != "" {
+		return h.address
+	}
+
+	_, hostname, found := strings.Cut(h.endpoint, "@")
+	if !found {
+		hostname = h.endpoint
+	}
+
+	if net.ParseIP(hostname) != nil {
+		return hostname
+	}
+
+	return ""
+}
+
+// OverlayAddress returns the host's WireGuard overlay IP, or empty string if it is not on the overlay.
+func (h *Host) OverlayAddress() string {
+	ip, _, found := strings.Cut(h.wireGuard, "/")
+	if !found {
+		return ""
+	}
+
+	return ip
+}
+
+// allowWireGuard opens the WireGuard port in the host's firewall, if one is configured.
+func (hb *HostBuilder) allowWireGuard() {
+	if hb.firewallConfig == nil || !hb.firewallConfig.Enabled {
+		return
+	}
+
+	for _, rule := range hb.firewallConfig.Rules {
+		if rule.Port == wireguard.DefaultListenPort && rule.Protocol == protocolUDP {
+			return
+		}
+	}
+
+	hb.firewallConfig.Rules = append(hb.firewallConfig.Rules, FirewallRule{
+		Port:     wireguard.DefaultListenPort,
+		Protocol: protocolUDP,
+		Comment:  "WireGuard",
+	})
+}
+
 // String returns a string representation of the host.
 func (h *Host) String() string {
 	return h.endpoint
@@ -0,0 +1,207 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/the-agent-c-ai/hadron/internal/docker"
+	"github.com/the-agent-c-ai/hadron/internal/systemd"
+	"github.com/the-agent-c-ai/hadron/sdk/ssh"
+)
+
+// jobUnitPrefix names the systemd units of scheduled jobs, keeping them apart from the host's own units.
+const jobUnitPrefix = "hadron-job-"
+
+// Job creates a builder for a container that runs to completion on a schedule (backups, certificate
+// renewals, ...) instead of running continuously. The schedule is a systemd calendar expression,
+// e.g. "daily", "hourly", or "*-*-* 03:00:00":
+//
+//	plan.Job("backup", "*-*-* 03:00:00").
+//		Host(db).
+//		Image("restic/restic@sha256:...").
+//		Command("backup", "/data").
+//		Volume(data, "/data", "ro").
+//		/* resource limits */
+//		Build()
+//
+// Deploying writes a systemd timer on the host that runs the container with docker run --rm and
+// --restart no; the container only exists while a run is in progress. Jobs are configured like any
+// other container, except that they cannot publish ports, have health checks, or be canaries.
+func (p *Plan) Job(name, schedule string) *ContainerBuilder {
+	cb := p.Container(name)
+	cb.schedule = schedule
+
+	return cb
+}
+
+// Schedule returns the job's systemd calendar expression, or empty string for a regular container.
+func (c *Container) Schedule() string {
+	return c.schedule
+}
+
+// validateJob checks the settings that make no sense for a container that is not running between runs.
+func (cb *ContainerBuilder) validateJob() error {
+	switch {
+	case len(cb.ports) > 0:
+		return fmt.Errorf("%w: %s: jobs cannot publish ports", ErrInvalidContainer, cb.name)
+	case cb.healthCheck != nil:
+		return fmt.Errorf("%w: %s: jobs cannot have health checks", ErrInvalidContainer, cb.name)
+	case cb.canary:
+		return fmt.Errorf("%w: %s: jobs cannot be canaries", ErrInvalidContainer, cb.name)
+	case len(cb.postStart) > 0:
+		return fmt.Errorf("%w: %s: jobs cannot have post-start commands", ErrInvalidContainer, cb.name)
+	case cb.restart != "" && cb.restart != "no":
+		return fmt.Errorf("%w: %s: jobs run with restart policy no, got %q", ErrInvalidContainer, cb.name, cb.restart)
+	}
+
+	return nil
+}
+
+// jobUnit returns the name (without suffix) of the systemd service and timer running a job.
+func jobUnit(job *Container) string {
+	return jobUnitPrefix + job.Name()
+}
+
+// selectedJobs returns the jobs Execute deploys, in plan order: all of them unless Only or Skip
+// names jobs, which they select the same way as containers.
+func (p *Plan) selectedJobs() []*Container {
+	jobs := make([]*Container, 0, len(p.jobs))
+
+	for _, job := range p.jobs {
+		if (len(p.only) == 0 || slices.Contains(p.only, job.Name())) && !slices.Contains(p.skip, job.Name()) {
+			jobs = append(jobs, job)
+		}
+	}
+
+	return jobs
+}
+
+// deployJobs installs the systemd timers of the plan's jobs, after the containers they may depend on.
+func (e *executor) deployJobs(ctx context.Context) error {
+	deploy := tracked(e, "job", e.deployJob)
+
+	for _, job := range e.plan.selectedJobs() {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("deployment stopped before job %s: %w", job.Name(), err)
+		}
+
+		if err := e.resolveSecrets(ctx, job); err != nil {
+			return err
+		}
+
+		if err := deploy(job); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deployJob writes a job's script, service, and timer, and enables the timer. Unchanged jobs are
+// left alone; the image is pulled on every deploy so the first scheduled run does not have to.
+func (e *executor) deployJob(job *Container) (Action, error) {
+	client, err := e.getSSHClient(job.host)
+	if err != nil {
+		return "", fmt.Errorf(errFailedSSHClient, job.host, err)
+	}
+
+	if !e.mutates() {
+		return e.planJob(client, job)
+	}
+
+	if _, err := e.pullImage(client, job); err != nil {
+		return "", err
+	}
+
+	volumes, err := e.prepareVolumes(client, job)
+	if err != nil {
+		return "", err
+	}
+
+	script, err := e.dockerExec.JobScript(client, e.runOptions(job, volumes))
+	if err != nil {
+		return "", fmt.Errorf("failed to prepare job %s: %w", job.Name(), err)
+	}
+
+	action := ActionUpdate
+	if _, _, err := client.Execute("test -f " + systemd.UnitPath(jobUnit(job)+".timer")); err != nil {
+		action = ActionCreate
+	}
+
+	changed, err := writeJobUnits(client, job, script)
+	if err != nil {
+		return action, fmt.Errorf("failed to write job %s: %w", job.Name(), err)
+	}
+
+	if err := systemd.Enable(client, jobUnit(job)+".timer", changed); err != nil {
+		return action, fmt.Errorf("failed to enable job %s: %w", job.Name(), err)
+	}
+
+	if !changed {
+		e.plan.logger.Info().Str("job", job.Name()).Msg("Job unchanged, skipping")
+
+		return ActionSkip, nil
+	}
+
+	e.plan.logger.Info().Str("job", job.Name()).Str("schedule", job.schedule).Msg("Job scheduled")
+
+	e.changed[job] = true
+	e.metrics.resourceChanged("job")
+
+	return action, nil
+}
+
+// writeJobUnits writes the job's script and systemd units, reporting whether any of them changed.
+func writeJobUnits(client ssh.Connection, job *Container, script string) (bool, error) {
+	unit := jobUnit(job)
+	description := fmt.Sprintf("Hadron job %s (plan %s)", job.Name(), job.plan.name)
+
+	service := systemd.Service{
+		Description: description,
+		Type:        "oneshot",
+		After:       []string{"docker.service"},
+		ExecStart:   "/bin/sh " + docker.JobScriptPath(client, job.Name()),
+	}
+
+	timer := systemd.Timer{Description: description, OnCalendar: job.schedule}
+
+	changed, err := systemd.WriteFiles(client, []systemd.File{
+		{Path: docker.JobScriptPath(client, job.Name()), Content: script, Mode: "644"},
+		{Path: systemd.UnitPath(unit + ".service"), Content: service.Render(), Mode: "644"},
+		{Path: systemd.UnitPath(unit + ".timer"), Content: timer.Render(), Mode: "644"},
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to write systemd units: %w", err)
+	}
+
+	return changed, nil
+}
+
+// destroyJob disables a job's timer, removes its units and script, and any run still in progress.
+func (e *executor) destroyJob(job *Container) error {
+	client, err := e.getSSHClient(job.host)
+	if err != nil {
+		return fmt.Errorf(errFailedSSHClient, job.host, err)
+	}
+
+	unit := jobUnit(job)
+
+	err = systemd.Remove(client, []string{unit + ".timer", unit + ".service"}, docker.JobScriptPath(client, job.Name()))
+	if err != nil {
+		return fmt.Errorf("failed to remove job %s: %w", job.Name(), err)
+	}
+
+	exists, err := e.dockerExec.ContainerExists(client, job.Name())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrContainerCheck, err)
+	}
+
+	if exists {
+		if err := e.dockerExec.RemoveContainer(client, job.Name(), true); err != nil {
+			return fmt.Errorf("failed to remove job container %s: %w", job.Name(), err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package sdk
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/the-agent-c-ai/hadron/internal/testutil"
+)
+
+func newTestJob(plan *Plan, host *Host, schedule string) *ContainerBuilder {
+	return plan.Job("backup", schedule).
+		Host(host).
+		Image("restic/restic:latest").
+		Command("backup", "/data").
+		Memory("256m").
+		CPUShares(512).
+		CPUs("0.5").
+		PIDsLimit(100)
+}
+
+func TestJob(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop())
+	host := plan.Host("user@192.168.1.1").Build()
+
+	job := newTestJob(plan, host, "daily").Build()
+
+	if len(plan.Containers()) != 0 || !slices.Equal(plan.Jobs(), []*Container{job}) {
+		t.Fatalf("expected the job to be registered as a job only, got containers %v and jobs %v",
+			plan.Containers(), plan.Jobs())
+	}
+
+	if job.Schedule() != "daily" || job.restart != "no" {
+		t.Errorf("expected schedule 'daily' with restart 'no', got %q/%q", job.Schedule(), job.restart)
+	}
+
+	_, err := newTestJob(plan, host, "daily").Port("8080:80").BuildE()
+	if !errors.Is(err, ErrInvalidContainer) {
+		t.Errorf("expected ErrInvalidContainer for a job publishing ports, got: %v", err)
+	}
+
+	_, err = newTestJob(plan, host, "daily").Restart("always").BuildE()
+	if !errors.Is(err, ErrInvalidContainer) {
+		t.Errorf("expected ErrInvalidContainer for a job with a restart policy, got: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := plan.Render(&out); err != nil {
+		t.Fatalf("expected render to succeed, got: %v", err)
+	}
+
+	if !strings.Contains(out.String(), "(scheduled daily)\ndocker run --rm --name backup") {
+		t.Errorf("expected the job's one-shot run command, got:\n%s", out.String())
+	}
+}
+
+func TestWriteJobUnits(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop())
+	host := plan.Host("user@192.168.1.1").Build()
+	job := newTestJob(plan, host, "*-*-* 03:00:00").Build()
+
+	client := &testutil.FakeConnection{}
+
+	changed, err := writeJobUnits(client, job, "#!/bin/sh\n")
+	if err != nil || !changed {
+		t.Fatalf("expected new units to be written, got changed=%v (err: %v)", changed, err)
+	}
+
+	for _, want := range []string{
+		"sudo install -D -m 644 /tmp/hadron-backup.sh /var/lib/hadron/jobs/backup.sh && rm -f /tmp/hadron-backup.sh",
+		"sudo install -D -m 644 /tmp/hadron-hadron-job-backup.timer /etc/systemd/system/hadron-job-backup.timer" +
+			" && rm -f /tmp/hadron-hadron-job-backup.timer",
+		"sudo systemctl daemon-reload",
+	} {
+		if !slices.Contains(client.Commands, want) {
+			t.Errorf("expected %q, got %v", want, client.Commands)
+		}
+	}
+
+	// Files already on the host are left alone, and systemd is not reloaded
+	installed := &testutil.FakeConnection{Respond: func(command string) string {
+		if strings.HasSuffix(command, ".sh") {
+			return "#!/bin/sh\n"
+		}
+
+		if strings.HasSuffix(command, ".timer") {
+			return "# Generated by Hadron\n[Unit]\nDescription=Hadron job backup (plan test)\n\n[Timer]\n" +
+				"OnCalendar=*-*-* 03:00:00\nPersistent=true\n\n[Install]\nWantedBy=timers.target\n"
+		}
+
+		return "# Generated by Hadron\n[Unit]\nDescription=Hadron job backup (plan test)\n" +
+			"Wants=docker.service\nAfter=docker.service\n\n[Service]\nType=oneshot\n" +
+			"ExecStart=/bin/sh /var/lib/hadron/jobs/backup.sh\n"
+	}}
+
+	changed, err = writeJobUnits(installed, job, "#!/bin/sh\n")
+	if err != nil || changed {
+		t.Fatalf("expected unchanged units to be skipped, got changed=%v (err: %v)", changed, err)
+	}
+
+	if len(installed.Commands) != 3 {
+		t.Errorf("expected only the three reads, got %v", installed.Commands)
+	}
+}
+
+func TestSelectedJobs(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop())
+	host := plan.Host("user@192.168.1.1").Build()
+
+	database := plan.Container("database").
+		Host(host).
+		Image("postgres:16").
+		Memory("256m").
+		CPUShares(512).
+		CPUs("0.5").
+		PIDsLimit(100).
+		Build()
+
+	job := newTestJob(plan, host, "daily").DependsOn(database).Build()
+
+	plan.Only("backup")
+
+	containers, err := plan.selectedContainers()
+	if err != nil {
+		t.Fatalf("expected a job name to be accepted by Only, got: %v", err)
+	}
+
+	if !slices.Equal(containers, []*Container{database}) {
+		t.Errorf("expected the job's dependency to be selected, got %v", containers)
+	}
+
+	if !slices.Equal(plan.selectedJobs(), []*Container{job}) {
+		t.Errorf("expected the job to be selected, got %v", plan.selectedJobs())
+	}
+
+	plan.Skip("backup")
+
+	if jobs := plan.selectedJobs(); len(jobs) != 0 {
+		t.Errorf("expected a skipped job not to be selected, got %v", jobs)
+	}
+}
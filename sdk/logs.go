@@ -0,0 +1,86 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/the-agent-c-ai/hadron/sdk/ssh"
+)
+
+// LogsOptions controls which container logs Plan.Logs shows.
+type LogsOptions struct {
+	Follow bool // keep streaming new output until interrupted
+	Tail   int  // number of lines from the end to show; 0 shows all
+}
+
+// Logs streams a plan container's logs from its host, resolving the host from the plan. Container
+// stdout goes to stdout and container stderr to stderr, as docker logs reports them.
+func (p *Plan) Logs(container string, opts LogsOptions, stdout, stderr io.Writer) error {
+	target, err := p.findContainer(container)
+	if err != nil {
+		return err
+	}
+
+	exec := newExecutor(p)
+
+	defer func() {
+		if err := exec.sshPool.CloseAll(); err != nil {
+			p.logger.Warn().Err(err).Msg("Failed to close SSH connections")
+		}
+	}()
+
+	client, err := exec.getSSHClient(target.host)
+	if err != nil {
+		return fmt.Errorf(errFailedSSHClient, target.host, err)
+	}
+
+	return streamLogs(client, target, opts, stdout, stderr)
+}
+
+// findContainer returns the plan container with the given name. A name used on several hosts is
+// ambiguous, since logs are read from a single host.
+func (p *Plan) findContainer(name string) (*Container, error) {
+	var found *Container
+
+	for _, container := range p.containers {
+		if container.Name() != name {
+			continue
+		}
+
+		if found != nil {
+			return nil, fmt.Errorf("%w: %s is defined on both %s and %s",
+				ErrUnknownContainer, name, found.host, container.host)
+		}
+
+		found = container
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("%w: %s is not in plan %s", ErrUnknownContainer, name, p.name)
+	}
+
+	return found, nil
+}
+
+// streamLogs runs docker logs for the container, streaming its output.
+func streamLogs(client ssh.Connection, container *Container, opts LogsOptions, stdout, stderr io.Writer) error {
+	cmd := "docker logs"
+	if opts.Tail > 0 {
+		cmd += " --tail " + strconv.Itoa(opts.Tail)
+	}
+
+	if opts.Follow {
+		cmd += " --follow"
+	}
+
+	cmd += " " + container.Name()
+
+	// Following logs runs until the user interrupts hadron, so the stream has no deadline
+	if err := client.StreamCommand(context.Background(), cmd, stdout, stderr); err != nil {
+		return fmt.Errorf("failed to read logs of %s on %s: %w", container.Name(), container.host, err)
+	}
+
+	return nil
+}
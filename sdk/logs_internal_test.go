@@ -0,0 +1,98 @@
+package sdk
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/the-agent-c-ai/hadron/internal/testutil"
+	"github.com/the-agent-c-ai/hadron/sdk/ssh"
+)
+
+func TestLogs(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop())
+	web := plan.Host("deploy@10.0.0.5").Build()
+	worker := plan.Host("deploy@10.0.0.6").Build()
+
+	newContainer := func(name string, host *Host) {
+		plan.Container(name).
+			Host(host).
+			Image("nginx:latest").
+			Memory("256m").
+			CPUShares(512).
+			CPUs("0.5").
+			PIDsLimit(100).
+			Build()
+	}
+
+	newContainer("caddy", web)
+	newContainer("queue", web)
+	newContainer("queue", worker)
+
+	caddy, err := plan.findContainer("caddy")
+	if err != nil {
+		t.Fatalf("expected caddy to be found, got: %v", err)
+	}
+
+	var stdout bytes.Buffer
+
+	client := &testutil.FakeConnection{Respond: func(string) string { return "serving on :443\n" }}
+
+	if err := streamLogs(client, caddy, LogsOptions{Follow: true, Tail: 100}, &stdout, &stdout); err != nil {
+		t.Fatalf("expected logs to stream, got: %v", err)
+	}
+
+	if len(client.Commands) != 1 || client.Commands[0] != "docker logs --tail 100 --follow caddy" {
+		t.Errorf("expected 'docker logs --tail 100 --follow caddy', got %v", client.Commands)
+	}
+
+	if stdout.String() != "serving on :443\n" {
+		t.Errorf("expected streamed output, got %q", stdout.String())
+	}
+
+	if _, err := plan.findContainer("missing"); !errors.Is(err, ErrUnknownContainer) {
+		t.Errorf("expected ErrUnknownContainer for an unknown name, got: %v", err)
+	}
+
+	if _, err := plan.findContainer("queue"); !errors.Is(err, ErrUnknownContainer) {
+		t.Errorf("expected ErrUnknownContainer for a name on several hosts, got: %v", err)
+	}
+}
+
+func TestExecInContainer(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop())
+	host := plan.Host("deploy@10.0.0.5").Build()
+
+	caddy := plan.Container("caddy").
+		Host(host).
+		Image("caddy:2").
+		Memory("256m").
+		CPUShares(512).
+		CPUs("0.5").
+		PIDsLimit(100).
+		Build()
+
+	client := &testutil.FakeConnection{}
+
+	err := execInContainer(client, caddy, []string{"sh", "-lc", "caddy version"}, nil, io.Discard, io.Discard,
+		&ssh.PTY{Width: 80, Height: 24})
+	if err != nil {
+		t.Fatalf("expected exec to succeed, got: %v", err)
+	}
+
+	want := "docker exec -i -t caddy 'sh' '-lc' 'caddy version'"
+	if len(client.Commands) != 1 || client.Commands[0] != want {
+		t.Errorf("expected %q, got %v", want, client.Commands)
+	}
+
+	if err := plan.Exec("caddy"); !errors.Is(err, errExecCommandRequired) {
+		t.Errorf("expected an error for exec without a command, got: %v", err)
+	}
+}
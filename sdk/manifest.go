@@ -0,0 +1,357 @@
+package sdk
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// manifest is the declarative (YAML or JSON) description of a plan.
+// JSON is valid YAML, so both formats go through the same decoder.
+type manifest struct {
+	Name       string              `yaml:"name"`
+	Hosts      []manifestHost      `yaml:"hosts"`
+	Networks   []manifestResource  `yaml:"networks"`
+	Volumes    []manifestResource  `yaml:"volumes"`
+	Containers []manifestContainer `yaml:"containers"`
+}
+
+// manifestHost describes a host. Resources refer to it by name, or by endpoint when no name is set.
+type manifestHost struct {
+	Name         string   `yaml:"name"`
+	Endpoint     string   `yaml:"endpoint"`
+	Address      string   `yaml:"address"`
+	Fingerprint  string   `yaml:"fingerprint"`
+	WorkDir      string   `yaml:"workDir"`
+	Packages     []string `yaml:"packages"`
+	HoldPackages []string `yaml:"holdPackages"`
+	HardenDocker bool     `yaml:"hardenDocker"`
+	HardenOS     bool     `yaml:"hardenOS"`
+	HardenSSH    bool     `yaml:"hardenSSH"`
+	NodeExporter bool     `yaml:"nodeExporter"`
+
+	DisableAutoUpdates bool `yaml:"disableAutoUpdates"`
+}
+
+// manifestResource describes a network or volume.
+type manifestResource struct {
+	Name   string `yaml:"name"`
+	Host   string `yaml:"host"`
+	Driver string `yaml:"driver"`
+}
+
+// manifestVolume describes a container volume. Source is a manifest volume name or a host path.
+type manifestVolume struct {
+	Source string `yaml:"source"`
+	Target string `yaml:"target"`
+	Mode   string `yaml:"mode"`
+}
+
+// manifestContainer describes a container.
+type manifestContainer struct {
+	Name              string            `yaml:"name"`
+	Host              string            `yaml:"host"`
+	Image             string            `yaml:"image"`
+	Command           []string          `yaml:"command"`
+	User              string            `yaml:"user"`
+	Memory            string            `yaml:"memory"`
+	MemoryReservation string            `yaml:"memoryReservation"`
+	CPUShares         int64             `yaml:"cpuShares"`
+	CPUs              string            `yaml:"cpus"`
+	PIDsLimit         int64             `yaml:"pidsLimit"`
+	ShmSize           string            `yaml:"shmSize"`
+	OOMScoreAdj       int               `yaml:"oomScoreAdj"`
+	Hostname          string            `yaml:"hostname"`
+	Networks          []string          `yaml:"networks"`
+	PrimaryNetwork    string            `yaml:"primaryNetwork"`
+	NetworkAlias      string            `yaml:"networkAlias"`
+	Ports             []string          `yaml:"ports"`
+	Volumes           []manifestVolume  `yaml:"volumes"`
+	EnvFile           string            `yaml:"envFile"`
+	Env               map[string]string `yaml:"env"`
+	Labels            map[string]string `yaml:"labels"`
+	LogDriver         string            `yaml:"logDriver"`
+	LogOpts           map[string]string `yaml:"logOpts"`
+	DependsOn         []string          `yaml:"dependsOn"`
+	ReadOnly          bool              `yaml:"readOnly"`
+	Privileged        bool              `yaml:"privileged"`
+	SecurityOpts      []string          `yaml:"securityOpts"`
+	CapDrop           []string          `yaml:"capDrop"`
+	CapAdd            []string          `yaml:"capAdd"`
+	Restart           string            `yaml:"restart"`
+	Pull              string            `yaml:"pull"`
+	Canary            int               `yaml:"canary"`
+	Schedule          string            `yaml:"schedule"` // makes the container a scheduled job, see Plan.Job
+}
+
+// PlanFromManifest reads a declarative YAML or JSON manifest and builds a Plan from it using the
+// regular builders, so the same validation applies as for plans written in Go.
+// Networks, volumes, and dependencies are referenced by name; hosts by name or endpoint.
+func PlanFromManifest(path string) (*Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest %s: %w", path, err)
+	}
+
+	var spec manifest
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrManifestInvalid, path, err)
+	}
+
+	if spec.Name == "" {
+		return nil, fmt.Errorf("%w: %s: plan name is required", ErrManifestInvalid, path)
+	}
+
+	return spec.build()
+}
+
+// build turns the parsed manifest into a Plan, resolving references between resources.
+func (m *manifest) build() (*Plan, error) {
+	plan := NewPlan(m.Name)
+
+	hosts := make(map[string]*Host, len(m.Hosts))
+	for _, spec := range m.Hosts {
+		hosts[spec.key()] = spec.build(plan)
+	}
+
+	networks := make(map[string]*Network, len(m.Networks))
+	for _, spec := range m.Networks {
+		host, ok := hosts[spec.Host]
+		if !ok {
+			return nil, fmt.Errorf(
+				"%w: network %s references unknown host %q", ErrManifestInvalid, spec.Name, spec.Host,
+			)
+		}
+
+		network, err := plan.Network(spec.Name).Host(host).Driver(spec.Driver).BuildE()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrManifestInvalid, err)
+		}
+
+		networks[spec.Name] = network
+	}
+
+	volumes := make(map[string]*Volume, len(m.Volumes))
+	for _, spec := range m.Volumes {
+		host, ok := hosts[spec.Host]
+		if !ok {
+			return nil, fmt.Errorf("%w: volume %s references unknown host %q", ErrManifestInvalid, spec.Name, spec.Host)
+		}
+
+		volume, err := plan.Volume(spec.Name).Host(host).Driver(spec.Driver).BuildE()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrManifestInvalid, err)
+		}
+
+		volumes[spec.Name] = volume
+	}
+
+	containers := make(map[string]*Container, len(m.Containers))
+	for _, spec := range m.Containers {
+		container, err := spec.build(plan, hosts, networks, volumes, containers)
+		if err != nil {
+			return nil, err
+		}
+
+		containers[container.Name()] = container
+	}
+
+	if err := plan.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrManifestInvalid, err)
+	}
+
+	return plan, nil
+}
+
+// key returns the name other resources use to reference this host.
+func (h *manifestHost) key() string {
+	if h.Name != "" {
+		return h.Name
+	}
+
+	return h.Endpoint
+}
+
+// build registers the host with the plan.
+func (h *manifestHost) build(plan *Plan) *Host {
+	builder := plan.Host(h.Endpoint)
+
+	if h.Address != "" {
+		builder.Address(h.Address)
+	}
+
+	if h.Fingerprint != "" {
+		builder.Fingerprint(h.Fingerprint)
+	}
+
+	if h.WorkDir != "" {
+		builder.WorkDir(h.WorkDir)
+	}
+
+	for _, pkg := range h.Packages {
+		builder.Package(pkg)
+	}
+
+	for _, pkg := range h.HoldPackages {
+		builder.HoldPackage(pkg)
+	}
+
+	if h.HardenDocker {
+		builder.HardenDocker()
+	}
+
+	if h.HardenOS {
+		builder.HardenOS()
+	}
+
+	if h.HardenSSH {
+		builder.HardenSSH()
+	}
+
+	if h.NodeExporter {
+		builder.NodeExporter()
+	}
+
+	if h.DisableAutoUpdates {
+		builder.DisableAutoUpdates()
+	}
+
+	return builder.Build()
+}
+
+// build registers the container with the plan. Dependencies must be declared before their dependents.
+func (c *manifestContainer) build(
+	plan *Plan,
+	hosts map[string]*Host,
+	networks map[string]*Network,
+	volumes map[string]*Volume,
+	containers map[string]*Container,
+) (*Container, error) {
+	host, ok := hosts[c.Host]
+	if !ok {
+		return nil, fmt.Errorf("%w: container %s references unknown host %q", ErrManifestInvalid, c.Name, c.Host)
+	}
+
+	builder := plan.Container(c.Name)
+	if c.Schedule != "" {
+		builder = plan.Job(c.Name, c.Schedule)
+	}
+
+	builder.
+		Host(host).
+		Image(c.Image).
+		Command(c.Command...).
+		User(c.User).
+		Memory(c.Memory).
+		MemoryReservation(c.MemoryReservation).
+		CPUShares(c.CPUShares).
+		CPUs(c.CPUs).
+		PIDsLimit(c.PIDsLimit).
+		ShmSize(c.ShmSize).
+		OOMScoreAdj(c.OOMScoreAdj).
+		Hostname(c.Hostname).
+		NetworkAlias(c.NetworkAlias).
+		Restart(c.Restart)
+
+	for _, name := range c.Networks {
+		network, ok := networks[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: container %s references unknown network %q", ErrManifestInvalid, c.Name, name)
+		}
+
+		builder.Network(network)
+
+		if name == c.PrimaryNetwork {
+			builder.PrimaryNetwork(network)
+		}
+	}
+
+	if c.PrimaryNetwork != "" && !slices.Contains(c.Networks, c.PrimaryNetwork) {
+		return nil, fmt.Errorf(
+			"%w: container %s primary network %q is not one of its networks",
+			ErrManifestInvalid, c.Name, c.PrimaryNetwork,
+		)
+	}
+
+	for _, port := range c.Ports {
+		builder.Port(port)
+	}
+
+	for _, vol := range c.Volumes {
+		var source any = vol.Source
+		if volume, ok := volumes[vol.Source]; ok {
+			source = volume
+		}
+
+		builder.Volume(source, vol.Target, vol.Mode)
+	}
+
+	if c.EnvFile != "" {
+		builder.EnvFile(c.EnvFile)
+	}
+
+	for key, value := range c.Env {
+		builder.Env(key, value)
+	}
+
+	for key, value := range c.Labels {
+		builder.Label(key, value)
+	}
+
+	if c.LogDriver != "" {
+		builder.LogDriver(c.LogDriver)
+	}
+
+	for key, value := range c.LogOpts {
+		builder.LogOpt(key, value)
+	}
+
+	for _, name := range c.DependsOn {
+		dep, ok := containers[name]
+		if !ok {
+			return nil, fmt.Errorf(
+				"%w: container %s depends on unknown container %q (declare it first)",
+				ErrManifestInvalid, c.Name, name,
+			)
+		}
+
+		builder.DependsOn(dep)
+	}
+
+	if c.ReadOnly {
+		builder.ReadOnly()
+	}
+
+	if c.Privileged {
+		builder.Privileged()
+	}
+
+	for _, opt := range c.SecurityOpts {
+		builder.SecurityOpt(opt)
+	}
+
+	for _, capability := range c.CapDrop {
+		builder.CapDrop(capability)
+	}
+
+	for _, capability := range c.CapAdd {
+		builder.CapAdd(capability)
+	}
+
+	if c.Pull != "" {
+		builder.PullPolicy(PullPolicy(c.Pull))
+	}
+
+	if c.Canary != 0 {
+		builder.Canary(c.Canary)
+	}
+
+	container, err := builder.BuildE()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrManifestInvalid, err)
+	}
+
+	return container, nil
+}
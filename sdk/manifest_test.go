@@ -0,0 +1,137 @@
+package sdk_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/the-agent-c-ai/hadron/sdk"
+)
+
+const testManifest = `
+name: web-stack
+hosts:
+  - name: web
+    endpoint: deploy@10.0.0.6
+networks:
+  - name: app-net
+    host: web
+volumes:
+  - name: db-data
+    host: web
+containers:
+  - name: postgres
+    host: web
+    image: postgres:16
+    memory: 512m
+    cpuShares: 512
+    cpus: "1"
+    pidsLimit: 200
+    networks: [app-net]
+    networkAlias: db
+    volumes:
+      - source: db-data
+        target: /var/lib/postgresql/data
+  - name: app
+    host: web
+    image: app:latest
+    memory: 256m
+    cpuShares: 256
+    cpus: "0.5"
+    pidsLimit: 100
+    networks: [app-net]
+    ports: ["8080:8080"]
+    env:
+      DATABASE_HOST: db
+    dependsOn: [postgres]
+`
+
+func writeManifest(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write manifest: %v", err)
+	}
+
+	return path
+}
+
+func TestPlanFromManifest(t *testing.T) {
+	t.Parallel()
+
+	plan, err := sdk.PlanFromManifest(writeManifest(t, "plan.yaml", testManifest))
+	if err != nil {
+		t.Fatalf("expected manifest to parse, got: %v", err)
+	}
+
+	if plan.Name() != "web-stack" {
+		t.Errorf("expected plan name 'web-stack', got '%s'", plan.Name())
+	}
+
+	if len(plan.Hosts()) != 1 || plan.Hosts()[0].Endpoint() != "deploy@10.0.0.6" {
+		t.Fatalf("expected a single host deploy@10.0.0.6, got %v", plan.Hosts())
+	}
+
+	if len(plan.Networks()) != 1 || plan.Networks()[0].Name() != "app-net" {
+		t.Errorf("expected network app-net, got %v", plan.Networks())
+	}
+
+	if len(plan.Volumes()) != 1 || plan.Volumes()[0].Name() != "db-data" {
+		t.Errorf("expected volume db-data, got %v", plan.Volumes())
+	}
+
+	containers := plan.Containers()
+	if len(containers) != 2 {
+		t.Fatalf("expected 2 containers, got %d", len(containers))
+	}
+
+	database, app := containers[0], containers[1]
+
+	if database.NetworkAlias() != "db" || database.Host() != plan.Hosts()[0] {
+		t.Errorf("expected postgres on the manifest host with alias 'db'")
+	}
+
+	if len(app.DependsOn()) != 1 || app.DependsOn()[0] != database {
+		t.Errorf("expected app to depend on postgres, got %v", app.DependsOn())
+	}
+}
+
+func TestPlanFromManifestJSON(t *testing.T) {
+	t.Parallel()
+
+	manifest := `{"name": "json-plan", "hosts": [{"endpoint": "10.0.0.7"}], ` +
+		`"networks": [{"name": "net", "host": "10.0.0.7", "driver": "bridge"}]}`
+
+	plan, err := sdk.PlanFromManifest(writeManifest(t, "plan.json", manifest))
+	if err != nil {
+		t.Fatalf("expected JSON manifest to parse, got: %v", err)
+	}
+
+	if len(plan.Networks()) != 1 || plan.Networks()[0].Driver() != "bridge" {
+		t.Errorf("expected bridge network referencing host by endpoint, got %v", plan.Networks())
+	}
+}
+
+func TestPlanFromManifestUnknownReference(t *testing.T) {
+	t.Parallel()
+
+	manifest := "name: broken\nnetworks:\n  - name: net\n    host: missing\n"
+
+	_, err := sdk.PlanFromManifest(writeManifest(t, "plan.yaml", manifest))
+	if !errors.Is(err, sdk.ErrManifestInvalid) {
+		t.Fatalf("expected ErrManifestInvalid for unknown host, got: %v", err)
+	}
+}
+
+func TestPlanFromManifestInvalidVolume(t *testing.T) {
+	t.Parallel()
+
+	manifest := "name: broken\nhosts:\n  - name: web\n    endpoint: deploy@10.0.0.6\nvolumes:\n  - host: web\n"
+
+	_, err := sdk.PlanFromManifest(writeManifest(t, "plan.yaml", manifest))
+	if !errors.Is(err, sdk.ErrManifestInvalid) || !errors.Is(err, sdk.ErrInvalidVolume) {
+		t.Fatalf("expected ErrManifestInvalid wrapping ErrInvalidVolume, got: %v", err)
+	}
+}
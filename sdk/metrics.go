@@ -0,0 +1,187 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// metricsJob is the Pushgateway job label; the plan name becomes the grouping key.
+	metricsJob = "hadron"
+
+	metricsPushTimeout = 10 * time.Second
+	metricsFileMode    = 0o644
+)
+
+// deployMetrics collects timings and change counts during a deploy, for WithMetricsPush.
+type deployMetrics struct {
+	mu      sync.Mutex
+	start   time.Time
+	phases  []phaseTiming
+	changed map[string]int // resources (re)created per type; networks and volumes deploy concurrently
+}
+
+// phaseTiming is the duration of one deploy phase (packages, firewalls, containers, ...).
+type phaseTiming struct {
+	name     string
+	duration time.Duration
+}
+
+// newDeployMetrics starts collecting metrics for a deploy.
+func newDeployMetrics() *deployMetrics {
+	return &deployMetrics{
+		start:   time.Now(),
+		changed: make(map[string]int),
+	}
+}
+
+// phase records how long a deploy phase took.
+func (m *deployMetrics) phase(name string, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.phases = append(m.phases, phaseTiming{name: name, duration: duration})
+}
+
+// resourceChanged counts a network, volume, container, job, or systemd unit (re)created during the deploy.
+func (m *deployMetrics) resourceChanged(resourceType string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.changed[resourceType]++
+}
+
+// write renders the metrics in the Prometheus text exposition format.
+func (m *deployMetrics) write(w io.Writer, plan string, success bool, end time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+
+	label := fmt.Sprintf("plan=%q", plan)
+
+	succeeded := 0
+	if success {
+		succeeded = 1
+	}
+
+	b.WriteString("# HELP hadron_deploy_success Whether the last deploy succeeded (1) or failed (0).\n")
+	b.WriteString("# TYPE hadron_deploy_success gauge\n")
+	fmt.Fprintf(&b, "hadron_deploy_success{%s} %d\n", label, succeeded)
+
+	b.WriteString("# HELP hadron_deploy_duration_seconds Duration of the last deploy.\n")
+	b.WriteString("# TYPE hadron_deploy_duration_seconds gauge\n")
+	fmt.Fprintf(&b, "hadron_deploy_duration_seconds{%s} %g\n", label, end.Sub(m.start).Seconds())
+
+	b.WriteString("# HELP hadron_deploy_timestamp_seconds Unix time the last deploy finished.\n")
+	b.WriteString("# TYPE hadron_deploy_timestamp_seconds gauge\n")
+	fmt.Fprintf(&b, "hadron_deploy_timestamp_seconds{%s} %d\n", label, end.Unix())
+
+	b.WriteString("# HELP hadron_deploy_phase_duration_seconds Duration of each phase of the last deploy.\n")
+	b.WriteString("# TYPE hadron_deploy_phase_duration_seconds gauge\n")
+
+	for _, phase := range m.phases {
+		fmt.Fprintf(&b, "hadron_deploy_phase_duration_seconds{%s,phase=%q} %g\n",
+			label, phase.name, phase.duration.Seconds())
+	}
+
+	b.WriteString("# HELP hadron_deploy_resources_changed Resources created or recreated by the last deploy.\n")
+	b.WriteString("# TYPE hadron_deploy_resources_changed gauge\n")
+
+	// Every type is always reported, so a deploy that changed nothing resets the series to 0
+	for _, resourceType := range []string{"network", "volume", "container", "job", "systemd_unit"} {
+		fmt.Fprintf(&b, "hadron_deploy_resources_changed{%s,type=%q} %d\n",
+			label, resourceType, m.changed[resourceType])
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+
+	return nil
+}
+
+// publishMetrics pushes the deploy metrics to the plan's Pushgateway, or writes them to its textfile.
+// Failures are logged rather than returned: the deploy outcome must not depend on monitoring.
+func (e *executor) publishMetrics(ctx context.Context, success bool) {
+	target := e.plan.metricsTarget
+	if target == "" {
+		return
+	}
+
+	var buf bytes.Buffer
+
+	if err := e.metrics.write(&buf, e.plan.name, success, time.Now()); err != nil {
+		e.plan.logger.Warn().Err(err).Msg("Failed to render deploy metrics")
+
+		return
+	}
+
+	var err error
+	if strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
+		err = pushMetrics(ctx, target, e.plan.name, buf.Bytes())
+	} else {
+		err = writeMetricsFile(target, buf.Bytes())
+	}
+
+	if err != nil {
+		e.plan.logger.Warn().Err(err).Str("target", target).Msg("Failed to publish deploy metrics")
+
+		return
+	}
+
+	e.plan.logger.Info().Str("target", target).Msg("Published deploy metrics")
+}
+
+// pushMetrics replaces the plan's metric group on a Prometheus Pushgateway.
+func pushMetrics(ctx context.Context, gateway, plan string, body []byte) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), metricsPushTimeout)
+	defer cancel()
+
+	endpoint := fmt.Sprintf("%s/metrics/job/%s/plan/%s",
+		strings.TrimSuffix(gateway, "/"), metricsJob, url.PathEscape(plan))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrMetricsPush, err)
+	}
+
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrMetricsPush, err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%w: %s returned %s", ErrMetricsPush, endpoint, resp.Status)
+	}
+
+	return nil
+}
+
+// writeMetricsFile atomically replaces a node_exporter textfile collector file, so the collector
+// never reads a partially written file.
+func writeMetricsFile(path string, body []byte) error {
+	tmp := path + ".tmp"
+
+	if err := os.WriteFile(tmp, body, metricsFileMode); err != nil {
+		return fmt.Errorf("failed to write metrics file: %w", err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write metrics file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package sdk
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestDeployMetrics(t *testing.T) {
+	t.Parallel()
+
+	start := time.Unix(1700000000, 0)
+
+	metrics := newDeployMetrics()
+	metrics.start = start
+	metrics.phase("packages", 1500*time.Millisecond)
+	metrics.phase("containers", 12*time.Second)
+	metrics.resourceChanged("volume")
+	metrics.resourceChanged("container")
+	metrics.resourceChanged("container")
+
+	var out strings.Builder
+	if err := metrics.write(&out, "web", true, start.Add(20*time.Second)); err != nil {
+		t.Fatalf("expected metrics to render, got: %v", err)
+	}
+
+	for _, want := range []string{
+		`hadron_deploy_success{plan="web"} 1`,
+		`hadron_deploy_duration_seconds{plan="web"} 20`,
+		`hadron_deploy_timestamp_seconds{plan="web"} 1700000020`,
+		`hadron_deploy_phase_duration_seconds{plan="web",phase="packages"} 1.5`,
+		`hadron_deploy_phase_duration_seconds{plan="web",phase="containers"} 12`,
+		`hadron_deploy_resources_changed{plan="web",type="network"} 0`,
+		`hadron_deploy_resources_changed{plan="web",type="volume"} 1`,
+		`hadron_deploy_resources_changed{plan="web",type="container"} 2`,
+		`# TYPE hadron_deploy_success gauge`,
+	} {
+		if !strings.Contains(out.String(), want+"\n") {
+			t.Errorf("expected metrics to contain %q, got:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestPushMetrics(t *testing.T) {
+	t.Parallel()
+
+	var method, path, body string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		method, path, body = r.Method, r.URL.Path, string(data)
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	if err := pushMetrics(context.Background(), server.URL+"/", "web", []byte("hadron_deploy_success 1\n")); err != nil {
+		t.Fatalf("expected push to succeed, got: %v", err)
+	}
+
+	if method != http.MethodPut || path != "/metrics/job/hadron/plan/web" || body != "hadron_deploy_success 1\n" {
+		t.Errorf("unexpected push: %s %s %q", method, path, body)
+	}
+}
+
+func TestMetricsWrittenOnFailedDeploy(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "hadron.prom")
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop()).WithMetricsPush(path)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := newExecutor(plan).execute(ctx); err == nil {
+		t.Fatal("expected cancelled deploy to fail")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("expected metrics file to be written, got: %v", err)
+	}
+
+	if !strings.Contains(string(data), `hadron_deploy_success{plan="test"} 0`+"\n") {
+		t.Errorf("expected failed deploy to be reported, got:\n%s", data)
+	}
+}
@@ -4,22 +4,33 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"net"
 )
 
 // Network represents a Docker network.
 type Network struct {
-	name   string
-	host   *Host
-	driver string
-	plan   *Plan
+	name     string
+	host     *Host
+	driver   string
+	subnet   string // CIDR (e.g., "172.28.0.0/16")
+	gateway  string
+	ipRange  string // CIDR within the subnet containers are assigned from
+	internal bool   // no external connectivity
+	ipv6     string // IPv6 subnet CIDR (enables dual-stack)
+	plan     *Plan
 }
 
 // NetworkBuilder builds a Network with a fluent API.
 type NetworkBuilder struct {
-	plan   *Plan
-	name   string
-	host   *Host
-	driver string
+	plan     *Plan
+	name     string
+	host     *Host
+	driver   string
+	subnet   string
+	gateway  string
+	ipRange  string
+	internal bool
+	ipv6     string
 }
 
 // Host sets the host where this network will be created.
@@ -36,22 +47,104 @@ func (nb *NetworkBuilder) Driver(driver string) *NetworkBuilder {
 	return nb
 }
 
+// Subnet pins the network's address space (e.g., "172.28.0.0/16") for predictable container IPs
+// and to avoid collisions with existing infrastructure.
+func (nb *NetworkBuilder) Subnet(cidr string) *NetworkBuilder {
+	if _, _, err := net.ParseCIDR(cidr); err != nil {
+		nb.plan.logger.Fatal().Str("network", nb.name).Str("subnet", cidr).Msg("invalid subnet CIDR")
+	}
+
+	nb.subnet = cidr
+
+	return nb
+}
+
+// Gateway sets the gateway IP for the network's subnet. Requires Subnet.
+func (nb *NetworkBuilder) Gateway(ip string) *NetworkBuilder {
+	if net.ParseIP(ip) == nil {
+		nb.plan.logger.Fatal().Str("network", nb.name).Str("gateway", ip).Msg("invalid gateway IP")
+	}
+
+	nb.gateway = ip
+
+	return nb
+}
+
+// IPRange restricts container address allocation to a CIDR within the subnet. Requires Subnet.
+func (nb *NetworkBuilder) IPRange(cidr string) *NetworkBuilder {
+	if _, _, err := net.ParseCIDR(cidr); err != nil {
+		nb.plan.logger.Fatal().Str("network", nb.name).Str("ip_range", cidr).Msg("invalid IP range CIDR")
+	}
+
+	nb.ipRange = cidr
+
+	return nb
+}
+
+// IPv6 enables IPv6 on the network with the given subnet (e.g., "fd00:dead:beef::/48").
+// Combined with Subnet, the network is dual-stack.
+func (nb *NetworkBuilder) IPv6(subnet string) *NetworkBuilder {
+	ip, _, err := net.ParseCIDR(subnet)
+	if err != nil || ip.To4() != nil {
+		nb.plan.logger.Fatal().Str("network", nb.name).Str("ipv6", subnet).Msg("invalid IPv6 subnet CIDR")
+	}
+
+	nb.ipv6 = subnet
+
+	return nb
+}
+
+// Internal cuts the network off from external connectivity (docker network create --internal),
+// e.g. for database tiers that must not reach the internet. Containers attached only to an internal
+// network cannot pull images themselves; the image must already be on the host, or the container
+// must also join a non-internal network.
+func (nb *NetworkBuilder) Internal() *NetworkBuilder {
+	nb.internal = true
+
+	return nb
+}
+
 // Build creates the Network and registers it with the plan.
+// An invalid configuration is fatal; use BuildE to handle it as an error instead.
 func (nb *NetworkBuilder) Build() *Network {
+	network, err := nb.BuildE()
+	if err != nil {
+		nb.plan.logger.Fatal().Err(err).Str("network", nb.name).Msg("invalid network")
+	}
+
+	return network
+}
+
+// BuildE creates the Network and registers it with the plan, returning an ErrInvalidNetwork error
+// instead of exiting when the configuration is invalid.
+func (nb *NetworkBuilder) BuildE() (*Network, error) {
+	if nb.name == "" {
+		return nil, fmt.Errorf("%w: network name is required", ErrInvalidNetwork)
+	}
+
 	if nb.host == nil {
-		nb.plan.logger.Fatal().Str("network", nb.name).Msg("network must be assigned to a host")
+		return nil, fmt.Errorf("%w: %s: network must be assigned to a host", ErrInvalidNetwork, nb.name)
+	}
+
+	if nb.subnet == "" && (nb.gateway != "" || nb.ipRange != "") {
+		return nil, fmt.Errorf("%w: %s: gateway and ip-range require a subnet", ErrInvalidNetwork, nb.name)
 	}
 
 	network := &Network{
-		name:   nb.name,
-		host:   nb.host,
-		driver: nb.driver,
-		plan:   nb.plan,
+		name:     nb.name,
+		host:     nb.host,
+		driver:   nb.driver,
+		subnet:   nb.subnet,
+		gateway:  nb.gateway,
+		ipRange:  nb.ipRange,
+		internal: nb.internal,
+		ipv6:     nb.ipv6,
+		plan:     nb.plan,
 	}
 
 	nb.plan.networks = append(nb.plan.networks, network)
 
-	return network
+	return network, nil
 }
 
 // Name returns the network name.
@@ -69,10 +162,49 @@ func (n *Network) Driver() string {
 	return n.driver
 }
 
+// Subnet returns the network subnet CIDR, or empty if docker allocates one.
+func (n *Network) Subnet() string {
+	return n.subnet
+}
+
+// Gateway returns the network gateway IP, or empty if docker picks one.
+func (n *Network) Gateway() string {
+	return n.gateway
+}
+
+// IPRange returns the CIDR containers are allocated from, or empty for the whole subnet.
+func (n *Network) IPRange() string {
+	return n.ipRange
+}
+
+// IPv6 returns the IPv6 subnet CIDR, or empty if IPv6 is disabled.
+func (n *Network) IPv6() string {
+	return n.ipv6
+}
+
+// Internal reports whether the network has no external connectivity.
+func (n *Network) Internal() bool {
+	return n.internal
+}
+
 // ConfigHash returns a SHA256 hash of the network configuration.
 // Used for idempotent deployments.
 func (n *Network) ConfigHash() string {
 	config := fmt.Sprintf("%s|%s|%s", n.name, n.driver, n.host.String())
+
+	// IPAM settings are only hashed when set, so existing networks keep their hash
+	if n.subnet != "" {
+		config += fmt.Sprintf("|subnet:%s|gateway:%s|ip-range:%s", n.subnet, n.gateway, n.ipRange)
+	}
+
+	if n.internal {
+		config += "|internal"
+	}
+
+	if n.ipv6 != "" {
+		config += "|ipv6:" + n.ipv6
+	}
+
 	hash := sha256.Sum256([]byte(config))
 
 	return hex.EncodeToString(hash[:])
@@ -75,3 +75,97 @@ func TestNetworkConfigHash(t *testing.T) {
 		t.Errorf("expected %d-character SHA256 hash, got %d characters", sha256HexLength, len(hash))
 	}
 }
+
+func TestNetworkSubnetConfig(t *testing.T) {
+	t.Parallel()
+
+	plan := sdk.NewPlan("test").WithLogger(zerolog.Nop())
+
+	host := plan.Host("testuser@192.168.1.1").
+		Build()
+
+	plain := plan.Network("app-net").
+		Host(host).
+		Build()
+
+	pinned := plan.Network("app-net").
+		Host(host).
+		Subnet("172.28.0.0/16").
+		Gateway("172.28.0.1").
+		IPRange("172.28.5.0/24").
+		Build()
+
+	if pinned.Subnet() != "172.28.0.0/16" || pinned.Gateway() != "172.28.0.1" || pinned.IPRange() != "172.28.5.0/24" {
+		t.Errorf("expected IPAM settings to be kept, got subnet=%s gateway=%s ip-range=%s",
+			pinned.Subnet(), pinned.Gateway(), pinned.IPRange())
+	}
+
+	if plain.ConfigHash() == pinned.ConfigHash() {
+		t.Error("expected subnet configuration to change the config hash")
+	}
+
+	regateway := plan.Network("app-net").
+		Host(host).
+		Subnet("172.28.0.0/16").
+		Gateway("172.28.0.254").
+		IPRange("172.28.5.0/24").
+		Build()
+
+	if regateway.ConfigHash() == pinned.ConfigHash() {
+		t.Error("expected gateway change to change the config hash")
+	}
+}
+
+func TestNetworkInternal(t *testing.T) {
+	t.Parallel()
+
+	plan := sdk.NewPlan("test").WithLogger(zerolog.Nop())
+
+	host := plan.Host("testuser@192.168.1.1").
+		Build()
+
+	external := plan.Network("db-net").
+		Host(host).
+		Build()
+
+	internal := plan.Network("db-net").
+		Host(host).
+		Internal().
+		Build()
+
+	if external.Internal() || !internal.Internal() {
+		t.Error("expected only the network built with Internal() to be internal")
+	}
+
+	if external.ConfigHash() == internal.ConfigHash() {
+		t.Error("expected toggling Internal() to change the config hash")
+	}
+}
+
+func TestNetworkIPv6(t *testing.T) {
+	t.Parallel()
+
+	plan := sdk.NewPlan("test").WithLogger(zerolog.Nop())
+
+	host := plan.Host("testuser@192.168.1.1").
+		Build()
+
+	v4 := plan.Network("dual").
+		Host(host).
+		Subnet("172.28.0.0/16").
+		Build()
+
+	dual := plan.Network("dual").
+		Host(host).
+		Subnet("172.28.0.0/16").
+		IPv6("fd00:dead:beef::/48").
+		Build()
+
+	if dual.IPv6() != "fd00:dead:beef::/48" {
+		t.Errorf("expected IPv6 subnet to be kept, got '%s'", dual.IPv6())
+	}
+
+	if v4.ConfigHash() == dual.ConfigHash() {
+		t.Error("expected enabling IPv6 to change the config hash")
+	}
+}
@@ -0,0 +1,98 @@
+package sdk
+
+import (
+	"fmt"
+
+	"github.com/the-agent-c-ai/hadron/internal/debian"
+	"github.com/the-agent-c-ai/hadron/internal/osrelease"
+)
+
+const (
+	// nodeExporterPort is where node_exporter listens on the docker0 address.
+	nodeExporterPort = 9100
+
+	// dockerNetworks is Docker's default address pool, covering every network containers scrape from.
+	dockerNetworks = "172.16.0.0/12"
+)
+
+// NodeExporter installs Prometheus node_exporter on the host, listening on the docker0 address
+// (172.17.0.1:9100) so containers can scrape host metrics without exposing them publicly. Containers
+// reach it through host.docker.internal with ExtraHosts("host.docker.internal:host-gateway").
+//
+// When the host's firewall is managed with Firewall, a rule allowing Docker networks to reach the
+// port is added to it. Debian-family hosts only.
+func (hb *HostBuilder) NodeExporter() *HostBuilder {
+	hb.nodeExporter = true
+
+	return hb
+}
+
+// allowNodeExporter adds the node_exporter port to the host's firewall for Docker networks, unless
+// a rule for it already exists.
+func (hb *HostBuilder) allowNodeExporter() {
+	if hb.firewallConfig == nil || !hb.firewallConfig.Enabled {
+		return
+	}
+
+	for _, rule := range hb.firewallConfig.Rules {
+		if rule.Port == nodeExporterPort && rule.Protocol == protocolTCP {
+			return
+		}
+	}
+
+	hb.firewallConfig.Rules = append(hb.firewallConfig.Rules, FirewallRule{
+		Port:     nodeExporterPort,
+		Protocol: protocolTCP,
+		Source:   dockerNetworks,
+		Comment:  "node_exporter from Docker networks",
+	})
+}
+
+// deployNodeExporter installs node_exporter on every host that asked for it.
+func (e *executor) deployNodeExporter() error {
+	for _, host := range e.plan.hosts {
+		if err := e.deployHostNodeExporter(host); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deployHostNodeExporter installs and configures node_exporter on a single host.
+func (e *executor) deployHostNodeExporter(host *Host) error {
+	if !host.nodeExporter {
+		return nil
+	}
+
+	client, err := e.getSSHClient(host)
+	if err != nil {
+		return fmt.Errorf(errFailedSSHClient, host, err)
+	}
+
+	info, err := e.hostOS(host)
+	if err != nil {
+		return err
+	}
+
+	if info.Family() != osrelease.FamilyDebian {
+		e.plan.logger.Warn().
+			Str("host", host.String()).
+			Str("os", info.ID).
+			Msg("node_exporter is only managed on Debian-family hosts, skipping")
+
+		return nil
+	}
+
+	e.plan.logger.Info().Str("host", host.String()).Msg("Ensuring node_exporter is running")
+
+	// A plan-managed firewall already allows the port (see allowNodeExporter) and would remove
+	// a rule added behind its back
+	openFirewall := host.firewallConfig == nil || !host.firewallConfig.Enabled
+
+	if err := debian.EnsureNodeExporter(client, openFirewall); err != nil {
+		return fmt.Errorf("failed to deploy node_exporter on %s: %w", host, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package sdk
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestNodeExporterFirewallRule(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop())
+
+	withFirewall := plan.Host("deploy@203.0.113.6").NodeExporter().Firewall().Done().Build()
+	withoutFirewall := plan.Host("deploy@203.0.113.7").NodeExporter().Build()
+
+	if !withFirewall.nodeExporter || !withoutFirewall.nodeExporter {
+		t.Fatal("expected node_exporter to be requested on both hosts")
+	}
+
+	// The managed firewall must let Docker networks reach node_exporter
+	want := FirewallRule{
+		Port:     9100,
+		Protocol: protocolTCP,
+		Source:   "172.16.0.0/12",
+		Comment:  "node_exporter from Docker networks",
+	}
+	if !slices.Contains(withFirewall.firewallConfig.Rules, want) {
+		t.Errorf("expected node_exporter firewall rule, got %+v", withFirewall.firewallConfig.Rules)
+	}
+
+	if withoutFirewall.firewallConfig != nil {
+		t.Errorf("expected no firewall config on a host without Firewall, got %+v", withoutFirewall.firewallConfig)
+	}
+}
+
+func TestNodeExporterNotRequested(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop())
+	host := plan.Host("user@192.168.1.1").Build()
+
+	// Hosts without NodeExporter are skipped before any SSH connection is made
+	if err := newExecutor(plan).deployHostNodeExporter(host); err != nil {
+		t.Errorf("expected host without node_exporter to be skipped, got: %v", err)
+	}
+}
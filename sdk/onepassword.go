@@ -1,17 +1,53 @@
 package sdk
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os/exec"
+	"sort"
 	"strings"
+	"sync"
 )
 
 const (
 	// opCLI is the 1Password CLI command name.
 	opCLI = "op"
+
+	// opConcurrency bounds concurrent `op` invocations in GetSecrets.
+	opConcurrency = 4
 )
 
+// SecretErrors holds the references GetSecrets failed to resolve, keyed by reference.
+type SecretErrors map[string]error
+
+// Error lists the failed references in order.
+func (e SecretErrors) Error() string {
+	references := make([]string, 0, len(e))
+	for reference := range e {
+		references = append(references, reference)
+	}
+
+	sort.Strings(references)
+
+	messages := make([]string, 0, len(references))
+	for _, reference := range references {
+		messages = append(messages, e[reference].Error())
+	}
+
+	return fmt.Sprintf("failed to resolve %d secret(s): %s", len(e), strings.Join(messages, "; "))
+}
+
+// Unwrap returns the individual errors, so errors.Is matches any of them.
+func (e SecretErrors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, err := range e {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
 // AuthenticateOp pre-authenticates with 1Password CLI to establish a session.
 // This should be called before making parallel GetSecret/GetDocument calls
 // to prevent multiple biometric authentication prompts.
@@ -82,6 +118,33 @@ func GetDocument(ctx context.Context, reference string) ([]byte, error) {
 	return output, nil
 }
 
+// InjectSecrets renders a template with `op inject`, replacing each "{{ op://vault/item/field }}"
+// reference with its secret value. Use it for configuration files that embed secrets, then mount
+// the result with MountData:
+//
+//	config, err := InjectSecrets(ctx, []byte("smtp_password: {{ op://Production/smtp/password }}\n"))
+//
+// The template is piped through stdin and the rendered file read from stdout, so neither touches disk.
+// Requires the `op` CLI to be installed and authenticated.
+func InjectSecrets(ctx context.Context, template []byte) ([]byte, error) {
+	if len(template) == 0 {
+		return nil, ErrInjectTemplateEmpty
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, opCLI, "inject")
+	cmd.Stdin = bytes.NewReader(template)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to inject secrets: %w\nOutput: %s", err, stderr.String())
+	}
+
+	return stdout.Bytes(), nil
+}
+
 // GetSecret retrieves a secret from 1Password using a secret reference.
 // Reference format: "op://vault/item/field"
 //
@@ -139,3 +202,75 @@ func GetSecret(ctx context.Context, reference string) (string, error) {
 
 	return secret, nil
 }
+
+// GetSecrets resolves many 1Password secret references at once: it authenticates a single time
+// with AuthenticateOp, then runs GetSecret for the distinct references, at most four at a time.
+//
+// Example:
+//
+//	secrets, err := GetSecrets(ctx, []string{
+//		"op://Production/database/password",
+//		"op://Production/smtp/password",
+//	})
+//
+// The returned map holds every reference that resolved. If some failed, the error is a
+// SecretErrors keyed by reference and the map still holds the others; if authentication fails,
+// nothing is resolved. Plans calling GetSecret directly from several goroutines should call
+// AuthenticateOp first for the same reason: a single biometric prompt.
+func GetSecrets(ctx context.Context, references []string) (map[string]string, error) {
+	if len(references) == 0 {
+		return map[string]string{}, nil
+	}
+
+	if err := AuthenticateOp(ctx); err != nil {
+		return nil, err
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		resolved = make(map[string]string, len(references))
+		failed   = make(SecretErrors)
+		seen     = make(map[string]bool, len(references))
+	)
+
+	semaphore := make(chan struct{}, opConcurrency)
+
+	for _, reference := range references {
+		if seen[reference] {
+			continue
+		}
+
+		seen[reference] = true
+
+		semaphore <- struct{}{}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			secret, err := GetSecret(ctx, reference)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				failed[reference] = err
+
+				return
+			}
+
+			resolved[reference] = secret
+		}()
+	}
+
+	wg.Wait()
+
+	if len(failed) > 0 {
+		return resolved, failed
+	}
+
+	return resolved, nil
+}
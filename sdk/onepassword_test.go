@@ -0,0 +1,107 @@
+package sdk_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/the-agent-c-ai/hadron/sdk"
+)
+
+// fakeOp puts an `op` script on PATH that signs in, logging each invocation, and answers
+// `op item get ITEM --vault VAULT --fields FIELD --reveal` with "ITEM-FIELD" unless ITEM is "missing".
+// `op inject` replaces every reference in stdin with "injected".
+func fakeOp(t *testing.T) string {
+	t.Helper()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("fake op CLI is a shell script")
+	}
+
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+
+	script := `#!/bin/sh
+echo "$1" >> "` + calls + `"
+case "$1" in
+signin) exit 0 ;;
+item)
+	if [ "$3" = missing ]; then
+		echo "item not found" >&2
+		exit 1
+	fi
+	echo "$3-$7"
+	;;
+inject) sed 's/{{ op:[^ ]* }}/injected/g' ;;
+esac
+`
+
+	//nolint:gosec // the fake CLI must be executable
+	if err := os.WriteFile(filepath.Join(dir, "op"), []byte(script), 0o755); err != nil {
+		t.Fatalf("failed to write fake op: %v", err)
+	}
+
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	return calls
+}
+
+func TestGetSecrets(t *testing.T) {
+	calls := fakeOp(t)
+
+	references := []string{
+		"op://Production/database/password",
+		"op://Production/smtp/password",
+		"op://Production/database/password",
+		"op://Production/missing/password",
+	}
+
+	secrets, err := sdk.GetSecrets(context.Background(), references)
+
+	var failed sdk.SecretErrors
+	if !errors.As(err, &failed) {
+		t.Fatalf("expected SecretErrors, got: %v", err)
+	}
+
+	if len(failed) != 1 || failed["op://Production/missing/password"] == nil {
+		t.Errorf("expected only the missing item to fail, got: %v", failed)
+	}
+
+	if secrets["op://Production/database/password"] != "database-password" ||
+		secrets["op://Production/smtp/password"] != "smtp-password" || len(secrets) != 2 {
+		t.Errorf("expected the other secrets to resolve, got: %v", secrets)
+	}
+
+	log, err := os.ReadFile(calls)
+	if err != nil {
+		t.Fatalf("failed to read op calls: %v", err)
+	}
+
+	// One sign-in, then one lookup per distinct reference
+	if got := strings.Fields(string(log)); strings.Count(strings.Join(got, " "), "signin") != 1 || len(got) != 4 {
+		t.Errorf("expected 1 signin and 3 lookups, got %v", got)
+	}
+}
+
+func TestInjectSecrets(t *testing.T) {
+	fakeOp(t)
+
+	template := "user: app\npassword: {{ op://Production/database/password }}\n"
+
+	rendered, err := sdk.InjectSecrets(context.Background(), []byte(template))
+	if err != nil {
+		t.Fatalf("expected inject to succeed, got: %v", err)
+	}
+
+	if string(rendered) != "user: app\npassword: injected\n" {
+		t.Errorf("expected references replaced and the rest kept, got %q", rendered)
+	}
+
+	if _, err := sdk.InjectSecrets(context.Background(), nil); !errors.Is(err, sdk.ErrInjectTemplateEmpty) {
+		t.Errorf("expected ErrInjectTemplateEmpty, got: %v", err)
+	}
+}
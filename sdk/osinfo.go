@@ -0,0 +1,79 @@
+package sdk
+
+import (
+	"fmt"
+
+	"github.com/the-agent-c-ai/hadron/internal/osrelease"
+	"github.com/the-agent-c-ai/hadron/internal/packages"
+)
+
+// checkHostOS fails fast, before anything is installed, when a host runs a distribution Hadron
+// cannot manage, instead of failing later with cryptic apt-get or dnf errors.
+func (e *executor) checkHostOS() error {
+	for _, host := range e.plan.hosts {
+		info, err := e.hostOS(host)
+		if err != nil {
+			return err
+		}
+
+		if err := supportedOS(host, info); err != nil {
+			return err
+		}
+
+		e.plan.logger.Debug().
+			Str("host", host.String()).
+			Str("os", info.String()).
+			Msg("Detected host operating system")
+	}
+
+	return nil
+}
+
+// hostOS returns the host's distribution, read from /etc/os-release once per run.
+func (e *executor) hostOS(host *Host) (osrelease.Info, error) {
+	e.osMu.Lock()
+	defer e.osMu.Unlock()
+
+	if info, ok := e.osInfo[host]; ok {
+		return info, nil
+	}
+
+	client, err := e.getSSHClient(host)
+	if err != nil {
+		return osrelease.Info{}, fmt.Errorf(errFailedSSHClient, host, err)
+	}
+
+	info, err := osrelease.Detect(client)
+	if err != nil {
+		return osrelease.Info{}, fmt.Errorf("failed to detect operating system on %s: %w", host, err)
+	}
+
+	e.osInfo[host] = info
+
+	return info, nil
+}
+
+// packageManager returns the package manager of the host's distribution: apt or dnf.
+func (e *executor) packageManager(host *Host) (packages.Manager, error) {
+	info, err := e.hostOS(host)
+	if err != nil {
+		return nil, err
+	}
+
+	manager, err := packages.ForFamily(info.Family())
+	if err != nil {
+		return nil, fmt.Errorf("failed to manage packages on %s: %w", host, err)
+	}
+
+	return manager, nil
+}
+
+// supportedOS returns ErrUnsupportedOS unless the distribution is Debian- or RHEL-family.
+func supportedOS(host *Host, info osrelease.Info) error {
+	if info.Family() == osrelease.FamilyUnknown {
+		return fmt.Errorf("%w: hadron currently supports Debian/Ubuntu and RHEL-family hosts; detected %s on %s",
+			ErrUnsupportedOS, info, host)
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package sdk
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/the-agent-c-ai/hadron/internal/osrelease"
+)
+
+func TestCheckHostOS(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop())
+	debianHost := plan.Host("user@192.168.1.1").Build()
+	alpineHost := plan.Host("user@192.168.1.2").Build()
+
+	exec := newExecutor(plan)
+
+	// Detected distributions are cached per host, so no SSH connection is made here
+	exec.osInfo[debianHost] = osrelease.Parse("PRETTY_NAME=\"Debian GNU/Linux 12 (bookworm)\"\nID=debian\n")
+	exec.osInfo[alpineHost] = osrelease.Parse("PRETTY_NAME=\"Alpine Linux v3.20\"\nID=alpine\n")
+
+	info, err := exec.hostOS(debianHost)
+	if err != nil || info.ID != "debian" {
+		t.Fatalf("expected cached Debian info, got %+v (err: %v)", info, err)
+	}
+
+	err = exec.checkHostOS()
+	if !errors.Is(err, ErrUnsupportedOS) {
+		t.Fatalf("expected ErrUnsupportedOS, got: %v", err)
+	}
+
+	if !strings.Contains(err.Error(), "detected Alpine Linux v3.20 on user@192.168.1.2") {
+		t.Errorf("expected error to name the detected distribution and host, got: %v", err)
+	}
+}
@@ -3,15 +3,23 @@ package sdk
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
 
 	"github.com/rs/zerolog"
-)
 
-var (
-	errDryRunNotImplemented  = errors.New("dry run not yet implemented")
-	errDestroyNotImplemented = errors.New("destroy not yet implemented")
+	"github.com/the-agent-c-ai/hadron/sdk/ssh"
 )
 
+// envConfirm is set to "true" by the hadron CLI when destructive actions were confirmed with --yes.
+const envConfirm = "HADRON_CONFIRM"
+
+// envTimeout is set to the CLI's --timeout (a Go duration) so the plan stops on its own in time.
+const envTimeout = "HADRON_TIMEOUT"
+
 // Plan represents a deployment plan containing hosts and resources.
 type Plan struct {
 	name       string
@@ -19,20 +27,88 @@ type Plan struct {
 	networks   []*Network
 	volumes    []*Volume
 	containers []*Container
+	jobs       []*Container // scheduled one-shot containers, see Job
 	logger     zerolog.Logger
+
+	systemdUnits []*SystemdUnit // host-level services outside Docker, see SystemdUnit
+
+	requireDigests bool
+	confirm        func() bool // approves destructive actions (destroy, volume recreation)
+	secrets        SecretProvider
+	commandTimeout time.Duration // per-command SSH timeout
+	deployTimeout  time.Duration // overall Execute and Destroy deadline, 0 for none
+
+	resourceConcurrency int    // concurrent network/volume operations per host
+	metricsTarget       string // Pushgateway URL or textfile path for deploy metrics
+	collectGarbage      bool   // remove orphaned uploaded files after deploy
+	pruneImages         bool   // remove dangling images after deploy, see WithImagePrune
+	pruneOlderThan      string // only prune images older than this docker duration
+
+	stateFile string // local record of the last deployed config hashes, see WithStateFile
+
+	driftMode DriftMode // how to treat containers changed by hand, see WithDriftDetection
+	force     bool      // overwrite drifted containers in DriftRefuse mode (CLI --force)
+
+	only []string // containers to deploy, see Only
+	skip []string // containers not to deploy, see Skip
+
+	forceRecreate []string // containers to recreate even when unchanged, see ForceRecreate
+
+	targetHosts []string // host endpoints or addresses to limit Execute and Destroy to, see TargetHosts
+	unscoped    *Plan    // the full plan this one was narrowed from by targeted
+
+	eventOutput io.Writer // JSON lines of resource actions, see WithJSONOutput
+
+	postDeployChecks []hook
+	hooks            []hook // commands run before/after deploy and destroy, see PreDeploy
 }
 
 // NewPlan creates a new deployment plan with the given name.
+// The plan logs to stderr until WithLogger replaces the logger.
 func NewPlan(name string) *Plan {
 	return &Plan{
-		name:       name,
-		hosts:      make([]*Host, 0),
-		networks:   make([]*Network, 0),
-		volumes:    make([]*Volume, 0),
-		containers: make([]*Container, 0),
+		name:           name,
+		hosts:          make([]*Host, 0),
+		networks:       make([]*Network, 0),
+		volumes:        make([]*Volume, 0),
+		containers:     make([]*Container, 0),
+		logger:         defaultLogger(),
+		confirm:        confirmFromEnv,
+		secrets:        &defaultSecretProvider{},
+		commandTimeout: ssh.DefaultCommandTimeout,
+		deployTimeout:  timeoutFromEnv(),
+
+		resourceConcurrency: defaultResourceConcurrency,
+		collectGarbage:      collectGarbageFromEnv(),
+		force:               forceFromEnv(),
+		only:                namesFromEnv(envOnly),
+		skip:                namesFromEnv(envSkip),
+		forceRecreate:       namesFromEnv(envForceRecreate),
+		targetHosts:         namesFromEnv(envHosts),
 	}
 }
 
+// defaultLogger returns a console logger on stderr, so builder misuse reported through Fatal is
+// visible even for plans that never call WithLogger (a zero zerolog.Logger exits silently).
+func defaultLogger() zerolog.Logger {
+	return zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: time.RFC3339}).With().Timestamp().Logger()
+}
+
+// timeoutFromEnv returns the CLI's --timeout, or 0 when it is unset or invalid.
+func timeoutFromEnv() time.Duration {
+	timeout, err := time.ParseDuration(os.Getenv(envTimeout))
+	if err != nil {
+		return 0
+	}
+
+	return timeout
+}
+
+// confirmFromEnv approves destructive actions when the CLI was invoked with --yes.
+func confirmFromEnv() bool {
+	return os.Getenv(envConfirm) == "true"
+}
+
 // WithLogger sets the logger for the plan.
 func (p *Plan) WithLogger(logger zerolog.Logger) *Plan {
 	p.logger = logger
@@ -40,6 +116,83 @@ func (p *Plan) WithLogger(logger zerolog.Logger) *Plan {
 	return p
 }
 
+// RequireDigests makes container images without a digest (e.g., "nginx:latest") a build-time fatal
+// instead of a warning. Use this for production plans where every image must be pinned.
+func (p *Plan) RequireDigests() *Plan {
+	p.requireDigests = true
+
+	return p
+}
+
+// WithSecretProvider sets the provider that resolves secret references used by EnvSecret,
+// MountSecret, and RegistryFromSecret. By default, "vault://path#field" references are read from
+// HashiCorp Vault (see GetVaultSecret), "awssm://secret-id#key" references from AWS Secrets Manager
+// (see GetAWSSecret), and all others with the 1Password CLI.
+func (p *Plan) WithSecretProvider(provider SecretProvider) *Plan {
+	p.secrets = provider
+
+	return p
+}
+
+// CommandTimeout sets how long a single remote command may run before it is killed and the
+// deploy fails with ssh.ErrCommandTimeout. Defaults to ssh.DefaultCommandTimeout; <= 0 disables it.
+func (p *Plan) CommandTimeout(timeout time.Duration) *Plan {
+	p.commandTimeout = timeout
+
+	return p
+}
+
+// DeployTimeout bounds a whole Execute or Destroy: once it expires, no further phase or resource is
+// started and they return an error wrapping context.DeadlineExceeded. Commands already running are bounded
+// by CommandTimeout. Defaults to the CLI's --timeout; <= 0 disables it.
+func (p *Plan) DeployTimeout(timeout time.Duration) *Plan {
+	p.deployTimeout = timeout
+
+	return p
+}
+
+// ResourceConcurrency sets how many networks or volumes are created at once on each host.
+// Defaults to 4; 1 creates them one at a time in plan order.
+func (p *Plan) ResourceConcurrency(limit int) *Plan {
+	if limit < 1 {
+		p.logger.Fatal().Int("limit", limit).Msg("resource concurrency must be at least 1")
+	}
+
+	p.resourceConcurrency = limit
+
+	return p
+}
+
+// WithMetricsPush publishes Prometheus deploy metrics (duration, per-phase timings, resources
+// changed, success) after every Execute, whether it succeeded or not. An http(s) URL is treated
+// as a Pushgateway; anything else as a file path for node_exporter's textfile collector
+// (e.g. "/var/lib/node_exporter/textfile/hadron.prom"). Publishing failures are only logged.
+func (p *Plan) WithMetricsPush(target string) *Plan {
+	p.metricsTarget = target
+
+	return p
+}
+
+// RequireConfirmation sets the callback that must approve destructive actions such as Destroy or
+// recreating a volume whose configuration changed. When it returns false the action aborts with
+// ErrConfirmationRequired. By default, approval comes from the CLI's --yes flag.
+func (p *Plan) RequireConfirmation(confirm func() bool) *Plan {
+	p.confirm = confirm
+
+	return p
+}
+
+// confirmDestructive asks for approval before a destructive action.
+func (p *Plan) confirmDestructive(action string) error {
+	if p.confirm != nil && p.confirm() {
+		return nil
+	}
+
+	p.logger.Error().Str("plan", p.name).Str("action", action).Msg("Destructive action not confirmed, aborting")
+
+	return fmt.Errorf("%w: %s (re-run with --yes to confirm)", ErrConfirmationRequired, action)
+}
+
 // Host creates a new host builder.
 // The endpoint can be an IP address, hostname, or SSH config alias.
 func (p *Plan) Host(endpoint string) *HostBuilder {
@@ -82,30 +235,201 @@ func (p *Plan) Container(name string) *ContainerBuilder {
 	}
 }
 
+// Name returns the plan name.
+func (p *Plan) Name() string {
+	return p.name
+}
+
+// Hosts returns the hosts registered with the plan.
+func (p *Plan) Hosts() []*Host {
+	return p.hosts
+}
+
+// Networks returns the networks registered with the plan.
+func (p *Plan) Networks() []*Network {
+	return p.networks
+}
+
+// Volumes returns the volumes registered with the plan.
+func (p *Plan) Volumes() []*Volume {
+	return p.volumes
+}
+
+// Containers returns the containers registered with the plan, in declaration order.
+func (p *Plan) Containers() []*Container {
+	return p.containers
+}
+
+// Jobs returns the scheduled jobs registered with the plan, in declaration order.
+func (p *Plan) Jobs() []*Container {
+	return p.jobs
+}
+
+// SystemdUnits returns the systemd units registered with the plan, in declaration order.
+func (p *Plan) SystemdUnits() []*SystemdUnit {
+	return p.systemdUnits
+}
+
+// Validate checks the plan for misconfigurations that individual builders cannot detect, such as a
+// container using a network or volume that is created on a different host, or two containers
+// with the same name on one host. Execute and Render validate the plan before doing anything.
+func (p *Plan) Validate() error {
+	errs := make([]error, 0, len(p.containers)+len(p.jobs)+4)
+	for _, container := range slices.Concat(p.containers, p.jobs) {
+		errs = append(errs, container.validateHosts())
+	}
+
+	// Jobs are containers while they run, so they share the container namespace
+	errs = append(errs,
+		duplicateNames("network", p.networks),
+		duplicateNames("volume", p.volumes),
+		duplicateNames("container", slices.Concat(p.containers, p.jobs)),
+		duplicateNames("systemd unit", p.systemdUnits),
+	)
+
+	return errors.Join(errs...)
+}
+
+// hostedResource is a resource identified by its name on its host.
+type hostedResource interface {
+	Name() string
+	Host() *Host
+}
+
+// duplicateNames reports resources of one kind registered twice under the same name on the same
+// host, which would otherwise fight over a single Docker object at deploy time.
+func duplicateNames[T hostedResource](kind string, resources []T) error {
+	seen := make(map[string]bool, len(resources))
+
+	var errs []error
+
+	for _, resource := range resources {
+		key := resource.Host().Endpoint() + "/" + resource.Name()
+		if seen[key] {
+			errs = append(errs, fmt.Errorf("%w: %s %s is defined more than once on %s",
+				ErrDuplicateResource, kind, resource.Name(), resource.Host()))
+		}
+
+		seen[key] = true
+	}
+
+	return errors.Join(errs...)
+}
+
 // Execute executes the plan by deploying all resources to their respective hosts.
-// Execute runs the plan with the given context.
+// Execute runs the plan with the given context. It refuses to deploy when the hadron CLI asked
+// for another mode (see Run), returning ErrModeNotHandled.
 func (p *Plan) Execute(ctx context.Context) error {
+	_, err := p.ExecuteWithResult(ctx)
+
+	return err
+}
+
+// ExecuteWithResult runs the plan like Execute and also returns what each network, volume, and
+// container deploy did, for callers that act on the outcome (e.g. only notify when something
+// changed). The result is returned even when the deploy fails, covering the resources reached.
+func (p *Plan) ExecuteWithResult(ctx context.Context) (*Result, error) {
+	if key := modeFromEnv(); key != "" {
+		return &Result{}, fmt.Errorf("%w: %s is set; call plan.Run instead of Execute", ErrModeNotHandled, key)
+	}
+
+	if err := p.Validate(); err != nil {
+		return &Result{}, err
+	}
+
+	scoped, err := p.targeted()
+	if err != nil {
+		return &Result{}, err
+	}
+
+	if p.deployTimeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, p.deployTimeout)
+		defer cancel()
+	}
+
+	exec := newExecutor(scoped)
+
+	err = exec.execute(ctx)
+	if err == nil {
+		exec.saveState(ctx)
+	}
+
+	return &exec.result, err
+}
+
+// Render writes the docker commands each container would run to w, without executing anything.
+// Use it to review exactly what a deploy will run.
+func (p *Plan) Render(w io.Writer) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
 	exec := newExecutor(p)
 
-	return exec.execute(ctx)
+	return exec.render(w)
 }
 
-// DryRun shows what would be deployed without actually deploying.
+// DryRun shows what would be deployed without actually deploying. With a state file (see
+// WithStateFile) it logs the changes the next deploy would make, without connecting to any host.
+// Otherwise it inspects the hosts like Execute and logs the action each resource would get.
 func (p *Plan) DryRun() error {
 	p.logger.Info().Str("plan", p.name).Msg("Dry run - showing planned changes")
 
-	// TODO: Implement dry run logic
-	// Show what docker commands would be executed
+	if p.stateFile != "" {
+		return p.logChanges(context.Background())
+	}
+
+	_, err := p.DryRunWithResult(context.Background())
+
+	return err
+}
+
+// DryRunWithResult connects to the hosts and returns the action Execute would take for every systemd
+// unit, network, volume, container, and job it reaches, without changing anything. Host
+// configuration (packages, hardening, firewalls) is not previewed, and images are not pulled.
+func (p *Plan) DryRunWithResult(ctx context.Context) (*Result, error) {
+	if err := p.Validate(); err != nil {
+		return &Result{}, err
+	}
+
+	scoped, err := p.targeted()
+	if err != nil {
+		return &Result{}, err
+	}
+
+	exec := newExecutor(scoped)
+	exec.mode = modeDryRun
 
-	return errDryRunNotImplemented
+	err = exec.dryRun(ctx)
+
+	return &exec.result, err
 }
 
 // Destroy removes all resources defined in the plan.
 func (p *Plan) Destroy() error {
+	if err := p.confirmDestructive("destroy plan " + p.name); err != nil {
+		return err
+	}
+
+	scoped, err := p.targeted()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	if p.deployTimeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, p.deployTimeout)
+		defer cancel()
+	}
+
 	p.logger.Info().Str("plan", p.name).Msg("Destroying resources")
 
-	// TODO: Implement destroy logic
-	// Remove in reverse order: Containers -> Volumes -> Networks
+	exec := newExecutor(scoped)
 
-	return errDestroyNotImplemented
+	return exec.destroy(ctx)
 }
@@ -1,7 +1,13 @@
 package sdk_test
 
 import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/rs/zerolog"
 
@@ -87,3 +93,211 @@ func TestPlanHostWithoutFingerprint(t *testing.T) {
 		t.Errorf("expected empty fingerprint, got '%s'", host.SSHFingerprint())
 	}
 }
+
+func TestPlanDestroyRequiresConfirmation(t *testing.T) {
+	t.Parallel()
+
+	asked := 0
+	plan := sdk.NewPlan("test").
+		WithLogger(zerolog.Nop()).
+		RequireConfirmation(func() bool {
+			asked++
+
+			return false
+		})
+
+	err := plan.Destroy()
+	if !errors.Is(err, sdk.ErrConfirmationRequired) {
+		t.Fatalf("expected ErrConfirmationRequired without confirmation, got: %v", err)
+	}
+
+	if asked != 1 {
+		t.Errorf("expected confirmation to be requested once, got %d", asked)
+	}
+}
+
+func TestPlanDestroyProceedsWhenConfirmed(t *testing.T) {
+	t.Parallel()
+
+	plan := sdk.NewPlan("test").
+		WithLogger(zerolog.Nop()).
+		RequireConfirmation(func() bool { return true })
+
+	// An empty plan has nothing to remove, so a confirmed destroy succeeds without touching any host
+	if err := plan.Destroy(); err != nil {
+		t.Fatalf("expected confirmed destroy to proceed, got: %v", err)
+	}
+}
+
+// envFatalChild marks the subprocess TestDefaultLoggerReportsFatal runs to trigger a fatal.
+const envFatalChild = "HADRON_TEST_FATAL_CHILD"
+
+func TestDefaultLoggerReportsFatal(t *testing.T) {
+	t.Parallel()
+
+	if os.Getenv(envFatalChild) == "1" {
+		// No WithLogger: the default logger must still report the misuse before exiting
+		plan := sdk.NewPlan("test")
+		plan.Network("orphan").Build()
+
+		return
+	}
+
+	//nolint:gosec // Re-runs this test binary
+	cmd := exec.Command(os.Args[0], "-test.run=^TestDefaultLoggerReportsFatal$")
+	cmd.Env = append(os.Environ(), envFatalChild+"=1")
+
+	output, err := cmd.CombinedOutput()
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("expected the plan to exit on misuse, got: %v", err)
+	}
+
+	if !strings.Contains(string(output), "network must be assigned to a host") {
+		t.Errorf("expected the fatal message on stderr, got: %q", output)
+	}
+}
+
+func TestPlanValidateSameHost(t *testing.T) {
+	t.Parallel()
+
+	plan := sdk.NewPlan("test").WithLogger(zerolog.Nop())
+	host := plan.Host("deploy@10.0.0.6").Build()
+
+	network := plan.Network("app-net").Host(host).Build()
+	volume := plan.Volume("app-data").Host(host).Build()
+
+	plan.Container("app").
+		Host(host).
+		Image("nginx:latest").
+		Memory("256m").
+		CPUShares(512).
+		CPUs("0.5").
+		PIDsLimit(100).
+		Network(network).
+		Volume(volume, "/data").
+		Volume("/srv/static", "/static", "ro").
+		Build()
+
+	if err := plan.Validate(); err != nil {
+		t.Errorf("expected plan with same-host resources to validate, got: %v", err)
+	}
+}
+
+func TestPlanValidateDuplicateNames(t *testing.T) {
+	t.Parallel()
+
+	plan := sdk.NewPlan("test").WithLogger(zerolog.Nop())
+	web := plan.Host("deploy@10.0.0.6").Build()
+	db := plan.Host("deploy@10.0.0.7").Build()
+
+	newContainer := func(host *sdk.Host) {
+		plan.Container("app").
+			Host(host).
+			Image("nginx:latest").
+			Memory("256m").
+			CPUShares(512).
+			CPUs("0.5").
+			PIDsLimit(100).
+			Build()
+	}
+
+	// The same name on different hosts is fine
+	newContainer(web)
+	newContainer(db)
+	plan.Volume("data").Host(web).Build()
+	plan.Volume("data").Host(db).Build()
+
+	if err := plan.Validate(); err != nil {
+		t.Fatalf("expected names to be unique per host, got: %v", err)
+	}
+
+	newContainer(web)
+	plan.Network("app-net").Host(db).Build()
+	plan.Network("app-net").Host(db).Build()
+
+	err := plan.Validate()
+	if !errors.Is(err, sdk.ErrDuplicateResource) {
+		t.Fatalf("expected ErrDuplicateResource, got: %v", err)
+	}
+
+	for _, want := range []string{"container app is defined more than once on deploy@10.0.0.6", "network app-net"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to mention %q, got: %v", want, err)
+		}
+	}
+}
+
+func TestPlanValidateCrossHost(t *testing.T) {
+	t.Parallel()
+
+	plan := sdk.NewPlan("test").WithLogger(zerolog.Nop())
+	web := plan.Host("deploy@10.0.0.6").Build()
+	db := plan.Host("deploy@10.0.0.7").Build()
+
+	network := plan.Network("app-net").Host(db).Build()
+	volume := plan.Volume("app-data").Host(db).Build()
+
+	plan.Container("app").
+		Host(web).
+		Image("nginx:latest").
+		Memory("256m").
+		CPUShares(512).
+		CPUs("0.5").
+		PIDsLimit(100).
+		Network(network).
+		Volume(volume, "/data").
+		Build()
+
+	err := plan.Validate()
+	if !errors.Is(err, sdk.ErrCrossHostReference) {
+		t.Fatalf("expected ErrCrossHostReference, got: %v", err)
+	}
+
+	for _, want := range []string{"network app-net on deploy@10.0.0.7", "volume app-data on deploy@10.0.0.7"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to mention %q, got: %v", want, err)
+		}
+	}
+
+	if err := plan.Execute(context.Background()); !errors.Is(err, sdk.ErrCrossHostReference) {
+		t.Errorf("expected Execute to refuse the plan before connecting, got: %v", err)
+	}
+}
+
+func TestExecuteRefusesOtherCLIModes(t *testing.T) {
+	t.Setenv("HADRON_STATUS", "true")
+
+	plan := sdk.NewPlan("test").WithLogger(zerolog.Nop())
+
+	err := plan.Execute(context.Background())
+	if !errors.Is(err, sdk.ErrModeNotHandled) {
+		t.Fatalf("expected ErrModeNotHandled, got %v", err)
+	}
+}
+
+func TestRunDispatchesOnCLIMode(t *testing.T) {
+	t.Setenv("HADRON_DRY_RUN", "true")
+
+	plan := sdk.NewPlan("test").WithLogger(zerolog.Nop())
+
+	// An empty plan dry-runs without connecting anywhere; Execute would have refused.
+	if err := plan.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+}
+
+func TestDestroyHonorsDeployTimeout(t *testing.T) {
+	t.Setenv("HADRON_CONFIRM", "true")
+
+	plan := sdk.NewPlan("test").WithLogger(zerolog.Nop()).DeployTimeout(time.Nanosecond)
+	host := plan.Host("deploy@10.0.0.6").Build()
+	plan.Volume("app-data").Host(host).Build()
+
+	// The deadline passes before the volume is reached, so no SSH connection is attempted
+	err := plan.Destroy()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package sdk
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/the-agent-c-ai/hadron/sdk/ssh"
+)
+
+// PostDeployCheck runs a command on the host after all resources are deployed, failing the deploy
+// with ErrPostDeployCheck if it exits non-zero. Checks run in the order they were added. Like hooks,
+// a single argument is run as a shell command line, so pipes work, and several are passed as they are:
+//
+//	plan.PostDeployCheck(web, "curl", "-fsS", "https://example.com/health")
+//	plan.PostDeployCheck(worker, "test $(redis-cli llen jobs) -lt 1000")
+func (p *Plan) PostDeployCheck(host *Host, cmd ...string) *Plan {
+	if host == nil || len(cmd) == 0 {
+		p.logger.Fatal().Msg("post-deploy check requires a host and a command")
+	}
+
+	p.postDeployChecks = append(p.postDeployChecks, hook{stage: stagePostDeployCheck, host: host, command: cmd})
+
+	return p
+}
+
+// runPostDeployChecks runs every post-deploy check, stopping at the first failure.
+func (e *executor) runPostDeployChecks() error {
+	for _, check := range e.plan.postDeployChecks {
+		client, err := e.getSSHClient(check.host)
+		if err != nil {
+			return fmt.Errorf(errFailedSSHClient, check.host, err)
+		}
+
+		if err := e.runPostDeployCheck(client, check); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runPostDeployCheck runs a single post-deploy check on its host.
+func (e *executor) runPostDeployCheck(client ssh.Connection, check hook) error {
+	e.plan.logger.Info().
+		Str("host", check.host.String()).
+		Str("command", check.String()).
+		Msg("Running post-deploy check")
+
+	stdout, stderr, err := client.Execute(check.String())
+	if err != nil {
+		return fmt.Errorf("%w on %s: %s: %w (stdout: %s, stderr: %s)",
+			ErrPostDeployCheck, check.host, check, err, strings.TrimSpace(stdout), strings.TrimSpace(stderr))
+	}
+
+	e.plan.logger.Info().
+		Str("host", check.host.String()).
+		Str("command", check.String()).
+		Msg("Post-deploy check passed")
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package sdk
+
+import (
+	"fmt"
+	"slices"
+)
+
+// envForceRecreate carries the CLI's --force-recreate container names, comma-separated.
+const envForceRecreate = "HADRON_FORCE_RECREATE"
+
+// recreateAll as a ForceRecreate name recreates every deployed container.
+const recreateAll = "all"
+
+// ForceRecreate makes Execute stop, remove, and recreate the named containers even when their
+// configuration and image are unchanged, e.g. to pick up a rotated secret file or clear bad state.
+// The name "all" recreates every container the deploy reaches. Defaults to the CLI's --force-recreate flag.
+func (p *Plan) ForceRecreate(names ...string) *Plan {
+	p.forceRecreate = append(p.forceRecreate, names...)
+
+	return p
+}
+
+// recreates reports whether ForceRecreate names the container.
+func (p *Plan) recreates(container *Container) bool {
+	return slices.Contains(p.forceRecreate, recreateAll) || slices.Contains(p.forceRecreate, container.Name())
+}
+
+// checkForceRecreate fails with ErrUnknownContainer when ForceRecreate names a container the plan
+// does not have, rather than deploying without recreating anything.
+func (p *Plan) checkForceRecreate() error {
+	for _, name := range p.forceRecreate {
+		if name == recreateAll {
+			continue
+		}
+
+		if !slices.ContainsFunc(p.all().containers, func(container *Container) bool {
+			return container.Name() == name
+		}) {
+			return fmt.Errorf("%w: %q is not a container in plan %s", ErrUnknownContainer, name, p.name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package sdk
+
+import (
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/the-agent-c-ai/hadron/internal/docker"
+)
+
+// render writes the docker commands the plan's containers and jobs would run, without connecting to
+// any host. Uploaded files are shown as placeholders since their remote paths depend on content hashes.
+func (e *executor) render(w io.Writer) error {
+	for _, container := range slices.Concat(e.plan.containers, e.plan.jobs) {
+		opts := e.runOptions(container, renderVolumes(container))
+
+		header := fmt.Sprintf("# %s on %s", container.Name(), container.host)
+		if container.schedule != "" {
+			opts.RunOnce = true
+			header += " (scheduled " + container.schedule + ")"
+		}
+
+		if _, err := fmt.Fprintln(w, header); err != nil {
+			return fmt.Errorf("failed to write render output: %w", err)
+		}
+
+		commands := make([]string, 0, len(container.initContainers)+len(container.networks))
+
+		for _, initContainer := range container.initContainers {
+			commands = append(commands, docker.RenderRunCommand(initOptions(opts, initContainer)))
+		}
+
+		commands = append(commands, docker.RenderRunCommand(opts))
+
+		for i := 1; i < len(container.networks); i++ {
+			commands = append(
+				commands,
+				fmt.Sprintf("docker network connect %s %s", container.networks[i].Name(), container.name),
+			)
+		}
+
+		for _, command := range commands {
+			if _, err := fmt.Fprintln(w, command); err != nil {
+				return fmt.Errorf("failed to write render output: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// renderVolumes returns the container's volume mounts with placeholders for uploaded content.
+func renderVolumes(container *Container) []docker.VolumeMount {
+	volumes := make(
+		[]docker.VolumeMount, 0,
+		len(container.volumes)+len(container.mounts)+len(container.dataMounts)+len(container.secretMounts),
+	)
+
+	for _, v := range container.volumes {
+		volumes = append(volumes, docker.VolumeMount{Source: v.source, Target: v.target, Mode: v.mode})
+	}
+
+	for _, mount := range container.mounts {
+		volumes = append(volumes, docker.VolumeMount{
+			Source: "<upload:" + mount.localPath + ">",
+			Target: mount.containerPath,
+			Mode:   mount.mode,
+		})
+	}
+
+	for _, mount := range container.dataMounts {
+		volumes = append(volumes, docker.VolumeMount{
+			Source: fmt.Sprintf("<data:%d bytes>", len(mount.data)),
+			Target: mount.containerPath,
+			Mode:   mount.mode,
+		})
+	}
+
+	for _, mount := range container.secretMounts {
+		volumes = append(volumes, docker.VolumeMount{
+			Source: "<secret:" + mount.reference + ">",
+			Target: mount.containerPath,
+			Mode:   "ro",
+		})
+	}
+
+	return volumes
+}
@@ -0,0 +1,114 @@
+package sdk_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/the-agent-c-ai/hadron/sdk"
+)
+
+func TestPlanRender(t *testing.T) {
+	t.Parallel()
+
+	plan := sdk.NewPlan("test").WithLogger(zerolog.Nop())
+
+	host := plan.Host("deploy@10.0.0.6").
+		Build()
+
+	network := plan.Network("app-net").
+		Host(host).
+		Build()
+
+	plan.Container("web").
+		Host(host).
+		Image("nginx:latest").
+		Network(network).
+		Port("80:80").
+		Env("SECRET_TOKEN", "hunter2").
+		Memory("256m").
+		CPUShares(512).
+		CPUs("0.5").
+		PIDsLimit(100).
+		Build()
+
+	var buf bytes.Buffer
+	if err := plan.Render(&buf); err != nil {
+		t.Fatalf("expected render to succeed, got: %v", err)
+	}
+
+	output := buf.String()
+
+	for _, want := range []string{
+		"# web on deploy@10.0.0.6",
+		"docker run -d --name web",
+		"--memory 256m",
+		"--network app-net",
+		"-p 80:80",
+		"--env-file <generated:SECRET_TOKEN>",
+		" nginx:latest",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected render output to contain %q, got:\n%s", want, output)
+		}
+	}
+
+	if strings.Contains(output, "hunter2") {
+		t.Errorf("expected render output not to leak env values, got:\n%s", output)
+	}
+}
+
+func TestRenderPullPolicy(t *testing.T) {
+	t.Parallel()
+
+	plan := sdk.NewPlan("test").WithLogger(zerolog.Nop())
+
+	host := plan.Host("deploy@10.0.0.6").
+		Build()
+
+	policies := map[string]sdk.PullPolicy{
+		"always":  sdk.PullAlways,
+		"missing": sdk.PullMissing,
+		"never":   sdk.PullNever,
+		"default": "",
+	}
+
+	for name, policy := range policies {
+		builder := plan.Container(name).
+			Host(host).
+			Image("nginx@sha256:0123456789abcdef").
+			Memory("256m").
+			CPUShares(512).
+			CPUs("0.5").
+			PIDsLimit(100)
+
+		if policy != "" {
+			builder.PullPolicy(policy)
+		}
+
+		builder.Build()
+	}
+
+	var buf bytes.Buffer
+	if err := plan.Render(&buf); err != nil {
+		t.Fatalf("expected render to succeed, got: %v", err)
+	}
+
+	output := buf.String()
+
+	for _, want := range []string{
+		"docker run -d --name always --pull always ",
+		"docker run -d --name missing --pull missing ",
+		"docker run -d --name never --pull never ",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected render output to contain %q, got:\n%s", want, output)
+		}
+	}
+
+	if strings.Contains(output, "--name default --pull") {
+		t.Errorf("expected no --pull flag without a pull policy, got:\n%s", output)
+	}
+}
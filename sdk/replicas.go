@@ -0,0 +1,165 @@
+package sdk
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// invalidNameChars matches characters Docker does not allow in container names.
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// Hosts runs the container on every given host instead of a single Host. BuildReplicas then
+// registers one container per host, named "<name>-<host>" (e.g. "web-10.0.0.1" for "deploy@10.0.0.1"), with the
+// same configuration. Each replica is deployed, hashed, and health-checked independently.
+//
+// Networks and volumes are per host, so each replica uses the plan's network or volume of the same
+// name on its own host: declare them on every replica host. On those networks, replicas are
+// reachable by the base name unless NetworkAlias sets another alias.
+func (cb *ContainerBuilder) Hosts(hosts ...*Host) *ContainerBuilder {
+	cb.replicaHosts = append(cb.replicaHosts, hosts...)
+
+	return cb
+}
+
+// BuildReplicas creates one container per host given to Hosts and registers them with the plan.
+// An invalid configuration is fatal; use BuildReplicasE to handle it as an error instead.
+func (cb *ContainerBuilder) BuildReplicas() []*Container {
+	containers, err := cb.BuildReplicasE()
+	if err != nil {
+		cb.plan.logger.Fatal().Err(err).Str("container", cb.name).Msg("invalid container")
+	}
+
+	return containers
+}
+
+// BuildReplicasE creates one container per host given to Hosts and registers them with the plan,
+// returning an error instead of exiting when the configuration is invalid. Nothing is registered
+// unless every replica is valid.
+func (cb *ContainerBuilder) BuildReplicasE() ([]*Container, error) {
+	if len(cb.replicaHosts) == 0 {
+		return nil, fmt.Errorf("%w: %s: replicas need at least one host (use Hosts)", ErrInvalidContainer, cb.name)
+	}
+
+	if cb.host != nil {
+		return nil, fmt.Errorf("%w: %s: use either Host or Hosts, not both", ErrInvalidContainer, cb.name)
+	}
+
+	replicas := make([]*ContainerBuilder, 0, len(cb.replicaHosts))
+
+	for _, host := range cb.replicaHosts {
+		replica, err := cb.replicaOn(host)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := replica.validate(); err != nil {
+			return nil, err
+		}
+
+		replicas = append(replicas, replica)
+	}
+
+	containers := make([]*Container, 0, len(replicas))
+
+	for _, replica := range replicas {
+		container, err := replica.BuildE()
+		if err != nil {
+			return nil, err
+		}
+
+		containers = append(containers, container)
+	}
+
+	return containers, nil
+}
+
+// replicaOn returns a copy of the builder for one replica host, with networks and volumes swapped
+// for the same-named ones on that host.
+func (cb *ContainerBuilder) replicaOn(host *Host) (*ContainerBuilder, error) {
+	replica := *cb
+	replica.replicaHosts = nil
+	replica.host = host
+	replica.name = cb.name + "-" + replicaSuffix(host)
+
+	// BuildE appends to extraHosts, which must not leak into the other replicas
+	replica.extraHosts = slices.Clone(cb.extraHosts)
+
+	if replica.networkAlias == "" && len(cb.networks) > 0 {
+		replica.networkAlias = cb.name
+	}
+
+	var errs []error
+
+	replica.networks = make([]*Network, 0, len(cb.networks))
+
+	for _, network := range cb.networks {
+		local := cb.plan.networkOn(network.Name(), host)
+		if local == nil {
+			errs = append(errs, fmt.Errorf("%w: %s: network %s is not declared on %s",
+				ErrInvalidContainer, replica.name, network.Name(), host))
+
+			continue
+		}
+
+		replica.networks = append(replica.networks, local)
+
+		if network == cb.primaryNetwork {
+			replica.primaryNetwork = local
+		}
+	}
+
+	replica.volumes = make([]VolumeMount, 0, len(cb.volumes))
+
+	for _, mount := range cb.volumes {
+		if mount.volume != nil {
+			local := cb.plan.volumeOn(mount.volume.Name(), host)
+			if local == nil {
+				errs = append(errs, fmt.Errorf("%w: %s: volume %s is not declared on %s",
+					ErrInvalidContainer, replica.name, mount.volume.Name(), host))
+
+				continue
+			}
+
+			mount.volume = local
+		}
+
+		replica.volumes = append(replica.volumes, mount)
+	}
+
+	return &replica, errors.Join(errs...)
+}
+
+// replicaSuffix identifies a replica's host in its container name: the endpoint without the user.
+func replicaSuffix(host *Host) string {
+	_, hostname, found := strings.Cut(host.Endpoint(), "@")
+	if !found {
+		hostname = host.Endpoint()
+	}
+
+	return invalidNameChars.ReplaceAllString(hostname, "-")
+}
+
+// networkOn returns the plan's network with the given name on host, or nil.
+func (p *Plan) networkOn(name string, host *Host) *Network {
+	for _, network := range p.networks {
+		if network.Name() == name && network.Host() == host {
+			return network
+		}
+	}
+
+	return nil
+}
+
+// volumeOn returns the plan's volume with the given name on host, or nil.
+func (p *Plan) volumeOn(name string, host *Host) *Volume {
+	for _, volume := range p.volumes {
+		if volume.Name() == name && volume.Host() == host {
+			return volume
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package sdk_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/the-agent-c-ai/hadron/sdk"
+)
+
+func TestContainerReplicas(t *testing.T) {
+	t.Parallel()
+
+	plan := sdk.NewPlan("test").WithLogger(zerolog.Nop())
+	first := plan.Host("deploy@10.0.0.1").Build()
+	second := plan.Host("deploy@web2.example.com").Build()
+
+	firstNet := plan.Network("app").Host(first).Build()
+	secondNet := plan.Network("app").Host(second).Build()
+	plan.Volume("cache").Host(first).Build()
+	plan.Volume("cache").Host(second).Build()
+
+	replicas := plan.Container("web").
+		Hosts(first, second).
+		Image("nginx:latest").
+		Memory("256m").
+		CPUShares(512).
+		CPUs("0.5").
+		PIDsLimit(100).
+		Network(firstNet).
+		Volume("cache", "/var/cache/nginx").
+		BuildReplicas()
+
+	if len(replicas) != 2 {
+		t.Fatalf("expected one replica per host, got %d", len(replicas))
+	}
+
+	want := []struct {
+		name    string
+		host    *sdk.Host
+		network *sdk.Network
+	}{
+		{name: "web-10.0.0.1", host: first, network: firstNet},
+		{name: "web-web2.example.com", host: second, network: secondNet},
+	}
+
+	for i, replica := range replicas {
+		if replica.Name() != want[i].name || replica.Host() != want[i].host {
+			t.Errorf("expected %s on %s, got %s on %s", want[i].name, want[i].host, replica.Name(), replica.Host())
+		}
+
+		if len(replica.Networks()) != 1 || replica.Networks()[0] != want[i].network {
+			t.Errorf("expected %s to use the app network on its own host, got %v", replica.Name(), replica.Networks())
+		}
+
+		if replica.NetworkAlias() != "web" {
+			t.Errorf("expected %s to be reachable as web, got alias %q", replica.Name(), replica.NetworkAlias())
+		}
+	}
+
+	if replicas[0].ConfigHash() == replicas[1].ConfigHash() {
+		t.Error("expected replicas to be hashed independently")
+	}
+
+	if err := plan.Validate(); err != nil {
+		t.Errorf("expected replicas to pass plan validation, got: %v", err)
+	}
+}
+
+func TestContainerReplicasRequireResourcesOnEveryHost(t *testing.T) {
+	t.Parallel()
+
+	plan := sdk.NewPlan("test").WithLogger(zerolog.Nop())
+	first := plan.Host("deploy@10.0.0.1").Build()
+	second := plan.Host("deploy@10.0.0.2").Build()
+
+	network := plan.Network("app").Host(first).Build()
+
+	_, err := plan.Container("web").
+		Hosts(first, second).
+		Image("nginx:latest").
+		Memory("256m").
+		CPUShares(512).
+		CPUs("0.5").
+		PIDsLimit(100).
+		Network(network).
+		BuildReplicasE()
+	if !errors.Is(err, sdk.ErrInvalidContainer) {
+		t.Fatalf("expected ErrInvalidContainer for a network missing on a replica host, got: %v", err)
+	}
+
+	if len(plan.Containers()) != 0 {
+		t.Errorf("expected no replica to be registered, got %d", len(plan.Containers()))
+	}
+}
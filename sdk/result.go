@@ -0,0 +1,129 @@
+package sdk
+
+import (
+	"sort"
+	"time"
+)
+
+// Result summarizes the resource actions of one Execute, in the order they finished.
+// Resources the deploy never reached (because an earlier step failed) are not listed.
+type Result struct {
+	Resources []ResourceResult
+}
+
+// ResourceResult is the outcome of deploying one network, volume, or container.
+type ResourceResult struct {
+	Type     string // "network", "volume", or "container"
+	Name     string
+	Host     string
+	Action   Action // the attempted action if Err is set
+	Duration time.Duration
+	Err      error
+}
+
+// Changed reports whether any resource was created, updated, or restarted.
+func (r *Result) Changed() bool {
+	for _, resource := range r.Resources {
+		if resource.Err == nil && resource.Action != ActionSkip {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Failed returns the resources whose action failed.
+func (r *Result) Failed() []ResourceResult {
+	var failed []ResourceResult
+
+	for _, resource := range r.Resources {
+		if resource.Err != nil {
+			failed = append(failed, resource)
+		}
+	}
+
+	return failed
+}
+
+// summaryCounts tallies the outcomes of one resource type on one host.
+type summaryCounts struct {
+	host, resourceType                             string
+	created, updated, unchanged, restarted, failed int
+}
+
+// summary tallies outcomes per host and resource type, sorted by host then type.
+func (r *Result) summary() []summaryCounts {
+	index := make(map[[2]string]*summaryCounts)
+
+	var counts []*summaryCounts
+
+	for _, resource := range r.Resources {
+		key := [2]string{resource.Host, resource.Type}
+
+		c, ok := index[key]
+		if !ok {
+			c = &summaryCounts{host: resource.Host, resourceType: resource.Type}
+			index[key] = c
+			counts = append(counts, c)
+		}
+
+		switch {
+		case resource.Err != nil:
+			c.failed++
+		case resource.Action == ActionCreate:
+			c.created++
+		case resource.Action == ActionUpdate:
+			c.updated++
+		case resource.Action == ActionRestart:
+			c.restarted++
+		default:
+			c.unchanged++
+		}
+	}
+
+	sort.SliceStable(counts, func(i, j int) bool {
+		if counts[i].host != counts[j].host {
+			return counts[i].host < counts[j].host
+		}
+
+		return counts[i].resourceType < counts[j].resourceType
+	})
+
+	summary := make([]summaryCounts, 0, len(counts))
+	for _, c := range counts {
+		summary = append(summary, *c)
+	}
+
+	return summary
+}
+
+// logSummary logs a recap of what the deploy changed, one line per host and resource type.
+func (e *executor) logSummary() {
+	e.resultMu.Lock()
+	defer e.resultMu.Unlock()
+
+	message := "Deploy summary"
+	if e.mode == modeDryRun {
+		message = "Dry run summary"
+	}
+
+	for _, c := range e.result.summary() {
+		e.plan.logger.Info().
+			Str("host", c.host).
+			Str("type", c.resourceType).
+			Int("created", c.created).
+			Int("updated", c.updated).
+			Int("unchanged", c.unchanged).
+			Int("restarted", c.restarted).
+			Int("failed", c.failed).
+			Msg(message)
+	}
+}
+
+// record adds a resource outcome to the deploy result.
+func (e *executor) record(resource ResourceResult) {
+	e.resultMu.Lock()
+	defer e.resultMu.Unlock()
+
+	e.result.Resources = append(e.result.Resources, resource)
+}
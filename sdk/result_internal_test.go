@@ -0,0 +1,64 @@
+package sdk
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestResultSummary(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("web").WithLogger(zerolog.Nop())
+	web := plan.Host("deploy@10.0.0.5").Build()
+	db := plan.Host("deploy@10.0.0.6").Build()
+
+	exec := newExecutor(plan)
+
+	outcomes := []struct {
+		network *Network
+		action  Action
+		err     error
+	}{
+		{plan.Network("frontend").Host(web).Build(), ActionCreate, nil},
+		{plan.Network("backend").Host(web).Build(), ActionSkip, nil},
+		{plan.Network("monitoring").Host(web).Build(), ActionUpdate, errCreateFailed},
+		{plan.Network("db-net").Host(db).Build(), ActionSkip, nil},
+	}
+
+	for _, outcome := range outcomes {
+		_ = tracked(exec, "network", func(*Network) (Action, error) {
+			return outcome.action, outcome.err
+		})(outcome.network)
+	}
+
+	want := []summaryCounts{
+		{host: "deploy@10.0.0.5", resourceType: "network", created: 1, unchanged: 1, failed: 1},
+		{host: "deploy@10.0.0.6", resourceType: "network", unchanged: 1},
+	}
+	if got := exec.result.summary(); !slices.Equal(got, want) {
+		t.Errorf("expected summary %+v, got %+v", want, got)
+	}
+
+	if !exec.result.Changed() {
+		t.Error("expected result to report a change")
+	}
+
+	if failed := exec.result.Failed(); len(failed) != 1 || failed[0].Name != "monitoring" {
+		t.Errorf("expected monitoring to be the only failure, got %+v", failed)
+	}
+}
+
+func TestResultUnchanged(t *testing.T) {
+	t.Parallel()
+
+	result := Result{Resources: []ResourceResult{
+		{Type: "container", Name: "app", Action: ActionSkip},
+		{Type: "container", Name: "worker", Action: ActionCreate, Err: errCreateFailed},
+	}}
+
+	if result.Changed() {
+		t.Error("expected skipped and failed actions not to count as changes")
+	}
+}
@@ -0,0 +1,75 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// Environment variables the hadron CLI sets to select what a Go plan does when it is run.
+const (
+	envDryRun      = "HADRON_DRY_RUN"
+	envDestroy     = "HADRON_DESTROY"
+	envRender      = "HADRON_RENDER"
+	envStatus      = "HADRON_STATUS"
+	envLogs        = "HADRON_LOGS"         // container name
+	envLogsFollow  = "HADRON_LOGS_FOLLOW"  // "true" to keep streaming
+	envLogsTail    = "HADRON_LOGS_TAIL"    // number of lines
+	envExec        = "HADRON_EXEC"         // container name
+	envExecCommand = "HADRON_EXEC_COMMAND" // JSON array of command arguments
+)
+
+// Run does what the hadron CLI asked for when it ran the plan: deploy, dry-run, destroy, render,
+// status, logs, or exec, as selected by the HADRON_* environment variables the CLI sets. Go plans
+// should call Run rather than Execute so every CLI command works:
+//
+//	if err := plan.Run(ctx); err != nil {
+//		log.Fatal(err)
+//	}
+func (p *Plan) Run(ctx context.Context) error {
+	switch {
+	case os.Getenv(envDestroy) == "true":
+		return p.Destroy()
+	case os.Getenv(envRender) == "true":
+		return p.Render(os.Stdout)
+	case os.Getenv(envLogs) != "":
+		tail, _ := strconv.Atoi(os.Getenv(envLogsTail))
+		opts := LogsOptions{Follow: os.Getenv(envLogsFollow) == "true", Tail: tail}
+
+		return p.Logs(os.Getenv(envLogs), opts, os.Stdout, os.Stderr)
+	case os.Getenv(envExec) != "":
+		var command []string
+		if err := json.Unmarshal([]byte(os.Getenv(envExecCommand)), &command); err != nil {
+			return fmt.Errorf("invalid %s: %w", envExecCommand, err)
+		}
+
+		return p.Exec(os.Getenv(envExec), command...)
+	case os.Getenv(envStatus) == "true":
+		return p.Status(ctx, os.Stdout)
+	case os.Getenv(envDryRun) == "true":
+		return p.DryRun()
+	default:
+		return p.Execute(ctx)
+	}
+}
+
+// modeFromEnv returns the HADRON_* variable that asks for something other than a deploy, or ""
+// when none is set. Execute refuses to deploy when one is set: the plan was run by a read-only or
+// destructive CLI command but does not dispatch on it (see Run).
+func modeFromEnv() string {
+	for _, key := range []string{envDestroy, envRender, envStatus, envDryRun} {
+		if os.Getenv(key) == "true" {
+			return key
+		}
+	}
+
+	for _, key := range []string{envLogs, envExec} {
+		if os.Getenv(key) != "" {
+			return key
+		}
+	}
+
+	return ""
+}
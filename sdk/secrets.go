@@ -0,0 +1,184 @@
+package sdk
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SecretProvider resolves secret references (e.g., "op://vault/item/field") to their values.
+type SecretProvider interface {
+	GetSecret(ctx context.Context, reference string) (string, error)
+}
+
+// SecretMount represents a secret written to a read-only file inside the container.
+type SecretMount struct {
+	reference     string // secret reference resolved at execute time
+	containerPath string // container mount path
+}
+
+// defaultSecretProvider sends vault:// references to Vault, awssm:// references to AWS Secrets
+// Manager, and everything else to 1Password.
+type defaultSecretProvider struct {
+	onePassword onePasswordProvider
+	vault       VaultProvider
+	aws         AWSSecretsProvider
+}
+
+// GetSecret resolves the reference with the backend its scheme selects.
+func (p *defaultSecretProvider) GetSecret(ctx context.Context, reference string) (string, error) {
+	switch {
+	case strings.HasPrefix(reference, vaultScheme):
+		return p.vault.GetSecret(ctx, reference)
+	case strings.HasPrefix(reference, awsSecretsScheme):
+		return p.aws.GetSecret(ctx, reference)
+	default:
+		return p.onePassword.GetSecret(ctx, reference)
+	}
+}
+
+// onePasswordProvider resolves secrets with the 1Password CLI, authenticating once per run
+// so resolving many secrets prompts at most once.
+type onePasswordProvider struct {
+	authenticated bool
+}
+
+// GetSecret authenticates on first use and resolves the reference with GetSecret.
+func (p *onePasswordProvider) GetSecret(ctx context.Context, reference string) (string, error) {
+	if !p.authenticated {
+		if err := AuthenticateOp(ctx); err != nil {
+			return "", err
+		}
+
+		p.authenticated = true
+	}
+
+	return GetSecret(ctx, reference)
+}
+
+// resolveSecrets resolves every secret the container references through the plan's provider.
+// It runs on every deploy, before the config hash is computed, so a secret rotated in the
+// provider changes the hash and the container is recreated even if the plan is unchanged.
+func (e *executor) resolveSecrets(ctx context.Context, container *Container) error {
+	references := make([]string, 0, len(container.envSecrets)+len(container.envTemplates)+len(container.secretMounts))
+	for _, reference := range container.envSecrets {
+		references = append(references, reference)
+	}
+
+	for _, template := range container.envTemplates {
+		references = append(references, secretPlaceholders(template)...)
+	}
+
+	for _, mount := range container.secretMounts {
+		references = append(references, mount.reference)
+	}
+
+	resolved := make(map[string]string, len(references))
+
+	for _, reference := range references {
+		if _, ok := resolved[reference]; ok {
+			continue
+		}
+
+		value, err := e.plan.secrets.GetSecret(ctx, reference)
+		if err != nil {
+			return fmt.Errorf("failed to resolve secret for container %s: %w", container.Name(), err)
+		}
+
+		resolved[reference] = value
+	}
+
+	container.resolvedSecrets = resolved
+
+	return nil
+}
+
+// secretHashParts returns config hash entries for the container's secrets. Resolved secrets
+// contribute a digest of their value (never the value itself); unresolved ones their reference.
+func (c *Container) secretHashParts() []string {
+	parts := make([]string, 0, len(c.envSecrets)+len(c.envTemplates)+len(c.secretMounts))
+
+	keys := make([]string, 0, len(c.envSecrets))
+	for key := range c.envSecrets {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("envsecret:%s=%s", key, c.secretDigest(c.envSecrets[key])))
+	}
+
+	templateKeys := make([]string, 0, len(c.envTemplates))
+	for key := range c.envTemplates {
+		templateKeys = append(templateKeys, key)
+	}
+
+	sort.Strings(templateKeys)
+
+	// Placeholders are replaced by digests, so the hash follows rotation without embedding values
+	for _, key := range templateKeys {
+		template := interpolateSecrets(c.envTemplates[key], c.secretDigest)
+		parts = append(parts, fmt.Sprintf("envtemplate:%s=%s", key, template))
+	}
+
+	for _, mount := range c.secretMounts {
+		parts = append(parts, fmt.Sprintf("secretmount:%s:%s", c.secretDigest(mount.reference), mount.containerPath))
+	}
+
+	return parts
+}
+
+// secretDigest identifies a secret's current value for hashing.
+func (c *Container) secretDigest(reference string) string {
+	value, ok := c.resolvedSecrets[reference]
+	if !ok {
+		return "ref:" + reference
+	}
+
+	digest := sha256.Sum256([]byte(value))
+
+	return hex.EncodeToString(digest[:])
+}
+
+// jsonSecretValue returns a field of a JSON secret as a string. Numbers, booleans, and nested
+// objects are returned in their JSON form.
+func jsonSecretValue(value any) (string, error) {
+	if secret, ok := value.(string); ok {
+		return secret, nil
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode secret value: %w", err)
+	}
+
+	return string(encoded), nil
+}
+
+// secretPlaceholder matches a "${scheme://...}" secret reference inside an EnvFromSecret value.
+var secretPlaceholder = regexp.MustCompile(`\$\{([a-z][a-z0-9]*://[^}]+)\}`)
+
+// secretPlaceholders returns the secret references embedded in an EnvFromSecret value.
+func secretPlaceholders(template string) []string {
+	matches := secretPlaceholder.FindAllStringSubmatch(template, -1)
+
+	references := make([]string, 0, len(matches))
+	for _, match := range matches {
+		references = append(references, match[1])
+	}
+
+	return references
+}
+
+// interpolateSecrets replaces each placeholder in an EnvFromSecret value with lookup(reference).
+func interpolateSecrets(template string, lookup func(reference string) string) string {
+	return secretPlaceholder.ReplaceAllStringFunc(template, func(placeholder string) string {
+		return lookup(secretPlaceholder.FindStringSubmatch(placeholder)[1])
+	})
+}
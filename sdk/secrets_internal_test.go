@@ -0,0 +1,162 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+// fakeSecretProvider returns values from an in-memory map and counts lookups.
+type fakeSecretProvider struct {
+	values  map[string]string
+	lookups int
+}
+
+func (f *fakeSecretProvider) GetSecret(_ context.Context, reference string) (string, error) {
+	f.lookups++
+
+	return f.values[reference], nil
+}
+
+func TestSecretRotationChangesConfigHash(t *testing.T) {
+	t.Parallel()
+
+	const (
+		passwordRef = "op://Production/database/password"
+		tlsKeyRef   = "op://Production/tls/key"
+	)
+
+	provider := &fakeSecretProvider{values: map[string]string{
+		passwordRef: "first-password",
+		tlsKeyRef:   "first-key",
+	}}
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop()).WithSecretProvider(provider)
+	host := plan.Host("user@192.168.1.1").Build()
+
+	container := plan.Container("app").
+		Host(host).
+		Image("nginx:latest").
+		Memory("256m").
+		CPUShares(512).
+		CPUs("0.5").
+		PIDsLimit(100).
+		EnvSecret("DB_PASSWORD", passwordRef).
+		MountSecret(tlsKeyRef, "/run/secrets/tls.key").
+		Build()
+
+	exec := newExecutor(plan)
+	ctx := context.Background()
+
+	// First deploy: secrets resolved, hash recorded on the container label
+	if err := exec.resolveSecrets(ctx, container); err != nil {
+		t.Fatalf("expected secrets to resolve, got: %v", err)
+	}
+
+	deployedHash := container.ConfigHash()
+
+	// Next deploy with unchanged secrets: hash matches, container is skipped
+	if err := exec.resolveSecrets(ctx, container); err != nil {
+		t.Fatalf("expected secrets to resolve, got: %v", err)
+	}
+
+	if container.ConfigHash() != deployedHash {
+		t.Fatal("expected unchanged secrets to keep the config hash")
+	}
+
+	// Rotate the password in the provider: the next deploy sees a new hash and recreates the container
+	provider.values[passwordRef] = "rotated-password"
+
+	if err := exec.resolveSecrets(ctx, container); err != nil {
+		t.Fatalf("expected secrets to resolve, got: %v", err)
+	}
+
+	if container.ConfigHash() == deployedHash {
+		t.Error("expected rotated secret to change the config hash")
+	}
+
+	opts := exec.runOptions(container, nil)
+	if opts.EnvVars["DB_PASSWORD"] != "rotated-password" {
+		t.Errorf("expected rotated secret in env, got %q", opts.EnvVars["DB_PASSWORD"])
+	}
+
+	if provider.lookups != 6 {
+		t.Errorf("expected secrets to be resolved on every deploy (6 lookups), got %d", provider.lookups)
+	}
+}
+
+func TestEnvFromSecretInterpolatesAtDeploy(t *testing.T) {
+	t.Parallel()
+
+	const (
+		userRef     = "vault://secret/data/database#user"
+		passwordRef = "op://Production/database/password"
+		template    = "postgres://${" + userRef + "}:${" + passwordRef + "}@db:5432/app"
+	)
+
+	provider := &fakeSecretProvider{values: map[string]string{userRef: "app", passwordRef: "first-password"}}
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop()).WithSecretProvider(provider)
+	host := plan.Host("user@192.168.1.1").Build()
+
+	container := plan.Container("app").
+		Host(host).
+		Image("nginx:latest").
+		Memory("256m").
+		CPUShares(512).
+		CPUs("0.5").
+		PIDsLimit(100).
+		EnvFromSecret("DATABASE_URL", template).
+		Build()
+
+	exec := newExecutor(plan)
+	ctx := context.Background()
+
+	if err := exec.resolveSecrets(ctx, container); err != nil {
+		t.Fatalf("expected secrets to resolve, got: %v", err)
+	}
+
+	deployedHash := container.ConfigHash()
+
+	want := "postgres://app:first-password@db:5432/app"
+	if got := exec.runOptions(container, nil).EnvVars["DATABASE_URL"]; got != want {
+		t.Errorf("expected interpolated connection string, got %q", got)
+	}
+
+	if strings.Contains(strings.Join(container.secretHashParts(), "\n"), "first-password") {
+		t.Error("expected the config hash input not to contain the secret value")
+	}
+
+	provider.values[passwordRef] = "rotated-password"
+
+	if err := exec.resolveSecrets(ctx, container); err != nil {
+		t.Fatalf("expected secrets to resolve, got: %v", err)
+	}
+
+	if container.ConfigHash() == deployedHash {
+		t.Error("expected a rotated embedded secret to change the config hash")
+	}
+}
+
+func TestEnvFromSecretRequiresReference(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop())
+	host := plan.Host("user@192.168.1.1").Build()
+
+	_, err := plan.Container("app").
+		Host(host).
+		Image("nginx:latest").
+		Memory("256m").
+		CPUShares(512).
+		CPUs("0.5").
+		PIDsLimit(100).
+		EnvFromSecret("DATABASE_URL", "postgres://app@db:5432/app").
+		BuildE()
+	if !errors.Is(err, ErrInvalidContainer) {
+		t.Errorf("expected ErrInvalidContainer for a value without placeholders, got: %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package sdk
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// envOnly and envSkip carry the CLI's --only and --skip container names, comma-separated.
+const (
+	envOnly = "HADRON_ONLY"
+	envSkip = "HADRON_SKIP"
+)
+
+// Only restricts Execute to the named containers and jobs and the containers they depend on, so
+// fixing one service does not redeploy the whole plan. Hosts, networks, and volumes are still deployed.
+// Defaults to the CLI's --only flag.
+func (p *Plan) Only(names ...string) *Plan {
+	p.only = append(p.only, names...)
+
+	return p
+}
+
+// Skip excludes the named containers and jobs from Execute; they are left as they are on the host, never removed.
+// Defaults to the CLI's --skip flag.
+func (p *Plan) Skip(names ...string) *Plan {
+	p.skip = append(p.skip, names...)
+
+	return p
+}
+
+// selectedContainers returns the containers Execute deploys, in plan order. With Only set, the
+// named containers and their transitive dependencies are selected; Skip then removes containers,
+// even dependencies. Unknown names fail with ErrUnknownContainer rather than deploying nothing.
+func (p *Plan) selectedContainers() ([]*Container, error) {
+	if len(p.only) == 0 && len(p.skip) == 0 {
+		return p.containers, nil
+	}
+
+	// Names are checked against the whole plan, so --only/--skip compose with --host
+	byName := make(map[string]bool, len(p.all().containers)+len(p.all().jobs))
+	for _, container := range slices.Concat(p.all().containers, p.all().jobs) {
+		byName[container.Name()] = true
+	}
+
+	for _, name := range slices.Concat(p.only, p.skip) {
+		if !byName[name] {
+			return nil, fmt.Errorf("%w: %q is not a container in plan %s", ErrUnknownContainer, name, p.name)
+		}
+	}
+
+	selected := make(map[*Container]bool, len(p.containers))
+
+	var include func(container *Container)
+
+	include = func(container *Container) {
+		if selected[container] {
+			return
+		}
+
+		selected[container] = true
+
+		for _, dep := range container.DependsOn() {
+			include(dep)
+		}
+	}
+
+	for _, container := range p.containers {
+		if len(p.only) == 0 || slices.Contains(p.only, container.Name()) {
+			include(container)
+		}
+	}
+
+	// A selected job needs the containers it depends on, but is not deployed as a container itself
+	for _, job := range p.jobs {
+		if slices.Contains(p.only, job.Name()) {
+			for _, dep := range job.DependsOn() {
+				include(dep)
+			}
+		}
+	}
+
+	containers := make([]*Container, 0, len(selected))
+
+	for _, container := range p.containers {
+		if selected[container] && !slices.Contains(p.skip, container.Name()) {
+			containers = append(containers, container)
+		}
+	}
+
+	return containers, nil
+}
+
+// namesFromEnv splits a comma-separated list of container names or host endpoints set by the CLI.
+func namesFromEnv(key string) []string {
+	var names []string
+
+	for name := range strings.SplitSeq(os.Getenv(key), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
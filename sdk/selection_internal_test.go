@@ -0,0 +1,130 @@
+package sdk
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestSelectedContainers(t *testing.T) {
+	t.Parallel()
+
+	names := func(containers []*Container) []string {
+		result := make([]string, 0, len(containers))
+		for _, container := range containers {
+			result = append(result, container.Name())
+		}
+
+		return result
+	}
+
+	newPlan := func() *Plan {
+		plan := NewPlan("test").WithLogger(zerolog.Nop())
+		host := plan.Host("user@192.168.1.1").Build()
+
+		newContainer := func(name string, deps ...*Container) *Container {
+			builder := plan.Container(name).
+				Host(host).
+				Image("nginx:latest").
+				Memory("256m").
+				CPUShares(512).
+				CPUs("0.5").
+				PIDsLimit(100)
+
+			for _, dep := range deps {
+				builder.DependsOn(dep)
+			}
+
+			return builder.Build()
+		}
+
+		database := newContainer("database")
+		cache := newContainer("cache")
+		newContainer("app", database, cache)
+		newContainer("worker", database)
+
+		return plan
+	}
+
+	tests := []struct {
+		name string
+		only []string
+		skip []string
+		want []string
+	}{
+		{name: "everything by default", want: []string{"database", "cache", "app", "worker"}},
+		{name: "only pulls in dependencies", only: []string{"app"}, want: []string{"database", "cache", "app"}},
+		{name: "skip", skip: []string{"worker"}, want: []string{"database", "cache", "app"}},
+		{
+			name: "skip wins over dependencies",
+			only: []string{"app", "worker"},
+			skip: []string{"database"},
+			want: []string{"cache", "app", "worker"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			plan := newPlan().Only(tt.only...).Skip(tt.skip...)
+
+			selected, err := plan.selectedContainers()
+			if err != nil {
+				t.Fatalf("expected selection to succeed, got: %v", err)
+			}
+
+			if got := names(selected); !slices.Equal(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := newPlan().Only("ap").selectedContainers(); !errors.Is(err, ErrUnknownContainer) {
+		t.Errorf("expected ErrUnknownContainer for a misspelled name, got: %v", err)
+	}
+}
+
+func TestForceRecreate(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop())
+	host := plan.Host("user@192.168.1.1").Build()
+	newContainer := func(name string) *Container {
+		return plan.Container(name).
+			Host(host).
+			Image("nginx:latest").
+			Memory("256m").
+			CPUShares(512).
+			CPUs("0.5").
+			PIDsLimit(100).
+			Build()
+	}
+
+	web := newContainer("web")
+	api := newContainer("api")
+
+	if plan.recreates(web) || plan.checkForceRecreate() != nil {
+		t.Fatal("expected no container to be recreated by default")
+	}
+
+	plan.ForceRecreate("web")
+
+	if !plan.recreates(web) || plan.recreates(api) {
+		t.Errorf("expected only web to be recreated")
+	}
+
+	plan.ForceRecreate(recreateAll)
+
+	if !plan.recreates(api) || plan.checkForceRecreate() != nil {
+		t.Errorf("expected all to recreate every container")
+	}
+
+	plan.ForceRecreate("db")
+
+	if err := plan.checkForceRecreate(); !errors.Is(err, ErrUnknownContainer) {
+		t.Errorf("expected ErrUnknownContainer, got: %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package ssh
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	batchNonceBytes = 8
+)
+
+var (
+	errBatchCommandFailed = errors.New("batch command failed")
+	errBatchOutputParse   = errors.New("failed to parse batch output")
+)
+
+// Result holds the outcome of a single command executed as part of a batch.
+type Result struct {
+	Command  string
+	Stdout   string
+	Stderr   string
+	ExitCode int
+}
+
+// runBatch executes commands as a single combined shell script through execute (one SSH session).
+// Commands run in order and the batch stops at the first command exiting non-zero.
+// Results are returned for every command that ran, including the failing one.
+func runBatch(execute func(command string) (string, string, error), commands []string) ([]Result, error) {
+	if len(commands) == 0 {
+		return nil, nil
+	}
+
+	nonce := make([]byte, batchNonceBytes)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate batch marker: %w", err)
+	}
+
+	marker := "__HADRON_BATCH_" + hex.EncodeToString(nonce)
+
+	// The script itself always exits 0 so that the per-command exit codes are reported through markers.
+	// Error from execute is therefore a transport-level failure, not a command failure.
+	stdout, stderr, err := execute(batchScript(marker, commands))
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute batch: %w (stderr: %s)", err, stderr)
+	}
+
+	results, err := parseBatchOutput(marker, commands, stdout, stderr)
+	if err != nil {
+		return nil, err
+	}
+
+	if last := results[len(results)-1]; last.ExitCode != 0 {
+		return results, fmt.Errorf("%w: %q exited with %d (stderr: %s)",
+			errBatchCommandFailed, last.Command, last.ExitCode, strings.TrimSpace(last.Stderr))
+	}
+
+	return results, nil
+}
+
+// batchScript builds a shell script that delimits each command's stdout and stderr with markers.
+// Each command runs in a subshell so that "exit" or "cd" in one command doesn't leak into the next.
+func batchScript(marker string, commands []string) string {
+	var script strings.Builder
+
+	for i, command := range commands {
+		fmt.Fprintf(&script, "printf '%%s\\n' '%s_BEGIN_%d'; printf '%%s\\n' '%s_BEGIN_%d' >&2\n", marker, i, marker, i)
+		fmt.Fprintf(&script, "(\n%s\n)\n", command)
+		_, _ = script.WriteString("hadron_rc=$?\n")
+		fmt.Fprintf(&script, "printf '\\n%%s %%d\\n' '%s_END_%d' \"$hadron_rc\"; printf '\\n%%s\\n' '%s_END_%d' >&2\n",
+			marker, i, marker, i)
+		_, _ = script.WriteString("[ \"$hadron_rc\" -eq 0 ] || exit 0\n")
+	}
+
+	return script.String()
+}
+
+// parseBatchOutput splits combined batch output back into per-command results.
+func parseBatchOutput(marker string, commands []string, stdout, stderr string) ([]Result, error) {
+	results := make([]Result, 0, len(commands))
+
+	for i, command := range commands {
+		begin := fmt.Sprintf("%s_BEGIN_%d\n", marker, i)
+		end := fmt.Sprintf("\n%s_END_%d", marker, i)
+
+		out, rest, ok := section(stdout, begin, end)
+		if !ok {
+			return nil, fmt.Errorf("%w: missing output for %q", errBatchOutputParse, command)
+		}
+
+		// Remainder of the end marker line holds the exit code: " <rc>\n..."
+		line, _, _ := strings.Cut(rest, "\n")
+
+		fields := strings.Fields(line)
+		if len(fields) != 1 {
+			return nil, fmt.Errorf("%w: malformed exit code for %q", errBatchOutputParse, command)
+		}
+
+		exitCode, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid exit code for %q: %w", errBatchOutputParse, command, err)
+		}
+
+		errOut, _, _ := section(stderr, begin, end)
+
+		results = append(results, Result{
+			Command:  command,
+			Stdout:   out,
+			Stderr:   errOut,
+			ExitCode: exitCode,
+		})
+
+		if exitCode != 0 {
+			break
+		}
+	}
+
+	return results, nil
+}
+
+// section returns the text between begin and end markers, and whatever follows the end marker.
+func section(output, begin, end string) (content, rest string, found bool) {
+	_, after, found := strings.Cut(output, begin)
+	if !found {
+		return "", "", false
+	}
+
+	content, rest, found = strings.Cut(after, end)
+	if !found {
+		return "", "", false
+	}
+
+	return content, rest, true
+}
@@ -0,0 +1,87 @@
+package ssh
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+	"testing"
+)
+
+// localShell executes a script with the local shell, counting invocations (one invocation = one SSH session).
+type localShell struct {
+	sessions int
+}
+
+func (l *localShell) execute(command string) (string, string, error) {
+	l.sessions++
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+
+	return stdout.String(), stderr.String(), err
+}
+
+func TestBatchRunsInSingleSession(t *testing.T) {
+	t.Parallel()
+
+	shell := &localShell{}
+
+	results, err := runBatch(shell.execute, []string{
+		"echo first",
+		"printf 'no-newline'",
+		"echo oops >&2",
+		"true",
+	})
+	if err != nil {
+		t.Fatalf("expected batch to succeed, got: %v", err)
+	}
+
+	if shell.sessions != 1 {
+		t.Errorf("expected 1 session, got %d", shell.sessions)
+	}
+
+	expected := []Result{
+		{Command: "echo first", Stdout: "first\n"},
+		{Command: "printf 'no-newline'", Stdout: "no-newline"},
+		{Command: "echo oops >&2", Stderr: "oops\n"},
+		{Command: "true"},
+	}
+
+	if len(results) != len(expected) {
+		t.Fatalf("expected %d results, got %d", len(expected), len(results))
+	}
+
+	for i, want := range expected {
+		if results[i] != want {
+			t.Errorf("result %d: expected %+v, got %+v", i, want, results[i])
+		}
+	}
+}
+
+func TestBatchStopsAtFirstFailure(t *testing.T) {
+	t.Parallel()
+
+	shell := &localShell{}
+
+	results, err := runBatch(shell.execute, []string{
+		"echo ok",
+		"echo broken >&2; exit 3",
+		"echo never",
+	})
+	if !errors.Is(err, errBatchCommandFailed) {
+		t.Fatalf("expected errBatchCommandFailed, got: %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+
+	if results[1].ExitCode != 3 || results[1].Stderr != "broken\n" {
+		t.Errorf("unexpected failing result: %+v", results[1])
+	}
+}
@@ -2,6 +2,7 @@
 package ssh
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -11,6 +12,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/kevinburke/ssh_config"
 	"github.com/pkg/sftp"
@@ -20,12 +22,10 @@ import (
 )
 
 var (
-	errNotConnected        = errors.New("not connected")
-	errHostKeyMismatch     = errors.New("host key verification failed: key mismatch (possible MITM attack)")
-	errHostNotInKnownHosts = errors.New("host key verification failed: host not found in known_hosts")
-	errNoSSHAgent          = errors.New("SSH agent not available: ensure SSH_AUTH_SOCK is set and ssh-agent is running")
-	errInvalidPort         = errors.New("invalid port in SSH config")
-	errPassphraseKey       = errors.New("SSH key is passphrase-protected (use unencrypted key or SSH agent)")
+	errNotConnected  = errors.New("not connected")
+	errNoSSHAgent    = errors.New("SSH agent not available: ensure SSH_AUTH_SOCK is set and ssh-agent is running")
+	errInvalidPort   = errors.New("invalid port in SSH config")
+	errPassphraseKey = errors.New("SSH key is passphrase-protected (use unencrypted key or SSH agent)")
 )
 
 const (
@@ -38,8 +38,19 @@ const (
 // All methods are safe for use within the context managed by Pool.
 type Connection interface {
 	Execute(command string) (stdout, stderr string, err error)
+	ExecuteWithInput(command string, stdin io.Reader) (stdout, stderr string, err error)
+	ExecuteAs(user, command string) (stdout, stderr string, err error)
+	StreamCommand(ctx context.Context, command string, stdout, stderr io.Writer) error
+	Interactive(command string, stdin io.Reader, stdout, stderr io.Writer, pty *PTY) error
+	Batch(commands []string) ([]Result, error)
 	UploadFile(localPath, remotePath string) error
 	UploadData(data []byte, remotePath string) error
+	DownloadFile(remotePath, localPath string) error
+	ReadRemoteFile(remotePath string) ([]byte, error)
+	FileExists(remotePath string) (bool, error)
+	RemoteSHA256(remotePath string) (string, error)
+	IsRoot() bool
+	WorkDir() string
 }
 
 // client represents an SSH client with connection pooling.
@@ -54,6 +65,13 @@ type client struct {
 	agentConn      net.Conn
 	sshFingerprint string
 	sshKeyContent  string
+	trustOnFirst   bool          // record unknown host keys in known_hosts instead of failing
+	hostKeyAlgos   []string      // host key algorithms accepted in addition to Ed25519
+	sudoPassword   string        // fed to sudo through stdin on hosts without passwordless sudo
+	root           bool          // the effective remote user is root, detected at connect
+	workDir        string        // remote work directory (empty: DefaultWorkDir, staging in /tmp)
+	trustedKey     string        // fingerprint of a host key recorded on first use, for the pool to log
+	commandTimeout time.Duration // per-command limit for Execute (<= 0 disables)
 	mu             sync.Mutex
 }
 
@@ -67,6 +85,7 @@ func newClient(endpoint, fingerprint, keyContent string) *client {
 		endpoint:       endpoint,
 		sshFingerprint: fingerprint,
 		sshKeyContent:  keyContent,
+		commandTimeout: DefaultCommandTimeout,
 	}
 }
 
@@ -104,10 +123,8 @@ func (c *client) connect() error {
 			authMethod,
 		},
 		HostKeyCallback: hostKeyCallback,
-		// Only accept Ed25519 host keys (most secure, modern standard)
-		HostKeyAlgorithms: []string{
-			ssh.KeyAlgoED25519,
-		},
+		// Only accept Ed25519 host keys (most secure, modern standard) unless more were allowed
+		HostKeyAlgorithms: append([]string{ssh.KeyAlgoED25519}, c.hostKeyAlgos...),
 	}
 
 	// Connect to remote host
@@ -115,7 +132,7 @@ func (c *client) connect() error {
 
 	client, err := ssh.Dial("tcp", addr, config)
 	if err != nil {
-		return fmt.Errorf("failed to connect to %s: %w", addr, err)
+		return c.dialError(addr, err)
 	}
 
 	c.sshClient = client
@@ -131,9 +148,44 @@ func (c *client) connect() error {
 
 	c.sftpClient = sftpClient
 
+	c.root = c.detectRoot()
+
+	if err := c.ensureWorkDir(); err != nil {
+		_ = c.close()
+
+		return err
+	}
+
 	return nil
 }
 
+// SupportedHostKeyAlgorithms returns the host key algorithms ClientOptions.HostKeyAlgorithms may allow.
+// Algorithms with known weaknesses, such as ssh-rsa with SHA-1, are not supported.
+func SupportedHostKeyAlgorithms() []string {
+	return ssh.SupportedAlgorithms().HostKeys
+}
+
+// dialError classifies a failed ssh.Dial, so callers can tell an unreachable host from a rejected key
+// with errors.Is. Host key verification failures already wrap ErrHostKeyMismatch or ErrHostNotInKnownHosts.
+func (c *client) dialError(addr string, err error) error {
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, ErrHostKeyMismatch), errors.Is(err, ErrHostNotInKnownHosts):
+		return fmt.Errorf("failed to connect to %s: %w", addr, err)
+	case strings.Contains(err.Error(), "no common algorithm for host key"):
+		return fmt.Errorf("%w: %s accepts %s: %w",
+			ErrHostKeyAlgorithm, addr, strings.Join(append([]string{ssh.KeyAlgoED25519}, c.hostKeyAlgos...), ", "), err)
+	case strings.Contains(err.Error(), "unable to authenticate"):
+		// x/crypto/ssh reports rejected credentials only as text
+		return fmt.Errorf("%w: %s as %s: %w", ErrAuthFailed, addr, c.user, err)
+	case errors.As(err, &netErr):
+		return fmt.Errorf("%w: %s: %w", ErrHostUnreachable, addr, err)
+	default:
+		return fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+}
+
 // resolveConfig resolves SSH connection parameters from ~/.ssh/config.
 func (c *client) resolveConfig() error {
 	// Parse endpoint to extract user@hostname if present
@@ -225,11 +277,21 @@ func (c *client) close() error {
 }
 
 // Execute runs a command on the remote host and returns stdout, stderr, and error.
+// Commands running longer than the command timeout are killed and return ErrCommandTimeout.
 func (c *client) Execute(command string) (stdout, stderr string, err error) {
+	return c.ExecuteWithInput(command, nil)
+}
+
+// ExecuteWithInput runs a command like Execute, streaming stdin to the command's standard input
+// (e.g. a tar archive for "tar -xf -", or a secret for "--password-stdin" without echoing it into
+// the command line). The command sees EOF once stdin is exhausted. A nil stdin behaves like Execute.
+func (c *client) ExecuteWithInput(command string, stdin io.Reader) (stdout, stderr string, err error) {
 	if c.sshClient == nil {
 		return "", "", errNotConnected
 	}
 
+	command, stdin = withSudoPassword(command, c.sudoPassword, stdin)
+
 	// Create a new session for this command
 	session, err := c.sshClient.NewSession()
 	if err != nil {
@@ -238,6 +300,13 @@ func (c *client) Execute(command string) (stdout, stderr string, err error) {
 
 	defer func() { _ = session.Close() }()
 
+	var stdinPipe io.WriteCloser
+	if stdin != nil {
+		if stdinPipe, err = session.StdinPipe(); err != nil {
+			return "", "", fmt.Errorf("failed to get stdin pipe: %w", err)
+		}
+	}
+
 	// Capture stdout and stderr
 	stdoutPipe, err := session.StdoutPipe()
 	if err != nil {
@@ -254,16 +323,120 @@ func (c *client) Execute(command string) (stdout, stderr string, err error) {
 		return "", "", fmt.Errorf("failed to start command: %w", err)
 	}
 
-	// Read output
-	stdoutBytes, _ := io.ReadAll(stdoutPipe)
-	stderrBytes, _ := io.ReadAll(stderrPipe)
+	// Feed stdin concurrently with reading output, closing it so the command sees EOF.
+	// A command that exits without reading everything fails the copy, which is fine to ignore.
+	if stdinPipe != nil {
+		go func() {
+			_, _ = io.Copy(stdinPipe, stdin)
+			_ = stdinPipe.Close()
+		}()
+	}
+
+	run := func() (string, string, error) {
+		// Read output
+		stdoutBytes, _ := io.ReadAll(stdoutPipe)
+		stderrBytes, _ := io.ReadAll(stderrPipe)
+
+		// Wait for command to complete
+		if err := session.Wait(); err != nil {
+			return string(stdoutBytes), string(stderrBytes), fmt.Errorf("command failed: %w", err)
+		}
+
+		return string(stdoutBytes), string(stderrBytes), nil
+	}
 
-	// Wait for command to complete
-	if err := session.Wait(); err != nil {
-		return string(stdoutBytes), string(stderrBytes), fmt.Errorf("command failed: %w", err)
+	// Kill the remote process and close the session so the reads above unblock
+	abort := func() {
+		_ = session.Signal(ssh.SIGKILL)
+		_ = session.Close()
 	}
 
-	return string(stdoutBytes), string(stderrBytes), nil
+	return withTimeout(command, c.commandTimeout, run, abort)
+}
+
+// StreamCommand runs a command on the remote host, writing its output to stdout and stderr as it
+// arrives instead of buffering it, e.g. for "docker logs -f". It is not bound by the command
+// timeout, since streaming commands may run until the caller interrupts them; instead, the command
+// is killed once ctx is done, and ErrCommandTimeout is returned if ctx's deadline passed.
+func (c *client) StreamCommand(ctx context.Context, command string, stdout, stderr io.Writer) error {
+	if c.sshClient == nil {
+		return errNotConnected
+	}
+
+	session, err := c.sshClient.NewSession()
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	defer func() { _ = session.Close() }()
+
+	session.Stdout = stdout
+	session.Stderr = stderr
+
+	if err := session.Start(command); err != nil {
+		return fmt.Errorf("failed to start command: %w", err)
+	}
+
+	done := make(chan error, 1)
+
+	go func() { done <- session.Wait() }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("command failed: %w", err)
+		}
+
+		return nil
+	case <-ctx.Done():
+		// Kill the remote process and close the session so Wait returns
+		_ = session.Signal(ssh.SIGKILL)
+		_ = session.Close()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("%w: %s", ErrCommandTimeout, command)
+		}
+
+		return fmt.Errorf("command cancelled: %w", ctx.Err())
+	}
+}
+
+// ExecuteAs runs a command on the remote host as the given user via sudo -u, e.g. to provision
+// files owned by a service account. The connecting user needs sudo rights for the target user;
+// when connected as root, runuser switches users instead, so sudo need not be installed.
+func (c *client) ExecuteAs(user, command string) (stdout, stderr string, err error) {
+	if c.root {
+		return c.Execute(runuserAs(user, command))
+	}
+
+	return c.Execute(sudoAs(user, command))
+}
+
+// sudoAs wraps a command so it runs through a shell as the given user.
+func sudoAs(user, command string) string {
+	return fmt.Sprintf("sudo -n -u %s -- sh -c %s", shellQuote(user), shellQuote(command))
+}
+
+// runuserAs wraps a command so root runs it through a shell as the given user.
+func runuserAs(user, command string) string {
+	return fmt.Sprintf("runuser -u %s -- sh -c %s", shellQuote(user), shellQuote(command))
+}
+
+// shellQuote wraps a value in single quotes for safe use as a single shell argument.
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// Batch runs several commands in a single SSH session and returns per-command results.
+// Commands run in order and the batch stops at the first failing command, returning an error
+// alongside the results collected so far. Useful for grouped idempotent setup steps where
+// opening one session per command adds noticeable latency.
+func (c *client) Batch(commands []string) ([]Result, error) {
+	if c.sshClient == nil {
+		return nil, errNotConnected
+	}
+
+	return runBatch(c.Execute, commands)
 }
 
 // getAuthMethod returns an SSH auth method, preferring SSH key over agent.
@@ -350,7 +523,7 @@ func (c *client) fingerprintCallback() ssh.HostKeyCallback {
 		if actualFingerprint != c.sshFingerprint {
 			return fmt.Errorf(
 				"%w: expected %s, got %s for %s",
-				errHostKeyMismatch,
+				ErrHostKeyMismatch,
 				c.sshFingerprint,
 				actualFingerprint,
 				hostname,
@@ -408,20 +581,24 @@ func (c *client) knownHostsCallback() (ssh.HostKeyCallback, error) {
 			var keyErr *knownhosts.KeyError
 			if errors.As(err, &keyErr) && len(keyErr.Want) > 0 {
 				return fmt.Errorf(
-					"%w for %s. If you trust this host, remove the old key from %s and retry",
-					errHostKeyMismatch,
+					"%w for %s (%s)",
+					ErrHostKeyMismatch,
 					hostname,
 					knownHostsPath,
 				)
 			}
 
+			// Record an unknown host when trusting on first use; a changed key still fails above
+			if errors.As(err, &keyErr) && len(keyErr.Want) == 0 && c.trustOnFirst {
+				return c.trustHostKey(knownHostsPath, hostname, key)
+			}
+
 			// Check if this is an unknown host error
 			if errors.As(err, &keyErr) && len(keyErr.Want) == 0 {
 				return fmt.Errorf(
-					"%w: %s. To add this host, run: ssh-keyscan -H %s >> %s",
-					errHostNotInKnownHosts,
+					"%w: %s (%s)",
+					ErrHostNotInKnownHosts,
 					hostname,
-					c.hostname,
 					knownHostsPath,
 				)
 			}
@@ -433,6 +610,28 @@ func (c *client) knownHostsCallback() (ssh.HostKeyCallback, error) {
 	}, nil
 }
 
+// trustHostKey appends an unknown host's key to known_hosts (hashed, like ssh-keyscan -H), so later
+// connections verify against it.
+func (c *client) trustHostKey(knownHostsPath, hostname string, key ssh.PublicKey) error {
+	line := knownhosts.Line([]string{knownhosts.HashHostname(knownhosts.Normalize(hostname))}, key)
+
+	//nolint:gosec // path is ~/.ssh/known_hosts
+	file, err := os.OpenFile(knownHostsPath, os.O_APPEND|os.O_WRONLY, filePermission)
+	if err != nil {
+		return fmt.Errorf("failed to open known_hosts: %w", err)
+	}
+
+	defer func() { _ = file.Close() }()
+
+	if _, err := file.WriteString(line + "\n"); err != nil {
+		return fmt.Errorf("failed to record host key in known_hosts: %w", err)
+	}
+
+	c.trustedKey = ssh.FingerprintSHA256(key)
+
+	return nil
+}
+
 // String returns a string representation of the client.
 func (c *client) String() string {
 	if c.hostname != "" {
@@ -515,3 +714,95 @@ func (c *client) UploadData(data []byte, remotePath string) error {
 
 	return nil
 }
+
+// DownloadFile downloads a remote file to a local path using SFTP protocol.
+// The local file is created with 0600 permissions, since remote files may hold secrets.
+func (c *client) DownloadFile(remotePath, localPath string) error {
+	if c.sshClient == nil {
+		return errNotConnected
+	}
+
+	remoteFile, err := c.sftpClient.Open(remotePath)
+	if err != nil {
+		return fmt.Errorf("failed to open remote file: %w", err)
+	}
+
+	defer func() { _ = remoteFile.Close() }()
+
+	//nolint:gosec // Path is from user config, not user input
+	localFile, err := os.OpenFile(localPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, filePermission)
+	if err != nil {
+		return fmt.Errorf("failed to create local file: %w", err)
+	}
+
+	// Copy content from remote to local, removing a partial download on failure
+	if _, err := io.Copy(localFile, remoteFile); err != nil {
+		_ = localFile.Close()
+		_ = os.Remove(localPath)
+
+		return fmt.Errorf("failed to download file content: %w", err)
+	}
+
+	if err := localFile.Close(); err != nil {
+		return fmt.Errorf("failed to close local file: %w", err)
+	}
+
+	return nil
+}
+
+// ReadRemoteFile reads a remote file into memory using SFTP protocol. The file must be readable
+// by the SSH user; root-only files still need "cat" through RunPrivileged.
+func (c *client) ReadRemoteFile(remotePath string) ([]byte, error) {
+	if c.sshClient == nil {
+		return nil, errNotConnected
+	}
+
+	remoteFile, err := c.sftpClient.Open(remotePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open remote file: %w", err)
+	}
+
+	defer func() { _ = remoteFile.Close() }()
+
+	data, err := io.ReadAll(remoteFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read remote file: %w", err)
+	}
+
+	return data, nil
+}
+
+// FileExists reports whether remotePath exists (file or directory), using SFTP. Paths inside
+// directories the SSH user cannot search are reported as an error, not as missing.
+func (c *client) FileExists(remotePath string) (bool, error) {
+	if c.sshClient == nil {
+		return false, errNotConnected
+	}
+
+	if _, err := c.sftpClient.Stat(remotePath); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("failed to stat remote file: %w", err)
+	}
+
+	return true, nil
+}
+
+// RemoteSHA256 returns the hex SHA-256 of a remote file's content, computed on the host with
+// sha256sum so the file is not transferred, or "" if the file does not exist. Callers compare it with
+// the local content's hash to skip uploads that would change nothing.
+func (c *client) RemoteSHA256(remotePath string) (string, error) {
+	quoted := shellQuote(remotePath)
+
+	stdout, stderr, err := c.Execute(fmt.Sprintf("if [ -e %[1]s ]; then sha256sum -- %[1]s; fi", quoted))
+	if err != nil {
+		return "", fmt.Errorf("failed to checksum %s: %w (stderr: %s)", remotePath, err, strings.TrimSpace(stderr))
+	}
+
+	// sha256sum prefixes the line with a backslash when it escapes the file name
+	sum, _, _ := strings.Cut(strings.TrimPrefix(strings.TrimSpace(stdout), `\`), " ")
+
+	return sum, nil
+}
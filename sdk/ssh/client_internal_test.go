@@ -0,0 +1,266 @@
+package ssh
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestSudoAsWrapsCommand(t *testing.T) {
+	t.Parallel()
+
+	got := sudoAs("app", "touch /srv/app/ready")
+
+	want := "sudo -n -u 'app' -- sh -c 'touch /srv/app/ready'"
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestSudoAsQuotesEmbeddedQuotes(t *testing.T) {
+	t.Parallel()
+
+	got := sudoAs("app", "echo 'hello' > /srv/app/greeting")
+
+	want := `sudo -n -u 'app' -- sh -c 'echo '\''hello'\'' > /srv/app/greeting'`
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestWithSudoPassword(t *testing.T) {
+	t.Parallel()
+
+	command, stdin := withSudoPassword("sudo tee /etc/app.conf", "s3cret", strings.NewReader("data"))
+
+	if !strings.HasPrefix(command, sudoPasswordPrelude) || !strings.HasSuffix(command, "sudo tee /etc/app.conf") {
+		t.Errorf("expected the prelude before the command, got %q", command)
+	}
+
+	if strings.Contains(command, "s3cret") {
+		t.Errorf("password leaked into the command line: %q", command)
+	}
+
+	input, err := io.ReadAll(stdin)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(input) != "s3cret\ndata" {
+		t.Errorf("expected the password line before the command's stdin, got %q", input)
+	}
+}
+
+func TestWithSudoPasswordLeavesOtherCommands(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct{ command, password string }{
+		{"docker ps", "s3cret"},
+		{"sudo docker ps", ""},
+	} {
+		command, stdin := withSudoPassword(tc.command, tc.password, nil)
+		if command != tc.command || stdin != nil {
+			t.Errorf("expected %q unchanged, got %q", tc.command, command)
+		}
+	}
+}
+
+// newInMemoryClient returns a client whose SFTP session is served from memory.
+func newInMemoryClient(t *testing.T) *client {
+	t.Helper()
+
+	serverConn, clientConn := net.Pipe()
+
+	server := sftp.NewRequestServer(serverConn, sftp.InMemHandler())
+
+	go func() { _ = server.Serve() }()
+
+	sftpClient, err := sftp.NewClientPipe(clientConn, clientConn)
+	if err != nil {
+		t.Fatalf("failed to start sftp client: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = sftpClient.Close()
+		_ = server.Close()
+	})
+
+	// sshClient is only checked for nil; file transfers go through sftpClient
+	return &client{sshClient: &ssh.Client{}, sftpClient: sftpClient}
+}
+
+func TestDialError(t *testing.T) {
+	t.Parallel()
+
+	c := &client{user: "deploy"}
+
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")} //nolint:err113 // simulated
+
+	cases := []struct {
+		err  error
+		want error
+	}{
+		{fmt.Errorf("ssh: handshake failed: %w: 10.0.0.1", ErrHostNotInKnownHosts), ErrHostNotInKnownHosts},
+		{fmt.Errorf("ssh: handshake failed: %w", ErrHostKeyMismatch), ErrHostKeyMismatch},
+		{errors.New("ssh: handshake failed: ssh: unable to authenticate"), ErrAuthFailed}, //nolint:err113 // simulated
+		{
+			errors.New("ssh: handshake failed: ssh: no common algorithm for host key"), //nolint:err113 // simulated
+			ErrHostKeyAlgorithm,
+		},
+		{refused, ErrHostUnreachable},
+	}
+
+	for _, tc := range cases {
+		if err := c.dialError("10.0.0.1:22", tc.err); !errors.Is(err, tc.want) {
+			t.Errorf("expected %v, got: %v", tc.want, err)
+		}
+	}
+}
+
+//nolint:paralleltest // sets HOME to a temporary known_hosts
+func TestTrustOnFirstUse(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	newKey := func() ssh.PublicKey {
+		public, _, err := ed25519.GenerateKey(nil)
+		if err != nil {
+			t.Fatalf("failed to generate key: %v", err)
+		}
+
+		key, err := ssh.NewPublicKey(public)
+		if err != nil {
+			t.Fatalf("failed to convert key: %v", err)
+		}
+
+		return key
+	}
+
+	verify := func(c *client, key ssh.PublicKey) error {
+		callback, err := c.knownHostsCallback()
+		if err != nil {
+			t.Fatalf("failed to load known_hosts: %v", err)
+		}
+
+		remote := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 22}
+
+		return callback("10.0.0.1:22", remote, key)
+	}
+
+	key := newKey()
+
+	// Unknown hosts fail unless trusted on first use
+	if err := verify(&client{hostname: "10.0.0.1"}, key); !errors.Is(err, ErrHostNotInKnownHosts) {
+		t.Fatalf("expected ErrHostNotInKnownHosts, got: %v", err)
+	}
+
+	trusting := &client{hostname: "10.0.0.1", trustOnFirst: true}
+	if err := verify(trusting, key); err != nil {
+		t.Fatalf("expected the unknown key to be trusted, got: %v", err)
+	}
+
+	if trusting.trustedKey != ssh.FingerprintSHA256(key) {
+		t.Errorf("expected trusted fingerprint %s, got %q", ssh.FingerprintSHA256(key), trusting.trustedKey)
+	}
+
+	// The recorded key now verifies without trusting anything
+	if err := verify(&client{hostname: "10.0.0.1"}, key); err != nil {
+		t.Errorf("expected the recorded key to verify, got: %v", err)
+	}
+
+	// A changed key is never accepted
+	if err := verify(&client{hostname: "10.0.0.1", trustOnFirst: true}, newKey()); !errors.Is(err, ErrHostKeyMismatch) {
+		t.Errorf("expected ErrHostKeyMismatch for a changed key, got: %v", err)
+	}
+}
+
+func TestDownloadFile(t *testing.T) {
+	t.Parallel()
+
+	c := newInMemoryClient(t)
+
+	if err := c.UploadData([]byte("version: 2\n"), "/config.yml"); err != nil {
+		t.Fatalf("failed to upload: %v", err)
+	}
+
+	data, err := c.ReadRemoteFile("/config.yml")
+	if err != nil {
+		t.Fatalf("failed to read remote file: %v", err)
+	}
+
+	if string(data) != "version: 2\n" {
+		t.Errorf("expected %q, got %q", "version: 2\n", data)
+	}
+
+	localPath := filepath.Join(t.TempDir(), "config.yml")
+
+	if err := c.DownloadFile("/config.yml", localPath); err != nil {
+		t.Fatalf("failed to download: %v", err)
+	}
+
+	info, err := os.Stat(localPath)
+	if err != nil {
+		t.Fatalf("expected downloaded file: %v", err)
+	}
+
+	if info.Mode().Perm() != filePermission {
+		t.Errorf("expected mode %o, got %o", filePermission, info.Mode().Perm())
+	}
+
+	if _, err := c.ReadRemoteFile("/missing"); err == nil {
+		t.Error("expected error reading a missing remote file")
+	}
+}
+
+func TestPrivileged(t *testing.T) {
+	t.Parallel()
+
+	if got := Privileged(&client{}, "systemctl restart docker"); got != "sudo systemctl restart docker" {
+		t.Errorf("expected sudo for an unprivileged user, got %q", got)
+	}
+
+	if got := Privileged(&client{root: true}, "systemctl restart docker"); got != "systemctl restart docker" {
+		t.Errorf("expected no sudo for root, got %q", got)
+	}
+}
+
+func TestRunuserAsWrapsCommand(t *testing.T) {
+	t.Parallel()
+
+	got := runuserAs("app", "touch /srv/app/ready")
+
+	want := "runuser -u 'app' -- sh -c 'touch /srv/app/ready'"
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestWorkPaths(t *testing.T) {
+	t.Parallel()
+
+	if got := WorkPath(&client{}, "files", "abc"); got != "/var/lib/hadron/files/abc" {
+		t.Errorf("expected the default work directory, got %q", got)
+	}
+
+	if got := TempPath(&client{}, "hadron-daemon.json"); got != "/tmp/hadron-daemon.json" {
+		t.Errorf("expected staging in /tmp by default, got %q", got)
+	}
+
+	custom := &client{workDir: "/data/hadron"}
+
+	if got := WorkPath(custom, "jobs"); got != "/data/hadron/jobs" {
+		t.Errorf("expected the configured work directory, got %q", got)
+	}
+
+	if got := TempPath(custom, "hadron-daemon.json"); got != "/data/hadron/tmp/hadron-daemon.json" {
+		t.Errorf("expected staging below the configured work directory, got %q", got)
+	}
+}
@@ -2,5 +2,25 @@ package ssh
 
 import "errors"
 
-// ErrConnectionClose indicates failure closing SSH connection.
-var ErrConnectionClose = errors.New("failed to close SSH connection")
+var (
+	// ErrConnectionClose indicates failure closing SSH connection.
+	ErrConnectionClose = errors.New("failed to close SSH connection")
+
+	// ErrCommandTimeout indicates a remote command exceeded the command timeout and was aborted.
+	ErrCommandTimeout = errors.New("command timed out")
+
+	// ErrHostKeyMismatch indicates the host presented a key other than the known or pinned one.
+	ErrHostKeyMismatch = errors.New("host key verification failed: key mismatch (possible MITM attack)")
+
+	// ErrHostNotInKnownHosts indicates the host has no entry in ~/.ssh/known_hosts.
+	ErrHostNotInKnownHosts = errors.New("host key verification failed: host not found in known_hosts")
+
+	// ErrHostKeyAlgorithm indicates the host offers no host key of an accepted algorithm.
+	ErrHostKeyAlgorithm = errors.New("host key verification failed: no accepted host key algorithm")
+
+	// ErrAuthFailed indicates the host rejected every offered key or password.
+	ErrAuthFailed = errors.New("SSH authentication failed")
+
+	// ErrHostUnreachable indicates no TCP connection to the SSH port could be established.
+	ErrHostUnreachable = errors.New("SSH host unreachable")
+)
@@ -0,0 +1,32 @@
+package ssh_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/the-agent-c-ai/hadron/internal/testutil"
+	"github.com/the-agent-c-ai/hadron/sdk/ssh"
+)
+
+func TestRSAHostKey(t *testing.T) { //nolint:paralleltest // Integration test modifies known_hosts and the SSH agent
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testutil.StartDebianSSHContainerWithHostKey(t, "rsa", ssh.ClientOptions{
+		HostKeyAlgorithms: []string{"rsa-sha2-512", "rsa-sha2-256"},
+	})
+
+	stdout, _, err := container.Client().Execute("echo ok")
+	if err != nil || stdout != "ok\n" {
+		t.Fatalf("expected to run a command over an RSA host key, got %q (err: %v)", stdout, err)
+	}
+
+	// Without allowing RSA, only Ed25519 host keys are accepted
+	_, err = ssh.NewPool(zerolog.Nop()).GetClient(container.Endpoint)
+	if !errors.Is(err, ssh.ErrHostKeyAlgorithm) {
+		t.Errorf("expected ErrHostKeyAlgorithm, got: %v", err)
+	}
+}
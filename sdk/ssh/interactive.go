@@ -0,0 +1,89 @@
+package ssh
+
+import (
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// defaultTerm is the terminal type requested when PTY.Term is empty.
+const defaultTerm = "xterm"
+
+// terminalSpeed is the baud rate reported to the remote terminal.
+const terminalSpeed = 14400
+
+// PTY requests a pseudo-terminal for Interactive, so shells and full-screen programs behave as
+// they would in a local terminal.
+type PTY struct {
+	Term    string            // terminal type, e.g. the local $TERM (defaults to "xterm")
+	Width   int               // columns
+	Height  int               // rows
+	Resizes <-chan WindowSize // local window size changes forwarded to the remote terminal; may be nil
+}
+
+// WindowSize is a terminal size in columns and rows.
+type WindowSize struct {
+	Width  int
+	Height int
+}
+
+// Interactive runs a command attached to the given streams, for commands that read input
+// (e.g. docker exec -it). With a PTY, stderr is merged into stdout by the remote terminal.
+// Like StreamCommand, it is not bound by the command timeout.
+func (c *client) Interactive(command string, stdin io.Reader, stdout, stderr io.Writer, pty *PTY) error {
+	if c.sshClient == nil {
+		return errNotConnected
+	}
+
+	session, err := c.sshClient.NewSession()
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	defer func() { _ = session.Close() }()
+
+	if pty != nil {
+		if err := requestPTY(session, pty); err != nil {
+			return err
+		}
+	}
+
+	session.Stdin = stdin
+	session.Stdout = stdout
+	session.Stderr = stderr
+
+	if err := session.Run(command); err != nil {
+		return fmt.Errorf("command failed: %w", err)
+	}
+
+	return nil
+}
+
+// requestPTY allocates the remote terminal and forwards window size changes until they stop.
+func requestPTY(session *ssh.Session, pty *PTY) error {
+	term := pty.Term
+	if term == "" {
+		term = defaultTerm
+	}
+
+	modes := ssh.TerminalModes{
+		ssh.ECHO:          1,
+		ssh.TTY_OP_ISPEED: terminalSpeed,
+		ssh.TTY_OP_OSPEED: terminalSpeed,
+	}
+
+	if err := session.RequestPty(term, pty.Height, pty.Width, modes); err != nil {
+		return fmt.Errorf("failed to request pty: %w", err)
+	}
+
+	if pty.Resizes != nil {
+		go func() {
+			for size := range pty.Resizes {
+				_ = session.WindowChange(size.Height, size.Width)
+			}
+		}()
+	}
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/rs/zerolog"
 )
@@ -14,19 +15,36 @@ var errClosingConnections = errors.New("errors closing connections")
 // Pool manages SSH connections to multiple hosts.
 // It ensures one connection per unique host and reuses connections.
 type Pool struct {
-	clients map[string]*client
-	mu      sync.RWMutex
-	logger  zerolog.Logger
+	clients        map[string]*client
+	mu             sync.RWMutex
+	logger         zerolog.Logger
+	commandTimeout time.Duration
 }
 
 // NewPool creates a new SSH connection pool.
 func NewPool(logger zerolog.Logger) *Pool {
 	return &Pool{
-		clients: make(map[string]*client),
-		logger:  logger,
+		clients:        make(map[string]*client),
+		logger:         logger,
+		commandTimeout: DefaultCommandTimeout,
 	}
 }
 
+// WithCommandTimeout sets the per-command timeout for connections created by the pool.
+// A timeout <= 0 disables the limit.
+func (p *Pool) WithCommandTimeout(timeout time.Duration) *Pool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.commandTimeout = timeout
+
+	for _, client := range p.clients {
+		client.commandTimeout = timeout
+	}
+
+	return p
+}
+
 // GetClient returns a Connection for the given endpoint, creating and connecting if needed.
 // The endpoint can be an IP address, hostname, or SSH config alias.
 // Connection parameters are resolved from ~/.ssh/config.
@@ -45,6 +63,34 @@ func (p *Pool) GetClientWithFingerprint(endpoint, fingerprint string) (Connectio
 // If keyContent is provided, it will be used for authentication instead of SSH agent.
 // If fingerprint is provided, it will be used for host key verification instead of ~/.ssh/known_hosts.
 func (p *Pool) GetClientWithKey(endpoint, fingerprint, keyContent string) (Connection, error) {
+	return p.GetClientWithOptions(endpoint, ClientOptions{Fingerprint: fingerprint, KeyContent: keyContent})
+}
+
+// ClientOptions configures how GetClientWithOptions authenticates and verifies a host.
+type ClientOptions struct {
+	Fingerprint string // expected host key fingerprint, used instead of ~/.ssh/known_hosts
+	KeyContent  string // private key, used instead of the SSH agent
+
+	// TrustOnFirstUse records the key of a host missing from ~/.ssh/known_hosts there and connects,
+	// instead of failing. A host whose key differs from its known_hosts entry still fails with
+	// ErrHostKeyMismatch. Ignored when Fingerprint is set.
+	TrustOnFirstUse bool
+
+	// HostKeyAlgorithms are accepted in addition to Ed25519, e.g. "rsa-sha2-512" for hosts without
+	// an Ed25519 host key. See SupportedHostKeyAlgorithms.
+	HostKeyAlgorithms []string
+
+	// SudoPassword is passed to sudo through stdin by Execute, ExecuteWithInput, ExecuteAs, and Batch,
+	// for hosts where sudo asks for a password. Empty assumes passwordless sudo.
+	SudoPassword string
+
+	// WorkDir replaces DefaultWorkDir for uploaded files, job scripts, and backups, and /tmp for staged
+	// files (in its tmp subdirectory). It is created at connect. Empty keeps the defaults.
+	WorkDir string
+}
+
+// GetClientWithOptions returns a Connection for the given endpoint, creating and connecting if needed.
+func (p *Pool) GetClientWithOptions(endpoint string, opts ClientOptions) (Connection, error) {
 	// Use endpoint as key since SSH config will resolve the actual connection params
 	key := endpoint
 
@@ -68,11 +114,24 @@ func (p *Pool) GetClientWithKey(endpoint, fingerprint, keyContent string) (Conne
 
 	p.logger.Debug().Str("endpoint", key).Msg("Creating new SSH connection")
 
-	client := newClient(endpoint, fingerprint, keyContent)
+	client := newClient(endpoint, opts.Fingerprint, opts.KeyContent)
+	client.trustOnFirst = opts.TrustOnFirstUse
+	client.hostKeyAlgos = opts.HostKeyAlgorithms
+	client.sudoPassword = opts.SudoPassword
+	client.workDir = opts.WorkDir
+	client.commandTimeout = p.commandTimeout
+
 	if err := client.connect(); err != nil {
 		return nil, fmt.Errorf("failed to connect to %s: %w", key, err)
 	}
 
+	if client.trustedKey != "" {
+		p.logger.Warn().
+			Str("endpoint", key).
+			Str("fingerprint", client.trustedKey).
+			Msg("Unknown host key trusted on first use and added to known_hosts, verify it out of band")
+	}
+
 	p.clients[key] = client
 	p.logger.Info().Str("endpoint", key).Str("resolved", client.String()).Msg("SSH connection established")
 
@@ -0,0 +1,33 @@
+package ssh
+
+import "strings"
+
+// IsRoot reports whether commands run as root on the remote host, as detected when connecting.
+func (c *client) IsRoot() bool {
+	return c.root
+}
+
+// detectRoot asks the remote host for the effective user ID. A failed check assumes an unprivileged
+// user, so privileged commands keep going through sudo.
+func (c *client) detectRoot() bool {
+	stdout, _, err := c.Execute("id -u")
+
+	return err == nil && strings.TrimSpace(stdout) == "0"
+}
+
+// Privileged returns a command that needs root for the given connection: prefixed with sudo for an
+// unprivileged user, unchanged when connected as root, where sudo is redundant and often not installed.
+// Use it for privileged steps inside a compound command, e.g. "curl -o /tmp/key URL && " +
+// Privileged(conn, "mv /tmp/key /etc/apt/keyrings/").
+func Privileged(conn Connection, command string) string {
+	if conn.IsRoot() {
+		return command
+	}
+
+	return "sudo " + command
+}
+
+// RunPrivileged runs a command that needs root, through sudo unless connected as root.
+func RunPrivileged(conn Connection, command string) (stdout, stderr string, err error) {
+	return conn.Execute(Privileged(conn, command))
+}
@@ -0,0 +1,192 @@
+package ssh
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// newSessionClient connects a client to a loopback SSH server that runs every exec request with
+// handle, which writes the command's output to the channel and returns its exit status.
+func newSessionClient(t *testing.T, handle func(command string, channel ssh.Channel) uint32) *client {
+	t.Helper()
+
+	_, hostKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("failed to generate host key: %v", err)
+	}
+
+	signer, err := ssh.NewSignerFromKey(hostKey)
+	if err != nil {
+		t.Fatalf("failed to create host key signer: %v", err)
+	}
+
+	config := &ssh.ServerConfig{NoClientAuth: true}
+	config.AddHostKey(signer)
+
+	// net.Pipe is unbuffered, so both sides sending their version at once would deadlock the handshake
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		if serverConn, err := listener.Accept(); err == nil {
+			serveSessions(serverConn, config, handle)
+		}
+	}()
+
+	sshClient, err := ssh.Dial("tcp", listener.Addr().String(), &ssh.ClientConfig{
+		User:            "deploy",
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(), //nolint:gosec // loopback test server
+	})
+	if err != nil {
+		t.Fatalf("failed to connect to test server: %v", err)
+	}
+
+	t.Cleanup(func() { _ = sshClient.Close() })
+
+	return &client{sshClient: sshClient}
+}
+
+// serveSessions answers session channels on conn, running each exec request with handle.
+func serveSessions(conn net.Conn, config *ssh.ServerConfig, handle func(string, ssh.Channel) uint32) {
+	_, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+
+	go ssh.DiscardRequests(requests)
+
+	for newChannel := range channels {
+		channel, channelRequests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+
+		go func() {
+			defer func() { _ = channel.Close() }()
+
+			for request := range channelRequests {
+				if request.Type != "exec" {
+					_ = request.Reply(false, nil)
+
+					continue
+				}
+
+				_ = request.Reply(true, nil)
+
+				// The payload is the command as an SSH string: a 4-byte length, then the bytes
+				command := string(request.Payload[4:])
+				status := handle(command, channel)
+
+				_, _ = channel.SendRequest("exit-status", false, binary.BigEndian.AppendUint32(nil, status))
+
+				return
+			}
+		}()
+	}
+}
+
+func TestStreamCommandDeadline(t *testing.T) {
+	t.Parallel()
+
+	// A command that does not finish before the test does, like a docker pull from a stalled registry
+	stalled := make(chan struct{})
+	t.Cleanup(func() { close(stalled) })
+
+	c := newSessionClient(t, func(_ string, channel ssh.Channel) uint32 {
+		_, _ = channel.Write([]byte("Pulling fs layer\n"))
+		<-stalled
+
+		return 0
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := c.StreamCommand(ctx, "docker pull app:1", io.Discard, io.Discard)
+
+	if !errors.Is(err, ErrCommandTimeout) {
+		t.Fatalf("expected ErrCommandTimeout, got: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the stream to stop at its deadline, took %s", elapsed)
+	}
+}
+
+func TestStreamCommand(t *testing.T) {
+	t.Parallel()
+
+	c := newSessionClient(t, func(command string, channel ssh.Channel) uint32 {
+		if command != "docker logs --tail 2 app" {
+			_, _ = channel.Stderr().Write([]byte("No such container\n"))
+
+			return 1
+		}
+
+		_, _ = channel.Write([]byte("listening on :8080\n"))
+		_, _ = channel.Stderr().Write([]byte("deprecated flag\n"))
+		_, _ = channel.Write([]byte("ready\n"))
+
+		return 0
+	})
+
+	var stdout, stderr bytes.Buffer
+
+	if err := c.StreamCommand(context.Background(), "docker logs --tail 2 app", &stdout, &stderr); err != nil {
+		t.Fatalf("expected the command to succeed, got: %v", err)
+	}
+
+	if stdout.String() != "listening on :8080\nready\n" || stderr.String() != "deprecated flag\n" {
+		t.Errorf("expected output on both streams, got stdout %q, stderr %q", stdout.String(), stderr.String())
+	}
+
+	// A non-zero exit status is an error, with the command's stderr still written
+	stderr.Reset()
+
+	err := c.StreamCommand(context.Background(), "docker logs missing", io.Discard, &stderr)
+
+	var exitErr *ssh.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitStatus() != 1 {
+		t.Fatalf("expected exit status 1, got: %v", err)
+	}
+
+	if stderr.String() != "No such container\n" {
+		t.Errorf("expected stderr to be streamed, got %q", stderr.String())
+	}
+}
+
+func TestExecuteWithInput(t *testing.T) {
+	t.Parallel()
+
+	// Echoes stdin back, like docker login reading a password with --password-stdin
+	c := newSessionClient(t, func(_ string, channel ssh.Channel) uint32 {
+		input, _ := io.ReadAll(channel)
+		_, _ = channel.Write(input)
+
+		return 0
+	})
+
+	stdout, _, err := c.ExecuteWithInput("docker login --password-stdin ghcr.io", strings.NewReader("it's-a-secret"))
+	if err != nil {
+		t.Fatalf("expected the command to succeed, got: %v", err)
+	}
+
+	if stdout != "it's-a-secret" {
+		t.Errorf("expected the input to reach the command, got %q", stdout)
+	}
+}
@@ -0,0 +1,36 @@
+package ssh
+
+import (
+	"io"
+	"strings"
+)
+
+// sudoPasswordVar holds the sudo password in the remote shell. It is read from stdin and never exported,
+// so it appears neither on a command line nor in the environment of the commands run.
+const sudoPasswordVar = "HADRON_SUDO_PASSWORD"
+
+// sudoPasswordPrelude reads the password from the first line of stdin and shadows sudo with a function
+// that validates it with sudo -S from a here-document, then runs the command with sudo -n against the
+// fresh credentials. The command keeps the rest of stdin for itself (e.g. "sudo tee FILE").
+const sudoPasswordPrelude = "IFS= read -r " + sudoPasswordVar + "\n" +
+	"sudo() {\n" +
+	"command sudo -S -p '' -v <<__HADRON_SUDO__ || return 1\n" +
+	"$" + sudoPasswordVar + "\n" +
+	"__HADRON_SUDO__\n" +
+	"command sudo -n \"$@\"\n" +
+	"}\n"
+
+// withSudoPassword prepares a command using sudo for a host where sudo asks for a password, feeding the
+// password ahead of the command's own stdin. Commands without sudo are returned unchanged.
+func withSudoPassword(command, password string, stdin io.Reader) (string, io.Reader) {
+	if password == "" || !strings.Contains(command, "sudo") {
+		return command, stdin
+	}
+
+	input := strings.NewReader(password + "\n")
+	if stdin == nil {
+		return sudoPasswordPrelude + command, input
+	}
+
+	return sudoPasswordPrelude + command, io.MultiReader(input, stdin)
+}
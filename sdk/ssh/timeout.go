@@ -0,0 +1,48 @@
+package ssh
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultCommandTimeout bounds how long a single remote command may run before it is aborted.
+// Generous enough for package upgrades, short enough that a stuck mirror doesn't hang a deploy forever.
+const DefaultCommandTimeout = 15 * time.Minute
+
+// withTimeout runs fn and returns its result, or calls abort and returns ErrCommandTimeout
+// naming the command if it has not finished within timeout. A timeout <= 0 disables the limit.
+func withTimeout(
+	command string,
+	timeout time.Duration,
+	run func() (string, string, error),
+	abort func(),
+) (string, string, error) {
+	if timeout <= 0 {
+		return run()
+	}
+
+	type result struct {
+		stdout string
+		stderr string
+		err    error
+	}
+
+	done := make(chan result, 1)
+
+	go func() {
+		stdout, stderr, err := run()
+		done <- result{stdout: stdout, stderr: stderr, err: err}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case res := <-done:
+		return res.stdout, res.stderr, res.err
+	case <-timer.C:
+		abort()
+
+		return "", "", fmt.Errorf("%w after %s: %s", ErrCommandTimeout, timeout, command)
+	}
+}
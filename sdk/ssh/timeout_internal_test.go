@@ -0,0 +1,74 @@
+package ssh
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCommandTimeout(t *testing.T) {
+	t.Parallel()
+
+	const (
+		command = "sleep 30"
+		timeout = 2 * time.Second
+	)
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("failed to start command: %v", err)
+	}
+
+	run := func() (string, string, error) {
+		err := cmd.Wait()
+
+		return stdout.String(), stderr.String(), err
+	}
+
+	abort := func() {
+		_ = cmd.Process.Kill()
+	}
+
+	start := time.Now()
+	_, _, err := withTimeout(command, timeout, run, abort)
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, ErrCommandTimeout) {
+		t.Fatalf("expected ErrCommandTimeout, got: %v", err)
+	}
+
+	if !strings.Contains(err.Error(), command) {
+		t.Errorf("expected timeout error to name the command, got: %v", err)
+	}
+
+	if elapsed > timeout+time.Second {
+		t.Errorf("expected prompt timeout after %s, took %s", timeout, elapsed)
+	}
+}
+
+func TestCommandWithinTimeout(t *testing.T) {
+	t.Parallel()
+
+	run := func() (string, string, error) {
+		return "done\n", "", nil
+	}
+
+	stdout, _, err := withTimeout("echo done", time.Second, run, func() {
+		t.Error("expected abort not to be called for a fast command")
+	})
+	if err != nil {
+		t.Fatalf("expected command to succeed, got: %v", err)
+	}
+
+	if stdout != "done\n" {
+		t.Errorf("expected stdout 'done\\n', got %q", stdout)
+	}
+}
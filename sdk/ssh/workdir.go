@@ -0,0 +1,59 @@
+package ssh
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// DefaultWorkDir is where Hadron keeps uploaded files, job scripts, and volume backups on hosts without
+// a configured work directory. Files staged for privileged commands then go to /tmp.
+const DefaultWorkDir = "/var/lib/hadron"
+
+// workTempDir is the directory below a configured work directory that replaces /tmp for staged files.
+const workTempDir = "tmp"
+
+// WorkDir returns the work directory configured for the host, or "" for DefaultWorkDir and /tmp.
+func (c *client) WorkDir() string {
+	return c.workDir
+}
+
+// ensureWorkDir creates the configured work directory (0755) and its staging directory (0700), both
+// owned by the SSH user so uploads into them need no sudo.
+func (c *client) ensureWorkDir() error {
+	if c.workDir == "" {
+		return nil
+	}
+
+	staging := path.Join(c.workDir, workTempDir)
+	owner := "-o $(id -u) -g $(id -g)"
+	cmd := Privileged(c, fmt.Sprintf("install -d -m 755 %s %s", owner, c.workDir)) + " && " +
+		Privileged(c, fmt.Sprintf("install -d -m 700 %s %s", owner, staging))
+
+	if _, stderr, err := c.Execute(cmd); err != nil {
+		return fmt.Errorf("failed to create work directory %s: %w (stderr: %s)",
+			c.workDir, err, strings.TrimSpace(stderr))
+	}
+
+	return nil
+}
+
+// WorkPath returns a path below the connection's work directory (DefaultWorkDir unless configured).
+func WorkPath(conn Connection, elem ...string) string {
+	workDir := conn.WorkDir()
+	if workDir == "" {
+		workDir = DefaultWorkDir
+	}
+
+	return path.Join(append([]string{workDir}, elem...)...)
+}
+
+// TempPath returns where to stage a file before a privileged command moves it into place: in /tmp, or
+// below the configured work directory on hosts where /tmp is read-only or shared.
+func TempPath(conn Connection, name string) string {
+	if conn.WorkDir() == "" {
+		return "/tmp/" + name
+	}
+
+	return path.Join(conn.WorkDir(), workTempDir, name)
+}
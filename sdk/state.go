@@ -0,0 +1,350 @@
+package sdk
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// stateFileMode keeps the state file private: it names every host and resource of the plan.
+const stateFileMode = 0o600
+
+// Change actions reported by Changes.
+const (
+	changeCreate = "create"
+	changeUpdate = "update"
+	changeRemove = "remove"
+)
+
+// Change is a difference between the plan and its state file, computed without connecting to hosts.
+type Change struct {
+	Type   string // "network", "volume", "container", "job", or "systemd_unit"
+	Name   string
+	Host   string
+	Action string // "create", "update", or "remove"
+}
+
+// planState is the state file: every resource a successful deploy left on the hosts.
+type planState struct {
+	Plan      string       `json:"plan"`
+	UpdatedAt time.Time    `json:"updatedAt"`
+	Resources []stateEntry `json:"resources"`
+}
+
+// stateEntry is the deployed configuration of one resource.
+type stateEntry struct {
+	Type       string `json:"type"`
+	Name       string `json:"name"`
+	Host       string `json:"host"`
+	ConfigHash string `json:"configHash"`
+}
+
+// key identifies a resource across the plan and its state.
+func (r stateEntry) key() string {
+	return r.Type + "/" + r.Host + "/" + r.Name
+}
+
+// WithStateFile records, after every successful Execute, each resource's name, host, and config hash
+// in a local JSON file, so Changes, DryRun, and Status can tell what the next deploy would change without
+// connecting to any host.
+//
+// The state is only a cache: deploys keep comparing against the labels on the hosts before changing
+// anything, so a stale or deleted state file never causes a wrong deploy.
+func (p *Plan) WithStateFile(path string) *Plan {
+	p.stateFile = path
+
+	return p
+}
+
+// Changes compares the plan with its state file and returns the resources the next deploy would
+// create or update, and those it deployed before that are no longer in the plan. Nothing connects
+// to the hosts, but secrets are resolved so containers using them are compared by current value.
+// Without a state file every resource is reported as created.
+func (p *Plan) Changes(ctx context.Context) ([]Change, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	previous, err := readState(p.stateFile)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := newExecutor(p).stateResources(ctx, p, p.containers, p.jobs)
+	if err != nil {
+		return nil, err
+	}
+
+	deployed := make(map[string]stateEntry, len(previous.Resources))
+	for _, resource := range previous.Resources {
+		deployed[resource.key()] = resource
+	}
+
+	var changes []Change
+
+	for _, resource := range current {
+		before, ok := deployed[resource.key()]
+		delete(deployed, resource.key())
+
+		switch {
+		case !ok:
+			changes = append(changes, resource.change(changeCreate))
+		case before.ConfigHash != resource.ConfigHash:
+			changes = append(changes, resource.change(changeUpdate))
+		}
+	}
+
+	for _, resource := range previous.Resources {
+		if _, ok := deployed[resource.key()]; ok {
+			changes = append(changes, resource.change(changeRemove))
+		}
+	}
+
+	return changes, nil
+}
+
+// logChanges logs the plan's changes against its state file.
+func (p *Plan) logChanges(ctx context.Context) error {
+	changes, err := p.Changes(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		p.logger.Info().
+			Str("type", change.Type).
+			Str("name", change.Name).
+			Str("host", change.Host).
+			Str("action", change.Action).
+			Msg("Planned change")
+	}
+
+	p.logger.Info().Int("changes", len(changes)).Msg("Dry run complete")
+
+	return nil
+}
+
+// writeChanges writes the plan's changes against its state file to w, as a section of Status
+// that needs no host to be reachable.
+func (p *Plan) writeChanges(ctx context.Context, w io.Writer) error {
+	changes, err := p.Changes(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(changes) == 0 {
+		_, _ = fmt.Fprintf(w, "# no changes since the last deploy (%s)\n", p.stateFile)
+
+		return nil
+	}
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	_, _ = fmt.Fprintf(table, "# changes since the last deploy (%s)\n", p.stateFile)
+
+	for _, change := range changes {
+		_, _ = fmt.Fprintf(table, "%s\t%s\t%s\t%s\n", change.Type, change.Name, change.Host, change.Action)
+	}
+
+	if err := table.Flush(); err != nil {
+		return fmt.Errorf("failed to write status output: %w", err)
+	}
+
+	return nil
+}
+
+// change describes the resource with the given action.
+func (r stateEntry) change(action string) Change {
+	return Change{Type: r.Type, Name: r.Name, Host: r.Host, Action: action}
+}
+
+// stateResources returns the plan's networks, volumes, and systemd units and the given containers and
+// jobs with their config hashes, in plan order. Secrets of containers and jobs are resolved first, since
+// they are part of the hash.
+func (e *executor) stateResources(
+	ctx context.Context,
+	plan *Plan,
+	containers, jobs []*Container,
+) ([]stateEntry, error) {
+	resources := make([]stateEntry, 0,
+		len(plan.networks)+len(plan.volumes)+len(containers)+len(jobs)+len(plan.systemdUnits))
+
+	for _, network := range plan.networks {
+		resources = append(resources, stateEntry{"network", network.Name(), network.Host().String(),
+			network.ConfigHash()})
+	}
+
+	for _, volume := range plan.volumes {
+		resources = append(resources, stateEntry{"volume", volume.Name(), volume.Host().String(),
+			volume.ConfigHash()})
+	}
+
+	for _, container := range containers {
+		if err := e.ensureSecrets(ctx, container); err != nil {
+			return nil, err
+		}
+
+		resources = append(resources, stateEntry{"container", container.Name(), container.Host().String(),
+			container.ConfigHash()})
+	}
+
+	for _, job := range jobs {
+		if err := e.ensureSecrets(ctx, job); err != nil {
+			return nil, err
+		}
+
+		resources = append(resources, stateEntry{"job", job.Name(), job.Host().String(), job.ConfigHash()})
+	}
+
+	for _, unit := range plan.systemdUnits {
+		unitHash := sha256.Sum256([]byte(unit.service.Render()))
+		resources = append(resources, stateEntry{"systemd_unit", unit.Name(), unit.Host().String(),
+			hex.EncodeToString(unitHash[:])})
+	}
+
+	return resources, nil
+}
+
+// ensureSecrets resolves a container's secrets unless the deploy already did.
+func (e *executor) ensureSecrets(ctx context.Context, container *Container) error {
+	if container.resolvedSecrets != nil {
+		return nil
+	}
+
+	return e.resolveSecrets(ctx, container)
+}
+
+// saveState merges the resources this deploy reached into the state file. Resources of hosts or
+// containers left out by TargetHosts, Only, or Skip keep their previous entry; entries of resources
+// no longer in the plan are dropped. Failures are logged rather than returned: the deploy itself
+// succeeded, and the next one verifies against the hosts anyway.
+func (e *executor) saveState(ctx context.Context) {
+	if e.plan.stateFile == "" {
+		return
+	}
+
+	if err := e.writeState(ctx); err != nil {
+		e.plan.logger.Warn().Err(err).Str("path", e.plan.stateFile).Msg("Failed to write plan state file")
+	}
+}
+
+// writeState merges and writes the state file.
+func (e *executor) writeState(ctx context.Context) error {
+	previous, err := readState(e.plan.stateFile)
+	if err != nil {
+		return err
+	}
+
+	containers, err := e.plan.selectedContainers()
+	if err != nil {
+		return err
+	}
+
+	deployed, err := e.stateResources(ctx, e.plan, containers, e.plan.selectedJobs())
+	if err != nil {
+		return err
+	}
+
+	declared := declaredKeys(e.plan.all())
+
+	merged := make(map[string]stateEntry, len(previous.Resources)+len(deployed))
+
+	for _, resource := range previous.Resources {
+		if declared[resource.key()] {
+			merged[resource.key()] = resource
+		}
+	}
+
+	for _, resource := range deployed {
+		merged[resource.key()] = resource
+	}
+
+	state := planState{Plan: e.plan.name, UpdatedAt: time.Now().UTC()}
+	for _, resource := range merged {
+		state.Resources = append(state.Resources, resource)
+	}
+
+	sort.Slice(state.Resources, func(i, j int) bool {
+		return state.Resources[i].key() < state.Resources[j].key()
+	})
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode plan state: %w", err)
+	}
+
+	tmp := e.plan.stateFile + ".tmp"
+
+	if err := os.WriteFile(tmp, append(data, '\n'), stateFileMode); err != nil {
+		return fmt.Errorf("failed to write plan state: %w", err)
+	}
+
+	if err := os.Rename(tmp, e.plan.stateFile); err != nil {
+		return fmt.Errorf("failed to write plan state: %w", err)
+	}
+
+	return nil
+}
+
+// declaredKeys returns the state keys of every resource in the plan, without hashing anything.
+func declaredKeys(plan *Plan) map[string]bool {
+	keys := make(map[string]bool)
+
+	add := func(resourceType, name string, host *Host) {
+		keys[stateEntry{Type: resourceType, Name: name, Host: host.String()}.key()] = true
+	}
+
+	for _, network := range plan.networks {
+		add("network", network.Name(), network.Host())
+	}
+
+	for _, volume := range plan.volumes {
+		add("volume", volume.Name(), volume.Host())
+	}
+
+	for _, container := range plan.containers {
+		add("container", container.Name(), container.Host())
+	}
+
+	for _, job := range plan.jobs {
+		add("job", job.Name(), job.Host())
+	}
+
+	for _, unit := range plan.systemdUnits {
+		add("systemd_unit", unit.Name(), unit.Host())
+	}
+
+	return keys
+}
+
+// readState reads a state file; a missing file (or no file configured) is an empty state.
+func readState(path string) (*planState, error) {
+	if path == "" {
+		return &planState{}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &planState{}, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan state: %w", err)
+	}
+
+	var state planState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse plan state %s: %w", path, err)
+	}
+
+	return &state, nil
+}
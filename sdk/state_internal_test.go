@@ -0,0 +1,101 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestStateFileChanges(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	newPlan := func(image string, withWorker bool) *Plan {
+		plan := NewPlan("test").WithLogger(zerolog.Nop()).WithStateFile(path)
+		host := plan.Host("user@192.168.1.1").Build()
+
+		plan.Network("app").Host(host).Build()
+
+		names := []string{"web"}
+		if withWorker {
+			names = append(names, "worker")
+		}
+
+		for _, name := range names {
+			plan.Container(name).
+				Host(host).
+				Image(image).
+				Memory("256m").
+				CPUShares(512).
+				CPUs("0.5").
+				PIDsLimit(100).
+				Build()
+		}
+
+		return plan
+	}
+
+	ctx := context.Background()
+
+	// Without a state file everything is new
+	deployed := newPlan("app:1", true)
+
+	changes, err := deployed.Changes(ctx)
+	if err != nil || len(changes) != 3 || changes[0].Action != changeCreate {
+		t.Fatalf("expected 3 creations, got %+v (err: %v)", changes, err)
+	}
+
+	if err := newExecutor(deployed).writeState(ctx); err != nil {
+		t.Fatalf("failed to write state: %v", err)
+	}
+
+	// Once deployed, the same plan has no changes
+	changes, err = newPlan("app:1", true).Changes(ctx)
+	if err != nil || len(changes) != 0 {
+		t.Fatalf("expected no changes, got %+v (err: %v)", changes, err)
+	}
+
+	// A new image updates web, and the dropped worker is reported as removed
+	changes, err = newPlan("app:2", false).Changes(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	host := deployed.hosts[0].String()
+	want := []Change{
+		{Type: "container", Name: "web", Host: host, Action: changeUpdate},
+		{Type: "container", Name: "worker", Host: host, Action: changeRemove},
+	}
+
+	if !slices.Equal(changes, want) {
+		t.Errorf("expected %+v, got %+v", want, changes)
+	}
+
+	// Status lists the same changes without connecting to the host
+	var out bytes.Buffer
+	if err := newPlan("app:2", false).writeChanges(ctx, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{"# changes since the last deploy (" + path + ")", "web", "worker", changeRemove} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("expected status output to contain %q, got:\n%s", line, out.String())
+		}
+	}
+
+	out.Reset()
+
+	if err := newPlan("app:1", true).writeChanges(ctx, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(out.String(), "# no changes since the last deploy") {
+		t.Errorf("expected no changes to be reported, got:\n%s", out.String())
+	}
+}
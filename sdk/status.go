@@ -0,0 +1,218 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/the-agent-c-ai/hadron/sdk/ssh"
+)
+
+// Resource states reported by Status.
+const (
+	stateMissing = "missing"
+	stateInSync  = "in sync"
+	stateDrift   = "drift"
+)
+
+// resourceStatus is the deployed state of one plan resource.
+type resourceStatus struct {
+	resourceType string
+	name         string
+	state        string // stateMissing, stateInSync, or stateDrift
+	runtime      string // container status and health, e.g. "running (healthy)"
+}
+
+// Status writes, per host, whether each network, volume, and container in the plan exists, whether
+// its config hash label still matches the plan (drift), and whether containers are running and
+// healthy. It only inspects hosts and never changes anything. Secrets are resolved so containers
+// using them are compared against their current values. With a state file (see WithStateFile), the
+// changes since the last deploy are written first, before any host is contacted.
+func (p *Plan) Status(ctx context.Context, w io.Writer) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	if p.stateFile != "" {
+		if err := p.writeChanges(ctx, w); err != nil {
+			return err
+		}
+	}
+
+	exec := newExecutor(p)
+	exec.mode = modeStatus
+
+	return exec.status(ctx, w)
+}
+
+// status inspects every host in the plan and writes a table of resource states to w.
+func (e *executor) status(ctx context.Context, w io.Writer) error {
+	defer func() {
+		if err := e.sshPool.CloseAll(); err != nil {
+			e.plan.logger.Warn().Err(err).Msg("Failed to close SSH connections")
+		}
+	}()
+
+	for _, container := range e.plan.containers {
+		if err := e.resolveSecrets(ctx, container); err != nil {
+			return err
+		}
+	}
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	for _, host := range e.plan.hosts {
+		statuses, err := e.hostStatus(host)
+		if err != nil {
+			return err
+		}
+
+		if len(statuses) == 0 {
+			continue
+		}
+
+		_, _ = fmt.Fprintf(table, "# %s\n", host)
+
+		for _, status := range statuses {
+			_, _ = fmt.Fprintf(table, "%s\t%s\t%s\t%s\n",
+				status.resourceType, status.name, status.state, status.runtime)
+		}
+	}
+
+	if err := table.Flush(); err != nil {
+		return fmt.Errorf("failed to write status output: %w", err)
+	}
+
+	return nil
+}
+
+// hostStatus inspects the plan's networks, volumes, and containers on one host.
+func (e *executor) hostStatus(host *Host) ([]resourceStatus, error) {
+	if !e.hasResources(host) {
+		return nil, nil
+	}
+
+	client, err := e.getSSHClient(host)
+	if err != nil {
+		return nil, fmt.Errorf(errFailedSSHClient, host, err)
+	}
+
+	return e.inspectHost(client, host)
+}
+
+// hasResources reports whether any plan network, volume, or container is deployed to the host.
+func (e *executor) hasResources(host *Host) bool {
+	return slices.ContainsFunc(e.plan.networks, func(n *Network) bool { return n.host == host }) ||
+		slices.ContainsFunc(e.plan.volumes, func(v *Volume) bool { return v.host == host }) ||
+		slices.ContainsFunc(e.plan.containers, func(c *Container) bool { return c.host == host })
+}
+
+// inspectHost reads the state of the host's plan resources, networks first, in plan order.
+func (e *executor) inspectHost(client ssh.Connection, host *Host) ([]resourceStatus, error) {
+	var statuses []resourceStatus
+
+	for _, network := range e.plan.networks {
+		if network.host != host {
+			continue
+		}
+
+		state, err := resourceState(client, network, e.dockerExec.NetworkExists, e.dockerExec.GetNetworkLabel)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrNetworkCheck, err)
+		}
+
+		statuses = append(statuses, resourceStatus{resourceType: "network", name: network.Name(), state: state})
+	}
+
+	for _, volume := range e.plan.volumes {
+		if volume.host != host {
+			continue
+		}
+
+		state, err := resourceState(client, volume, e.dockerExec.VolumeExists, e.dockerExec.GetVolumeLabel)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrVolumeCheck, err)
+		}
+
+		statuses = append(statuses, resourceStatus{resourceType: "volume", name: volume.Name(), state: state})
+	}
+
+	for _, container := range e.plan.containers {
+		if container.host != host {
+			continue
+		}
+
+		status, err := e.containerStatus(client, container)
+		if err != nil {
+			return nil, err
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// containerStatus inspects one container's drift and runtime state.
+func (e *executor) containerStatus(client ssh.Connection, container *Container) (resourceStatus, error) {
+	status := resourceStatus{resourceType: "container", name: container.Name()}
+
+	state, err := resourceState(client, container, e.dockerExec.ContainerExists, e.dockerExec.GetContainerLabel)
+	if err != nil {
+		return status, fmt.Errorf("%w: %w", ErrContainerCheck, err)
+	}
+
+	status.state = state
+
+	if state == stateMissing {
+		return status, nil
+	}
+
+	running, health, err := e.dockerExec.ContainerState(client, container.Name())
+	if err != nil {
+		return status, fmt.Errorf("%w: %w", ErrContainerCheck, err)
+	}
+
+	status.runtime = running
+	if health != "" {
+		status.runtime += " (" + health + ")"
+	}
+
+	return status, nil
+}
+
+// hashedResource is a resource labeled with its config hash when deployed.
+type hashedResource interface {
+	Name() string
+	ConfigHash() string
+}
+
+// resourceState compares a deployed resource's config hash label with the plan.
+func resourceState(
+	client ssh.Connection,
+	resource hashedResource,
+	exists func(ssh.Connection, string) (bool, error),
+	getLabel func(ssh.Connection, string, string) (string, error),
+) (string, error) {
+	found, err := exists(client, resource.Name())
+	if err != nil {
+		return "", err
+	}
+
+	if !found {
+		return stateMissing, nil
+	}
+
+	hash, err := getLabel(client, resource.Name(), labelConfigSHA)
+	if err != nil {
+		return "", err
+	}
+
+	if hash != resource.ConfigHash() {
+		return stateDrift, nil
+	}
+
+	return stateInSync, nil
+}
@@ -0,0 +1,72 @@
+package sdk
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/the-agent-c-ai/hadron/internal/testutil"
+)
+
+func TestStatus(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop())
+	host := plan.Host("deploy@10.0.0.5").Build()
+	network := plan.Network("app-net").Host(host).Build()
+	plan.Volume("app-data").Host(host).Build()
+
+	app := plan.Container("app").
+		Host(host).
+		Image("nginx:latest").
+		Memory("256m").
+		CPUShares(512).
+		CPUs("0.5").
+		PIDsLimit(100).
+		Network(network).
+		Build()
+
+	client := &testutil.FakeConnection{
+		Respond: func(command string) string {
+			switch {
+			case strings.HasPrefix(command, "docker network inspect app-net >/dev/null"),
+				strings.HasPrefix(command, "docker container inspect app >/dev/null"):
+				return "exists\n"
+			case strings.HasPrefix(command, "docker volume inspect"):
+				return "missing\n"
+			case strings.HasPrefix(command, "docker network inspect -f"):
+				return network.ConfigHash() + "\n"
+			case strings.Contains(command, ".State.Status"):
+				return "running healthy\n"
+			case strings.HasPrefix(command, "docker container inspect -f"):
+				return "outdated-hash\n"
+			}
+
+			return ""
+		},
+	}
+
+	statuses, err := newExecutor(plan).inspectHost(client, host)
+	if err != nil {
+		t.Fatalf("expected status to succeed, got: %v", err)
+	}
+
+	want := []resourceStatus{
+		{resourceType: "network", name: "app-net", state: stateInSync},
+		{resourceType: "volume", name: "app-data", state: stateMissing},
+		{resourceType: "container", name: app.Name(), state: stateDrift, runtime: "running (healthy)"},
+	}
+	if !slices.Equal(statuses, want) {
+		t.Errorf("expected %+v, got %+v", want, statuses)
+	}
+
+	for _, command := range client.Commands {
+		if !strings.HasPrefix(command, "docker network inspect") &&
+			!strings.HasPrefix(command, "docker volume inspect") &&
+			!strings.HasPrefix(command, "docker container inspect") {
+			t.Errorf("expected status to only inspect, got %q", command)
+		}
+	}
+}
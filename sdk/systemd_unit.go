@@ -0,0 +1,273 @@
+package sdk
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/the-agent-c-ai/hadron/internal/systemd"
+	"github.com/the-agent-c-ai/hadron/sdk/ssh"
+)
+
+// systemdUnitPrefix namespaces plan units, so a unit named after a system service (e.g. "docker")
+// cannot overwrite it.
+const systemdUnitPrefix = "hadron-"
+
+// systemdUnitName matches the characters systemd allows in a unit name.
+var systemdUnitName = regexp.MustCompile(`^[A-Za-z0-9:_.-]+$`)
+
+// SystemdUnit is a service run by systemd directly on a host rather than in a container,
+// e.g. a host-level monitoring agent.
+type SystemdUnit struct {
+	name    string
+	host    *Host
+	service systemd.Service
+	plan    *Plan
+}
+
+// SystemdUnitBuilder builds a SystemdUnit with a fluent API.
+type SystemdUnitBuilder struct {
+	plan        *Plan
+	name        string
+	host        *Host
+	description string
+	unitType    string
+	execStart   []string
+	env         map[string]string
+	user        string
+	restart     string
+	after       []string
+	wantedBy    string
+}
+
+// SystemdUnit creates a builder for a systemd service on a host, written to
+// /etc/systemd/system/hadron-<name>.service, enabled, and started on deploy:
+//
+//	plan.SystemdUnit("node-agent").
+//		Host(web).
+//		ExecStart("/usr/local/bin/node-agent", "--listen", "127.0.0.1:9100").
+//		User("nobody").
+//		Build()
+//
+// The unit file is compared with the one on the host, so an unchanged unit is left running as it is
+// and a changed one is restarted.
+func (p *Plan) SystemdUnit(name string) *SystemdUnitBuilder {
+	return &SystemdUnitBuilder{plan: p, name: name}
+}
+
+// Host sets the host the unit runs on.
+func (ub *SystemdUnitBuilder) Host(host *Host) *SystemdUnitBuilder {
+	ub.host = host
+
+	return ub
+}
+
+// Description sets the unit description shown by systemctl (default: "Hadron unit <name>").
+func (ub *SystemdUnitBuilder) Description(description string) *SystemdUnitBuilder {
+	ub.description = description
+
+	return ub
+}
+
+// Type sets the service type, e.g. "notify" or "forking" (default: systemd's "simple").
+func (ub *SystemdUnitBuilder) Type(unitType string) *SystemdUnitBuilder {
+	ub.unitType = unitType
+
+	return ub
+}
+
+// ExecStart sets the command the service runs. The first argument must be an absolute path. Arguments
+// are passed to the program as given: they are quoted for systemd, not split or expanded.
+func (ub *SystemdUnitBuilder) ExecStart(cmd ...string) *SystemdUnitBuilder {
+	ub.execStart = cmd
+
+	return ub
+}
+
+// Env sets an environment variable for the service.
+func (ub *SystemdUnitBuilder) Env(key, value string) *SystemdUnitBuilder {
+	if ub.env == nil {
+		ub.env = make(map[string]string)
+	}
+
+	ub.env[key] = value
+
+	return ub
+}
+
+// User sets the user the service runs as (default: root).
+func (ub *SystemdUnitBuilder) User(user string) *SystemdUnitBuilder {
+	ub.user = user
+
+	return ub
+}
+
+// Restart sets the systemd restart policy, e.g. "always" or "no" (default: "on-failure").
+func (ub *SystemdUnitBuilder) Restart(policy string) *SystemdUnitBuilder {
+	ub.restart = policy
+
+	return ub
+}
+
+// After starts the service after the given units (e.g., "docker.service"), which it also wants.
+func (ub *SystemdUnitBuilder) After(units ...string) *SystemdUnitBuilder {
+	ub.after = append(ub.after, units...)
+
+	return ub
+}
+
+// WantedBy sets the target that starts the service at boot (default: "multi-user.target").
+func (ub *SystemdUnitBuilder) WantedBy(target string) *SystemdUnitBuilder {
+	ub.wantedBy = target
+
+	return ub
+}
+
+// Build creates the SystemdUnit and registers it with the plan.
+// An invalid configuration is fatal; use BuildE to handle it as an error instead.
+func (ub *SystemdUnitBuilder) Build() *SystemdUnit {
+	unit, err := ub.BuildE()
+	if err != nil {
+		ub.plan.logger.Fatal().Err(err).Str("unit", ub.name).Msg("invalid systemd unit")
+	}
+
+	return unit
+}
+
+// BuildE creates the SystemdUnit and registers it with the plan, returning an ErrInvalidSystemdUnit
+// error instead of exiting when the configuration is invalid.
+func (ub *SystemdUnitBuilder) BuildE() (*SystemdUnit, error) {
+	if !systemdUnitName.MatchString(ub.name) {
+		return nil, fmt.Errorf("%w: %q: name may only contain letters, digits, and \":_.-\"",
+			ErrInvalidSystemdUnit, ub.name)
+	}
+
+	if ub.host == nil {
+		return nil, fmt.Errorf("%w: %s: systemd unit must be assigned to a host", ErrInvalidSystemdUnit, ub.name)
+	}
+
+	if len(ub.execStart) == 0 || !strings.HasPrefix(ub.execStart[0], "/") {
+		return nil, fmt.Errorf("%w: %s: ExecStart must start with an absolute path", ErrInvalidSystemdUnit, ub.name)
+	}
+
+	if ub.description == "" {
+		ub.description = "Hadron unit " + ub.name
+	}
+
+	if ub.restart == "" {
+		ub.restart = "on-failure"
+	}
+
+	if ub.wantedBy == "" {
+		ub.wantedBy = "multi-user.target"
+	}
+
+	unit := &SystemdUnit{
+		name: ub.name,
+		host: ub.host,
+		service: systemd.Service{
+			Description: ub.description,
+			Type:        ub.unitType,
+			After:       ub.after,
+			ExecStart:   systemd.CommandLine(ub.execStart...),
+			Environment: ub.env,
+			User:        ub.user,
+			Restart:     ub.restart,
+			WantedBy:    ub.wantedBy,
+		},
+		plan: ub.plan,
+	}
+
+	ub.plan.systemdUnits = append(ub.plan.systemdUnits, unit)
+
+	return unit, nil
+}
+
+// Name returns the unit name, without the .service suffix.
+func (u *SystemdUnit) Name() string {
+	return u.name
+}
+
+// Host returns the host the unit runs on.
+func (u *SystemdUnit) Host() *Host {
+	return u.host
+}
+
+// unitFile returns the unit's file name under /etc/systemd/system.
+func (u *SystemdUnit) unitFile() string {
+	return systemdUnitPrefix + u.name + ".service"
+}
+
+// deploySystemdUnits writes, enables, and starts the plan's systemd units.
+func (e *executor) deploySystemdUnits() error {
+	deploy := tracked(e, "systemd_unit", e.deploySystemdUnit)
+
+	for _, unit := range e.plan.systemdUnits {
+		if err := deploy(unit); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deploySystemdUnit writes a unit file if it changed, then makes sure the unit is enabled and running.
+func (e *executor) deploySystemdUnit(unit *SystemdUnit) (Action, error) {
+	client, err := e.getSSHClient(unit.host)
+	if err != nil {
+		return "", fmt.Errorf(errFailedSSHClient, unit.host, err)
+	}
+
+	return e.applySystemdUnit(client, unit)
+}
+
+// applySystemdUnit deploys a unit over an established connection and returns the action taken.
+func (e *executor) applySystemdUnit(client ssh.Connection, unit *SystemdUnit) (Action, error) {
+	path := systemd.UnitPath(unit.unitFile())
+
+	action := ActionUpdate
+	if _, _, err := client.Execute("test -f " + path); err != nil {
+		action = ActionCreate
+	}
+
+	if !e.mutates() {
+		return e.planSystemdUnit(client, unit, action)
+	}
+
+	changed, err := systemd.WriteFiles(client, []systemd.File{
+		{Path: path, Content: unit.service.Render(), Mode: "644"},
+	})
+	if err != nil {
+		return action, fmt.Errorf("failed to write systemd unit %s: %w", unit.name, err)
+	}
+
+	// Enabling runs even for unchanged units, so a unit stopped by hand is started again
+	if err := systemd.Enable(client, unit.unitFile(), changed); err != nil {
+		return action, fmt.Errorf("failed to enable systemd unit %s: %w", unit.name, err)
+	}
+
+	if !changed {
+		e.plan.logger.Info().Str("unit", unit.name).Msg("Systemd unit unchanged, skipping")
+
+		return ActionSkip, nil
+	}
+
+	e.plan.logger.Info().Str("unit", unit.name).Str("host", unit.host.String()).Msg("Systemd unit started")
+	e.metrics.resourceChanged("systemd_unit")
+
+	return action, nil
+}
+
+// destroySystemdUnit stops, disables, and removes a unit.
+func (e *executor) destroySystemdUnit(unit *SystemdUnit) error {
+	client, err := e.getSSHClient(unit.host)
+	if err != nil {
+		return fmt.Errorf(errFailedSSHClient, unit.host, err)
+	}
+
+	if err := systemd.Remove(client, []string{unit.unitFile()}); err != nil {
+		return fmt.Errorf("failed to remove systemd unit %s: %w", unit.name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package sdk
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/the-agent-c-ai/hadron/internal/testutil"
+)
+
+func TestSystemdUnit(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop())
+	host := plan.Host("user@192.168.1.1").Build()
+
+	unit := plan.SystemdUnit("node-agent").
+		Host(host).
+		ExecStart("/usr/local/bin/node-agent", "--listen", "127.0.0.1:9100").
+		Env("AGENT_TOKEN_FILE", "/etc/node-agent/token").
+		User("nobody").
+		Build()
+
+	content := unit.service.Render()
+
+	want := `# Generated by Hadron
+[Unit]
+Description=Hadron unit node-agent
+
+[Service]
+User=nobody
+Environment="AGENT_TOKEN_FILE=/etc/node-agent/token"
+ExecStart=/usr/local/bin/node-agent --listen 127.0.0.1:9100
+Restart=on-failure
+
+[Install]
+WantedBy=multi-user.target
+`
+	if content != want {
+		t.Fatalf("unexpected unit:\n%s\nwant:\n%s", content, want)
+	}
+
+	exec := newExecutor(plan)
+
+	// A new unit is written, systemd reloaded, and the unit enabled and started
+	client := &testutil.FakeConnection{Failing: []string{"test -f /etc/systemd/system/hadron-node-agent.service"}}
+
+	action, err := exec.applySystemdUnit(client, unit)
+	if err != nil || action != ActionCreate {
+		t.Fatalf("expected the unit to be created, got %q (err: %v)", action, err)
+	}
+
+	created := []string{"sudo systemctl daemon-reload", "sudo systemctl enable --now hadron-node-agent.service"}
+	for _, want := range created {
+		if !slices.Contains(client.Commands, want) {
+			t.Errorf("expected %q, got %v", want, client.Commands)
+		}
+	}
+
+	// An unchanged unit is only made sure to be running
+	installed := &testutil.FakeConnection{Respond: func(command string) string {
+		if strings.HasPrefix(command, "sudo cat ") {
+			return content
+		}
+
+		return ""
+	}}
+
+	action, err = exec.applySystemdUnit(installed, unit)
+	if err != nil || action != ActionSkip {
+		t.Fatalf("expected the unchanged unit to be skipped, got %q (err: %v)", action, err)
+	}
+
+	wantCommands := []string{
+		"test -f /etc/systemd/system/hadron-node-agent.service",
+		"sudo cat /etc/systemd/system/hadron-node-agent.service",
+		"sudo systemctl enable --now hadron-node-agent.service",
+	}
+	if !slices.Equal(installed.Commands, wantCommands) {
+		t.Errorf("expected %v, got %v", wantCommands, installed.Commands)
+	}
+}
+
+func TestSystemdUnitQuoting(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop())
+	host := plan.Host("user@192.168.1.1").Build()
+
+	unit := plan.SystemdUnit("agent").
+		Host(host).
+		ExecStart("/usr/bin/agent", "--label", "web 1", "--format", "100%", "$HOME").
+		Env("GREETING", "say \"hi\"\nbye 5%").
+		Build()
+
+	content := unit.service.Render()
+
+	for _, want := range []string{
+		`ExecStart=/usr/bin/agent --label "web 1" --format "100%%" $$HOME` + "\n",
+		`Environment="GREETING=say \"hi\"\nbye 5%%"` + "\n",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("expected %q in unit:\n%s", want, content)
+		}
+	}
+}
+
+func TestSystemdUnitInvalidName(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop())
+	host := plan.Host("user@192.168.1.1").Build()
+
+	for _, name := range []string{"", "agent;reboot", "../docker", "my agent"} {
+		_, err := plan.SystemdUnit(name).Host(host).ExecStart("/usr/bin/agent").BuildE()
+		if !errors.Is(err, ErrInvalidSystemdUnit) {
+			t.Errorf("%q: expected ErrInvalidSystemdUnit, got %v", name, err)
+		}
+	}
+
+	// Plan units are namespaced, so one named after a system service does not replace it
+	unit := plan.SystemdUnit("docker").Host(host).ExecStart("/usr/bin/agent").Build()
+	if unit.unitFile() != "hadron-docker.service" {
+		t.Errorf("expected hadron-docker.service, got %q", unit.unitFile())
+	}
+}
@@ -0,0 +1,78 @@
+package sdk
+
+import (
+	"fmt"
+	"slices"
+)
+
+// envHosts carries the CLI's --host endpoints, comma-separated.
+const envHosts = "HADRON_HOSTS"
+
+// TargetHosts restricts Execute and Destroy to the given hosts, matched by endpoint (e.g.
+// "deploy@10.0.0.1") or address. Every step skips the other hosts entirely: packages, hardening,
+// firewalls, systemd units, networks, volumes, containers, and jobs. Composes with Only and Skip.
+// Defaults to the CLI's --host flag.
+func (p *Plan) TargetHosts(endpoints ...string) *Plan {
+	p.targetHosts = append(p.targetHosts, endpoints...)
+
+	return p
+}
+
+// targeted returns a copy of the plan holding only the resources on targeted hosts, or the plan
+// itself when no hosts are targeted. Unknown hosts fail with ErrUnknownHost.
+func (p *Plan) targeted() (*Plan, error) {
+	if len(p.targetHosts) == 0 {
+		return p, nil
+	}
+
+	for _, target := range p.targetHosts {
+		if !slices.ContainsFunc(p.hosts, func(host *Host) bool { return host.matches(target) }) {
+			return nil, fmt.Errorf("%w: %q is not a host in plan %s", ErrUnknownHost, target, p.name)
+		}
+	}
+
+	inScope := func(host *Host) bool {
+		return slices.ContainsFunc(p.targetHosts, host.matches)
+	}
+
+	scoped := *p
+	scoped.unscoped = p
+	scoped.hosts = onTargets(p.hosts, func(host *Host) *Host { return host }, inScope)
+	scoped.networks = onTargets(p.networks, (*Network).Host, inScope)
+	scoped.volumes = onTargets(p.volumes, (*Volume).Host, inScope)
+	scoped.containers = onTargets(p.containers, (*Container).Host, inScope)
+	scoped.jobs = onTargets(p.jobs, (*Container).Host, inScope)
+	scoped.systemdUnits = onTargets(p.systemdUnits, (*SystemdUnit).Host, inScope)
+	scoped.postDeployChecks = onTargets(p.postDeployChecks, hook.Host, inScope)
+	scoped.hooks = onTargets(p.hooks, hook.Host, inScope)
+
+	return &scoped, nil
+}
+
+// all returns the full plan a targeted copy was made from, for steps that need to see every host
+// (e.g. WireGuard peers) or every container (e.g. resolving --only names).
+func (p *Plan) all() *Plan {
+	if p.unscoped != nil {
+		return p.unscoped
+	}
+
+	return p
+}
+
+// onTargets returns the items whose host is in scope, in plan order.
+func onTargets[T any](items []T, host func(T) *Host, inScope func(*Host) bool) []T {
+	result := make([]T, 0, len(items))
+
+	for _, item := range items {
+		if inScope(host(item)) {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
+
+// matches reports whether target names this host by endpoint or address.
+func (h *Host) matches(target string) bool {
+	return target == h.endpoint || target == h.Address()
+}
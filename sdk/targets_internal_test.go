@@ -0,0 +1,78 @@
+package sdk
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestTargetHosts(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop())
+	first := plan.Host("deploy@10.0.0.1").Build()
+	second := plan.Host("deploy@10.0.0.2").Build()
+
+	newContainer := func(name string, host *Host, deps ...*Container) *Container {
+		builder := plan.Container(name).
+			Host(host).
+			Image("nginx:latest").
+			Memory("256m").
+			CPUShares(512).
+			CPUs("0.5").
+			PIDsLimit(100)
+
+		for _, dep := range deps {
+			builder.DependsOn(dep)
+		}
+
+		return builder.Build()
+	}
+
+	plan.Network("app").Host(first).Build()
+	plan.Network("app").Host(second).Build()
+	plan.Volume("data").Host(first).Build()
+
+	database := newContainer("database", first)
+	newContainer("app", second, database)
+	newContainer("worker", second)
+
+	plan.PostDeployCheck(first, "true").PostDeployCheck(second, "true")
+
+	scoped, err := plan.TargetHosts("10.0.0.2").Only("app").targeted()
+	if err != nil {
+		t.Fatalf("expected targeting by address to succeed, got: %v", err)
+	}
+
+	if len(scoped.hosts) != 1 || scoped.hosts[0] != second {
+		t.Errorf("expected only the second host, got %v", scoped.hosts)
+	}
+
+	if len(scoped.networks) != 1 || scoped.networks[0].Host() != second || len(scoped.volumes) != 0 {
+		t.Errorf("expected only resources on the second host, got %v and %v", scoped.networks, scoped.volumes)
+	}
+
+	if len(scoped.postDeployChecks) != 1 || scoped.postDeployChecks[0].host != second {
+		t.Errorf("expected only the second host's post-deploy check, got %v", scoped.postDeployChecks)
+	}
+
+	// The database dependency lives on the untargeted host, so only app is deployed
+	selected, err := scoped.selectedContainers()
+	if err != nil {
+		t.Fatalf("expected --only to compose with --host, got: %v", err)
+	}
+
+	if len(selected) != 1 || selected[0].Name() != "app" {
+		t.Errorf("expected [app], got %v", selected)
+	}
+
+	if scoped.all() != plan || len(plan.containers) != 3 {
+		t.Error("expected targeting to leave the full plan untouched")
+	}
+
+	unknown := NewPlan("test").WithLogger(zerolog.Nop()).TargetHosts("deploy@10.0.0.9")
+	if _, err := unknown.targeted(); !errors.Is(err, ErrUnknownHost) {
+		t.Errorf("expected ErrUnknownHost, got: %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package sdk
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/term"
+
+	"github.com/the-agent-c-ai/hadron/sdk/ssh"
+)
+
+// Fallback terminal size when the local size cannot be read.
+const (
+	defaultTerminalWidth  = 80
+	defaultTerminalHeight = 24
+)
+
+// attachTerminal prepares the local terminal for an interactive remote command. If stdin is not a
+// terminal it returns a nil PTY, so input is piped. Otherwise it switches stdin to raw mode and
+// returns a PTY that follows local window size changes; restore undoes both and must be called.
+func attachTerminal(stdin, stdout *os.File) (*ssh.PTY, func(), error) {
+	fd := int(stdin.Fd()) //nolint:gosec // file descriptors fit in an int
+	if !term.IsTerminal(fd) {
+		return nil, func() {}, nil
+	}
+
+	width, height := terminalSize(stdout)
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to switch terminal to raw mode: %w", err)
+	}
+
+	resizes, stop := watchResize(func() ssh.WindowSize {
+		width, height := terminalSize(stdout)
+
+		return ssh.WindowSize{Width: width, Height: height}
+	})
+
+	pty := &ssh.PTY{
+		Term:    os.Getenv("TERM"),
+		Width:   width,
+		Height:  height,
+		Resizes: resizes,
+	}
+
+	restore := func() {
+		stop()
+
+		_ = term.Restore(fd, state)
+	}
+
+	return pty, restore, nil
+}
+
+// terminalSize returns the size of the terminal behind f, or 80x24 if it cannot be read.
+func terminalSize(f *os.File) (int, int) {
+	width, height, err := term.GetSize(int(f.Fd())) //nolint:gosec // file descriptors fit in an int
+	if err != nil {
+		return defaultTerminalWidth, defaultTerminalHeight
+	}
+
+	return width, height
+}
@@ -0,0 +1,35 @@
+//go:build !windows
+
+package sdk
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/the-agent-c-ai/hadron/sdk/ssh"
+)
+
+// watchResize reports the terminal size, read with size, whenever the window changes (SIGWINCH)
+// until stop is called.
+func watchResize(size func() ssh.WindowSize) (<-chan ssh.WindowSize, func()) {
+	signals := make(chan os.Signal, 1)
+	resizes := make(chan ssh.WindowSize, 1)
+
+	signal.Notify(signals, syscall.SIGWINCH)
+
+	go func() {
+		defer close(resizes)
+
+		for range signals {
+			resizes <- size()
+		}
+	}()
+
+	stop := func() {
+		signal.Stop(signals)
+		close(signals)
+	}
+
+	return resizes, stop
+}
@@ -0,0 +1,10 @@
+//go:build windows
+
+package sdk
+
+import "github.com/the-agent-c-ai/hadron/sdk/ssh"
+
+// watchResize is a no-op on Windows, which has no SIGWINCH; the remote terminal keeps its size.
+func watchResize(func() ssh.WindowSize) (<-chan ssh.WindowSize, func()) {
+	return nil, func() {}
+}
@@ -0,0 +1,133 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// vaultScheme prefixes secret references resolved from HashiCorp Vault: "vault://path#field".
+	vaultScheme = "vault://"
+
+	vaultRequestTimeout = 30 * time.Second
+)
+
+// VaultProvider resolves "vault://path#field" references from HashiCorp Vault, e.g.
+// "vault://secret/data/production/database#password". Use it with Plan.WithSecretProvider, or
+// rely on the default provider, which sends vault:// references here.
+type VaultProvider struct{}
+
+// GetSecret parses the reference and resolves it with GetVaultSecret.
+func (VaultProvider) GetSecret(ctx context.Context, reference string) (string, error) {
+	if reference == "" {
+		return "", ErrSecretReferenceEmpty
+	}
+
+	if !strings.HasPrefix(reference, vaultScheme) {
+		return "", fmt.Errorf("%w: %q", ErrVaultReferenceInvalidPrefix, reference)
+	}
+
+	path, field, found := strings.Cut(strings.TrimPrefix(reference, vaultScheme), "#")
+	if !found || path == "" || field == "" {
+		return "", fmt.Errorf("%w (expected 'vault://path#field'): %q", ErrVaultReferenceInvalidFormat, reference)
+	}
+
+	return GetVaultSecret(ctx, path, field)
+}
+
+// GetVaultSecret reads a field of the secret at path from HashiCorp Vault.
+// Path is the API path below /v1/: "secret/data/app" for the KV v2 engine mounted at "secret",
+// "kv/app" for KV v1. KV v2 responses are unwrapped, so field names the secret's key either way.
+//
+// Example:
+//
+//	password, err := GetVaultSecret(ctx, "secret/data/production/database", "password")
+//
+// Uses VAULT_ADDR and VAULT_TOKEN, plus VAULT_NAMESPACE when set (Vault Enterprise).
+func GetVaultSecret(ctx context.Context, path, field string) (string, error) {
+	address := os.Getenv("VAULT_ADDR")
+	token := os.Getenv("VAULT_TOKEN")
+
+	if address == "" || token == "" {
+		return "", ErrVaultNotConfigured
+	}
+
+	reference := path + "#" + field
+
+	ctx, cancel := context.WithTimeout(ctx, vaultRequestTimeout)
+	defer cancel()
+
+	endpoint := strings.TrimSuffix(address, "/") + "/v1/" + strings.TrimPrefix(path, "/")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to get vault secret %q: %w", reference, err)
+	}
+
+	req.Header.Set("X-Vault-Token", token)
+
+	if namespace := os.Getenv("VAULT_NAMESPACE"); namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get vault secret %q: %w", reference, err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to get vault secret %q: %w", reference, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: %q: %s: %s",
+			ErrVaultRequest, reference, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	return vaultField(body, reference, field)
+}
+
+// vaultField extracts a field from a Vault read response, unwrapping the KV v2 envelope.
+func vaultField(body []byte, reference, field string) (string, error) {
+	var response struct {
+		Data map[string]any `json:"data"`
+	}
+
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("%w: %q: %w", ErrVaultRequest, reference, err)
+	}
+
+	data := response.Data
+
+	// KV v2 nests the secret under data.data, next to data.metadata
+	if nested, ok := data["data"].(map[string]any); ok {
+		if _, versioned := data["metadata"]; versioned {
+			data = nested
+		}
+	}
+
+	value, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrVaultFieldNotFound, reference)
+	}
+
+	secret, err := jsonSecretValue(value)
+	if err != nil {
+		return "", fmt.Errorf("%w: %q: %w", ErrVaultRequest, reference, err)
+	}
+
+	if secret == "" {
+		return "", fmt.Errorf("%w: %q", ErrSecretEmpty, reference)
+	}
+
+	return secret, nil
+}
@@ -0,0 +1,105 @@
+package sdk_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/the-agent-c-ai/hadron/sdk"
+)
+
+func newVaultServer(t *testing.T) {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "test-token" {
+			http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v1/secret/data/production/database":
+			_, _ = w.Write([]byte(`{"data":{"data":{"password":"s3cret","port":5432},"metadata":{"version":3}}}`))
+		case "/v1/kv/legacy":
+			_, _ = w.Write([]byte(`{"data":{"token":"v1-token"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	t.Setenv("VAULT_ADDR", server.URL)
+	t.Setenv("VAULT_TOKEN", "test-token")
+}
+
+func TestGetVaultSecret(t *testing.T) {
+	newVaultServer(t)
+
+	ctx := context.Background()
+
+	tests := []struct {
+		path, field, want string
+	}{
+		{path: "secret/data/production/database", field: "password", want: "s3cret"},
+		{path: "secret/data/production/database", field: "port", want: "5432"},
+		{path: "kv/legacy", field: "token", want: "v1-token"},
+	}
+
+	for _, tt := range tests {
+		got, err := sdk.GetVaultSecret(ctx, tt.path, tt.field)
+		if err != nil {
+			t.Errorf("%s#%s: expected secret, got: %v", tt.path, tt.field, err)
+
+			continue
+		}
+
+		if got != tt.want {
+			t.Errorf("%s#%s: expected %q, got %q", tt.path, tt.field, tt.want, got)
+		}
+	}
+
+	_, err := sdk.GetVaultSecret(ctx, "secret/data/production/database", "user")
+	if !errors.Is(err, sdk.ErrVaultFieldNotFound) {
+		t.Errorf("expected ErrVaultFieldNotFound, got: %v", err)
+	}
+
+	if _, err := sdk.GetVaultSecret(ctx, "secret/data/missing", "password"); !errors.Is(err, sdk.ErrVaultRequest) {
+		t.Errorf("expected ErrVaultRequest for a missing secret, got: %v", err)
+	}
+}
+
+func TestVaultProviderReferences(t *testing.T) {
+	newVaultServer(t)
+
+	var provider sdk.VaultProvider
+
+	ctx := context.Background()
+
+	got, err := provider.GetSecret(ctx, "vault://secret/data/production/database#password")
+	if err != nil || got != "s3cret" {
+		t.Errorf("expected s3cret, got %q (%v)", got, err)
+	}
+
+	_, err = provider.GetSecret(ctx, "op://Production/database/password")
+	if !errors.Is(err, sdk.ErrVaultReferenceInvalidPrefix) {
+		t.Errorf("expected ErrVaultReferenceInvalidPrefix, got: %v", err)
+	}
+
+	_, err = provider.GetSecret(ctx, "vault://secret/data/production/database")
+	if !errors.Is(err, sdk.ErrVaultReferenceInvalidFormat) {
+		t.Errorf("expected ErrVaultReferenceInvalidFormat, got: %v", err)
+	}
+}
+
+func TestGetVaultSecretRequiresConfiguration(t *testing.T) {
+	t.Setenv("VAULT_ADDR", "")
+	t.Setenv("VAULT_TOKEN", "")
+
+	_, err := sdk.GetVaultSecret(context.Background(), "secret/data/app", "password")
+	if !errors.Is(err, sdk.ErrVaultNotConfigured) {
+		t.Errorf("expected ErrVaultNotConfigured, got: %v", err)
+	}
+}
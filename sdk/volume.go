@@ -4,22 +4,34 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"sort"
 )
 
+// defaultBackupImage archives volumes for BackupOnRecreate.
+const defaultBackupImage = "busybox:stable"
+
 // Volume represents a Docker volume.
 type Volume struct {
-	name   string
-	host   *Host
-	driver string
-	plan   *Plan
+	name       string
+	host       *Host
+	driver     string
+	driverOpts map[string]string // driver options passed as --opt key=value
+	labels     map[string]string // user labels (merged with hadron's own labels)
+	backup     string            // image archiving the volume before it is recreated (empty: no backup)
+	recreate   bool              // allow recreating the volume even when it holds data
+	plan       *Plan
 }
 
 // VolumeBuilder builds a Volume with a fluent API.
 type VolumeBuilder struct {
-	plan   *Plan
-	name   string
-	host   *Host
-	driver string
+	plan       *Plan
+	name       string
+	host       *Host
+	driver     string
+	driverOpts map[string]string
+	labels     map[string]string
+	backup     string
+	recreate   bool
 }
 
 // Host sets the host where this volume will be created.
@@ -36,10 +48,74 @@ func (vb *VolumeBuilder) Driver(driver string) *VolumeBuilder {
 	return vb
 }
 
+// DriverOpt sets a volume driver option, e.g. for an NFS-backed volume:
+// DriverOpt("type", "nfs").DriverOpt("o", "addr=10.0.0.2,rw").DriverOpt("device", ":/exports/data").
+func (vb *VolumeBuilder) DriverOpt(key, value string) *VolumeBuilder {
+	if vb.driverOpts == nil {
+		vb.driverOpts = make(map[string]string)
+	}
+
+	vb.driverOpts[key] = value
+
+	return vb
+}
+
+// Label sets a Docker label on the volume, e.g. to record what the mount point holds. Labels are
+// not part of the config hash: Docker cannot relabel a volume, and recreating it to do so would
+// wipe its data, so a changed label only applies once the volume is recreated for another reason.
+func (vb *VolumeBuilder) Label(key, value string) *VolumeBuilder {
+	if vb.labels == nil {
+		vb.labels = make(map[string]string)
+	}
+
+	vb.labels[key] = value
+
+	return vb
+}
+
+// BackupOnRecreate archives the volume's contents to backups/<name>-<timestamp>.tar.gz in the host's
+// WorkDir (/var/lib/hadron) before a configuration change recreates it, and aborts the recreate if the backup
+// fails. The archive is written by a throwaway container; the optional image (default "busybox:stable")
+// must provide tar.
+func (vb *VolumeBuilder) BackupOnRecreate(image ...string) *VolumeBuilder {
+	vb.backup = defaultBackupImage
+	if len(image) > 0 && image[0] != "" {
+		vb.backup = image[0]
+	}
+
+	return vb
+}
+
+// AllowRecreate lets a configuration change recreate the volume even when it holds data, wiping it
+// (after confirmation with --yes). Without it, a changed volume that is not empty fails the deploy
+// with ErrVolumeHasData so its data can be migrated by hand. Combine with BackupOnRecreate to keep
+// an archive.
+func (vb *VolumeBuilder) AllowRecreate() *VolumeBuilder {
+	vb.recreate = true
+
+	return vb
+}
+
 // Build creates the Volume and registers it with the plan.
+// An invalid configuration is fatal; use BuildE to handle it as an error instead.
 func (vb *VolumeBuilder) Build() *Volume {
+	volume, err := vb.BuildE()
+	if err != nil {
+		vb.plan.logger.Fatal().Err(err).Str("volume", vb.name).Msg("invalid volume")
+	}
+
+	return volume
+}
+
+// BuildE creates the Volume and registers it with the plan, returning an ErrInvalidVolume error
+// instead of exiting when the configuration is invalid.
+func (vb *VolumeBuilder) BuildE() (*Volume, error) {
+	if vb.name == "" {
+		return nil, fmt.Errorf("%w: volume name is required", ErrInvalidVolume)
+	}
+
 	if vb.host == nil {
-		vb.plan.logger.Fatal().Str("volume", vb.name).Msg("volume must be assigned to a host")
+		return nil, fmt.Errorf("%w: %s: volume must be assigned to a host", ErrInvalidVolume, vb.name)
 	}
 
 	if vb.driver == "" {
@@ -47,15 +123,19 @@ func (vb *VolumeBuilder) Build() *Volume {
 	}
 
 	volume := &Volume{
-		name:   vb.name,
-		host:   vb.host,
-		driver: vb.driver,
-		plan:   vb.plan,
+		name:       vb.name,
+		host:       vb.host,
+		driver:     vb.driver,
+		driverOpts: vb.driverOpts,
+		labels:     vb.labels,
+		backup:     vb.backup,
+		recreate:   vb.recreate,
+		plan:       vb.plan,
 	}
 
 	vb.plan.volumes = append(vb.plan.volumes, volume)
 
-	return volume
+	return volume, nil
 }
 
 // Name returns the volume name.
@@ -73,11 +153,34 @@ func (v *Volume) Driver() string {
 	return v.driver
 }
 
+// DriverOpts returns the volume driver options.
+func (v *Volume) DriverOpts() map[string]string {
+	return v.driverOpts
+}
+
 // ConfigHash returns a SHA256 hash of the volume configuration.
 // Used for idempotent deployments.
 func (v *Volume) ConfigHash() string {
 	config := fmt.Sprintf("%s|%s|%s", v.name, v.driver, v.host.String())
+
+	// Options are only hashed when set, so existing volumes keep their hash
+	for _, k := range sortedKeys(v.driverOpts) {
+		config += fmt.Sprintf("|opt:%s=%s", k, v.driverOpts[k])
+	}
+
 	hash := sha256.Sum256([]byte(config))
 
 	return hex.EncodeToString(hash[:])
 }
+
+// sortedKeys returns the keys of a string map in sorted order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,48 @@
+package sdk_test
+
+import (
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/the-agent-c-ai/hadron/sdk"
+)
+
+func TestVolumeDriverOpts(t *testing.T) {
+	t.Parallel()
+
+	plan := sdk.NewPlan("test").WithLogger(zerolog.Nop())
+
+	host := plan.Host("testuser@192.168.1.1").
+		Build()
+
+	local := plan.Volume("data").
+		Host(host).
+		Build()
+
+	nfs := plan.Volume("data").
+		Host(host).
+		DriverOpt("type", "nfs").
+		DriverOpt("o", "addr=10.0.0.2,rw").
+		DriverOpt("device", ":/exports/data").
+		Build()
+
+	opts := nfs.DriverOpts()
+	if opts["type"] != "nfs" || opts["o"] != "addr=10.0.0.2,rw" || opts["device"] != ":/exports/data" {
+		t.Errorf("unexpected driver options: %v", opts)
+	}
+
+	if local.ConfigHash() == nfs.ConfigHash() {
+		t.Error("expected driver options to change the config hash")
+	}
+
+	labeled := plan.Volume("data").
+		Host(host).
+		Label("mount-point", "/var/lib/postgresql/data").
+		Build()
+
+	// Docker cannot relabel a volume, so a label edit must not trigger a data-wiping recreate
+	if local.ConfigHash() != labeled.ConfigHash() {
+		t.Error("expected labels to leave the config hash unchanged")
+	}
+}
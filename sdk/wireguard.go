@@ -0,0 +1,150 @@
+package sdk
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+
+	"github.com/the-agent-c-ai/hadron/internal/wireguard"
+)
+
+// wireGuardHosts returns the plan's hosts that are on the WireGuard overlay.
+func (p *Plan) wireGuardHosts() []*Host {
+	var hosts []*Host
+
+	for _, host := range p.hosts {
+		if host.wireGuard != "" {
+			hosts = append(hosts, host)
+		}
+	}
+
+	return hosts
+}
+
+// deployWireGuard installs WireGuard on every overlay host and peers each host with all the others.
+// Keys are ensured on all hosts first, since every host's configuration needs its peers' public keys.
+// With TargetHosts, only targeted hosts are configured, but keys are still read from the other peers.
+// A peer that cannot be reached or was never provisioned is left out with a warning, until the
+// targeted hosts are deployed again.
+func (e *executor) deployWireGuard() error {
+	hosts := e.plan.wireGuardHosts()
+	if len(hosts) == 0 {
+		return nil
+	}
+
+	peers := e.plan.all().wireGuardHosts()
+	publicKeys := make(map[*Host]string, len(peers))
+
+	for _, host := range peers {
+		if !slices.Contains(hosts, host) {
+			if publicKey, err := e.peerPublicKey(host); err != nil {
+				e.plan.logger.Warn().Err(err).Str("peer", host.String()).
+					Msg("Skipping WireGuard peer outside the targeted hosts, its key could not be read")
+			} else {
+				publicKeys[host] = publicKey
+			}
+
+			continue
+		}
+
+		client, err := e.getSSHClient(host)
+		if err != nil {
+			return fmt.Errorf(errFailedSSHClient, host, err)
+		}
+
+		manager, err := e.packageManager(host)
+		if err != nil {
+			return err
+		}
+
+		if err := wireguard.Install(client, manager); err != nil {
+			return fmt.Errorf("failed to install wireguard on %s: %w", host, err)
+		}
+
+		publicKey, err := wireguard.EnsureKey(client, wireguard.DefaultInterface)
+		if err != nil {
+			return fmt.Errorf("failed to set up wireguard key on %s: %w", host, err)
+		}
+
+		publicKeys[host] = publicKey
+	}
+
+	for _, host := range hosts {
+		config, err := wireGuardConfig(host, peers, publicKeys)
+		if err != nil {
+			return err
+		}
+
+		client, err := e.getSSHClient(host)
+		if err != nil {
+			return fmt.Errorf(errFailedSSHClient, host, err)
+		}
+
+		changed, err := wireguard.Apply(client, config)
+		if err != nil {
+			return fmt.Errorf("failed to configure wireguard on %s: %w", host, err)
+		}
+
+		if changed {
+			e.plan.logger.Info().
+				Str("host", host.String()).
+				Str("address", host.wireGuard).
+				Int("peers", len(config.Peers)).
+				Msg("WireGuard overlay configured")
+		} else {
+			e.plan.logger.Debug().Str("host", host.String()).Msg("WireGuard configuration unchanged")
+		}
+	}
+
+	return nil
+}
+
+// peerPublicKey reads the WireGuard public key of an overlay host that is not being deployed.
+func (e *executor) peerPublicKey(host *Host) (string, error) {
+	client, err := e.getSSHClient(host)
+	if err != nil {
+		return "", fmt.Errorf(errFailedSSHClient, host, err)
+	}
+
+	publicKey, err := wireguard.PublicKey(client, wireguard.DefaultInterface)
+	if err != nil {
+		return "", fmt.Errorf("failed to read wireguard key of peer %s: %w", host, err)
+	}
+
+	return publicKey, nil
+}
+
+// wireGuardConfig builds a host's WireGuard configuration, peering it with every other overlay host
+// whose public key is known. Peers are reached at their Address, so every overlay host needs one.
+func wireGuardConfig(host *Host, hosts []*Host, publicKeys map[*Host]string) (wireguard.Config, error) {
+	config := wireguard.Config{
+		Interface:  wireguard.DefaultInterface,
+		Address:    host.wireGuard,
+		ListenPort: wireguard.DefaultListenPort,
+	}
+
+	for _, peer := range hosts {
+		if peer == host {
+			continue
+		}
+
+		if peer.Address() == "" {
+			return wireguard.Config{}, fmt.Errorf("%w: %s (set HostBuilder.Address)", ErrWireGuardPeerAddress, peer)
+		}
+
+		publicKey, ok := publicKeys[peer]
+		if !ok {
+			continue
+		}
+
+		config.Peers = append(config.Peers, wireguard.Peer{
+			Name:       peer.String(),
+			PublicKey:  publicKey,
+			Endpoint:   net.JoinHostPort(peer.Address(), strconv.Itoa(wireguard.DefaultListenPort)),
+			AllowedIPs: []string{peer.OverlayAddress() + "/32"},
+		})
+	}
+
+	return config, nil
+}
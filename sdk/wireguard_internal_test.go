@@ -0,0 +1,115 @@
+package sdk
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestWireGuardPeerWiring(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop())
+
+	web := plan.Host("deploy@203.0.113.6").
+		WireGuard("10.100.0.1/24").
+		Firewall().Done().
+		Build()
+	db := plan.Host("deploy@203.0.113.7").
+		WireGuard("10.100.0.2/24").
+		Build()
+	plan.Host("deploy@203.0.113.8").Build() // not on the overlay
+
+	hosts := plan.wireGuardHosts()
+	if len(hosts) != 2 {
+		t.Fatalf("expected 2 overlay hosts, got %d", len(hosts))
+	}
+
+	publicKeys := map[*Host]string{web: "d2ViLXB1YmxpYy1rZXk=", db: "ZGItcHVibGljLWtleQ=="}
+
+	webConfig, err := wireGuardConfig(web, hosts, publicKeys)
+	if err != nil {
+		t.Fatalf("expected web config, got: %v", err)
+	}
+
+	dbConfig, err := wireGuardConfig(db, hosts, publicKeys)
+	if err != nil {
+		t.Fatalf("expected db config, got: %v", err)
+	}
+
+	if webConfig.Address != "10.100.0.1/24" || len(webConfig.Peers) != 1 {
+		t.Fatalf("expected web on 10.100.0.1/24 with one peer, got %+v", webConfig)
+	}
+
+	webPeer := webConfig.Peers[0]
+	if webPeer.PublicKey != publicKeys[db] || webPeer.Endpoint != "203.0.113.7:51820" ||
+		!slices.Equal(webPeer.AllowedIPs, []string{"10.100.0.2/32"}) {
+		t.Errorf("expected web to peer with db, got %+v", webPeer)
+	}
+
+	dbPeer := dbConfig.Peers[0]
+	if dbPeer.PublicKey != publicKeys[web] || dbPeer.Endpoint != "203.0.113.6:51820" ||
+		!slices.Equal(dbPeer.AllowedIPs, []string{"10.100.0.1/32"}) {
+		t.Errorf("expected db to peer with web, got %+v", dbPeer)
+	}
+
+	// The firewall on web must let WireGuard traffic in
+	if !slices.ContainsFunc(web.firewallConfig.Rules, func(rule FirewallRule) bool {
+		return rule.Port == 51820 && rule.Protocol == protocolUDP
+	}) {
+		t.Errorf("expected WireGuard port in firewall rules, got %+v", web.firewallConfig.Rules)
+	}
+
+	// Cross-host dependencies between overlay hosts resolve to overlay addresses
+	newContainer := func(name string, host *Host) *ContainerBuilder {
+		return plan.Container(name).
+			Host(host).
+			Image("nginx:latest").
+			Memory("256m").
+			CPUShares(512).
+			CPUs("0.5").
+			PIDsLimit(100)
+	}
+
+	postgres := newContainer("postgres", db).NetworkAlias("db").Build()
+	app := newContainer("app", web).DependsOn(postgres).Build()
+
+	if !slices.Contains(app.ExtraHosts(), "db:10.100.0.2") {
+		t.Errorf("expected overlay add-host entry, got %v", app.ExtraHosts())
+	}
+}
+
+func TestWireGuardPeerWithoutAddress(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop())
+
+	web := plan.Host("deploy@203.0.113.6").WireGuard("10.100.0.1/24").Build()
+	plan.Host("db.internal").WireGuard("10.100.0.2/24").Build()
+
+	_, err := wireGuardConfig(web, plan.wireGuardHosts(), map[*Host]string{})
+	if !errors.Is(err, ErrWireGuardPeerAddress) {
+		t.Errorf("expected ErrWireGuardPeerAddress, got: %v", err)
+	}
+}
+
+func TestWireGuardPeerWithoutKey(t *testing.T) {
+	t.Parallel()
+
+	plan := NewPlan("test").WithLogger(zerolog.Nop())
+
+	web := plan.Host("deploy@203.0.113.6").WireGuard("10.100.0.1/24").Build()
+	db := plan.Host("deploy@203.0.113.7").WireGuard("10.100.0.2/24").Build()
+	plan.Host("deploy@203.0.113.8").WireGuard("10.100.0.3/24").Build() // down, or never deployed
+
+	config, err := wireGuardConfig(web, plan.wireGuardHosts(), map[*Host]string{db: "ZGItcHVibGljLWtleQ=="})
+	if err != nil {
+		t.Fatalf("expected web config, got: %v", err)
+	}
+
+	if len(config.Peers) != 1 || config.Peers[0].Name != db.String() {
+		t.Errorf("expected only the peer with a known key, got %+v", config.Peers)
+	}
+}
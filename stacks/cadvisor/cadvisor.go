@@ -0,0 +1,74 @@
+// Package cadvisor provides cAdvisor per-container resource metrics for the metrics stack.
+package cadvisor
+
+import (
+	"time"
+
+	"github.com/the-agent-c-ai/hadron/sdk"
+)
+
+const (
+	// Port cAdvisor serves /metrics and /healthz on.
+	Port = 8080
+
+	// Maximum number of PIDs allowed in the cAdvisor container.
+	maxPIDs = 100
+)
+
+// Config contains configuration for cAdvisor.
+type Config struct {
+	Image                string       // gcr.io/cadvisor/cadvisor image with digest
+	Network              *sdk.Network // Metrics network Alloy scrapes from
+	HousekeepingInterval string       // Interval between container stats collections (default: "30s")
+}
+
+// CAdvisor deploys cAdvisor on the host, exposing Prometheus metrics for every container at
+// cadvisor:8080/metrics on Config.Network, so the Alloy metrics stack can scrape it when joined
+// to the same network.
+//
+// The host's root filesystem, /var/run, /sys, and /var/lib/docker are mounted read-only. The
+// container runs without --privileged: every capability is dropped except DAC_READ_SEARCH, which
+// cAdvisor needs as root to read Docker's state directories.
+func CAdvisor(
+	plan *sdk.Plan,
+	host *sdk.Host,
+	cnf *Config,
+) *sdk.Container {
+	housekeeping := cnf.HousekeepingInterval
+	if housekeeping == "" {
+		housekeeping = "30s"
+	}
+
+	return plan.Container("cadvisor").
+		Host(host).
+		Image(cnf.Image).
+		Network(cnf.Network).
+		NetworkAlias("cadvisor").
+		Volume("/", "/rootfs", "ro").
+		Volume("/var/run", "/var/run", "ro").
+		Volume("/sys", "/sys", "ro").
+		Volume("/var/lib/docker", "/var/lib/docker", "ro").
+		Command(
+			"--housekeeping_interval="+housekeeping,
+			"--docker_only=true",
+			"--store_container_labels=false",
+			// Metrics that are expensive to collect or not useful for containers
+			"--disable_metrics=advtcp,disk,hugetlb,percpu,process,referenced_memory,sched,tcp,udp",
+		).
+		Restart("unless-stopped").
+		ReadOnly().
+		Tmpfs("/tmp", "size=16m").
+		CapDrop("ALL").
+		CapAdd("DAC_READ_SEARCH").
+		SecurityOpt("no-new-privileges").
+		Memory("256m").
+		MemoryReservation("128m").
+		CPUShares(256).
+		CPUs("0.25").
+		PIDsLimit(maxPIDs).
+		HealthCheck(sdk.HTTPCheck("/healthz", Port).
+			WithTimeout(10 * time.Second).
+			WithInterval(30 * time.Second).
+			WithRetries(3)).
+		Build()
+}
@@ -0,0 +1,118 @@
+// Package postgres provides a PostgreSQL database with a persistent data volume on an internal network.
+package postgres
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/the-agent-c-ai/hadron/sdk"
+)
+
+const (
+	// containerPort is the port PostgreSQL listens on inside the container.
+	containerPort = 5432
+
+	// dataDir is PGDATA, set explicitly so the data volume works across image versions
+	// (PostgreSQL 18 images moved the default).
+	dataDir = "/var/lib/postgresql/data"
+
+	// Maximum number of PIDs allowed in the PostgreSQL container (one per connection plus workers).
+	maxPIDs = 300
+
+	defaultName    = "postgres"
+	defaultUser    = "postgres"
+	defaultShmSize = "256m"
+	defaultMemory  = "1g"
+	defaultCPUs    = "1"
+)
+
+// Config contains configuration for the PostgreSQL database.
+type Config struct {
+	Image          string // postgres image with digest, e.g. "postgres:17@sha256:..."
+	Name           string // Container, volume, and network name prefix (default: "postgres")
+	Database       string // Database created on first start (default: User)
+	User           string // Superuser created on first start (default: "postgres")
+	Password       string // Superuser password (use PasswordSecret instead where possible)
+	PasswordSecret string // Secret reference for the superuser password (e.g., "op://vault/db/password")
+	Port           int    // Host port published on 127.0.0.1 (optional; 0 keeps it on the network only)
+	ShmSize        string // /dev/shm size for parallel queries (default: "256m")
+	Memory         string // Memory limit (default: "1g")
+	CPUs           string // CPU limit (default: "1")
+}
+
+// Postgres deploys a PostgreSQL container with its data in a named volume ("<name>-data") and
+// returns it with the network applications join to reach it at "<name>:5432".
+//
+// The network is internal unless Config.Port publishes the database on the host's loopback
+// interface. Only the data volume is writable, so it is the one thing to back up, e.g. with a
+// plan.Job running pg_dump on the same network.
+func Postgres(plan *sdk.Plan, host *sdk.Host, cnf *Config) (*sdk.Container, *sdk.Network) {
+	name := withDefault(cnf.Name, defaultName)
+	user := withDefault(cnf.User, defaultUser)
+	database := withDefault(cnf.Database, user)
+
+	networkBuilder := plan.Network(name).Host(host)
+	if cnf.Port == 0 {
+		// Published ports are unreachable on internal networks
+		networkBuilder = networkBuilder.Internal()
+	}
+
+	network := networkBuilder.Build()
+
+	data := plan.Volume(name + "-data").
+		Host(host).
+		Build()
+
+	builder := plan.Container(name).
+		Host(host).
+		Image(cnf.Image).
+		Network(network).
+		NetworkAlias(name).
+		Volume(data, dataDir). // Writable: database files
+		Env("PGDATA", dataDir).
+		Env("POSTGRES_USER", user).
+		Env("POSTGRES_DB", database)
+
+	if cnf.PasswordSecret != "" {
+		builder = builder.EnvSecret("POSTGRES_PASSWORD", cnf.PasswordSecret)
+	} else {
+		builder = builder.Env("POSTGRES_PASSWORD", cnf.Password)
+	}
+
+	if cnf.Port != 0 {
+		builder = builder.Port("127.0.0.1:" + strconv.Itoa(cnf.Port) + ":" + strconv.Itoa(containerPort))
+	}
+
+	return builder.
+		Restart("unless-stopped").
+		ReadOnly().
+		Tmpfs("/var/run/postgresql", "size=1m"). // Unix socket and lock file
+		Tmpfs("/tmp", "size=64m").
+		ShmSize(withDefault(cnf.ShmSize, defaultShmSize)). // Docker's default 64m is too small for parallel queries
+		CapDrop("ALL").
+		CapAdd("CHOWN"). // The entrypoint fixes data directory ownership before dropping to postgres
+		CapAdd("DAC_OVERRIDE").
+		CapAdd("FOWNER").
+		CapAdd("SETGID").
+		CapAdd("SETUID").
+		SecurityOpt("no-new-privileges").
+		Memory(withDefault(cnf.Memory, defaultMemory)).
+		MemoryReservation("256m").
+		CPUShares(1024).
+		CPUs(withDefault(cnf.CPUs, defaultCPUs)).
+		PIDsLimit(maxPIDs).
+		HealthCheck(sdk.CommandCheck("pg_isready", "-U", user, "-d", database).
+			WithTimeout(5 * time.Second).
+			WithInterval(10 * time.Second).
+			WithRetries(5)).
+		Build(), network
+}
+
+// withDefault returns value, or fallback when value is empty.
+func withDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+
+	return value
+}
@@ -0,0 +1,138 @@
+// Package prometheus provides a self-hosted Prometheus server for teams not forwarding metrics to Grafana Cloud.
+package prometheus
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/the-agent-c-ai/hadron/sdk"
+)
+
+const (
+	// Port Prometheus serves its UI, API, and /-/healthy on.
+	Port = 9090
+
+	// Maximum number of PIDs allowed in the Prometheus container.
+	maxPIDs = 100
+
+	configPath = "/etc/prometheus/prometheus.yml"
+	dataPath   = "/prometheus"
+
+	defaultScrapeInterval = "30s"
+	defaultRetention      = "15d"
+)
+
+// Target is a scrape job: the addresses Prometheus scrapes under one job label.
+type Target struct {
+	Job         string   // Job label, e.g. "cadvisor"
+	Addresses   []string // host:port addresses, e.g. "cadvisor:8080" on a shared network
+	MetricsPath string   // Metrics path (default: "/metrics")
+}
+
+// Config contains configuration for the Prometheus server.
+type Config struct {
+	Image          string         // Prometheus image with digest
+	Networks       []*sdk.Network // Networks to join for scraping targets
+	Targets        []Target       // Scrape jobs
+	ScrapeInterval string         // Scrape and rule evaluation interval (default: "30s")
+	Retention      string         // How long to keep samples (default: "15d")
+	RetentionSize  string         // Maximum TSDB size, e.g. "10GB" (optional)
+	Memory         string         // Memory limit (default: "1g")
+}
+
+// renderConfig generates prometheus.yml for the configured targets. Prometheus also scrapes itself
+// as the "prometheus" job.
+func renderConfig(cnf *Config) []byte {
+	interval := strconv.Quote(withDefault(cnf.ScrapeInterval, defaultScrapeInterval))
+
+	var config strings.Builder
+
+	config.WriteString("# Generated by Hadron\n")
+	config.WriteString("global:\n")
+	fmt.Fprintf(&config, "  scrape_interval: %s\n", interval)
+	fmt.Fprintf(&config, "  evaluation_interval: %s\n", interval)
+	config.WriteString("scrape_configs:\n")
+
+	self := Target{Job: "prometheus", Addresses: []string{fmt.Sprintf("localhost:%d", Port)}}
+
+	jobs := append([]Target{self}, cnf.Targets...)
+	for _, job := range jobs {
+		fmt.Fprintf(&config, "  - job_name: %s\n", strconv.Quote(job.Job))
+
+		if job.MetricsPath != "" {
+			fmt.Fprintf(&config, "    metrics_path: %s\n", strconv.Quote(job.MetricsPath))
+		}
+
+		config.WriteString("    static_configs:\n")
+		config.WriteString("      - targets:\n")
+
+		for _, address := range job.Addresses {
+			fmt.Fprintf(&config, "          - %s\n", strconv.Quote(address))
+		}
+	}
+
+	return []byte(config.String())
+}
+
+// Prometheus deploys a Prometheus server scraping Config.Targets, with its TSDB in the
+// "prometheus-data" volume.
+//
+// Like the Alloy metrics stack, the container connects to all networks in Config.Networks so
+// targets on different network segments are reachable by their network alias.
+func Prometheus(
+	plan *sdk.Plan,
+	host *sdk.Host,
+	cnf *Config,
+) *sdk.Container {
+	data := plan.Volume("prometheus-data").
+		Host(host).
+		Build()
+
+	builder := plan.Container("prometheus").
+		Host(host).
+		Image(cnf.Image).
+		NetworkAlias("prometheus")
+
+	for _, network := range cnf.Networks {
+		builder = builder.Network(network)
+	}
+
+	args := []string{
+		"--config.file=" + configPath,
+		"--storage.tsdb.path=" + dataPath,
+		"--storage.tsdb.retention.time=" + withDefault(cnf.Retention, defaultRetention),
+	}
+	if cnf.RetentionSize != "" {
+		args = append(args, "--storage.tsdb.retention.size="+cnf.RetentionSize)
+	}
+
+	return builder.
+		Volume(data, dataPath).                         // Writable: TSDB
+		MountData(renderConfig(cnf), configPath, "ro"). // Scrape config (read-only)
+		Command(args...).
+		Restart("unless-stopped").
+		ReadOnly().
+		CapDrop("ALL").
+		SecurityOpt("no-new-privileges").
+		Memory(withDefault(cnf.Memory, "1g")).
+		MemoryReservation("512m").
+		CPUShares(512).
+		CPUs("1").
+		PIDsLimit(maxPIDs).
+		HealthCheck(sdk.HTTPCheck("/-/healthy", Port).
+			WithTimeout(10 * time.Second).
+			WithInterval(30 * time.Second).
+			WithRetries(3)).
+		Build()
+}
+
+// withDefault returns value, or fallback when value is empty.
+func withDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+
+	return value
+}
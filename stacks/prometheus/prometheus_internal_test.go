@@ -0,0 +1,37 @@
+package prometheus
+
+import "testing"
+
+func TestRenderConfig(t *testing.T) {
+	t.Parallel()
+
+	config := renderConfig(&Config{
+		Targets: []Target{
+			{Job: "cadvisor", Addresses: []string{"cadvisor:8080"}},
+			{Job: "caddy", Addresses: []string{"caddy:2019"}, MetricsPath: "/metrics"},
+		},
+	})
+
+	want := `# Generated by Hadron
+global:
+  scrape_interval: "30s"
+  evaluation_interval: "30s"
+scrape_configs:
+  - job_name: "prometheus"
+    static_configs:
+      - targets:
+          - "localhost:9090"
+  - job_name: "cadvisor"
+    static_configs:
+      - targets:
+          - "cadvisor:8080"
+  - job_name: "caddy"
+    metrics_path: "/metrics"
+    static_configs:
+      - targets:
+          - "caddy:2019"
+`
+	if string(config) != want {
+		t.Errorf("unexpected config:\n%s\nwant:\n%s", config, want)
+	}
+}
@@ -3,6 +3,7 @@ package proxy
 
 import (
 	_ "embed"
+	"strconv"
 	"time"
 
 	"github.com/the-agent-c-ai/hadron/sdk"
@@ -11,6 +12,11 @@ import (
 //go:embed Caddyfile
 var caddyfile string
 
+const (
+	defaultHealthInterval = "30s"
+	defaultHealthTimeout  = "5s"
+)
+
 // Config contains configuration for the Caddy reverse proxy.
 type Config struct {
 	Image             string
@@ -19,11 +25,48 @@ type Config struct {
 	Email             string
 	Domain            string
 	ReversePort       string
-	ReverseHealth     string
-	ReverseHealthPort string
+	ReverseHealth     string // health check path; defaults to the backend's HTTP health check
+	ReverseHealthPort string // health check port; defaults to the backend's HTTP health check
 	MountPoint        string
 }
 
+// upstreamHealth is the active health check Caddy runs against the backend.
+type upstreamHealth struct {
+	uri      string
+	port     string
+	interval string
+	timeout  string
+}
+
+// backendHealth derives Caddy's upstream health check. Explicit config wins; otherwise the backend's own
+// HTTP health check is reused, so Caddy stops routing to the backend while it fails the check Docker runs.
+func backendHealth(depends *sdk.Container, cnf *Config) upstreamHealth {
+	health := upstreamHealth{
+		uri:      cnf.ReverseHealth,
+		port:     cnf.ReverseHealthPort,
+		interval: defaultHealthInterval,
+		timeout:  defaultHealthTimeout,
+	}
+
+	check := depends.HealthCheck()
+	if check == nil || check.Type() != sdk.HealthCheckHTTP {
+		return health
+	}
+
+	if health.uri == "" {
+		health.uri = check.Path()
+	}
+
+	if health.port == "" {
+		health.port = strconv.Itoa(check.Port())
+	}
+
+	health.interval = check.Interval().String()
+	health.timeout = check.Timeout().String()
+
+	return health
+}
+
 // Proxy deploys a Caddy reverse proxy container with automatic HTTPS.
 // It creates volumes for TLS certificates and runtime config, and configures
 // Caddy to reverse proxy to the specified dependency container.
@@ -43,6 +86,8 @@ func Proxy(plan *sdk.Plan, depends *sdk.Container, network *sdk.Network, host *s
 		mountPoint = "/*"
 	}
 
+	health := backendHealth(depends, cnf)
+
 	plan.Container("caddy").
 		Host(host).
 		Image(cnf.Image).
@@ -58,8 +103,10 @@ func Proxy(plan *sdk.Plan, depends *sdk.Container, network *sdk.Network, host *s
 		Env("EMAIL", cnf.Email).
 		Env("REVERSE", depends.NetworkAlias()).
 		Env("REVERSE_PORT", cnf.ReversePort).
-		Env("REVERSE_HEALTH", cnf.ReverseHealth).
-		Env("REVERSE_HEALTH_PORT", cnf.ReverseHealthPort).
+		Env("REVERSE_HEALTH", health.uri).
+		Env("REVERSE_HEALTH_PORT", health.port).
+		Env("REVERSE_HEALTH_INTERVAL", health.interval).
+		Env("REVERSE_HEALTH_TIMEOUT", health.timeout).
 		Env("MOUNTPOINT", mountPoint).
 		Restart("unless-stopped").
 		ReadOnly().
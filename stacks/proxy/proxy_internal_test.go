@@ -0,0 +1,67 @@
+package proxy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/the-agent-c-ai/hadron/sdk"
+)
+
+func newBackend(plan *sdk.Plan, check *sdk.HealthCheck) *sdk.Container {
+	host := plan.Host("deploy@10.0.0.6").Build()
+
+	return plan.Container("scim").
+		Host(host).
+		Image("scim:latest").
+		Memory("256m").
+		CPUShares(512).
+		CPUs("0.5").
+		PIDsLimit(100).
+		HealthCheck(check).
+		Build()
+}
+
+func TestBackendHealthFromHTTPCheck(t *testing.T) {
+	t.Parallel()
+
+	plan := sdk.NewPlan("test").WithLogger(zerolog.Nop())
+	backend := newBackend(plan, sdk.HTTPCheck("/healthz", 9000).
+		WithInterval(10*time.Second).
+		WithTimeout(3*time.Second))
+
+	health := backendHealth(backend, &Config{})
+
+	want := upstreamHealth{uri: "/healthz", port: "9000", interval: "10s", timeout: "3s"}
+	if health != want {
+		t.Errorf("expected %+v, got %+v", want, health)
+	}
+}
+
+func TestBackendHealthExplicitConfigWins(t *testing.T) {
+	t.Parallel()
+
+	plan := sdk.NewPlan("test").WithLogger(zerolog.Nop())
+	backend := newBackend(plan, sdk.HTTPCheck("/healthz", 9000))
+
+	health := backendHealth(backend, &Config{ReverseHealth: "/ready", ReverseHealthPort: "8081"})
+
+	if health.uri != "/ready" || health.port != "8081" {
+		t.Errorf("expected explicit health path and port, got %+v", health)
+	}
+}
+
+func TestBackendHealthNonHTTPCheck(t *testing.T) {
+	t.Parallel()
+
+	plan := sdk.NewPlan("test").WithLogger(zerolog.Nop())
+	backend := newBackend(plan, sdk.TCPCheck(9000))
+
+	health := backendHealth(backend, &Config{ReverseHealth: "/health", ReverseHealthPort: "9000"})
+
+	want := upstreamHealth{uri: "/health", port: "9000", interval: defaultHealthInterval, timeout: defaultHealthTimeout}
+	if health != want {
+		t.Errorf("expected %+v, got %+v", want, health)
+	}
+}